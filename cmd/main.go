@@ -1,7 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/Gergenus/bookingService/internal/config"
 	"github.com/Gergenus/bookingService/internal/handler"
@@ -17,7 +24,11 @@ import (
 	mid "github.com/labstack/echo/v4/middleware"
 )
 
+const shutdownTimeout = 10 * time.Second
+
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 	cfg := config.InitConfig()
 	db := db.InitDB(cfg.PostgresURL)
 	redisDB := redispkg.InitRedisDB(cfg.RedisAddress, cfg.RedisPassword, cfg.RedisDB)
@@ -26,38 +37,112 @@ func main() {
 	JWT := jwtpkg.NewUserJWTpkg(cfg.JWTSecret, cfg.AccessTTL)
 	middle := middleware.NewJWTMiddleware(JWT)
 
-	miniRepo := repository.NewMinioImageRepository(miniClient, cfg.MinioBucket, cfg.MinioEndpoint)
+	miniRepo := repository.NewMinioImageRepository(miniClient, cfg.MinioBucket, cfg.MinioEndpoint, cfg.ImageURLSecret)
 	postRepo := repository.NewPostgresLabRepository(db)
 	bookRepo := repository.NewPostgresBookingRepository(db)
 	userRepo := repository.NewUserRepository(db, redisDB)
+	projectRepo := repository.NewPostgresProjectRepository(db)
+	groupRepo := repository.NewPostgresGroupRepository(db)
+	certRepo := repository.NewPostgresCertificationRepository(db)
+	notificationRepo := repository.NewPostgresNotificationRepository(db)
+	reportRepo := repository.NewPostgresReportRepository(db)
+	reportStorage := repository.NewMinioReportStorage(miniClient, cfg.MinioBucket)
+	analyticsRepo := repository.NewAnalyticsRepository(db, redisDB)
+	technicianRepo := repository.NewPostgresTechnicianRepository(db)
+	interlockRepo := repository.NewPostgresInterlockRepository(db)
+	displayRepo := repository.NewPostgresDisplayRepository(db)
+	categoryRepo := repository.NewPostgresCategoryRepository(db)
+	suggestionCache := repository.NewRedisSuggestionCache(redisDB)
 
-	equipService := service.NewEquipmentService(log, &postRepo, miniRepo)
-	bookService := service.NewBookingService(&bookRepo, log)
+	equipService := service.NewEquipmentService(log, &postRepo, miniRepo, suggestionCache)
+	bookService := service.NewBookingService(&bookRepo, &projectRepo, &postRepo, &groupRepo, &certRepo, &notificationRepo, miniRepo, log)
 	userService := service.NewUserService(userRepo, log, JWT, cfg.RefreshTTL)
+	projectService := service.NewProjectService(&projectRepo, log)
+	groupService := service.NewGroupService(&groupRepo, log)
+	certService := service.NewCertificationService(&certRepo, log)
+	notificationService := service.NewNotificationService(&notificationRepo, log)
+	reportService := service.NewReportService(&reportRepo, reportStorage, log)
+	analyticsService := service.NewAnalyticsService(analyticsRepo, log)
+	technicianService := service.NewTechnicianService(&technicianRepo, log)
+	interlockService := service.NewInterlockService(&interlockRepo, userRepo, &bookRepo, log)
+	displayService := service.NewDisplayService(&displayRepo, log, cfg.FrontendURL)
+	categoryService := service.NewCategoryService(&categoryRepo, suggestionCache, log)
 
 	equipHandler := handler.NewEquipmentHandler(&equipService)
 	bookHandler := handler.NewBookingHandler(&bookService)
 	userHandler := handler.NewUserHandler(userService, cfg.AdminSecret)
+	projectHandler := handler.NewProjectHandler(&projectService)
+	groupHandler := handler.NewGroupHandler(&groupService)
+	certHandler := handler.NewCertificationHandler(&certService)
+	notificationHandler := handler.NewNotificationHandler(&notificationService)
+
+	go certService.RunExpiryNotifier(ctx)
+	go bookService.RunAllocator(ctx)
+	go reportService.RunJobs(ctx)
+	reportHandler := handler.NewReportHandler(&reportService)
+	analyticsHandler := handler.NewAnalyticsHandler(&analyticsService)
+	technicianHandler := handler.NewTechnicianHandler(&technicianService)
+	interlockHandler := handler.NewInterlockHandler(&interlockService)
+	deviceMiddle := middleware.NewDeviceMiddleware(&interlockService)
+	displayHandler := handler.NewDisplayHandler(&displayService)
+	categoryHandler := handler.NewCategoryHandler(&categoryService)
 
 	e := echo.New()
+	e.JSONSerializer = handler.ZonedJSONSerializer{}
 	e.Use(mid.CORSWithConfig(mid.CORSConfig{
-		AllowOrigins:     []string{"http://localhost:3000"},
-		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept},
+		AllowOrigins: []string{"http://localhost:3000"},
+		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, "If-Match",
+			echo.HeaderIfModifiedSince, "If-None-Match", "If-Range", "Range"},
+		ExposeHeaders:    []string{"ETag", echo.HeaderLastModified, "Accept-Ranges", "Content-Range"},
 		AllowCredentials: true,
 	}))
 	eq := e.Group("/api/v1/equipment", middle.Auth)
 	{
 		eq.POST("/create", equipHandler.CreateEquipment, middle.AdminAuth)
 		eq.GET("", equipHandler.EquipmentByName)
-		eq.PUT("/update", nil, middle.AdminAuth)
+		eq.GET("/browse", equipHandler.BrowseEquipment)
+		eq.GET("/search", equipHandler.SearchEquipment)
+		eq.GET("/suggest", equipHandler.Suggest)
+		eq.GET("/available", equipHandler.AvailableEquipment)
 		eq.DELETE("/:id", equipHandler.DeleteEquipment, middle.AdminAuth)
 		eq.GET("/:id", equipHandler.EquipmentById)
+		eq.PATCH("/:id", equipHandler.UpdateEquipment, middle.AdminAuth)
+		eq.PUT("/:id/status", equipHandler.SetStatus, middle.AdminAuth)
+		eq.GET("/:id/images", equipHandler.Images)
+		eq.POST("/:id/images", equipHandler.AddImage, middle.AdminAuth)
+		eq.PUT("/:id/images/order", equipHandler.ReorderImages, middle.AdminAuth)
+		eq.PATCH("/:id/images/:image_id", equipHandler.UpdateImage, middle.AdminAuth)
+		eq.DELETE("/:id/images/:image_id", equipHandler.DeleteImage, middle.AdminAuth)
+		eq.GET("/:id/rates", projectHandler.Rates)
+		eq.PUT("/:id/rates", projectHandler.SetRate, middle.AdminAuth)
+		eq.GET("/:id/hours", equipHandler.OperatingHours)
+		eq.PUT("/:id/hours", equipHandler.SetOperatingHours, middle.AdminAuth)
+		eq.GET("/:id/form", equipHandler.Form)
+		eq.PUT("/:id/form", equipHandler.SetForm, middle.AdminAuth)
+		eq.PUT("/:id/timezone", equipHandler.SetTimeZone, middle.AdminAuth)
+		eq.PUT("/:id/room", equipHandler.SetRoom, middle.AdminAuth)
+		eq.PUT("/:id/category", equipHandler.SetCategory, middle.AdminAuth)
+		eq.PUT("/:id/tags", equipHandler.SetTags, middle.AdminAuth)
+		eq.GET("/:id/certifications", certHandler.EquipmentCertifications)
+		eq.PUT("/:id/certifications", certHandler.SetEquipmentCertifications, middle.AdminAuth)
+		eq.GET("/:id/preemption", equipHandler.PreemptionRule)
+		eq.PUT("/:id/preemption", equipHandler.SetPreemptionRule, middle.AdminAuth)
+		eq.DELETE("/:id/preemption", equipHandler.DeletePreemptionRule, middle.AdminAuth)
+		eq.PUT("/:id/allocation", equipHandler.SetAllocationMode, middle.AdminAuth)
+		eq.GET("/:id/rounds", bookHandler.Rounds)
+		eq.POST("/:id/rounds", bookHandler.OpenRound, middle.AdminAuth)
+		eq.PUT("/:id/operator", equipHandler.SetRequiresOperator, middle.AdminAuth)
+		eq.GET("/:id/devices", interlockHandler.Devices, middle.AdminAuth)
+		eq.POST("/:id/devices", interlockHandler.CreateDevice, middle.AdminAuth)
+		eq.DELETE("/:id/devices/:deviceId", interlockHandler.RevokeDevice, middle.AdminAuth)
+		eq.GET("/:id/interlock/decisions", interlockHandler.Decisions, middle.AdminAuth)
 	}
 	auth := e.Group("/api/v1/auth")
 	{
 		auth.POST("/register", userHandler.Register)
 		auth.POST("/login", userHandler.Login)
 		auth.POST("/refresh", userHandler.Refresh)
+		auth.PUT("/profile/timezone", userHandler.SetTimeZone, middle.Auth)
 		auth.POST("/logout", nil)
 	}
 	booking := e.Group("/api/v1/booking", middle.Auth, middle.ScientistAuth)
@@ -66,14 +151,136 @@ func main() {
 		booking.DELETE("/:id", bookHandler.DeleteBooking)
 		booking.GET("/:id", bookHandler.Bookings)
 		booking.GET("/scientist", bookHandler.ScientistBookings)
+		booking.POST("/:id/checkin", bookHandler.CheckIn)
+		booking.POST("/:id/checkout", bookHandler.CheckOut)
+		booking.POST("/:id/attachments", bookHandler.AddAttachment)
+		booking.GET("/:id/attachments", bookHandler.Attachments)
+		booking.GET("/:id/attachments/:attachmentId", bookHandler.Attachment)
+		booking.DELETE("/:id/attachments/:attachmentId", bookHandler.DeleteAttachment)
+		booking.GET("/:id/history", bookHandler.History)
+		booking.POST("/:id/transfer", bookHandler.ProposeTransfer)
+		booking.GET("/transfers/incoming", bookHandler.IncomingTransfers)
+		booking.POST("/transfers/:transferId/accept", bookHandler.AcceptTransfer)
+		booking.POST("/transfers/:transferId/decline", bookHandler.DeclineTransfer)
+		booking.DELETE("/transfers/:transferId", bookHandler.CancelTransfer)
+		booking.GET("/offers", bookHandler.Offers)
+		booking.POST("/offers/:offerId/accept", bookHandler.AcceptOffer)
+		booking.POST("/offers/:offerId/decline", bookHandler.DeclineOffer)
+		booking.POST("/rounds/:roundId/requests", bookHandler.SubmitRequest)
+		booking.GET("/rounds/:roundId/results", bookHandler.RoundResults)
+		booking.GET("/requests", bookHandler.MyRequests)
+		booking.DELETE("/requests/:requestId", bookHandler.WithdrawRequest)
+		booking.GET("/waitlist", bookHandler.Waitlist)
+		booking.DELETE("/waitlist/:entryId", bookHandler.CancelWaitlistEntry)
+	}
+	adminBooking := e.Group("/api/v1/admin/bookings", middle.Auth, middle.AdminAuth)
+	{
+		adminBooking.GET("", bookHandler.AdminBookings)
+		adminBooking.POST("", bookHandler.AdminCreateBooking)
+		adminBooking.GET("/:id", bookHandler.AdminBooking)
+		adminBooking.GET("/:id/history", bookHandler.AdminHistory)
+		adminBooking.POST("/:id/cancel", bookHandler.AdminCancelBooking)
+		adminBooking.POST("/:id/move", bookHandler.MoveBooking)
+	}
+	adminUsers := e.Group("/api/v1/admin/users", middle.Auth, middle.AdminAuth)
+	{
+		adminUsers.PUT("/:uid/badge", userHandler.SetBadge)
+	}
+	interlock := e.Group("/api/v1/interlock", deviceMiddle.DeviceAuth)
+	{
+		interlock.GET("/status", interlockHandler.Status)
+		interlock.POST("/verify", interlockHandler.Verify)
+	}
+	categories := e.Group("/api/v1/categories", middle.Auth)
+	{
+		categories.GET("", categoryHandler.Categories)
+		categories.POST("", categoryHandler.CreateCategory, middle.AdminAuth)
+		categories.PUT("/:id", categoryHandler.RenameCategory, middle.AdminAuth)
+		categories.DELETE("/:id", categoryHandler.DeleteCategory, middle.AdminAuth)
+	}
+	displays := e.Group("/api/v1/displays", middle.Auth, middle.AdminAuth)
+	{
+		displays.POST("", displayHandler.CreateDisplay)
+		displays.GET("", displayHandler.Displays)
+		displays.DELETE("/:id", displayHandler.RevokeDisplay)
+	}
+	// doorside displays need no login, the token in the path is the credential
+	display := e.Group("/display")
+	{
+		display.GET("/:token", displayHandler.Page)
+		display.GET("/:token/schedule", displayHandler.Schedule)
+	}
+	projects := e.Group("/api/v1/projects", middle.Auth)
+	{
+		projects.POST("", projectHandler.CreateProject, middle.AdminAuth)
+		projects.GET("", projectHandler.Projects, middle.AdminAuth)
+		projects.GET("/my", projectHandler.MyProjects)
+		projects.GET("/charges", projectHandler.Charges, middle.AdminAuth)
+		projects.POST("/:id/members", projectHandler.AddMember, middle.AdminAuth)
+		projects.DELETE("/:id/members/:uid", projectHandler.RemoveMember, middle.AdminAuth)
+		projects.PUT("/:id/priority", projectHandler.SetPriority, middle.AdminAuth)
+		projects.GET("/priorities/roles", projectHandler.RolePriorities, middle.AdminAuth)
+		projects.PUT("/priorities/roles/:role", projectHandler.SetRolePriority, middle.AdminAuth)
+	}
+	groups := e.Group("/api/v1/groups", middle.Auth)
+	{
+		groups.POST("", groupHandler.CreateGroup, middle.AdminAuth)
+		groups.GET("/my", groupHandler.MyGroups)
+		groups.GET("/:id/members", groupHandler.Members)
+		groups.POST("/:id/members", groupHandler.AddMember)
+		groups.DELETE("/:id/members/:uid", groupHandler.RemoveMember)
+		groups.PUT("/:id/quota", groupHandler.SetQuota, middle.AdminAuth)
+		groups.PUT("/:id/share", groupHandler.SetShare, middle.AdminAuth)
+		groups.GET("/:id/usage", groupHandler.Usage)
+	}
+	certs := e.Group("/api/v1/certifications", middle.Auth)
+	{
+		certs.POST("", certHandler.CreateCertification, middle.AdminAuth)
+		certs.GET("", certHandler.Certifications)
+		certs.GET("/my", certHandler.MyCertifications)
+		certs.GET("/users/:uid", certHandler.UserCertifications, middle.AdminAuth)
+		certs.POST("/:id/grants", certHandler.Grant, middle.AdminAuth)
+		certs.DELETE("/:id/grants/:uid", certHandler.Revoke, middle.AdminAuth)
+	}
+	technicians := e.Group("/api/v1/technicians", middle.Auth)
+	{
+		technicians.POST("", technicianHandler.AddTechnician, middle.AdminAuth)
+		technicians.GET("", technicianHandler.Technicians, middle.AdminAuth)
+		technicians.GET("/me/schedule", technicianHandler.MySchedule)
+		technicians.DELETE("/:uid", technicianHandler.RemoveTechnician, middle.AdminAuth)
+		technicians.GET("/:uid/shifts", technicianHandler.Shifts, middle.AdminAuth)
+		technicians.POST("/:uid/shifts", technicianHandler.AddShift, middle.AdminAuth)
+		technicians.DELETE("/:uid/shifts/:shiftId", technicianHandler.DeleteShift, middle.AdminAuth)
+	}
+	notifications := e.Group("/api/v1/notifications", middle.Auth)
+	{
+		notifications.GET("", notificationHandler.Notifications)
+		notifications.POST("/:id/read", notificationHandler.MarkRead)
+	}
+	reports := e.Group("/api/v1/reports", middle.Auth, middle.AdminAuth)
+	{
+		reports.POST("", reportHandler.CreateReport)
+		reports.GET("/:id", reportHandler.Report)
+		reports.GET("/:id/download", reportHandler.Download)
+	}
+	analytics := e.Group("/api/v1/analytics", middle.Auth, middle.AdminAuth)
+	{
+		analytics.GET("/equipment/:id", analyticsHandler.Utilization)
 	}
 	e.GET("/api/v1/images/:image", equipHandler.SignedImageURL)
+	e.HEAD("/api/v1/images/:image", equipHandler.SignedImageURL)
 	e.GET("healthcheck", func(c echo.Context) error {
 		return c.JSON(http.StatusOK, map[string]any{
 			"status": "ok",
 		})
 	})
-	if err := e.Start(":" + cfg.HTTPPort); err != nil {
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		e.Shutdown(shutdownCtx)
+	}()
+	if err := e.Start(":" + cfg.HTTPPort); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		panic(err)
 	}
 }
@@ -23,6 +23,8 @@ type Config struct {
 	RedisPassword        string
 	RedisDB              int
 	AdminSecret          string
+	FrontendURL          string
+	ImageURLSecret       string
 }
 
 func InitConfig() Config {
@@ -42,6 +44,10 @@ func InitConfig() Config {
 	if err != nil {
 		panic(err)
 	}
+	imageURLSecret := os.Getenv("IMAGE_URL_SECRET")
+	if imageURLSecret == "" {
+		panic("IMAGE_URL_SECRET is not set")
+	}
 	return Config{
 		PostgresURL:          os.Getenv("POSTGRES_URL"),
 		LogLevel:             os.Getenv("LOG_LEVEL"),
@@ -57,5 +63,7 @@ func InitConfig() Config {
 		RedisPassword:        os.Getenv("REDIS_PASSWORD"),
 		RedisDB:              redisdb,
 		AdminSecret:          os.Getenv("ADMIN_SECRET"),
+		FrontendURL:          os.Getenv("FRONTEND_URL"),
+		ImageURLSecret:       imageURLSecret,
 	}
 }
@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/google/uuid"
+)
+
+// CreateBookingDTO is a booking request, with preempt a taken slot is claimed from
+// lower priority bookings when the equipment allows it
+type CreateBookingDTO struct {
+	models.Booking
+	Preempt bool `json:"preempt"`
+}
+
+type TransferDTO struct {
+	RecipientId uuid.UUID `json:"recipient_id"`
+}
+
+// ProjectId is optional, by default the booking keeps its project. GroupId is only needed
+// when the recipient is in several groups
+type AcceptTransferDTO struct {
+	ProjectId int `json:"project_id"`
+	GroupId   int `json:"group_id"`
+}
+
+// AdminBookingDTO is a booking made by an admin, user_id is the scientist it is made for
+type AdminBookingDTO struct {
+	models.Booking
+	Override bool   `json:"override"`
+	Reason   string `json:"reason"`
+}
+
+type CancelBookingDTO struct {
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type GrantCertificationDTO struct {
+	UserId    uuid.UUID `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type EquipmentCertificationsDTO struct {
+	CertificationIds []int `json:"certification_ids"`
+}
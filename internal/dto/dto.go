@@ -15,3 +15,84 @@ type LoginDTO struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
+
+type TimeZoneDTO struct {
+	TimeZone string `json:"time_zone"`
+}
+
+type BadgeDTO struct {
+	BadgeId string `json:"badge_id"`
+}
+
+type AllocationModeDTO struct {
+	Mode string `json:"mode"`
+}
+
+type RoomDTO struct {
+	Room string `json:"room"`
+}
+
+type OperatorRequirementDTO struct {
+	Required bool `json:"required"`
+}
+
+type DeviceDTO struct {
+	Name string `json:"name"`
+}
+
+// InterlockVerifyDTO carries either a badge id or a login (email) with its password
+type InterlockVerifyDTO struct {
+	BadgeId  string `json:"badge_id"`
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+// DisplayDTO targets either one equipment or one room
+type DisplayDTO struct {
+	Name        string `json:"name"`
+	EquipmentId *int   `json:"equipment_id"`
+	Room        string `json:"room"`
+}
+
+// EquipmentPatchDTO leaves the fields that are absent unchanged
+type EquipmentPatchDTO struct {
+	EquipmentName *string `json:"equipment_name"`
+	Manufacturer  *string `json:"manufacturer"`
+	Description   *string `json:"description"`
+	Capacity      *int    `json:"capacity"`
+}
+
+type CategoryDTO struct {
+	Name     string `json:"name"`
+	ParentId *int   `json:"parent_id"`
+}
+
+// EquipmentCategoryDTO moves equipment to a category, null leaves it uncategorized
+type EquipmentCategoryDTO struct {
+	CategoryId *int `json:"category_id"`
+}
+
+type TagsDTO struct {
+	Tags []string `json:"tags"`
+}
+
+type EquipmentStatusDTO struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// RetireEquipmentDTO is read from the query or the body of a delete
+type RetireEquipmentDTO struct {
+	CancelBookings bool   `json:"cancel_bookings" query:"cancel_bookings"`
+	Reason         string `json:"reason" query:"reason"`
+}
+
+// EquipmentImagePatchDTO changes the caption if set, primary makes the image the primary one
+type EquipmentImagePatchDTO struct {
+	Caption *string `json:"caption"`
+	Primary bool    `json:"primary"`
+}
+
+type ImageOrderDTO struct {
+	ImageIds []int `json:"image_ids"`
+}
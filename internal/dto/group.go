@@ -0,0 +1,16 @@
+package dto
+
+import "github.com/google/uuid"
+
+type GroupMemberDTO struct {
+	UserId  uuid.UUID `json:"user_id"`
+	IsAdmin bool      `json:"is_admin"`
+}
+
+type GroupQuotaDTO struct {
+	QuotaHours *float64 `json:"quota_hours"`
+}
+
+type GroupShareDTO struct {
+	Share float64 `json:"share"`
+}
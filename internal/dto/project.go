@@ -0,0 +1,11 @@
+package dto
+
+import "github.com/google/uuid"
+
+type ProjectMemberDTO struct {
+	UserId uuid.UUID `json:"user_id"`
+}
+
+type PriorityDTO struct {
+	Priority int `json:"priority"`
+}
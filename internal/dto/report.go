@@ -0,0 +1,9 @@
+package dto
+
+// dates are YYYY-MM-DD, both inclusive
+type ReportDTO struct {
+	Kind   string `json:"kind"`
+	Format string `json:"format"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
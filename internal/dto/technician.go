@@ -0,0 +1,7 @@
+package dto
+
+import "github.com/google/uuid"
+
+type TechnicianDTO struct {
+	UserId uuid.UUID `json:"user_id"`
+}
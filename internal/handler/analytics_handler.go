@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type AnalyticsHandler struct {
+	srv service.AnalyticsServiceInterface
+}
+
+func NewAnalyticsHandler(srv service.AnalyticsServiceInterface) AnalyticsHandler {
+	return AnalyticsHandler{srv: srv}
+}
+
+// query: from=2025-01-01&to=2025-01-31, both inclusive
+func (a *AnalyticsHandler) Utilization(c echo.Context) error {
+	equipmentId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	from, err := time.Parse(time.DateOnly, c.QueryParam("from"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid from date",
+		})
+	}
+	to, err := time.Parse(time.DateOnly, c.QueryParam("to"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid to date",
+		})
+	}
+	utilization, err := a.srv.Utilization(c.Request().Context(), equipmentId, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPeriod) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid period",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, utilization)
+}
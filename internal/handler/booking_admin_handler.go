@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Gergenus/bookingService/internal/dto"
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/service"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+func adminBookingError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrBookingNotFound):
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "booking not found",
+		})
+	case errors.Is(err, service.ErrReasonRequired):
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "reason required",
+		})
+	case errors.Is(err, service.ErrInvalidInterval):
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid interval",
+		})
+	}
+	return createBookingError(c, err)
+}
+
+// AdminBookings searches all bookings, see parseBookingFilter for the query parameters
+func (b *BookingHandler) AdminBookings(c echo.Context) error {
+	filter, err := parseBookingFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": err.Error(),
+		})
+	}
+	bookings, err := b.bookingService.AdminBookings(c.Request().Context(), filter)
+	if err != nil {
+		return bookingListError(c, err)
+	}
+	return c.JSON(http.StatusOK, bookings)
+}
+
+func (b *BookingHandler) AdminBooking(c echo.Context) error {
+	bookIdInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	booking, err := b.bookingService.Booking(c.Request().Context(), bookIdInt)
+	if err != nil {
+		return adminBookingError(c, err)
+	}
+	return c.JSON(http.StatusOK, booking)
+}
+
+func (b *BookingHandler) AdminHistory(c echo.Context) error {
+	bookIdInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	events, err := b.bookingService.AdminHistory(c.Request().Context(), bookIdInt)
+	if err != nil {
+		return adminBookingError(c, err)
+	}
+	return c.JSON(http.StatusOK, pagination.All(events))
+}
+
+func (b *BookingHandler) AdminCreateBooking(c echo.Context) error {
+	var req dto.AdminBookingDTO
+	err := c.Bind(&req)
+	if err != nil || req.UserId == uuid.Nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	id, err := b.bookingService.AdminCreateBooking(c.Request().Context(), req.Booking, uuid.MustParse(uid), req.Override, req.Reason)
+	if err != nil {
+		return adminBookingError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"id": id,
+	})
+}
+
+func (b *BookingHandler) AdminCancelBooking(c echo.Context) error {
+	bookIdInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	var req dto.CancelBookingDTO
+	err = c.Bind(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	err = b.bookingService.AdminCancelBooking(c.Request().Context(), bookIdInt, uuid.MustParse(uid), req.Reason)
+	if err != nil {
+		return adminBookingError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (b *BookingHandler) MoveBooking(c echo.Context) error {
+	bookIdInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	var move models.BookingMove
+	err = c.Bind(&move)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	move.BookingId = bookIdInt
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	err = b.bookingService.MoveBooking(c.Request().Context(), move, uuid.MustParse(uid))
+	if err != nil {
+		return adminBookingError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
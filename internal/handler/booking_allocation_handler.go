@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/service"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+func allocationError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidRound):
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid allocation round",
+		})
+	case errors.Is(err, service.ErrInvalidInterval):
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "interval is outside of the round's period",
+		})
+	case errors.Is(err, service.ErrNotLotteryEquipment):
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "equipment is not in lottery mode",
+		})
+	case errors.Is(err, service.ErrEquipmentNotFound):
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "equipment not found",
+		})
+	case errors.Is(err, service.ErrRoundNotFound):
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "allocation round not found",
+		})
+	case errors.Is(err, service.ErrRoundClosed):
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "allocation round is closed",
+		})
+	case errors.Is(err, service.ErrRoundNotAllocated):
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "allocation results are not published yet",
+		})
+	case errors.Is(err, service.ErrRequestNotFound):
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "allocation request not found",
+		})
+	case errors.Is(err, service.ErrWaitlistNotFound):
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "waitlist entry not found",
+		})
+	}
+	return createBookingError(c, err)
+}
+
+func (b *BookingHandler) OpenRound(c echo.Context) error {
+	equipmentId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	var round models.AllocationRound
+	err = c.Bind(&round)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	round.EquipmentId = equipmentId
+	id, err := b.bookingService.OpenRound(c.Request().Context(), round)
+	if err != nil {
+		return allocationError(c, err)
+	}
+	return c.JSON(http.StatusCreated, map[string]any{
+		"id": id,
+	})
+}
+
+func (b *BookingHandler) Rounds(c echo.Context) error {
+	equipmentId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	rounds, err := b.bookingService.Rounds(c.Request().Context(), equipmentId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, pagination.All(rounds))
+}
+
+func (b *BookingHandler) SubmitRequest(c echo.Context) error {
+	roundId, err := strconv.Atoi(c.Param("roundId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	var req models.AllocationRequest
+	err = c.Bind(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	req.RoundId, req.UserId = roundId, uuid.MustParse(uid)
+	id, err := b.bookingService.SubmitRequest(c.Request().Context(), req)
+	if err != nil {
+		return allocationError(c, err)
+	}
+	return c.JSON(http.StatusCreated, map[string]any{
+		"id": id,
+	})
+}
+
+func (b *BookingHandler) RoundResults(c echo.Context) error {
+	roundId, err := strconv.Atoi(c.Param("roundId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	results, err := b.bookingService.RoundResults(c.Request().Context(), roundId, uuid.MustParse(uid))
+	if err != nil {
+		return allocationError(c, err)
+	}
+	return c.JSON(http.StatusOK, results)
+}
+
+func (b *BookingHandler) MyRequests(c echo.Context) error {
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	requests, err := b.bookingService.MyRequests(c.Request().Context(), uuid.MustParse(uid))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, pagination.All(requests))
+}
+
+func (b *BookingHandler) WithdrawRequest(c echo.Context) error {
+	requestId, err := strconv.Atoi(c.Param("requestId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	err = b.bookingService.WithdrawRequest(c.Request().Context(), requestId, uuid.MustParse(uid))
+	if err != nil {
+		return allocationError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (b *BookingHandler) Waitlist(c echo.Context) error {
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	entries, err := b.bookingService.Waitlist(c.Request().Context(), uuid.MustParse(uid))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, pagination.All(entries))
+}
+
+func (b *BookingHandler) CancelWaitlistEntry(c echo.Context) error {
+	entryId, err := strconv.Atoi(c.Param("entryId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	err = b.bookingService.CancelWaitlistEntry(c.Request().Context(), entryId, uuid.MustParse(uid))
+	if err != nil {
+		return allocationError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
@@ -1,12 +1,18 @@
 package handler
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/Gergenus/bookingService/internal/dto"
 	"github.com/Gergenus/bookingService/internal/models"
 	"github.com/Gergenus/bookingService/internal/service"
+	"github.com/Gergenus/bookingService/pkg/pagination"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
@@ -20,48 +26,113 @@ func NewBookingHandler(bookingService service.BookingServiceInterface) BookingHa
 }
 
 func (b *BookingHandler) ScientistBookings(c echo.Context) error {
-	uid := c.Get("uuid")
-	uuid, ok := uid.(string)
+	uid, ok := c.Get("uuid").(string)
 	if !ok {
 		return c.JSON(http.StatusInternalServerError, map[string]any{
 			"error": "internal error",
 		})
 	}
-	booking, err := b.bookingService.ScientistBookings(c.Request().Context(), uuid)
+	filter, err := parseBookingFilter(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]any{
-			"error": "internal error",
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": err.Error(),
 		})
 	}
-	return c.JSON(http.StatusOK, booking)
+	bookings, err := b.bookingService.ScientistBookings(c.Request().Context(), uuid.MustParse(uid), filter)
+	if err != nil {
+		return bookingListError(c, err)
+	}
+	return c.JSON(http.StatusOK, bookings)
+}
+
+// parseBookingFilter reads the list query: equipment_id, user_id, status, from and to
+// in RFC3339, sort (asc or desc by start time), cursor and limit
+func parseBookingFilter(c echo.Context) (models.BookingFilter, error) {
+	var (
+		filter models.BookingFilter
+		err    error
+	)
+	if v := c.QueryParam("equipment_id"); v != "" {
+		filter.EquipmentId, err = strconv.Atoi(v)
+		if err != nil {
+			return filter, errors.New("invalid equipment_id")
+		}
+	}
+	if v := c.QueryParam("user_id"); v != "" {
+		uid, err := uuid.Parse(v)
+		if err != nil {
+			return filter, errors.New("invalid user_id")
+		}
+		filter.UserId = &uid
+	}
+	for param, dst := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if v := c.QueryParam(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, errors.New("invalid " + param)
+			}
+			*dst = &t
+		}
+	}
+	filter.Status = c.QueryParam("status")
+	switch c.QueryParam("sort") {
+	case "", "asc":
+	case "desc":
+		filter.Desc = true
+	default:
+		return filter, errors.New("invalid sort")
+	}
+	if v := c.QueryParam("cursor"); v != "" {
+		var cursor models.BookingCursor
+		err = pagination.DecodeCursor(v, &cursor)
+		if err != nil {
+			return filter, err
+		}
+		filter.After = &cursor
+	}
+	filter.Limit, err = pagination.ParseLimit(c.QueryParam("limit"))
+	if err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+func bookingListError(c echo.Context, err error) error {
+	if errors.Is(err, service.ErrInvalidFilter) {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid filter",
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]any{
+		"error": "internal error",
+	})
 }
 
 func (b *BookingHandler) Createbooking(c echo.Context) error {
-	var booking models.Booking
-	err := c.Bind(&booking)
+	var req dto.CreateBookingDTO
+	err := c.Bind(&req)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]any{
 			"error": "invalid payload",
 		})
 	}
-	uid, ok := c.Get("uuid").(string)
+	self, sysAdmin, ok := actor(c)
 	if !ok {
 		return c.JSON(http.StatusUnauthorized, map[string]any{
 			"error": "uuid not found",
 		})
 	}
-	booking.UserId = uuid.MustParse(uid)
-	// TODO: Добавить замену uid из middleware
-	id, err := b.bookingService.CreateBooking(c.Request().Context(), booking)
+	booking := req.Booking
+	// a user_id other than the caller's own means a PI books on behalf of a group member
+	if booking.UserId != uuid.Nil && booking.UserId != self {
+		booking.BookedBy = &self
+	} else {
+		booking.UserId = self
+		booking.BookedBy = nil
+	}
+	id, err := b.bookingService.CreateBooking(c.Request().Context(), booking, req.Preempt, sysAdmin)
 	if err != nil {
-		if errors.Is(err, service.ErrIntervalInterception) {
-			return c.JSON(http.StatusBadRequest, map[string]any{
-				"error": "interval interception",
-			})
-		}
-		return c.JSON(http.StatusInternalServerError, map[string]any{
-			"error": "internal error",
-		})
+		return createBookingError(c, err)
 	}
 	return c.JSON(http.StatusOK, map[string]any{
 		"id": id,
@@ -76,12 +147,23 @@ func (b *BookingHandler) Bookings(c echo.Context) error {
 			"error": "invalid payload",
 		})
 	}
-	bookings, err := b.bookingService.Bookings(c.Request().Context(), eqIdInt)
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	filter, err := parseBookingFilter(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]any{
-			"error": "internal error",
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": err.Error(),
 		})
 	}
+	filter.EquipmentId = eqIdInt
+	bookings, err := b.bookingService.Bookings(c.Request().Context(), filter, uuid.MustParse(uid))
+	if err != nil {
+		return bookingListError(c, err)
+	}
 	return c.JSON(http.StatusOK, bookings)
 }
 
@@ -93,7 +175,7 @@ func (b *BookingHandler) DeleteBooking(c echo.Context) error {
 			"error": "invalid payload",
 		})
 	}
-	uuid, ok := c.Get("uuid").(string)
+	uid, ok := c.Get("uuid").(string)
 	if !ok {
 		return c.JSON(http.StatusUnauthorized, map[string]any{
 			"error": "uuid not found",
@@ -101,18 +183,24 @@ func (b *BookingHandler) DeleteBooking(c echo.Context) error {
 	}
 	booking, err := b.bookingService.Booking(c.Request().Context(), bookIdInt)
 	if err != nil {
+		if errors.Is(err, service.ErrBookingNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]any{
+				"error": "booking not found",
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]any{
 			"error": "internal error",
 		})
 	}
 
-	if booking.UserId.String() != uuid {
+	// ownership follows accepted transfers, the booking row always holds the current owner
+	if booking.UserId.String() != uid {
 		return c.JSON(http.StatusForbidden, map[string]any{
 			"error": "invalid owner",
 		})
 	}
 
-	err = b.bookingService.DeleteBooking(c.Request().Context(), bookIdInt)
+	err = b.bookingService.DeleteBooking(c.Request().Context(), bookIdInt, booking.UserId)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]any{
 			"error": "internal error",
@@ -122,3 +210,458 @@ func (b *BookingHandler) DeleteBooking(c echo.Context) error {
 		"message": "success",
 	})
 }
+
+func (b *BookingHandler) CheckIn(c echo.Context) error {
+	return b.checkInOut(c, b.bookingService.CheckIn)
+}
+
+func (b *BookingHandler) CheckOut(c echo.Context) error {
+	return b.checkInOut(c, b.bookingService.CheckOut)
+}
+
+func (b *BookingHandler) checkInOut(c echo.Context, action func(ctx context.Context, bookingId int, uid uuid.UUID) error) error {
+	bookIdInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	err = action(c.Request().Context(), bookIdInt, uuid.MustParse(uid))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrBookingNotFound):
+			return c.JSON(http.StatusNotFound, map[string]any{
+				"error": "booking not found",
+			})
+		case errors.Is(err, service.ErrInvalidOwner):
+			return c.JSON(http.StatusForbidden, map[string]any{
+				"error": "invalid owner",
+			})
+		case errors.Is(err, service.ErrCheckInWindow):
+			return c.JSON(http.StatusConflict, map[string]any{
+				"error": "booking is not open for check-in",
+			})
+		case errors.Is(err, service.ErrAlreadyCheckedIn):
+			return c.JSON(http.StatusConflict, map[string]any{
+				"error": "already checked in",
+			})
+		case errors.Is(err, service.ErrNotCheckedIn):
+			return c.JSON(http.StatusConflict, map[string]any{
+				"error": "not checked in",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (b *BookingHandler) AddAttachment(c echo.Context) error {
+	bookIdInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid request",
+		})
+	}
+	id, err := b.bookingService.AddAttachment(c.Request().Context(), bookIdInt, uuid.MustParse(uid), file)
+	if err != nil {
+		return bookingAccessError(c, err)
+	}
+	return c.JSON(http.StatusCreated, map[string]any{
+		"id": id,
+	})
+}
+
+func (b *BookingHandler) Attachments(c echo.Context) error {
+	bookIdInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	attachments, err := b.bookingService.Attachments(c.Request().Context(), bookIdInt, uuid.MustParse(uid))
+	if err != nil {
+		return bookingAccessError(c, err)
+	}
+	return c.JSON(http.StatusOK, pagination.All(attachments))
+}
+
+func (b *BookingHandler) Attachment(c echo.Context) error {
+	bookIdInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	attachmentId, err := strconv.Atoi(c.Param("attachmentId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	attachment, obj, err := b.bookingService.Attachment(c.Request().Context(), bookIdInt, attachmentId, uuid.MustParse(uid))
+	if err != nil {
+		return bookingAccessError(c, err)
+	}
+	defer obj.Close()
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", attachment.FileName))
+	c.Response().Header().Set(echo.HeaderContentType, attachment.ContentType)
+	// attachments are uploaded by users, browsers must not render them as anything else
+	c.Response().Header().Set(echo.HeaderXContentTypeOptions, "nosniff")
+	c.Response().Header().Set(echo.HeaderContentSecurityPolicy, "sandbox")
+	c.Response().WriteHeader(http.StatusOK)
+	_, err = io.Copy(c.Response().Writer, obj)
+	return err
+}
+
+func (b *BookingHandler) DeleteAttachment(c echo.Context) error {
+	bookIdInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	attachmentId, err := strconv.Atoi(c.Param("attachmentId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	err = b.bookingService.DeleteAttachment(c.Request().Context(), bookIdInt, attachmentId, uuid.MustParse(uid))
+	if err != nil {
+		return bookingAccessError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func bookingAccessError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrBookingNotFound):
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "booking not found",
+		})
+	case errors.Is(err, service.ErrAttachmentNotFound):
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "attachment not found",
+		})
+	case errors.Is(err, service.ErrInvalidOwner):
+		return c.JSON(http.StatusForbidden, map[string]any{
+			"error": "invalid owner",
+		})
+	case errors.Is(err, service.ErrAttachmentTooLarge):
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]any{
+			"error": "attachment too large",
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]any{
+		"error": "internal error",
+	})
+}
+
+func (b *BookingHandler) ProposeTransfer(c echo.Context) error {
+	bookIdInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	var transferDTO dto.TransferDTO
+	err = c.Bind(&transferDTO)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	id, err := b.bookingService.ProposeTransfer(c.Request().Context(), bookIdInt, uuid.MustParse(uid), transferDTO.RecipientId)
+	if err != nil {
+		return transferError(c, err)
+	}
+	return c.JSON(http.StatusCreated, map[string]any{
+		"id": id,
+	})
+}
+
+func (b *BookingHandler) IncomingTransfers(c echo.Context) error {
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	transfers, err := b.bookingService.IncomingTransfers(c.Request().Context(), uuid.MustParse(uid))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, pagination.All(transfers))
+}
+
+func (b *BookingHandler) AcceptTransfer(c echo.Context) error {
+	transferId, err := strconv.Atoi(c.Param("transferId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	var acceptDTO dto.AcceptTransferDTO
+	err = c.Bind(&acceptDTO)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	err = b.bookingService.AcceptTransfer(c.Request().Context(), transferId, uuid.MustParse(uid), acceptDTO.ProjectId, acceptDTO.GroupId)
+	if err != nil {
+		return transferError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (b *BookingHandler) DeclineTransfer(c echo.Context) error {
+	return b.resolveTransfer(c, b.bookingService.DeclineTransfer)
+}
+
+func (b *BookingHandler) CancelTransfer(c echo.Context) error {
+	return b.resolveTransfer(c, b.bookingService.CancelTransfer)
+}
+
+func (b *BookingHandler) resolveTransfer(c echo.Context, action func(ctx context.Context, transferId int, uid uuid.UUID) error) error {
+	transferId, err := strconv.Atoi(c.Param("transferId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	err = action(c.Request().Context(), transferId, uuid.MustParse(uid))
+	if err != nil {
+		return transferError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (b *BookingHandler) History(c echo.Context) error {
+	bookIdInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	events, err := b.bookingService.History(c.Request().Context(), bookIdInt, uuid.MustParse(uid))
+	if err != nil {
+		return bookingAccessError(c, err)
+	}
+	return c.JSON(http.StatusOK, pagination.All(events))
+}
+
+func transferError(c echo.Context, err error) error {
+	var certErr *service.MissingCertificationError
+	switch {
+	case errors.Is(err, service.ErrBookingNotFound), errors.Is(err, service.ErrTransferNotFound):
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "transfer or booking not found",
+		})
+	case errors.Is(err, service.ErrInvalidOwner):
+		return c.JSON(http.StatusForbidden, map[string]any{
+			"error": "invalid owner",
+		})
+	case errors.Is(err, service.ErrNotProjectMember):
+		return c.JSON(http.StatusForbidden, map[string]any{
+			"error": "user is not a project member",
+		})
+	case errors.Is(err, service.ErrInvalidRecipient):
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid recipient",
+		})
+	case errors.Is(err, service.ErrBookingFinished):
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "booking already finished",
+		})
+	case errors.Is(err, service.ErrTransferPending):
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "booking already has a pending transfer",
+		})
+	case errors.Is(err, service.ErrTransferNotPending):
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "transfer is not pending",
+		})
+	case errors.Is(err, service.ErrGroupRequired):
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "group required",
+		})
+	case errors.Is(err, service.ErrNotGroupMember):
+		return c.JSON(http.StatusForbidden, map[string]any{
+			"error": "user is not a group member",
+		})
+	case errors.Is(err, service.ErrQuotaExceeded):
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "group booking quota exceeded",
+		})
+	case errors.As(err, &certErr):
+		names := make([]string, 0, len(certErr.Certifications))
+		for _, cert := range certErr.Certifications {
+			names = append(names, cert.Name)
+		}
+		return c.JSON(http.StatusForbidden, map[string]any{
+			"error":          "certification required",
+			"certifications": names,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]any{
+		"error": "internal error",
+	})
+}
+
+func createBookingError(c echo.Context, err error) error {
+	if errors.Is(err, service.ErrIntervalInterception) {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "interval interception",
+		})
+	}
+	if errors.Is(err, service.ErrAllocationPending) {
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "slot is assigned by an allocation round, submit a request instead",
+		})
+	}
+	if errors.Is(err, service.ErrNoOperatorAvailable) {
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "no technician is available to operate the equipment in this slot",
+		})
+	}
+	if errors.Is(err, service.ErrEquipmentUnavailable) {
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "equipment is not in service",
+		})
+	}
+	if errors.Is(err, service.ErrEquipmentNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "equipment not found",
+		})
+	}
+	if errors.Is(err, service.ErrPreemptionNotAllowed) {
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "slot is taken by bookings that can't be preempted",
+		})
+	}
+	if errors.Is(err, service.ErrProjectRequired) {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "project required",
+		})
+	}
+	if errors.Is(err, service.ErrNotProjectMember) {
+		return c.JSON(http.StatusForbidden, map[string]any{
+			"error": "user is not a project member",
+		})
+	}
+	if errors.Is(err, service.ErrCannotBookOnBehalf) {
+		return c.JSON(http.StatusForbidden, map[string]any{
+			"error": "cannot book on behalf of this user",
+		})
+	}
+	if errors.Is(err, service.ErrGroupRequired) {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "group required",
+		})
+	}
+	if errors.Is(err, service.ErrNotGroupMember) {
+		return c.JSON(http.StatusForbidden, map[string]any{
+			"error": "user is not a group member",
+		})
+	}
+	if errors.Is(err, service.ErrQuotaExceeded) {
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "group booking quota exceeded",
+		})
+	}
+	var certErr *service.MissingCertificationError
+	if errors.As(err, &certErr) {
+		names := make([]string, 0, len(certErr.Certifications))
+		for _, cert := range certErr.Certifications {
+			names = append(names, cert.Name)
+		}
+		return c.JSON(http.StatusForbidden, map[string]any{
+			"error":          "certification required",
+			"certifications": names,
+		})
+	}
+	var fieldErr *service.FieldError
+	if errors.As(err, &fieldErr) {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error":  "invalid metadata",
+			"field":  fieldErr.Field,
+			"reason": fieldErr.Reason,
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]any{
+		"error": "internal error",
+	})
+}
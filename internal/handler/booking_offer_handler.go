@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Gergenus/bookingService/internal/service"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+func offerError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrOfferNotFound), errors.Is(err, service.ErrBookingNotFound):
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "offer not found",
+		})
+	case errors.Is(err, service.ErrOfferNotPending):
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "offer is not pending",
+		})
+	case errors.Is(err, service.ErrOfferExpired):
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "offer expired",
+		})
+	case errors.Is(err, service.ErrIntervalInterception):
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "offered slot is no longer free",
+		})
+	}
+	return createBookingError(c, err)
+}
+
+func (b *BookingHandler) Offers(c echo.Context) error {
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	offers, err := b.bookingService.Offers(c.Request().Context(), uuid.MustParse(uid))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, pagination.All(offers))
+}
+
+func (b *BookingHandler) AcceptOffer(c echo.Context) error {
+	offerId, err := strconv.Atoi(c.Param("offerId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	id, err := b.bookingService.AcceptOffer(c.Request().Context(), offerId, uuid.MustParse(uid))
+	if err != nil {
+		return offerError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"id": id,
+	})
+}
+
+func (b *BookingHandler) DeclineOffer(c echo.Context) error {
+	offerId, err := strconv.Atoi(c.Param("offerId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	err = b.bookingService.DeclineOffer(c.Request().Context(), offerId, uuid.MustParse(uid))
+	if err != nil {
+		return offerError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Gergenus/bookingService/internal/dto"
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/service"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+	"github.com/labstack/echo/v4"
+)
+
+type CategoryHandler struct {
+	srv service.CategoryServiceInterface
+}
+
+func NewCategoryHandler(srv service.CategoryServiceInterface) CategoryHandler {
+	return CategoryHandler{srv: srv}
+}
+
+func categoryError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrCategoryNotFound):
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "category not found",
+		})
+	case errors.Is(err, service.ErrCategoryAlreadyExists):
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "category already exists",
+		})
+	case errors.Is(err, service.ErrCategoryInUse):
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "category has subcategories or equipment",
+		})
+	case errors.Is(err, service.ErrInvalidCategory):
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid category",
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]any{
+		"error": "internal error",
+	})
+}
+
+func (h *CategoryHandler) CreateCategory(c echo.Context) error {
+	var req dto.CategoryDTO
+	err := c.Bind(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	id, err := h.srv.CreateCategory(c.Request().Context(), models.Category{Name: req.Name, ParentId: req.ParentId})
+	if err != nil {
+		return categoryError(c, err)
+	}
+	return c.JSON(http.StatusCreated, map[string]any{
+		"id": id,
+	})
+}
+
+// Categories returns the category tree flattened, parents come before their children
+func (h *CategoryHandler) Categories(c echo.Context) error {
+	categories, err := h.srv.Categories(c.Request().Context())
+	if err != nil {
+		return categoryError(c, err)
+	}
+	return c.JSON(http.StatusOK, pagination.All(categories))
+}
+
+func (h *CategoryHandler) RenameCategory(c echo.Context) error {
+	categoryId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	var req dto.CategoryDTO
+	err = c.Bind(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	err = h.srv.RenameCategory(c.Request().Context(), categoryId, req.Name)
+	if err != nil {
+		return categoryError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (h *CategoryHandler) DeleteCategory(c echo.Context) error {
+	categoryId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	err = h.srv.DeleteCategory(c.Request().Context(), categoryId)
+	if err != nil {
+		return categoryError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
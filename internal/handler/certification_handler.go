@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Gergenus/bookingService/internal/dto"
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/service"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type CertificationHandler struct {
+	srv service.CertificationServiceInterface
+}
+
+func NewCertificationHandler(srv service.CertificationServiceInterface) CertificationHandler {
+	return CertificationHandler{srv: srv}
+}
+
+func certificationError(c echo.Context, err error) error {
+	if errors.Is(err, service.ErrCertificationNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "certification not found",
+		})
+	}
+	if errors.Is(err, service.ErrEquipmentNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "equipment not found",
+		})
+	}
+	if errors.Is(err, service.ErrUserNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "user not found",
+		})
+	}
+	if errors.Is(err, service.ErrExpiryRequired) {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "future expiry date required",
+		})
+	}
+	if errors.Is(err, service.ErrInvalidCertification) {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid certification",
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]any{
+		"error": "internal error",
+	})
+}
+
+func (h *CertificationHandler) CreateCertification(c echo.Context) error {
+	var cert models.Certification
+	err := c.Bind(&cert)
+	if err != nil || cert.Name == "" {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	id, err := h.srv.CreateCertification(c.Request().Context(), cert)
+	if err != nil {
+		if errors.Is(err, service.ErrCertificationAlreadyExists) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "certification already exists",
+			})
+		}
+		return certificationError(c, err)
+	}
+	return c.JSON(http.StatusCreated, map[string]any{
+		"id": id,
+	})
+}
+
+func (h *CertificationHandler) Certifications(c echo.Context) error {
+	certs, err := h.srv.Certifications(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, pagination.All(certs))
+}
+
+func (h *CertificationHandler) MyCertifications(c echo.Context) error {
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	certs, err := h.srv.UserCertifications(c.Request().Context(), uuid.MustParse(uid))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, pagination.All(certs))
+}
+
+func (h *CertificationHandler) UserCertifications(c echo.Context) error {
+	uid, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	certs, err := h.srv.UserCertifications(c.Request().Context(), uid)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, pagination.All(certs))
+}
+
+func (h *CertificationHandler) Grant(c echo.Context) error {
+	certId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	var grant dto.GrantCertificationDTO
+	err = c.Bind(&grant)
+	if err != nil || grant.UserId == uuid.Nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	admin := uuid.MustParse(uid)
+	err = h.srv.Grant(c.Request().Context(), models.UserCertification{UserId: grant.UserId, CertificationId: certId,
+		GrantedBy: &admin, ExpiresAt: grant.ExpiresAt})
+	if err != nil {
+		return certificationError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (h *CertificationHandler) Revoke(c echo.Context) error {
+	certId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	err = h.srv.Revoke(c.Request().Context(), uid, certId)
+	if err != nil {
+		return certificationError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (h *CertificationHandler) SetEquipmentCertifications(c echo.Context) error {
+	equipmentId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	var req dto.EquipmentCertificationsDTO
+	err = c.Bind(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	err = h.srv.SetEquipmentCertifications(c.Request().Context(), equipmentId, req.CertificationIds)
+	if err != nil {
+		return certificationError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (h *CertificationHandler) EquipmentCertifications(c echo.Context) error {
+	equipmentId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	certs, err := h.srv.EquipmentCertifications(c.Request().Context(), equipmentId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, pagination.All(certs))
+}
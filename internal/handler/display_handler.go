@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"github.com/Gergenus/bookingService/internal/dto"
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/service"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+	"github.com/labstack/echo/v4"
+)
+
+// displays poll for a fresh schedule this often, in seconds
+const displayRefresh = 30
+
+//go:embed templates/display.html
+var displayFS embed.FS
+
+var displayTemplate = template.Must(template.ParseFS(displayFS, "templates/display.html"))
+
+type DisplayHandler struct {
+	srv service.DisplayServiceInterface
+}
+
+func NewDisplayHandler(srv service.DisplayServiceInterface) DisplayHandler {
+	return DisplayHandler{srv: srv}
+}
+
+func displayError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidDisplayToken):
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "display not found",
+		})
+	case errors.Is(err, service.ErrDisplayNotFound):
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "display not found",
+		})
+	case errors.Is(err, service.ErrEquipmentNotFound):
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "equipment not found",
+		})
+	case errors.Is(err, service.ErrInvalidDisplay):
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid display, name and either equipment_id or room required",
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]any{
+		"error": "internal error",
+	})
+}
+
+// CreateDisplay registers a doorside display, the token is only returned here
+func (h *DisplayHandler) CreateDisplay(c echo.Context) error {
+	var req dto.DisplayDTO
+	err := c.Bind(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	display, token, err := h.srv.CreateDisplay(c.Request().Context(), models.Display{Name: req.Name,
+		EquipmentId: req.EquipmentId, Room: req.Room})
+	if err != nil {
+		return displayError(c, err)
+	}
+	return c.JSON(http.StatusCreated, map[string]any{
+		"display": display,
+		"token":   token,
+		"path":    "/display/" + token,
+	})
+}
+
+func (h *DisplayHandler) Displays(c echo.Context) error {
+	displays, err := h.srv.Displays(c.Request().Context())
+	if err != nil {
+		return displayError(c, err)
+	}
+	return c.JSON(http.StatusOK, pagination.All(displays))
+}
+
+func (h *DisplayHandler) RevokeDisplay(c echo.Context) error {
+	displayId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	err = h.srv.RevokeDisplay(c.Request().Context(), displayId)
+	if err != nil {
+		return displayError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+// Schedule is the public JSON feed of a display, times are in the display's zone
+func (h *DisplayHandler) Schedule(c echo.Context) error {
+	schedule, err := h.srv.Schedule(c.Request().Context(), c.Param("token"))
+	if err != nil {
+		return displayError(c, err)
+	}
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	c.Set("tz", schedule.TimeZone)
+	return c.JSON(http.StatusOK, schedule)
+}
+
+type displayPage struct {
+	Schedule models.DisplaySchedule
+	QR       template.URL
+	Updated  string
+	Refresh  int
+	Days     []displayDay
+}
+
+type displayDay struct {
+	Label     string
+	Equipment []displayEquipment
+}
+
+type displayEquipment struct {
+	Name  string
+	Slots []displaySlot
+}
+
+type displaySlot struct {
+	From   string
+	To     string
+	Status string
+}
+
+// Page is the public HTML page of a display. It needs no login, the token in the path is the credential
+func (h *DisplayHandler) Page(c echo.Context) error {
+	schedule, err := h.srv.Schedule(c.Request().Context(), c.Param("token"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDisplayToken) {
+			return c.String(http.StatusNotFound, "display not found")
+		}
+		return c.String(http.StatusInternalServerError, "internal error")
+	}
+	png, err := h.srv.BookingQR(*schedule)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "internal error")
+	}
+	page := displayPage{
+		Schedule: *schedule,
+		QR:       template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(png)),
+		Updated:  schedule.GeneratedAt.Format("15:04"),
+		Refresh:  displayRefresh,
+		Days:     displayDays(*schedule),
+	}
+	var buf bytes.Buffer
+	err = displayTemplate.Execute(&buf, page)
+	if err != nil {
+		return c.String(http.StatusInternalServerError, "internal error")
+	}
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	return c.HTMLBlob(http.StatusOK, buf.Bytes())
+}
+
+// displayDays splits the schedule into today and tomorrow, a slot crossing midnight shows on both days
+// clipped to the day
+func displayDays(schedule models.DisplaySchedule) []displayDay {
+	loc := schedule.From.Location()
+	labels := []string{"Today", "Tomorrow"}
+	days := make([]displayDay, 0, len(labels))
+	for i, label := range labels {
+		dayStart := schedule.From.AddDate(0, 0, i)
+		dayEnd := dayStart.AddDate(0, 0, 1)
+		day := displayDay{Label: label + ", " + dayStart.Format("Mon 2 Jan"), Equipment: make([]displayEquipment, 0, len(schedule.Equipment))}
+		for _, eq := range schedule.Equipment {
+			de := displayEquipment{Name: eq.Name}
+			for _, slot := range eq.Slots {
+				if !slot.StartTime.Before(dayEnd) || !slot.EndTime.After(dayStart) {
+					continue
+				}
+				from, to := slot.StartTime.In(loc).Format("15:04"), slot.EndTime.In(loc).Format("15:04")
+				if slot.StartTime.Before(dayStart) {
+					from = "00:00"
+				}
+				if slot.EndTime.After(dayEnd) {
+					to = "24:00"
+				}
+				de.Slots = append(de.Slots, displaySlot{From: from, To: to, Status: slot.Status})
+			}
+			day.Equipment = append(day.Equipment, de)
+		}
+		days = append(days, day)
+	}
+	return days
+}
@@ -0,0 +1,298 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/dto"
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/service"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+func (e *EquipmentHandler) SetCategory(c echo.Context) error {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	var req dto.EquipmentCategoryDTO
+	err = c.Bind(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	err = e.srv.SetCategory(c.Request().Context(), idInt, req.CategoryId)
+	if err != nil {
+		if errors.Is(err, service.ErrEquipmentNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]any{
+				"error": "equipment not found",
+			})
+		}
+		if errors.Is(err, service.ErrCategoryNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]any{
+				"error": "category not found",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (e *EquipmentHandler) SetTags(c echo.Context) error {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	var req dto.TagsDTO
+	err = c.Bind(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	err = e.srv.SetTags(c.Request().Context(), idInt, req.Tags)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTags) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid tags",
+			})
+		}
+		if errors.Is(err, service.ErrEquipmentNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]any{
+				"error": "equipment not found",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+// BrowseEquipment lists equipment with facet counts for filter sidebars.
+// query: category, tag (repeatable, all have to match), manufacturer, room (the location), cursor, limit
+func (e *EquipmentHandler) BrowseEquipment(c echo.Context) error {
+	filter := models.EquipmentFilter{
+		Tags:         c.QueryParams()["tag"],
+		Manufacturer: c.QueryParam("manufacturer"),
+		Room:         c.QueryParam("room"),
+	}
+	var err error
+	if v := c.QueryParam("category"); v != "" {
+		filter.CategoryId, err = strconv.Atoi(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid category",
+			})
+		}
+	}
+	if v := c.QueryParam("cursor"); v != "" {
+		var cursor models.EquipmentCursor
+		err = pagination.DecodeCursor(v, &cursor)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": err.Error(),
+			})
+		}
+		filter.After = &cursor
+	}
+	filter.Limit, err = pagination.ParseLimit(c.QueryParam("limit"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": err.Error(),
+		})
+	}
+	result, err := e.srv.BrowseEquipment(c.Request().Context(), filter)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidFilter) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid filter",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// SearchEquipment is a ranked full-text search with highlighted snippets.
+// query: q (web search syntax), cursor, limit
+func (e *EquipmentHandler) SearchEquipment(c echo.Context) error {
+	var after *models.EquipmentSearchCursor
+	if v := c.QueryParam("cursor"); v != "" {
+		var cursor models.EquipmentSearchCursor
+		err := pagination.DecodeCursor(v, &cursor)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": err.Error(),
+			})
+		}
+		after = &cursor
+	}
+	limit, err := pagination.ParseLimit(c.QueryParam("limit"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": err.Error(),
+		})
+	}
+	result, err := e.srv.SearchEquipment(c.Request().Context(), c.QueryParam("q"), after, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSearchQuery) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid search query",
+			})
+		}
+		if errors.Is(err, service.ErrInvalidFilter) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid filter",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+const (
+	defaultSuggestions = 10
+	maxSuggestions     = 20
+)
+
+// Suggest autocompletes equipment names, manufacturers and categories.
+// query: q, limit
+func (e *EquipmentHandler) Suggest(c echo.Context) error {
+	limit := defaultSuggestions
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid limit",
+			})
+		}
+		limit = min(n, maxSuggestions)
+	}
+	suggestions, err := e.srv.Suggest(c.Request().Context(), c.QueryParam("q"), limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSearchQuery) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid search query",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, pagination.All(suggestions))
+}
+
+// AvailableEquipment lists the equipment that can be booked for the whole window.
+// query: start, end (RFC3339), q, category, tag (repeatable), manufacturer, room, min_capacity,
+// certified (only equipment the user is certified for), sort (match or name), limit
+func (e *EquipmentHandler) AvailableEquipment(c echo.Context) error {
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	start, err := time.Parse(time.RFC3339, c.QueryParam("start"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid start",
+		})
+	}
+	end, err := time.Parse(time.RFC3339, c.QueryParam("end"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid end",
+		})
+	}
+	filter := models.AvailabilityFilter{
+		EquipmentFilter: models.EquipmentFilter{
+			Tags:         c.QueryParams()["tag"],
+			Manufacturer: c.QueryParam("manufacturer"),
+			Room:         c.QueryParam("room"),
+		},
+		Query:  c.QueryParam("q"),
+		Start:  start,
+		End:    end,
+		UserId: uuid.MustParse(uid),
+	}
+	if v := c.QueryParam("category"); v != "" {
+		filter.CategoryId, err = strconv.Atoi(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid category",
+			})
+		}
+	}
+	if v := c.QueryParam("min_capacity"); v != "" {
+		filter.MinCapacity, err = strconv.Atoi(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid min_capacity",
+			})
+		}
+	}
+	if v := c.QueryParam("certified"); v != "" {
+		filter.CertifiedOnly, err = strconv.ParseBool(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid certified",
+			})
+		}
+	}
+	switch c.QueryParam("sort") {
+	case "", "name":
+	case "match":
+		filter.SortByMatch = true
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid sort",
+		})
+	}
+	filter.Limit, err = pagination.ParseLimit(c.QueryParam("limit"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": err.Error(),
+		})
+	}
+	available, err := e.srv.AvailableEquipment(c.Request().Context(), filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidInterval):
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid interval",
+			})
+		case errors.Is(err, service.ErrInvalidSearchQuery):
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid search query",
+			})
+		case errors.Is(err, service.ErrInvalidFilter):
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid filter",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, pagination.All(available))
+}
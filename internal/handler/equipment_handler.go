@@ -3,15 +3,20 @@ package handler
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Gergenus/bookingService/internal/dto"
 	"github.com/Gergenus/bookingService/internal/models"
 	"github.com/Gergenus/bookingService/internal/service"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
@@ -19,7 +24,7 @@ type EquipmentHandler struct {
 	srv service.EquipmentServiceInterface
 }
 
-// form-file: image
+// form-file: image (repeatable, optional)
 func NewEquipmentHandler(srv service.EquipmentServiceInterface) *EquipmentHandler {
 	return &EquipmentHandler{srv: srv}
 }
@@ -32,104 +37,549 @@ func (e *EquipmentHandler) CreateEquipment(c echo.Context) error {
 			"error": "invalid request",
 		})
 	}
-	image, err := c.FormFile("image")
+	form, err := c.MultipartForm()
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]any{
 			"error": "invalid request",
 		})
 	}
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	part, err := writer.CreateFormFile("file", "photo.jpg")
+	// every image goes to the gallery, the first one is primary and describes the equipment
+	images := form.File["image"]
+	if len(images) > 0 {
+		image := images[0]
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("file", "photo.jpg")
+		if err != nil {
+			fmt.Println(err)
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "form file error",
+			})
+		}
+		file, err := image.Open()
+		if err != nil {
+			fmt.Println(err)
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "form file error",
+			})
+		}
+		defer file.Close()
+
+		_, err = io.Copy(part, file)
+		if err != nil {
+			fmt.Println(err)
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "copying error",
+			})
+		}
+		err = writer.Close()
+		if err != nil {
+			fmt.Println(err)
+			return c.JSON(http.StatusInternalServerError, map[string]any{
+				"error": "internal server error",
+			})
+		}
+		req, err := http.NewRequest("POST", "http://host.docker.internal:5000/describe", body)
+		if err != nil {
+			fmt.Println(err)
+			return c.JSON(http.StatusInternalServerError, map[string]any{
+				"error": "internal server error",
+			})
+		}
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+
+		cl := &http.Client{}
+		resp, err := cl.Do(req)
+		if err != nil {
+			fmt.Println(err)
+			return c.JSON(http.StatusInternalServerError, map[string]any{
+				"error": "internal server error",
+			})
+		}
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			fmt.Println(err)
+			return c.JSON(http.StatusInternalServerError, map[string]any{
+				"error": "internal server error",
+			})
+		}
+		if resp.StatusCode == 400 {
+			fmt.Println(err)
+			return c.JSON(http.StatusInternalServerError, map[string]any{
+				"error": "internal server error",
+			})
+		}
+		desc := dto.MLResponse{}
+		err = json.Unmarshal(data, &desc)
+		if err != nil {
+			fmt.Println(err)
+			return c.JSON(http.StatusInternalServerError, map[string]any{
+				"error": "internal server error",
+			})
+		}
+		eq.Description = desc.Description
+	}
+	id, err := e.srv.CreateEquipment(c.Request().Context(), eq, images)
+	if err != nil {
+		if errors.Is(err, service.ErrTooManyImages) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "too many images",
+			})
+		}
+		if errors.Is(err, service.ErrUnsupportedImage) {
+			return c.JSON(http.StatusUnsupportedMediaType, map[string]any{
+				"error": "image has to be a JPEG, PNG or WebP",
+			})
+		}
+		if errors.Is(err, service.ErrImageTooLarge) {
+			return c.JSON(http.StatusRequestEntityTooLarge, map[string]any{
+				"error": "image too large",
+			})
+		}
+		if errors.Is(err, service.ErrInvalidTimeZone) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid time zone",
+			})
+		}
+		if errors.Is(err, service.ErrInvalidRoom) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid room",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"id": id,
+	})
+}
+
+func (e *EquipmentHandler) DeleteEquipment(c echo.Context) error {
+	id := c.Param("id")
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	var req dto.RetireEquipmentDTO
+	err = c.Bind(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	cancelled, err := e.srv.DeleteEquipment(c.Request().Context(), idInt, uuid.MustParse(uid), req.Reason, req.CancelBookings)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrEquipmentNotFound):
+			return c.JSON(http.StatusNotFound, map[string]any{
+				"error": "equipment not found",
+			})
+		case errors.Is(err, service.ErrEquipmentHasBookings):
+			return c.JSON(http.StatusConflict, map[string]any{
+				"error": "equipment has future bookings, set cancel_bookings to cancel them",
+			})
+		case errors.Is(err, service.ErrInvalidStatus):
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid reason",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message":            "success",
+		"cancelled_bookings": cancelled,
+	})
+}
+
+func (e *EquipmentHandler) SetStatus(c echo.Context) error {
+	idInt, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		fmt.Println(err)
 		return c.JSON(http.StatusBadRequest, map[string]any{
-			"error": "form file error",
+			"error": "bad request",
+		})
+	}
+	var req dto.EquipmentStatusDTO
+	err = c.Bind(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	err = e.srv.SetStatus(c.Request().Context(), idInt, req.Status, req.Reason)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidStatus) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid status",
+			})
+		}
+		if errors.Is(err, service.ErrEquipmentNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]any{
+				"error": "equipment not found",
+			})
+		}
+		if errors.Is(err, service.ErrEquipmentRetired) {
+			return c.JSON(http.StatusConflict, map[string]any{
+				"error": "equipment is retired",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
 		})
 	}
-	file, err := image.Open()
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (e *EquipmentHandler) EquipmentById(c echo.Context) error {
+	id := c.Param("id")
+	idInt, err := strconv.Atoi(id)
 	if err != nil {
-		fmt.Println(err)
 		return c.JSON(http.StatusBadRequest, map[string]any{
-			"error": "form file error",
+			"error": "bad request",
+		})
+	}
+	eq, err := e.srv.Equipment(c.Request().Context(), idInt)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
 		})
 	}
-	defer file.Close()
+	c.Response().Header().Set("ETag", equipmentETag(eq.Version))
+	return c.JSON(http.StatusOK, eq)
+}
+
+func equipmentETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
 
-	_, err = io.Copy(part, file)
+// UpdateEquipment changes any of name, manufacturer, description and capacity and optionally replaces the
+// image. The request has to carry the ETag of the equipment it is based on in If-Match, or * to
+// overwrite any version.
+// body: json, or multipart form with form-file: image
+func (e *EquipmentHandler) UpdateEquipment(c echo.Context) error {
+	idInt, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		fmt.Println(err)
 		return c.JSON(http.StatusBadRequest, map[string]any{
-			"error": "copying error",
+			"error": "bad request",
 		})
 	}
-	err = writer.Close()
+	ifMatch := c.Request().Header.Get("If-Match")
+	if ifMatch == "" {
+		return c.JSON(http.StatusPreconditionRequired, map[string]any{
+			"error": "If-Match header required",
+		})
+	}
+	// If-Match: * applies the update to whatever version is current
+	version := 0
+	if ifMatch != "*" {
+		version, err = strconv.Atoi(strings.Trim(ifMatch, `"`))
+		if err != nil || version <= 0 {
+			return c.JSON(http.StatusPreconditionFailed, map[string]any{
+				"error": "equipment was modified, reload it and retry",
+			})
+		}
+	}
+	var (
+		req   dto.EquipmentPatchDTO
+		image *multipart.FileHeader
+	)
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		form, err := c.MultipartForm()
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid payload",
+			})
+		}
+		req.EquipmentName = formValue(form, "equipment_name")
+		req.Manufacturer = formValue(form, "manufacturer")
+		req.Description = formValue(form, "description")
+		if v := formValue(form, "capacity"); v != nil {
+			capacity, err := strconv.Atoi(*v)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]any{
+					"error": "invalid payload",
+				})
+			}
+			req.Capacity = &capacity
+		}
+		if files := form.File["image"]; len(files) > 0 {
+			image = files[0]
+		}
+	} else {
+		err = c.Bind(&req)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid payload",
+			})
+		}
+	}
+	eq, err := e.srv.UpdateEquipment(c.Request().Context(), idInt, models.EquipmentUpdate{EquipmentName: req.EquipmentName,
+		Manufacturer: req.Manufacturer, Description: req.Description, Capacity: req.Capacity, Version: version}, image)
 	if err != nil {
-		fmt.Println(err)
+		switch {
+		case errors.Is(err, service.ErrEquipmentNotFound):
+			return c.JSON(http.StatusNotFound, map[string]any{
+				"error": "equipment not found",
+			})
+		case errors.Is(err, service.ErrEquipmentModified):
+			return c.JSON(http.StatusPreconditionFailed, map[string]any{
+				"error": "equipment was modified, reload it and retry",
+			})
+		case errors.Is(err, service.ErrEquipmentRetired):
+			return c.JSON(http.StatusConflict, map[string]any{
+				"error": "equipment is retired",
+			})
+		case errors.Is(err, service.ErrUnsupportedImage):
+			return c.JSON(http.StatusUnsupportedMediaType, map[string]any{
+				"error": "image has to be a JPEG, PNG or WebP",
+			})
+		case errors.Is(err, service.ErrImageTooLarge):
+			return c.JSON(http.StatusRequestEntityTooLarge, map[string]any{
+				"error": "image too large",
+			})
+		case errors.Is(err, service.ErrInvalidEquipment):
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid equipment",
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]any{
-			"error": "internal server error",
+			"error": "internal error",
+		})
+	}
+	c.Response().Header().Set("ETag", equipmentETag(eq.Version))
+	return c.JSON(http.StatusOK, eq)
+}
+
+// formValue tells an absent field from an empty one
+func formValue(form *multipart.Form, key string) *string {
+	values, ok := form.Value[key]
+	if !ok || len(values) == 0 {
+		return nil
+	}
+	return &values[0]
+}
+
+func (e *EquipmentHandler) EquipmentByName(c echo.Context) error {
+	name := c.QueryParam("query")
+	if name == "" {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
 		})
 	}
-	req, err := http.NewRequest("POST", "http://host.docker.internal:5000/describe", body)
+	eqs, err := e.srv.EquipmentByName(c.Request().Context(), name)
 	if err != nil {
-		fmt.Println(err)
 		return c.JSON(http.StatusInternalServerError, map[string]any{
-			"error": "internal server error",
+			"error": "internal error",
 		})
 	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return c.JSON(http.StatusOK, pagination.All(eqs))
+}
+
+// SignedImageURL serves an equipment image through a signed link from an equipment response.
+// query: size (thumb, medium or original, the default), expires, sig
+func (e *EquipmentHandler) SignedImageURL(c echo.Context) error {
+	imagePath := c.Param("image")
+	expires, err := strconv.ParseInt(c.QueryParam("expires"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "invalid or expired link"})
+	}
+	obj, info, err := e.srv.Image(c.Request().Context(), imagePath, c.QueryParam("size"), expires, c.QueryParam("sig"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidImageSize) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid size"})
+		}
+		if errors.Is(err, service.ErrInvalidImageSignature) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "invalid or expired link"})
+		}
+		if errors.Is(err, service.ErrImageNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "image not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal error"})
+	}
+	defer obj.Close()
+	contentType := info.ContentType
+	if !strings.HasPrefix(contentType, "image/") {
+		// images uploaded before the type was detected are JPEGs
+		contentType = "image/jpeg"
+	}
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, contentType)
+	if info.ETag != "" {
+		header.Set("ETag", strconv.Quote(info.ETag))
+	}
+	// objects are never rewritten under the same name, so a copy stays valid as long as the link
+	maxAge := max(0, expires-time.Now().Unix())
+	header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", maxAge))
+	// ServeContent answers If-None-Match and If-Modified-Since with 304 and serves byte ranges
+	http.ServeContent(c.Response(), c.Request(), imagePath, info.LastModified, obj)
+	return nil
+}
 
-	cl := &http.Client{}
-	resp, err := cl.Do(req)
+func (e *EquipmentHandler) SetOperatingHours(c echo.Context) error {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	var hours []models.OperatingHours
+	err = c.Bind(&hours)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	err = e.srv.SetOperatingHours(c.Request().Context(), idInt, hours)
 	if err != nil {
-		fmt.Println(err)
+		if errors.Is(err, service.ErrInvalidOperatingHours) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid operating hours",
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]any{
-			"error": "internal server error",
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (e *EquipmentHandler) OperatingHours(c echo.Context) error {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
 		})
 	}
-	data, err := io.ReadAll(resp.Body)
+	hours, err := e.srv.OperatingHours(c.Request().Context(), idInt)
 	if err != nil {
-		fmt.Println(err)
 		return c.JSON(http.StatusInternalServerError, map[string]any{
-			"error": "internal server error",
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, pagination.All(hours))
+}
+
+func (e *EquipmentHandler) SetForm(c echo.Context) error {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
 		})
 	}
-	if resp.StatusCode == 400 {
-		fmt.Println(err)
+	var fields []models.FormField
+	err = c.Bind(&fields)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	err = e.srv.SetForm(c.Request().Context(), idInt, fields)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidForm) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid form",
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]any{
-			"error": "internal server error",
+			"error": "internal error",
 		})
 	}
-	desc := dto.MLResponse{}
-	err = json.Unmarshal(data, &desc)
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (e *EquipmentHandler) Form(c echo.Context) error {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	fields, err := e.srv.Form(c.Request().Context(), idInt)
 	if err != nil {
-		fmt.Println(err)
 		return c.JSON(http.StatusInternalServerError, map[string]any{
-			"error": "internal server error",
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, pagination.All(fields))
+}
+
+func (e *EquipmentHandler) SetTimeZone(c echo.Context) error {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	var req dto.TimeZoneDTO
+	err = c.Bind(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
 		})
 	}
-	eq.Description = desc.Description
-	id, err := e.srv.CreateEquipment(c.Request().Context(), eq, image)
+	err = e.srv.SetTimeZone(c.Request().Context(), idInt, req.TimeZone)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidTimeZone) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid time zone",
+			})
+		}
+		if errors.Is(err, service.ErrEquipmentNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]any{
+				"error": "equipment not found",
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]any{
 			"error": "internal error",
 		})
 	}
 	return c.JSON(http.StatusOK, map[string]any{
-		"id": id,
+		"message": "success",
 	})
 }
 
-func (e *EquipmentHandler) DeleteEquipment(c echo.Context) error {
-	id := c.Param("id")
-	idInt, err := strconv.Atoi(id)
+func (e *EquipmentHandler) SetAllocationMode(c echo.Context) error {
+	idInt, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]any{
 			"error": "bad request",
 		})
 	}
-	err = e.srv.DeleteEquipment(c.Request().Context(), idInt)
+	var req dto.AllocationModeDTO
+	err = c.Bind(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	err = e.srv.SetAllocationMode(c.Request().Context(), idInt, req.Mode)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidAllocationMode) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid allocation mode",
+			})
+		}
+		if errors.Is(err, service.ErrEquipmentNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]any{
+				"error": "equipment not found",
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]any{
 			"error": "internal error",
 		})
@@ -139,46 +589,142 @@ func (e *EquipmentHandler) DeleteEquipment(c echo.Context) error {
 	})
 }
 
-func (e *EquipmentHandler) EquipmentById(c echo.Context) error {
-	id := c.Param("id")
-	idInt, err := strconv.Atoi(id)
+func (e *EquipmentHandler) SetRequiresOperator(c echo.Context) error {
+	idInt, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]any{
 			"error": "bad request",
 		})
 	}
-	eq, err := e.srv.Equipment(c.Request().Context(), idInt)
+	var req dto.OperatorRequirementDTO
+	err = c.Bind(&req)
 	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	err = e.srv.SetRequiresOperator(c.Request().Context(), idInt, req.Required)
+	if err != nil {
+		if errors.Is(err, service.ErrEquipmentNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]any{
+				"error": "equipment not found",
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]any{
 			"error": "internal error",
 		})
 	}
-	return c.JSON(http.StatusOK, eq)
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
 }
 
-func (e *EquipmentHandler) EquipmentByName(c echo.Context) error {
-	name := c.QueryParam("query")
-	if name == "" {
+func (e *EquipmentHandler) SetRoom(c echo.Context) error {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	var req dto.RoomDTO
+	err = c.Bind(&req)
+	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]any{
 			"error": "invalid payload",
 		})
 	}
-	eqs, err := e.srv.EquipmentByName(c.Request().Context(), name)
+	err = e.srv.SetRoom(c.Request().Context(), idInt, req.Room)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidRoom) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid room",
+			})
+		}
+		if errors.Is(err, service.ErrEquipmentNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]any{
+				"error": "equipment not found",
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]any{
 			"error": "internal error",
 		})
 	}
-	return c.JSON(http.StatusOK, eqs)
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
 }
 
-func (e *EquipmentHandler) SignedImageURL(c echo.Context) error {
-	imagePath := c.Param("image")
-	obj, err := e.srv.SignURL(c.Request().Context(), imagePath)
+func preemptionRuleError(c echo.Context, err error) error {
+	if errors.Is(err, service.ErrInvalidPreemptionRule) {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid preemption rule",
+		})
+	}
+	if errors.Is(err, service.ErrEquipmentNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "equipment not found",
+		})
+	}
+	if errors.Is(err, service.ErrPreemptionRuleNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "preemption rule not found",
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]any{
+		"error": "internal error",
+	})
+}
+
+func (e *EquipmentHandler) SetPreemptionRule(c echo.Context) error {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	var rule models.PreemptionRule
+	err = c.Bind(&rule)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	rule.EquipmentId = idInt
+	err = e.srv.SetPreemptionRule(c.Request().Context(), rule)
+	if err != nil {
+		return preemptionRuleError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (e *EquipmentHandler) PreemptionRule(c echo.Context) error {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	rule, err := e.srv.PreemptionRule(c.Request().Context(), idInt)
 	if err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"error": "image not found"})
+		return preemptionRuleError(c, err)
 	}
-	c.Response().Header().Set("Content-Type", "image/jpeg")
-	_, err = io.Copy(c.Response().Writer, obj)
-	return err
+	return c.JSON(http.StatusOK, rule)
+}
+
+func (e *EquipmentHandler) DeletePreemptionRule(c echo.Context) error {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	err = e.srv.DeletePreemptionRule(c.Request().Context(), idInt)
+	if err != nil {
+		return preemptionRuleError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
 }
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Gergenus/bookingService/internal/dto"
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/service"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+	"github.com/labstack/echo/v4"
+)
+
+func equipmentImageError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrEquipmentNotFound):
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "equipment not found",
+		})
+	case errors.Is(err, service.ErrImageNotFound):
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "image not found",
+		})
+	case errors.Is(err, service.ErrTooManyImages):
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "too many images",
+		})
+	case errors.Is(err, service.ErrEquipmentRetired):
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "equipment is retired",
+		})
+	case errors.Is(err, service.ErrInvalidImageOrder):
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "image_ids has to list every image of the equipment once",
+		})
+	case errors.Is(err, service.ErrUnsupportedImage):
+		return c.JSON(http.StatusUnsupportedMediaType, map[string]any{
+			"error": "image has to be a JPEG, PNG or WebP",
+		})
+	case errors.Is(err, service.ErrImageTooLarge):
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]any{
+			"error": "image too large",
+		})
+	case errors.Is(err, service.ErrInvalidCaption):
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid caption",
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]any{
+		"error": "internal error",
+	})
+}
+
+func (e *EquipmentHandler) Images(c echo.Context) error {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	images, err := e.srv.Images(c.Request().Context(), idInt)
+	if err != nil {
+		return equipmentImageError(c, err)
+	}
+	return c.JSON(http.StatusOK, pagination.All(images))
+}
+
+// AddImage appends an image to the gallery.
+// form-file: image, form: caption, primary
+func (e *EquipmentHandler) AddImage(c echo.Context) error {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	file, err := c.FormFile("image")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "image required",
+		})
+	}
+	image := models.EquipmentImage{EquipmentId: idInt, Caption: c.FormValue("caption")}
+	if v := c.FormValue("primary"); v != "" {
+		image.Primary, err = strconv.ParseBool(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid primary",
+			})
+		}
+	}
+	added, err := e.srv.AddImage(c.Request().Context(), image, file)
+	if err != nil {
+		return equipmentImageError(c, err)
+	}
+	return c.JSON(http.StatusCreated, added)
+}
+
+func (e *EquipmentHandler) UpdateImage(c echo.Context) error {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	imageId, err := strconv.Atoi(c.Param("image_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	var req dto.EquipmentImagePatchDTO
+	err = c.Bind(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	image, err := e.srv.UpdateImage(c.Request().Context(), idInt, imageId,
+		models.EquipmentImageUpdate{Caption: req.Caption, Primary: req.Primary})
+	if err != nil {
+		return equipmentImageError(c, err)
+	}
+	return c.JSON(http.StatusOK, image)
+}
+
+func (e *EquipmentHandler) ReorderImages(c echo.Context) error {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	var req dto.ImageOrderDTO
+	err = c.Bind(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	err = e.srv.ReorderImages(c.Request().Context(), idInt, req.ImageIds)
+	if err != nil {
+		return equipmentImageError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (e *EquipmentHandler) DeleteImage(c echo.Context) error {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	imageId, err := strconv.Atoi(c.Param("image_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	err = e.srv.DeleteImage(c.Request().Context(), idInt, imageId)
+	if err != nil {
+		return equipmentImageError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/dto"
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/service"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type GroupHandler struct {
+	srv service.GroupServiceInterface
+}
+
+func NewGroupHandler(srv service.GroupServiceInterface) GroupHandler {
+	return GroupHandler{srv: srv}
+}
+
+// actor returns the caller's uuid and whether they have the admin role
+func actor(c echo.Context) (uuid.UUID, bool, bool) {
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return uuid.Nil, false, false
+	}
+	id, err := uuid.Parse(uid)
+	if err != nil {
+		return uuid.Nil, false, false
+	}
+	role, _ := c.Get("role").(string)
+	return id, role == "admin", true
+}
+
+func groupError(c echo.Context, err error) error {
+	if errors.Is(err, service.ErrGroupNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "group not found",
+		})
+	}
+	if errors.Is(err, service.ErrNotGroupAdmin) {
+		return c.JSON(http.StatusForbidden, map[string]any{
+			"error": "group admin access required",
+		})
+	}
+	if errors.Is(err, service.ErrNotGroupPI) {
+		return c.JSON(http.StatusForbidden, map[string]any{
+			"error": "group PI access required",
+		})
+	}
+	if errors.Is(err, service.ErrUserNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "user not found",
+		})
+	}
+	if errors.Is(err, service.ErrInvalidQuota) {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid quota",
+		})
+	}
+	if errors.Is(err, service.ErrInvalidShare) {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid share",
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]any{
+		"error": "internal error",
+	})
+}
+
+func (g *GroupHandler) CreateGroup(c echo.Context) error {
+	var group models.Group
+	err := c.Bind(&group)
+	if err != nil || group.Name == "" || group.PIId == nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	id, err := g.srv.CreateGroup(c.Request().Context(), group)
+	if err != nil {
+		if errors.Is(err, service.ErrGroupAlreadyExists) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "group already exists",
+			})
+		}
+		return groupError(c, err)
+	}
+	return c.JSON(http.StatusCreated, map[string]any{
+		"id": id,
+	})
+}
+
+func (g *GroupHandler) MyGroups(c echo.Context) error {
+	uid, _, ok := actor(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	groups, err := g.srv.UserGroups(c.Request().Context(), uid)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, pagination.All(groups))
+}
+
+func (g *GroupHandler) Members(c echo.Context) error {
+	groupId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, sysAdmin, ok := actor(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	members, err := g.srv.Members(c.Request().Context(), groupId, uid, sysAdmin)
+	if err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(http.StatusOK, pagination.All(members))
+}
+
+func (g *GroupHandler) AddMember(c echo.Context) error {
+	groupId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	var member dto.GroupMemberDTO
+	err = c.Bind(&member)
+	if err != nil || member.UserId == uuid.Nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, sysAdmin, ok := actor(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	err = g.srv.AddMember(c.Request().Context(), models.GroupMember{GroupId: groupId, UserId: member.UserId, IsAdmin: member.IsAdmin}, uid, sysAdmin)
+	if err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (g *GroupHandler) RemoveMember(c echo.Context) error {
+	groupId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	member, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, sysAdmin, ok := actor(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	err = g.srv.RemoveMember(c.Request().Context(), groupId, member, uid, sysAdmin)
+	if err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (g *GroupHandler) SetQuota(c echo.Context) error {
+	groupId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	var quota dto.GroupQuotaDTO
+	err = c.Bind(&quota)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	err = g.srv.SetQuota(c.Request().Context(), groupId, quota.QuotaHours)
+	if err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (g *GroupHandler) SetShare(c echo.Context) error {
+	groupId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	var share dto.GroupShareDTO
+	err = c.Bind(&share)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	err = g.srv.SetShare(c.Request().Context(), groupId, share.Share)
+	if err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+// Usage expects month in YYYY-MM format, the current month by default
+func (g *GroupHandler) Usage(c echo.Context) error {
+	groupId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	month := time.Now()
+	if m := c.QueryParam("month"); m != "" {
+		month, err = time.Parse("2006-01", m)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid month",
+			})
+		}
+	}
+	uid, sysAdmin, ok := actor(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	usage, err := g.srv.Usage(c.Request().Context(), groupId, month, uid, sysAdmin)
+	if err != nil {
+		return groupError(c, err)
+	}
+	return c.JSON(http.StatusOK, usage)
+}
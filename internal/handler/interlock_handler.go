@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/dto"
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/service"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+	"github.com/labstack/echo/v4"
+)
+
+type InterlockHandler struct {
+	srv service.InterlockServiceInterface
+}
+
+func NewInterlockHandler(srv service.InterlockServiceInterface) InterlockHandler {
+	return InterlockHandler{srv: srv}
+}
+
+func interlockError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrEquipmentNotFound):
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "equipment not found",
+		})
+	case errors.Is(err, service.ErrDeviceNotFound):
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "device not found",
+		})
+	case errors.Is(err, service.ErrInvalidDevice):
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid device",
+		})
+	case errors.Is(err, service.ErrCredentialsRequired):
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "either badge_id or login required",
+		})
+	case errors.Is(err, service.ErrInvalidPeriod):
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid period",
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]any{
+		"error": "internal error",
+	})
+}
+
+// CreateDevice registers an interlock PC, the key is only returned here
+func (h *InterlockHandler) CreateDevice(c echo.Context) error {
+	equipmentId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	var req dto.DeviceDTO
+	err = c.Bind(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	device, key, err := h.srv.CreateDevice(c.Request().Context(), models.Device{EquipmentId: equipmentId, Name: req.Name})
+	if err != nil {
+		return interlockError(c, err)
+	}
+	return c.JSON(http.StatusCreated, map[string]any{
+		"device": device,
+		"key":    key,
+	})
+}
+
+func (h *InterlockHandler) Devices(c echo.Context) error {
+	equipmentId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	devices, err := h.srv.Devices(c.Request().Context(), equipmentId)
+	if err != nil {
+		return interlockError(c, err)
+	}
+	return c.JSON(http.StatusOK, pagination.All(devices))
+}
+
+func (h *InterlockHandler) RevokeDevice(c echo.Context) error {
+	equipmentId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	deviceId, err := strconv.Atoi(c.Param("deviceId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	err = h.srv.RevokeDevice(c.Request().Context(), equipmentId, deviceId)
+	if err != nil {
+		return interlockError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+// Decisions returns the interlock access log of the equipment.
+// query: from and to in RFC3339, by default the past week
+func (h *InterlockHandler) Decisions(c echo.Context) error {
+	equipmentId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "bad request",
+		})
+	}
+	from, to, err := parsePeriod(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": err.Error(),
+		})
+	}
+	if c.QueryParam("from") == "" && c.QueryParam("to") == "" {
+		to = time.Now()
+		from = to.Add(-defaultSchedulePeriod)
+	}
+	decisions, err := h.srv.Decisions(c.Request().Context(), equipmentId, from, to)
+	if err != nil {
+		return interlockError(c, err)
+	}
+	return c.JSON(http.StatusOK, pagination.All(decisions))
+}
+
+// Status answers the device who is allowed to use the equipment now
+func (h *InterlockHandler) Status(c echo.Context) error {
+	device, ok := c.Get("device").(models.Device)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "device not found",
+		})
+	}
+	status, err := h.srv.Status(c.Request().Context(), device)
+	if err != nil {
+		return interlockError(c, err)
+	}
+	return c.JSON(http.StatusOK, status)
+}
+
+// Verify checks a badge or login against the active booking. Denials are regular
+// answers with allowed set to false
+func (h *InterlockHandler) Verify(c echo.Context) error {
+	device, ok := c.Get("device").(models.Device)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "device not found",
+		})
+	}
+	var req dto.InterlockVerifyDTO
+	err := c.Bind(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	decision, err := h.srv.Verify(c.Request().Context(), device, req.BadgeId, req.Login, req.Password)
+	if err != nil {
+		return interlockError(c, err)
+	}
+	return c.JSON(http.StatusOK, decision)
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	locations sync.Map
+)
+
+// ZonedJSONSerializer renders every time in a response in the caller's preferred zone,
+// which the auth middleware puts into the context. Anonymous callers get UTC
+type ZonedJSONSerializer struct {
+	echo.DefaultJSONSerializer
+}
+
+func (z ZonedJSONSerializer) Serialize(c echo.Context, i any, indent string) error {
+	loc := time.UTC
+	if name, ok := c.Get("tz").(string); ok && name != "" {
+		loc = location(name)
+	}
+	if i != nil {
+		i = inLocation(reflect.ValueOf(i), loc).Interface()
+	}
+	return z.DefaultJSONSerializer.Serialize(c, i, indent)
+}
+
+// location caches loaded zones, unknown names fall back to UTC
+func location(name string) *time.Location {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		loc = time.UTC
+	}
+	locations.Store(name, loc)
+	return loc
+}
+
+// inLocation returns a copy of v with every reachable time.Time moved to loc,
+// the handler's values are left untouched
+func inLocation(v reflect.Value, loc *time.Location) reflect.Value {
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == timeType {
+			if t := v.Interface().(time.Time); !t.IsZero() {
+				return reflect.ValueOf(t.In(loc))
+			}
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := range v.NumField() {
+			if f := out.Field(i); f.CanSet() {
+				f.Set(inLocation(v.Field(i), loc))
+			}
+		}
+		return out
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(inLocation(v.Elem(), loc))
+		return out
+	case reflect.Slice:
+		if v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			out.Index(i).Set(inLocation(v.Index(i), loc))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		for i := range v.Len() {
+			out.Index(i).Set(inLocation(v.Index(i), loc))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), inLocation(iter.Value(), loc))
+		}
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(inLocation(v.Elem(), loc))
+		return out
+	}
+	return v
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Gergenus/bookingService/internal/service"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type NotificationHandler struct {
+	srv service.NotificationServiceInterface
+}
+
+func NewNotificationHandler(srv service.NotificationServiceInterface) NotificationHandler {
+	return NotificationHandler{srv: srv}
+}
+
+// Notifications returns the newest notifications first, only unread ones with ?unread=true.
+// Pages are continued with ?cursor=next_cursor
+func (n *NotificationHandler) Notifications(c echo.Context) error {
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	unread, _ := strconv.ParseBool(c.QueryParam("unread"))
+	limit, err := pagination.ParseLimit(c.QueryParam("limit"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": err.Error(),
+		})
+	}
+	notifications, err := n.srv.Notifications(c.Request().Context(), uuid.MustParse(uid), unread, c.QueryParam("cursor"), limit)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid cursor",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, notifications)
+}
+
+func (n *NotificationHandler) MarkRead(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	err = n.srv.MarkRead(c.Request().Context(), id, uuid.MustParse(uid))
+	if err != nil {
+		if errors.Is(err, service.ErrNotificationNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]any{
+				"error": "notification not found",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
@@ -0,0 +1,295 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/dto"
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/service"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type ProjectHandler struct {
+	srv service.ProjectServiceInterface
+}
+
+func NewProjectHandler(srv service.ProjectServiceInterface) ProjectHandler {
+	return ProjectHandler{srv: srv}
+}
+
+func (p *ProjectHandler) CreateProject(c echo.Context) error {
+	var project models.Project
+	err := c.Bind(&project)
+	if err != nil || project.GrantCode == "" || project.Title == "" {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	id, err := p.srv.CreateProject(c.Request().Context(), project)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTier) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid tier",
+			})
+		}
+		if errors.Is(err, service.ErrProjectAlreadyExists) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "project already exists",
+			})
+		}
+		if errors.Is(err, service.ErrInvalidPriority) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid priority",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusCreated, map[string]any{
+		"id": id,
+	})
+}
+
+func (p *ProjectHandler) Projects(c echo.Context) error {
+	projects, err := p.srv.Projects(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, pagination.All(projects))
+}
+
+func (p *ProjectHandler) MyProjects(c echo.Context) error {
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	projects, err := p.srv.UserProjects(c.Request().Context(), uuid.MustParse(uid))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, pagination.All(projects))
+}
+
+func (p *ProjectHandler) AddMember(c echo.Context) error {
+	projectId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	var member dto.ProjectMemberDTO
+	err = c.Bind(&member)
+	if err != nil || member.UserId == uuid.Nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	err = p.srv.AddMember(c.Request().Context(), projectId, member.UserId)
+	if err != nil {
+		if errors.Is(err, service.ErrProjectNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]any{
+				"error": "project or user not found",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (p *ProjectHandler) RemoveMember(c echo.Context) error {
+	projectId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	err = p.srv.RemoveMember(c.Request().Context(), projectId, uid)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (p *ProjectHandler) SetRate(c echo.Context) error {
+	equipmentId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	var rate models.EquipmentRate
+	err = c.Bind(&rate)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	rate.EquipmentId = equipmentId
+	id, err := p.srv.SetRate(c.Request().Context(), rate)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTier) || errors.Is(err, service.ErrInvalidRate) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid rate",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"id": id,
+	})
+}
+
+func (p *ProjectHandler) Rates(c echo.Context) error {
+	equipmentId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	rates, err := p.srv.Rates(c.Request().Context(), equipmentId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, pagination.All(rates))
+}
+
+// query: from=2025-01&to=2025-03 (months, both inclusive), project_id (optional)
+func (p *ProjectHandler) Charges(c echo.Context) error {
+	from, err := time.Parse("2006-01", c.QueryParam("from"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid from month",
+		})
+	}
+	to := from
+	if c.QueryParam("to") != "" {
+		to, err = time.Parse("2006-01", c.QueryParam("to"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid to month",
+			})
+		}
+	}
+	projectId := 0
+	if c.QueryParam("project_id") != "" {
+		projectId, err = strconv.Atoi(c.QueryParam("project_id"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid project_id",
+			})
+		}
+	}
+	charges, err := p.srv.Charges(c.Request().Context(), from, to.AddDate(0, 1, 0), projectId)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPeriod) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid period",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, pagination.All(charges))
+}
+
+func priorityError(c echo.Context, err error) error {
+	if errors.Is(err, service.ErrInvalidPriority) {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid priority",
+		})
+	}
+	if errors.Is(err, service.ErrInvalidRole) {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid role",
+		})
+	}
+	if errors.Is(err, service.ErrProjectNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "project not found",
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]any{
+		"error": "internal error",
+	})
+}
+
+func (p *ProjectHandler) SetPriority(c echo.Context) error {
+	projectId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	var req dto.PriorityDTO
+	err = c.Bind(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	err = p.srv.SetPriority(c.Request().Context(), projectId, req.Priority)
+	if err != nil {
+		return priorityError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (p *ProjectHandler) RolePriorities(c echo.Context) error {
+	priorities, err := p.srv.RolePriorities(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, pagination.All(priorities))
+}
+
+func (p *ProjectHandler) SetRolePriority(c echo.Context) error {
+	var req dto.PriorityDTO
+	err := c.Bind(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	err = p.srv.SetRolePriority(c.Request().Context(), models.RolePriority{Role: c.Param("role"), Priority: req.Priority})
+	if err != nil {
+		return priorityError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/dto"
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/service"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type ReportHandler struct {
+	srv service.ReportServiceInterface
+}
+
+func NewReportHandler(srv service.ReportServiceInterface) ReportHandler {
+	return ReportHandler{srv: srv}
+}
+
+func (r *ReportHandler) CreateReport(c echo.Context) error {
+	var reportDTO dto.ReportDTO
+	err := c.Bind(&reportDTO)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	from, err := time.Parse(time.DateOnly, reportDTO.From)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid from date",
+		})
+	}
+	to, err := time.Parse(time.DateOnly, reportDTO.To)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid to date",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	file, job, err := r.srv.Generate(c.Request().Context(), models.ReportRequest{
+		Kind:   reportDTO.Kind,
+		Format: reportDTO.Format,
+		From:   from,
+		To:     to.AddDate(0, 0, 1),
+		UserId: uuid.MustParse(uid),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidReportKind):
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid report kind",
+			})
+		case errors.Is(err, service.ErrInvalidReportFormat):
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid report format",
+			})
+		case errors.Is(err, service.ErrInvalidPeriod):
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid period",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	if job != nil {
+		return c.JSON(http.StatusAccepted, job)
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", file.Name))
+	return c.Blob(http.StatusOK, file.ContentType, file.Data)
+}
+
+func (r *ReportHandler) Report(c echo.Context) error {
+	id, uid, err := reportIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	job, err := r.srv.Job(c.Request().Context(), id, uid)
+	if err != nil {
+		if errors.Is(err, service.ErrReportNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]any{
+				"error": "report not found",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+func (r *ReportHandler) Download(c echo.Context) error {
+	id, uid, err := reportIds(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	job, obj, err := r.srv.Download(c.Request().Context(), id, uid)
+	if err != nil {
+		if errors.Is(err, service.ErrReportNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]any{
+				"error": "report not found",
+			})
+		}
+		if errors.Is(err, service.ErrReportNotReady) {
+			return c.JSON(http.StatusConflict, map[string]any{
+				"error": "report not ready",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	defer obj.Close()
+	info, err := obj.Stat()
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "report not found",
+		})
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", path.Base(job.ObjectName)))
+	c.Response().Header().Set(echo.HeaderContentType, info.ContentType)
+	c.Response().WriteHeader(http.StatusOK)
+	_, err = io.Copy(c.Response().Writer, obj)
+	return err
+}
+
+func reportIds(c echo.Context) (uuid.UUID, uuid.UUID, error) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return uuid.Nil, uuid.Nil, errors.New("uuid not found")
+	}
+	userId, err := uuid.Parse(uid)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	return id, userId, nil
+}
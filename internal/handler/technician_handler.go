@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/dto"
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/service"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// schedules default to the coming week
+const defaultSchedulePeriod = 7 * 24 * time.Hour
+
+type TechnicianHandler struct {
+	srv service.TechnicianServiceInterface
+}
+
+func NewTechnicianHandler(srv service.TechnicianServiceInterface) TechnicianHandler {
+	return TechnicianHandler{srv: srv}
+}
+
+func technicianError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrTechnicianNotFound):
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "technician not found",
+		})
+	case errors.Is(err, service.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "user not found",
+		})
+	case errors.Is(err, service.ErrShiftNotFound):
+		return c.JSON(http.StatusNotFound, map[string]any{
+			"error": "shift not found",
+		})
+	case errors.Is(err, service.ErrTechnicianAlreadyExists):
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "technician already exists",
+		})
+	case errors.Is(err, service.ErrTechnicianAssigned):
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "technician operates upcoming bookings",
+		})
+	case errors.Is(err, service.ErrShiftOverlap):
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "shift overlaps another shift",
+		})
+	case errors.Is(err, service.ErrShiftHasAssignments):
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": "technician operates upcoming bookings in this shift",
+		})
+	case errors.Is(err, service.ErrInvalidShift):
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid shift",
+		})
+	case errors.Is(err, service.ErrInvalidPeriod):
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid period",
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]any{
+		"error": "internal error",
+	})
+}
+
+// parsePeriod reads from and to in RFC3339, by default the coming week
+func parsePeriod(c echo.Context) (time.Time, time.Time, error) {
+	from, to := time.Now(), time.Time{}
+	var err error
+	if v := c.QueryParam("from"); v != "" {
+		from, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			return from, to, errors.New("invalid from")
+		}
+	}
+	to = from.Add(defaultSchedulePeriod)
+	if v := c.QueryParam("to"); v != "" {
+		to, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			return from, to, errors.New("invalid to")
+		}
+	}
+	return from, to, nil
+}
+
+func (h *TechnicianHandler) AddTechnician(c echo.Context) error {
+	var req dto.TechnicianDTO
+	err := c.Bind(&req)
+	if err != nil || req.UserId == uuid.Nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	err = h.srv.AddTechnician(c.Request().Context(), req.UserId)
+	if err != nil {
+		return technicianError(c, err)
+	}
+	return c.JSON(http.StatusCreated, map[string]any{
+		"message": "success",
+	})
+}
+
+func (h *TechnicianHandler) Technicians(c echo.Context) error {
+	technicians, err := h.srv.Technicians(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, pagination.All(technicians))
+}
+
+func (h *TechnicianHandler) RemoveTechnician(c echo.Context) error {
+	uid, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	err = h.srv.RemoveTechnician(c.Request().Context(), uid)
+	if err != nil {
+		return technicianError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+func (h *TechnicianHandler) AddShift(c echo.Context) error {
+	uid, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	var shift models.Shift
+	err = c.Bind(&shift)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	shift.TechnicianId = uid
+	id, err := h.srv.AddShift(c.Request().Context(), shift)
+	if err != nil {
+		return technicianError(c, err)
+	}
+	return c.JSON(http.StatusCreated, map[string]any{
+		"id": id,
+	})
+}
+
+// query: from and to in RFC3339
+func (h *TechnicianHandler) Shifts(c echo.Context) error {
+	uid, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	from, to, err := parsePeriod(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": err.Error(),
+		})
+	}
+	shifts, err := h.srv.Shifts(c.Request().Context(), uid, from, to)
+	if err != nil {
+		return technicianError(c, err)
+	}
+	return c.JSON(http.StatusOK, pagination.All(shifts))
+}
+
+func (h *TechnicianHandler) DeleteShift(c echo.Context) error {
+	uid, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	shiftId, err := strconv.Atoi(c.Param("shiftId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	err = h.srv.DeleteShift(c.Request().Context(), uid, shiftId)
+	if err != nil {
+		return technicianError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
+
+// MySchedule returns the caller's shifts and the bookings they operate.
+// query: from and to in RFC3339
+func (h *TechnicianHandler) MySchedule(c echo.Context) error {
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	from, to, err := parsePeriod(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": err.Error(),
+		})
+	}
+	schedule, err := h.srv.Schedule(c.Request().Context(), uuid.MustParse(uid), from, to)
+	if err != nil {
+		return technicianError(c, err)
+	}
+	return c.JSON(http.StatusOK, schedule)
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/Gergenus/bookingService/internal/dto"
 	"github.com/Gergenus/bookingService/internal/service"
@@ -150,3 +151,75 @@ func (u *UserHandler) Refresh(c echo.Context) error {
 		"RefreshToken": newRefresh,
 	})
 }
+
+// SetTimeZone updates the preferred zone of the current user, API times are rendered in it.
+// The access token is reissued because it carries the zone
+func (u *UserHandler) SetTimeZone(c echo.Context) error {
+	var req dto.TimeZoneDTO
+	err := c.Bind(&req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	uid, ok := c.Get("uuid").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]any{
+			"error": "uuid not found",
+		})
+	}
+	token, err := u.srv.SetTimeZone(c.Request().Context(), uuid.MustParse(uid), req.TimeZone)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTimeZone) {
+			return c.JSON(http.StatusBadRequest, map[string]any{
+				"error": "invalid time zone",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	err = setCookie(c, "AccessToken", token, AccessTokenDuration)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "internal error")
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"AccessToken": token,
+	})
+}
+
+// SetBadge assigns a badge to a user for instrument interlocks, an empty id removes it
+func (u *UserHandler) SetBadge(c echo.Context) error {
+	uid, err := uuid.Parse(c.Param("uid"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	var req dto.BadgeDTO
+	err = c.Bind(&req)
+	if err != nil || len(req.BadgeId) > 64 {
+		return c.JSON(http.StatusBadRequest, map[string]any{
+			"error": "invalid payload",
+		})
+	}
+	err = u.srv.SetBadge(c.Request().Context(), uid, strings.TrimSpace(req.BadgeId))
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]any{
+				"error": "user not found",
+			})
+		}
+		if errors.Is(err, service.ErrBadgeTaken) {
+			return c.JSON(http.StatusConflict, map[string]any{
+				"error": "badge is assigned to another user",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]any{
+			"error": "internal error",
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "success",
+	})
+}
@@ -1,8 +1,12 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/service"
 	"github.com/Gergenus/bookingService/pkg/jwtpkg"
 	"github.com/labstack/echo/v4"
 )
@@ -36,6 +40,7 @@ func (j *JWTMiddleware) Auth(next echo.HandlerFunc) echo.HandlerFunc {
 		}
 		c.Set("role", claims.Role)
 		c.Set("uuid", claims.UUID)
+		c.Set("tz", claims.TimeZone)
 		return next(c)
 	}
 }
@@ -75,3 +80,41 @@ func (j *JWTMiddleware) ScientistAuth(next echo.HandlerFunc) echo.HandlerFunc {
 		return next(c)
 	}
 }
+
+// DeviceAuthenticator resolves the instrument PC a device key belongs to
+type DeviceAuthenticator interface {
+	AuthenticateDevice(ctx context.Context, key string) (*models.Device, error)
+}
+
+type DeviceMiddleware struct {
+	auth DeviceAuthenticator
+}
+
+func NewDeviceMiddleware(auth DeviceAuthenticator) *DeviceMiddleware {
+	return &DeviceMiddleware{auth: auth}
+}
+
+// DeviceAuth authenticates interlock devices by the X-Device-Key header and sets "device"
+func (d *DeviceMiddleware) DeviceAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		key := c.Request().Header.Get("X-Device-Key")
+		if key == "" {
+			return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+				"error": "No device key",
+			})
+		}
+		device, err := d.auth.AuthenticateDevice(c.Request().Context(), key)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidDeviceKey) {
+				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+					"error": "Invalid device key",
+				})
+			}
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"error": "internal error",
+			})
+		}
+		c.Set("device", *device)
+		return next(c)
+	}
+}
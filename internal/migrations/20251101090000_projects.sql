@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE price_tiers AS ENUM (
+    'internal', 'external'
+);
+
+CREATE TABLE IF NOT EXISTS projects(
+    id SERIAL PRIMARY KEY,
+    grant_code VARCHAR(50) NOT NULL UNIQUE,
+    title VARCHAR(255) NOT NULL,
+    tier price_tiers NOT NULL DEFAULT 'internal'
+);
+
+CREATE TABLE IF NOT EXISTS project_members(
+    project_id int REFERENCES projects(id) ON DELETE CASCADE,
+    user_id uuid REFERENCES users(uid) ON DELETE CASCADE,
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS equipment_rates(
+    id SERIAL PRIMARY KEY,
+    equipment_id int REFERENCES equipment(id) ON DELETE CASCADE,
+    tier price_tiers NOT NULL,
+    peak_rate NUMERIC(10, 2) NOT NULL,
+    off_peak_rate NUMERIC(10, 2) NOT NULL,
+    peak_start SMALLINT NOT NULL DEFAULT 9 CHECK (peak_start BETWEEN 0 AND 24),
+    peak_end SMALLINT NOT NULL DEFAULT 18 CHECK (peak_end BETWEEN 0 AND 24),
+    UNIQUE (equipment_id, tier)
+);
+
+-- project_id stays nullable for bookings created before projects existed,
+-- the service layer requires it for every new booking
+ALTER TABLE booking
+    ADD COLUMN project_id int REFERENCES projects(id),
+    ADD COLUMN checked_in_at TIMESTAMP,
+    ADD COLUMN checked_out_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS booking_project_id_idx ON booking(project_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS booking_project_id_idx;
+ALTER TABLE booking
+    DROP COLUMN IF EXISTS project_id,
+    DROP COLUMN IF EXISTS checked_in_at,
+    DROP COLUMN IF EXISTS checked_out_at;
+DROP TABLE IF EXISTS equipment_rates;
+DROP TABLE IF EXISTS project_members;
+DROP TABLE IF EXISTS projects;
+DROP TYPE IF EXISTS price_tiers;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE report_statuses AS ENUM (
+    'pending', 'ready', 'failed'
+);
+
+CREATE TABLE IF NOT EXISTS report_jobs(
+    id uuid PRIMARY KEY,
+    user_id uuid REFERENCES users(uid) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    date_from TIMESTAMP NOT NULL,
+    date_to TIMESTAMP NOT NULL,
+    status report_statuses NOT NULL DEFAULT 'pending',
+    object_name VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS booking_start_time_idx ON booking(start_time);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS booking_start_time_idx;
+DROP TABLE IF EXISTS report_jobs;
+DROP TYPE IF EXISTS report_statuses;
+-- +goose StatementEnd
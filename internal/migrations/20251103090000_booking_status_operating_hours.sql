@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE booking_statuses AS ENUM (
+    'active', 'cancelled'
+);
+
+ALTER TABLE booking
+    ADD COLUMN status booking_statuses NOT NULL DEFAULT 'active',
+    ADD COLUMN cancelled_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS booking_equipment_start_idx ON booking(equipment_id, start_time);
+
+-- weekday is ISO: 1 = monday, 7 = sunday
+CREATE TABLE IF NOT EXISTS equipment_operating_hours(
+    equipment_id int REFERENCES equipment(id) ON DELETE CASCADE,
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 1 AND 7),
+    open_time TIME NOT NULL,
+    close_time TIME NOT NULL CHECK (close_time > open_time),
+    PRIMARY KEY (equipment_id, weekday)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS equipment_operating_hours;
+DROP INDEX IF EXISTS booking_equipment_start_idx;
+ALTER TABLE booking
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS cancelled_at;
+DROP TYPE IF EXISTS booking_statuses;
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE form_field_types AS ENUM (
+    'text', 'select', 'number', 'checkbox'
+);
+
+CREATE TABLE IF NOT EXISTS equipment_form_fields(
+    id SERIAL PRIMARY KEY,
+    equipment_id int REFERENCES equipment(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    label VARCHAR(255) NOT NULL,
+    field_type form_field_types NOT NULL,
+    required BOOLEAN NOT NULL DEFAULT false,
+    options TEXT[] NOT NULL DEFAULT '{}',
+    position int NOT NULL DEFAULT 0,
+    UNIQUE (equipment_id, name)
+);
+
+ALTER TABLE booking
+    ADD COLUMN notes TEXT NOT NULL DEFAULT '',
+    ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}';
+
+CREATE TABLE IF NOT EXISTS booking_attachments(
+    id SERIAL PRIMARY KEY,
+    booking_id int REFERENCES booking(id) ON DELETE CASCADE,
+    object_name VARCHAR(255) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    uploaded_at TIMESTAMP NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS booking_attachments;
+ALTER TABLE booking
+    DROP COLUMN IF EXISTS notes,
+    DROP COLUMN IF EXISTS metadata;
+DROP TABLE IF EXISTS equipment_form_fields;
+DROP TYPE IF EXISTS form_field_types;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE transfer_statuses AS ENUM (
+    'pending', 'accepted', 'declined', 'cancelled'
+);
+
+CREATE TABLE IF NOT EXISTS booking_transfers(
+    id SERIAL PRIMARY KEY,
+    booking_id int REFERENCES booking(id) ON DELETE CASCADE,
+    from_user uuid REFERENCES users(uid) ON DELETE CASCADE,
+    to_user uuid REFERENCES users(uid) ON DELETE CASCADE,
+    status transfer_statuses NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    resolved_at TIMESTAMP
+);
+
+-- a booking can have only one open transfer at a time
+CREATE UNIQUE INDEX IF NOT EXISTS booking_transfers_pending_idx ON booking_transfers(booking_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS booking_transfers_to_user_idx ON booking_transfers(to_user) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS booking_history(
+    id BIGSERIAL PRIMARY KEY,
+    booking_id int REFERENCES booking(id) ON DELETE CASCADE,
+    action VARCHAR(50) NOT NULL,
+    actor_id uuid REFERENCES users(uid) ON DELETE SET NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS booking_history_booking_id_idx ON booking_history(booking_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS booking_history;
+DROP TABLE IF EXISTS booking_transfers;
+DROP TYPE IF EXISTS transfer_statuses;
+-- +goose StatementEnd
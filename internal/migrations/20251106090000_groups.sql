@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS research_groups(
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    pi_id uuid REFERENCES users(uid) ON DELETE SET NULL,
+    -- booked hours per calendar month, NULL means unlimited
+    quota_hours NUMERIC(10, 2)
+);
+
+CREATE TABLE IF NOT EXISTS group_members(
+    group_id int REFERENCES research_groups(id) ON DELETE CASCADE,
+    user_id uuid REFERENCES users(uid) ON DELETE CASCADE,
+    is_admin BOOLEAN NOT NULL DEFAULT false,
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS group_members_user_id_idx ON group_members(user_id);
+
+ALTER TABLE booking
+    ADD COLUMN group_id int REFERENCES research_groups(id) ON DELETE SET NULL,
+    ADD COLUMN booked_by uuid REFERENCES users(uid) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS booking_group_id_idx ON booking(group_id, start_time);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS booking_group_id_idx;
+ALTER TABLE booking
+    DROP COLUMN IF EXISTS group_id,
+    DROP COLUMN IF EXISTS booked_by;
+DROP TABLE IF EXISTS group_members;
+DROP TABLE IF EXISTS research_groups;
+-- +goose StatementEnd
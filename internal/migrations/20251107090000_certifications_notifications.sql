@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS certifications(
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    -- how long a new grant is valid when the admin doesn't set an explicit expiry
+    validity_days int CHECK (validity_days > 0)
+);
+
+CREATE TABLE IF NOT EXISTS equipment_certifications(
+    equipment_id int REFERENCES equipment(id) ON DELETE CASCADE,
+    certification_id int REFERENCES certifications(id) ON DELETE CASCADE,
+    PRIMARY KEY (equipment_id, certification_id)
+);
+
+CREATE TABLE IF NOT EXISTS user_certifications(
+    user_id uuid REFERENCES users(uid) ON DELETE CASCADE,
+    certification_id int REFERENCES certifications(id) ON DELETE CASCADE,
+    granted_by uuid REFERENCES users(uid) ON DELETE SET NULL,
+    granted_at TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at TIMESTAMP NOT NULL,
+    expiry_notified_at TIMESTAMP,
+    PRIMARY KEY (user_id, certification_id)
+);
+
+CREATE INDEX IF NOT EXISTS user_certifications_expires_at_idx ON user_certifications(expires_at) WHERE expiry_notified_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS notifications(
+    id BIGSERIAL PRIMARY KEY,
+    user_id uuid REFERENCES users(uid) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL,
+    message TEXT NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    read_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_id_idx ON notifications(user_id, id DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS user_certifications;
+DROP TABLE IF EXISTS equipment_certifications;
+DROP TABLE IF EXISTS certifications;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- keyset pagination orders every booking list by (start_time, id)
+DROP INDEX IF EXISTS booking_start_time_idx;
+DROP INDEX IF EXISTS booking_equipment_start_idx;
+CREATE INDEX IF NOT EXISTS booking_start_id_idx ON booking(start_time, id);
+CREATE INDEX IF NOT EXISTS booking_equipment_start_id_idx ON booking(equipment_id, start_time, id);
+CREATE INDEX IF NOT EXISTS booking_user_start_id_idx ON booking(user_id, start_time, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS booking_user_start_id_idx;
+DROP INDEX IF EXISTS booking_equipment_start_id_idx;
+DROP INDEX IF EXISTS booking_start_id_idx;
+CREATE INDEX IF NOT EXISTS booking_equipment_start_idx ON booking(equipment_id, start_time);
+CREATE INDEX IF NOT EXISTS booking_start_time_idx ON booking(start_time);
+-- +goose StatementEnd
@@ -0,0 +1,84 @@
+-- +goose Up
+-- +goose StatementBegin
+-- existing values were written without a zone and are taken as UTC
+ALTER TABLE booking
+    ALTER COLUMN start_time TYPE TIMESTAMPTZ USING start_time AT TIME ZONE 'UTC',
+    ALTER COLUMN end_time TYPE TIMESTAMPTZ USING end_time AT TIME ZONE 'UTC',
+    ALTER COLUMN checked_in_at TYPE TIMESTAMPTZ USING checked_in_at AT TIME ZONE 'UTC',
+    ALTER COLUMN checked_out_at TYPE TIMESTAMPTZ USING checked_out_at AT TIME ZONE 'UTC',
+    ALTER COLUMN cancelled_at TYPE TIMESTAMPTZ USING cancelled_at AT TIME ZONE 'UTC';
+
+ALTER TABLE report_jobs
+    ALTER COLUMN date_from TYPE TIMESTAMPTZ USING date_from AT TIME ZONE 'UTC',
+    ALTER COLUMN date_to TYPE TIMESTAMPTZ USING date_to AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN finished_at TYPE TIMESTAMPTZ USING finished_at AT TIME ZONE 'UTC';
+
+ALTER TABLE booking_attachments
+    ALTER COLUMN uploaded_at TYPE TIMESTAMPTZ USING uploaded_at AT TIME ZONE 'UTC';
+
+ALTER TABLE booking_transfers
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN resolved_at TYPE TIMESTAMPTZ USING resolved_at AT TIME ZONE 'UTC';
+
+ALTER TABLE booking_history
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC';
+
+ALTER TABLE user_certifications
+    ALTER COLUMN granted_at TYPE TIMESTAMPTZ USING granted_at AT TIME ZONE 'UTC',
+    ALTER COLUMN expires_at TYPE TIMESTAMPTZ USING expires_at AT TIME ZONE 'UTC',
+    ALTER COLUMN expiry_notified_at TYPE TIMESTAMPTZ USING expiry_notified_at AT TIME ZONE 'UTC';
+
+ALTER TABLE notifications
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN read_at TYPE TIMESTAMPTZ USING read_at AT TIME ZONE 'UTC';
+
+-- IANA zone the equipment's operating hours and peak windows are defined in
+ALTER TABLE equipment
+    ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- preferred zone for rendering API responses, NULL means UTC
+ALTER TABLE users
+    ADD COLUMN time_zone VARCHAR(64);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN IF EXISTS time_zone;
+
+ALTER TABLE equipment
+    DROP COLUMN IF EXISTS time_zone;
+
+ALTER TABLE booking
+    ALTER COLUMN start_time TYPE TIMESTAMP USING start_time AT TIME ZONE 'UTC',
+    ALTER COLUMN end_time TYPE TIMESTAMP USING end_time AT TIME ZONE 'UTC',
+    ALTER COLUMN checked_in_at TYPE TIMESTAMP USING checked_in_at AT TIME ZONE 'UTC',
+    ALTER COLUMN checked_out_at TYPE TIMESTAMP USING checked_out_at AT TIME ZONE 'UTC',
+    ALTER COLUMN cancelled_at TYPE TIMESTAMP USING cancelled_at AT TIME ZONE 'UTC';
+
+ALTER TABLE report_jobs
+    ALTER COLUMN date_from TYPE TIMESTAMP USING date_from AT TIME ZONE 'UTC',
+    ALTER COLUMN date_to TYPE TIMESTAMP USING date_to AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN finished_at TYPE TIMESTAMP USING finished_at AT TIME ZONE 'UTC';
+
+ALTER TABLE booking_attachments
+    ALTER COLUMN uploaded_at TYPE TIMESTAMP USING uploaded_at AT TIME ZONE 'UTC';
+
+ALTER TABLE booking_transfers
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN resolved_at TYPE TIMESTAMP USING resolved_at AT TIME ZONE 'UTC';
+
+ALTER TABLE booking_history
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC';
+
+ALTER TABLE user_certifications
+    ALTER COLUMN granted_at TYPE TIMESTAMP USING granted_at AT TIME ZONE 'UTC',
+    ALTER COLUMN expires_at TYPE TIMESTAMP USING expires_at AT TIME ZONE 'UTC',
+    ALTER COLUMN expiry_notified_at TYPE TIMESTAMP USING expiry_notified_at AT TIME ZONE 'UTC';
+
+ALTER TABLE notifications
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN read_at TYPE TIMESTAMP USING read_at AT TIME ZONE 'UTC';
+-- +goose StatementEnd
@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE offer_statuses AS ENUM (
+    'pending', 'accepted', 'declined'
+);
+
+-- a booking gets the higher of its project's and its owner's role priority
+ALTER TABLE projects
+    ADD COLUMN priority SMALLINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS role_priorities(
+    role roles PRIMARY KEY,
+    priority SMALLINT NOT NULL DEFAULT 0
+);
+
+ALTER TABLE booking
+    ADD COLUMN priority SMALLINT NOT NULL DEFAULT 0;
+
+-- equipment without a rule never preempts bookings
+CREATE TABLE IF NOT EXISTS preemption_rules(
+    equipment_id int PRIMARY KEY REFERENCES equipment(id) ON DELETE CASCADE,
+    min_priority SMALLINT NOT NULL DEFAULT 0,
+    priority_margin SMALLINT NOT NULL DEFAULT 1 CHECK (priority_margin > 0),
+    min_notice_minutes int NOT NULL DEFAULT 0 CHECK (min_notice_minutes >= 0)
+);
+
+-- alternative slots offered to the owners of preempted bookings
+CREATE TABLE IF NOT EXISTS slot_offers(
+    id SERIAL PRIMARY KEY,
+    booking_id int NOT NULL REFERENCES booking(id) ON DELETE CASCADE,
+    user_id uuid NOT NULL REFERENCES users(uid) ON DELETE CASCADE,
+    equipment_id int NOT NULL REFERENCES equipment(id) ON DELETE CASCADE,
+    start_time TIMESTAMPTZ NOT NULL,
+    end_time TIMESTAMPTZ NOT NULL,
+    status offer_statuses NOT NULL DEFAULT 'pending',
+    new_booking_id int REFERENCES booking(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    resolved_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS slot_offers_user_id_idx ON slot_offers(user_id) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS slot_offers_user_id_idx;
+DROP TABLE IF EXISTS slot_offers;
+DROP TABLE IF EXISTS preemption_rules;
+ALTER TABLE booking
+    DROP COLUMN IF EXISTS priority;
+DROP TABLE IF EXISTS role_priorities;
+ALTER TABLE projects
+    DROP COLUMN IF EXISTS priority;
+DROP TYPE IF EXISTS offer_statuses;
+-- +goose StatementEnd
@@ -0,0 +1,93 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE allocation_modes AS ENUM (
+    'direct', 'lottery'
+);
+
+CREATE TYPE round_statuses AS ENUM (
+    'open', 'allocated'
+);
+
+CREATE TYPE allocation_request_statuses AS ENUM (
+    'pending', 'granted', 'rejected'
+);
+
+CREATE TYPE waitlist_statuses AS ENUM (
+    'waiting', 'cancelled'
+);
+
+-- lottery equipment can only be booked directly outside of allocation rounds
+ALTER TABLE equipment
+    ADD COLUMN allocation_mode allocation_modes NOT NULL DEFAULT 'direct';
+
+-- fair-share weight of the group in allocation rounds
+ALTER TABLE research_groups
+    ADD COLUMN share NUMERIC(8, 2) NOT NULL DEFAULT 1 CHECK (share > 0);
+
+CREATE TABLE IF NOT EXISTS allocation_rounds(
+    id SERIAL PRIMARY KEY,
+    equipment_id int NOT NULL REFERENCES equipment(id) ON DELETE CASCADE,
+    period_start TIMESTAMPTZ NOT NULL,
+    period_end TIMESTAMPTZ NOT NULL,
+    closes_at TIMESTAMPTZ NOT NULL,
+    status round_statuses NOT NULL DEFAULT 'open',
+    -- the lottery seed is kept so an allocation can be reproduced
+    seed BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    allocated_at TIMESTAMPTZ,
+    CHECK (period_start < period_end AND closes_at <= period_start)
+);
+
+CREATE INDEX IF NOT EXISTS allocation_rounds_equipment_id_idx ON allocation_rounds(equipment_id, period_start);
+CREATE INDEX IF NOT EXISTS allocation_rounds_open_idx ON allocation_rounds(closes_at) WHERE status = 'open';
+
+CREATE TABLE IF NOT EXISTS allocation_requests(
+    id SERIAL PRIMARY KEY,
+    round_id int NOT NULL REFERENCES allocation_rounds(id) ON DELETE CASCADE,
+    user_id uuid NOT NULL REFERENCES users(uid) ON DELETE CASCADE,
+    project_id int NOT NULL REFERENCES projects(id),
+    group_id int REFERENCES research_groups(id) ON DELETE SET NULL,
+    start_time TIMESTAMPTZ NOT NULL,
+    end_time TIMESTAMPTZ NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    metadata JSONB NOT NULL DEFAULT '{}',
+    status allocation_request_statuses NOT NULL DEFAULT 'pending',
+    booking_id int REFERENCES booking(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS allocation_requests_round_id_idx ON allocation_requests(round_id);
+CREATE INDEX IF NOT EXISTS allocation_requests_user_id_idx ON allocation_requests(user_id);
+
+CREATE TABLE IF NOT EXISTS waitlist_entries(
+    id SERIAL PRIMARY KEY,
+    equipment_id int NOT NULL REFERENCES equipment(id) ON DELETE CASCADE,
+    user_id uuid NOT NULL REFERENCES users(uid) ON DELETE CASCADE,
+    project_id int NOT NULL REFERENCES projects(id),
+    group_id int REFERENCES research_groups(id) ON DELETE SET NULL,
+    request_id int REFERENCES allocation_requests(id) ON DELETE SET NULL,
+    start_time TIMESTAMPTZ NOT NULL,
+    end_time TIMESTAMPTZ NOT NULL,
+    status waitlist_statuses NOT NULL DEFAULT 'waiting',
+    notified_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS waitlist_entries_equipment_id_idx ON waitlist_entries(equipment_id, start_time) WHERE status = 'waiting';
+CREATE INDEX IF NOT EXISTS waitlist_entries_user_id_idx ON waitlist_entries(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS waitlist_entries;
+DROP TABLE IF EXISTS allocation_requests;
+DROP TABLE IF EXISTS allocation_rounds;
+ALTER TABLE research_groups
+    DROP COLUMN IF EXISTS share;
+ALTER TABLE equipment
+    DROP COLUMN IF EXISTS allocation_mode;
+DROP TYPE IF EXISTS waitlist_statuses;
+DROP TYPE IF EXISTS allocation_request_statuses;
+DROP TYPE IF EXISTS round_statuses;
+DROP TYPE IF EXISTS allocation_modes;
+-- +goose StatementEnd
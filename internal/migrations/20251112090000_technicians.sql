@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- bookings of such equipment are only accepted together with a free technician
+ALTER TABLE equipment
+    ADD COLUMN requires_operator BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS technicians(
+    user_id uuid PRIMARY KEY REFERENCES users(uid) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS technician_shifts(
+    id SERIAL PRIMARY KEY,
+    technician_id uuid NOT NULL REFERENCES technicians(user_id) ON DELETE CASCADE,
+    start_time TIMESTAMPTZ NOT NULL,
+    end_time TIMESTAMPTZ NOT NULL CHECK (end_time > start_time)
+);
+
+CREATE INDEX IF NOT EXISTS technician_shifts_technician_id_idx ON technician_shifts(technician_id, start_time);
+
+ALTER TABLE booking
+    ADD COLUMN operator_id uuid REFERENCES users(uid) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS booking_operator_id_idx ON booking(operator_id, start_time) WHERE operator_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS booking_operator_id_idx;
+ALTER TABLE booking
+    DROP COLUMN IF EXISTS operator_id;
+DROP TABLE IF EXISTS technician_shifts;
+DROP TABLE IF EXISTS technicians;
+ALTER TABLE equipment
+    DROP COLUMN IF EXISTS requires_operator;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN badge_id VARCHAR(64) UNIQUE;
+
+-- the PCs attached to instruments, only a sha256 digest of the device key is stored
+CREATE TABLE IF NOT EXISTS interlock_devices(
+    id SERIAL PRIMARY KEY,
+    equipment_id int NOT NULL REFERENCES equipment(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_seen_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS interlock_devices_equipment_id_idx ON interlock_devices(equipment_id);
+
+CREATE TABLE IF NOT EXISTS interlock_decisions(
+    id BIGSERIAL PRIMARY KEY,
+    device_id int REFERENCES interlock_devices(id) ON DELETE SET NULL,
+    equipment_id int NOT NULL REFERENCES equipment(id) ON DELETE CASCADE,
+    method VARCHAR(16) NOT NULL,
+    -- the badge id or login that was presented, never the password
+    credential VARCHAR(255) NOT NULL DEFAULT '',
+    user_id uuid REFERENCES users(uid) ON DELETE SET NULL,
+    booking_id int REFERENCES booking(id) ON DELETE SET NULL,
+    allowed BOOLEAN NOT NULL,
+    reason VARCHAR(50) NOT NULL,
+    checked_in BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS interlock_decisions_equipment_id_idx ON interlock_decisions(equipment_id, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS interlock_decisions;
+DROP TABLE IF EXISTS interlock_devices;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS badge_id;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE equipment
+    ADD COLUMN room VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS equipment_room_idx ON equipment(room) WHERE room IS NOT NULL;
+
+-- doorside tablets showing the schedule of one equipment or of all equipment in a room,
+-- only a sha256 digest of the token in the display url is stored
+CREATE TABLE IF NOT EXISTS displays(
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    equipment_id int REFERENCES equipment(id) ON DELETE CASCADE,
+    room VARCHAR(100),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at TIMESTAMPTZ,
+    CHECK ((equipment_id IS NULL) <> (room IS NULL))
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS displays;
+DROP INDEX IF EXISTS equipment_room_idx;
+ALTER TABLE equipment
+    DROP COLUMN IF EXISTS room;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- bumped by every equipment update, clients send it back in If-Match to avoid lost updates
+ALTER TABLE equipment
+    ADD COLUMN version INT NOT NULL DEFAULT 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE equipment
+    DROP COLUMN IF EXISTS version;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- categories form a tree, e.g. Microscopy > Confocal
+CREATE TABLE IF NOT EXISTS categories(
+    id SERIAL PRIMARY KEY,
+    parent_id INT REFERENCES categories(id) ON DELETE RESTRICT,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- sibling names are unique, roots included
+CREATE UNIQUE INDEX IF NOT EXISTS categories_parent_name_idx ON categories(COALESCE(parent_id, 0), LOWER(name));
+
+ALTER TABLE equipment
+    ADD COLUMN category_id INT REFERENCES categories(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS equipment_category_idx ON equipment(category_id);
+
+-- free-form tags, stored lowercased
+CREATE TABLE IF NOT EXISTS equipment_tags(
+    equipment_id INT NOT NULL REFERENCES equipment(id) ON DELETE CASCADE,
+    tag VARCHAR(50) NOT NULL,
+    PRIMARY KEY (equipment_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS equipment_tags_tag_idx ON equipment_tags(tag);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS equipment_tags;
+DROP INDEX IF EXISTS equipment_category_idx;
+ALTER TABLE equipment
+    DROP COLUMN IF EXISTS category_id;
+DROP TABLE IF EXISTS categories;
+-- +goose StatementEnd
@@ -0,0 +1,58 @@
+-- +goose Up
+-- +goose StatementBegin
+-- descriptions are written in russian and english, the document is indexed with both configurations.
+-- name weighs most, then tags and manufacturer, then description
+CREATE OR REPLACE FUNCTION equipment_document(name TEXT, manufacturer TEXT, description TEXT, tags TEXT)
+RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('russian', COALESCE(name, '')) || to_tsvector('english', COALESCE(name, '')), 'A') ||
+        setweight(to_tsvector('russian', COALESCE(tags, '')) || to_tsvector('english', COALESCE(tags, '')), 'B') ||
+        setweight(to_tsvector('russian', COALESCE(manufacturer, '')) || to_tsvector('english', COALESCE(manufacturer, '')), 'B') ||
+        setweight(to_tsvector('russian', COALESCE(description, '')) || to_tsvector('english', COALESCE(description, '')), 'C')
+$$ LANGUAGE SQL IMMUTABLE;
+
+ALTER TABLE equipment
+    ADD COLUMN search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION equipment_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector := equipment_document(NEW.equipment_name, NEW.manufacturer, NEW.description,
+        (SELECT string_agg(tag, ' ') FROM equipment_tags WHERE equipment_id = NEW.id));
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER equipment_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF equipment_name, manufacturer, description ON equipment
+    FOR EACH ROW EXECUTE FUNCTION equipment_search_vector_update();
+
+-- tags live in their own table, changing them rebuilds the document of their equipment
+CREATE OR REPLACE FUNCTION equipment_tags_search_update() RETURNS trigger AS $$
+BEGIN
+    UPDATE equipment SET search_vector = equipment_document(equipment_name, manufacturer, description,
+        (SELECT string_agg(tag, ' ') FROM equipment_tags WHERE equipment_id = equipment.id))
+    WHERE id = COALESCE(NEW.equipment_id, OLD.equipment_id);
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER equipment_tags_search_trigger
+    AFTER INSERT OR UPDATE OR DELETE ON equipment_tags
+    FOR EACH ROW EXECUTE FUNCTION equipment_tags_search_update();
+
+UPDATE equipment SET search_vector = equipment_document(equipment_name, manufacturer, description,
+    (SELECT string_agg(tag, ' ') FROM equipment_tags WHERE equipment_id = equipment.id));
+
+CREATE INDEX IF NOT EXISTS equipment_search_idx ON equipment USING GIN(search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS equipment_search_idx;
+DROP TRIGGER IF EXISTS equipment_tags_search_trigger ON equipment_tags;
+DROP FUNCTION IF EXISTS equipment_tags_search_update();
+DROP TRIGGER IF EXISTS equipment_search_vector_trigger ON equipment;
+DROP FUNCTION IF EXISTS equipment_search_vector_update();
+ALTER TABLE equipment
+    DROP COLUMN IF EXISTS search_vector;
+DROP FUNCTION IF EXISTS equipment_document(TEXT, TEXT, TEXT, TEXT);
+-- +goose StatementEnd
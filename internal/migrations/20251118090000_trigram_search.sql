@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- typo tolerant search and autocomplete match lowercased names by trigrams and prefixes
+CREATE INDEX IF NOT EXISTS equipment_name_trgm_idx ON equipment USING GIN(LOWER(equipment_name) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS equipment_manufacturer_trgm_idx ON equipment USING GIN(LOWER(manufacturer) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS categories_name_trgm_idx ON categories USING GIN(LOWER(name) gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS categories_name_trgm_idx;
+DROP INDEX IF EXISTS equipment_manufacturer_trgm_idx;
+DROP INDEX IF EXISTS equipment_name_trgm_idx;
+-- +goose StatementEnd
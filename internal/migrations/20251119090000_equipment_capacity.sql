@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- how many samples, slots or seats the equipment takes at once, unknown when null
+ALTER TABLE equipment
+    ADD COLUMN capacity INT CHECK (capacity > 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE equipment
+    DROP COLUMN IF EXISTS capacity;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE equipment_statuses AS ENUM (
+    'active', 'maintenance', 'out_of_order', 'retired'
+);
+
+-- only active equipment can be booked, retired equipment is hidden from the catalog but keeps its history
+ALTER TABLE equipment
+    ADD COLUMN status equipment_statuses NOT NULL DEFAULT 'active',
+    ADD COLUMN status_reason TEXT,
+    ADD COLUMN status_changed_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- equipment is retired instead of deleted, a delete must never take the booking history with it
+ALTER TABLE booking
+    DROP CONSTRAINT IF EXISTS booking_equipment_id_fkey,
+    ADD CONSTRAINT booking_equipment_id_fkey FOREIGN KEY (equipment_id) REFERENCES equipment(id) ON DELETE RESTRICT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE booking
+    DROP CONSTRAINT IF EXISTS booking_equipment_id_fkey,
+    ADD CONSTRAINT booking_equipment_id_fkey FOREIGN KEY (equipment_id) REFERENCES equipment(id) ON DELETE CASCADE;
+
+ALTER TABLE equipment
+    DROP COLUMN IF EXISTS status_changed_at,
+    DROP COLUMN IF EXISTS status_reason,
+    DROP COLUMN IF EXISTS status;
+
+DROP TYPE IF EXISTS equipment_statuses;
+-- +goose StatementEnd
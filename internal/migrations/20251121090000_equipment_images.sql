@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the gallery of the equipment, equipment.image_url keeps the object of the primary image
+CREATE TABLE IF NOT EXISTS equipment_images(
+    id SERIAL PRIMARY KEY,
+    equipment_id INT NOT NULL REFERENCES equipment(id) ON DELETE CASCADE,
+    object_name TEXT NOT NULL,
+    caption VARCHAR(255) NOT NULL DEFAULT '',
+    position INT NOT NULL,
+    is_primary BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS equipment_images_equipment_idx ON equipment_images(equipment_id, position);
+CREATE UNIQUE INDEX IF NOT EXISTS equipment_images_primary_idx ON equipment_images(equipment_id) WHERE is_primary;
+
+INSERT INTO equipment_images (equipment_id, object_name, position, is_primary)
+SELECT id, image_url, 0, true FROM equipment WHERE COALESCE(image_url, '') <> '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS equipment_images;
+-- +goose StatementEnd
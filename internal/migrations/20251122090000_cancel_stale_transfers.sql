@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- transfers of bookings cancelled while they were pending could never be resolved
+UPDATE booking_transfers t SET status = 'cancelled', resolved_at = now()
+FROM booking b
+WHERE b.id = t.booking_id AND t.status = 'pending' AND b.status = 'cancelled';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 1;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- failed logins at interlock devices are counted to throttle password guessing
+CREATE INDEX IF NOT EXISTS interlock_decisions_failed_device_idx ON interlock_decisions(device_id, created_at)
+    WHERE method = 'login' AND reason = 'invalid_credentials';
+CREATE INDEX IF NOT EXISTS interlock_decisions_failed_login_idx ON interlock_decisions(credential, created_at)
+    WHERE method = 'login' AND reason = 'invalid_credentials';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS interlock_decisions_failed_login_idx;
+DROP INDEX IF EXISTS interlock_decisions_failed_device_idx;
+-- +goose StatementEnd
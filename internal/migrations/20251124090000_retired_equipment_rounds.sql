@@ -0,0 +1,21 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+-- retiring equipment cancels its open allocation rounds and expires its pending slot offers
+ALTER TYPE round_statuses ADD VALUE IF NOT EXISTS 'cancelled';
+ALTER TYPE offer_statuses ADD VALUE IF NOT EXISTS 'expired';
+
+UPDATE slot_offers o SET status = 'expired', resolved_at = now()
+FROM equipment e
+WHERE e.id = o.equipment_id AND o.status = 'pending' AND e.status = 'retired';
+
+UPDATE allocation_requests r SET status = 'rejected'
+FROM allocation_rounds rd JOIN equipment e ON e.id = rd.equipment_id
+WHERE rd.id = r.round_id AND rd.status = 'open' AND e.status = 'retired';
+
+UPDATE allocation_rounds rd SET status = 'cancelled'
+FROM equipment e
+WHERE e.id = rd.equipment_id AND rd.status = 'open' AND e.status = 'retired';
+
+-- +goose Down
+-- enum values can't be dropped, the resolved rounds and offers stay resolved
+SELECT 1;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- pending offers hold their slots when free slots are searched
+CREATE INDEX IF NOT EXISTS slot_offers_equipment_id_idx ON slot_offers(equipment_id, start_time) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS slot_offers_equipment_id_idx;
+-- +goose StatementEnd
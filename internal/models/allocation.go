@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AllocationDirect  = "direct"
+	AllocationLottery = "lottery"
+)
+
+const (
+	RoundOpen      = "open"
+	RoundAllocated = "allocated"
+	RoundCancelled = "cancelled"
+)
+
+const (
+	RequestPending  = "pending"
+	RequestGranted  = "granted"
+	RequestRejected = "rejected"
+)
+
+const (
+	WaitlistWaiting   = "waiting"
+	WaitlistCancelled = "cancelled"
+)
+
+// AllocationRound collects booking requests for [PeriodStart, PeriodEnd) until ClosesAt,
+// then the slots are assigned by the fair-share lottery
+type AllocationRound struct {
+	Id          int        `json:"id"`
+	EquipmentId int        `json:"equipment_id"`
+	PeriodStart time.Time  `json:"period_start"`
+	PeriodEnd   time.Time  `json:"period_end"`
+	ClosesAt    time.Time  `json:"closes_at"`
+	Status      string     `json:"status"`
+	Seed        *int64     `json:"seed,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	AllocatedAt *time.Time `json:"allocated_at,omitempty"`
+}
+
+type AllocationRequest struct {
+	Id        int            `json:"id"`
+	RoundId   int            `json:"round_id"`
+	UserId    uuid.UUID      `json:"user_id"`
+	ProjectId int            `json:"project_id"`
+	GroupId   int            `json:"group_id,omitempty"`
+	StartTime time.Time      `json:"start_time"`
+	EndTime   time.Time      `json:"end_time"`
+	Notes     string         `json:"notes,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+	Status    string         `json:"status"`
+	BookingId *int           `json:"booking_id,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	// Priority is the booking priority of granted requests, it is not stored
+	Priority int `json:"-"`
+}
+
+// RoundResults is the published outcome of a round, Requests are the viewer's own ones
+type RoundResults struct {
+	Round    AllocationRound     `json:"round"`
+	Granted  int                 `json:"granted"`
+	Rejected int                 `json:"rejected"`
+	Requests []AllocationRequest `json:"requests"`
+}
+
+// UsageEntry is the past usage of a group, or of a user without a group
+type UsageEntry struct {
+	GroupId int
+	UserId  uuid.UUID
+	Hours   float64
+}
+
+type WaitlistEntry struct {
+	Id          int        `json:"id"`
+	EquipmentId int        `json:"equipment_id"`
+	UserId      uuid.UUID  `json:"user_id"`
+	ProjectId   int        `json:"project_id"`
+	GroupId     int        `json:"group_id,omitempty"`
+	RequestId   *int       `json:"request_id,omitempty"`
+	StartTime   time.Time  `json:"start_time"`
+	EndTime     time.Time  `json:"end_time"`
+	Status      string     `json:"status"`
+	NotifiedAt  *time.Time `json:"notified_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
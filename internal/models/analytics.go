@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Heatmap counts bookings active in every hour, indexed by [ISO weekday - 1][hour]
+type Utilization struct {
+	EquipmentId      int         `json:"equipment_id"`
+	From             time.Time   `json:"from"`
+	To               time.Time   `json:"to"`
+	Bookings         int         `json:"bookings"`
+	BookedHours      float64     `json:"booked_hours"`
+	UsedHours        float64     `json:"used_hours"`
+	OperatingHours   float64     `json:"operating_hours"`
+	Utilization      float64     `json:"utilization"`
+	NoShowRate       float64     `json:"no_show_rate"`
+	CancellationRate float64     `json:"cancellation_rate"`
+	Heatmap          [7][24]int  `json:"heatmap"`
+	TopUsers         []UserUsage `json:"top_users"`
+}
+
+type UserUsage struct {
+	UserId      uuid.UUID `json:"user_id"`
+	Username    string    `json:"username"`
+	Bookings    int       `json:"bookings"`
+	BookedHours float64   `json:"booked_hours"`
+}
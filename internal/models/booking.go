@@ -6,10 +6,55 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	BookingActive    = "active"
+	BookingCancelled = "cancelled"
+)
+
 type Booking struct {
-	Id          int       `json:"id,omitempty"`
+	Id           int            `json:"id,omitempty"`
+	EquipmentId  int            `json:"equipment_id"`
+	UserId       uuid.UUID      `json:"user_id,omitempty"`
+	ProjectId    int            `json:"project_id"`
+	GroupId      int            `json:"group_id,omitempty"`
+	Priority     int            `json:"priority"`
+	OperatorId   *uuid.UUID     `json:"operator_id,omitempty"`
+	BookedBy     *uuid.UUID     `json:"booked_by,omitempty"`
+	StartTime    time.Time      `json:"start_time"`
+	EndTime      time.Time      `json:"end_time"`
+	Status       string         `json:"status,omitempty"`
+	Notes        string         `json:"notes,omitempty"`
+	Metadata     map[string]any `json:"metadata,omitempty"`
+	CheckedInAt  *time.Time     `json:"checked_in_at,omitempty"`
+	CheckedOutAt *time.Time     `json:"checked_out_at,omitempty"`
+	CancelledAt  *time.Time     `json:"cancelled_at,omitempty"`
+}
+
+// BookingFilter narrows booking lists, zero values match everything. Results are
+// ordered by start time and id, After continues a list after the given position
+type BookingFilter struct {
+	EquipmentId int
+	UserId      *uuid.UUID
+	From        *time.Time
+	To          *time.Time
+	Status      string
+	Desc        bool
+	After       *BookingCursor
+	Limit       int
+}
+
+// BookingCursor is the keyset position of a booking in a list
+type BookingCursor struct {
+	StartTime time.Time `json:"t"`
+	Id        int       `json:"id"`
+}
+
+// BookingMove is an admin change of a booking's time or equipment
+type BookingMove struct {
+	BookingId   int       `json:"-"`
 	EquipmentId int       `json:"equipment_id"`
-	UserId      uuid.UUID `json:"user_id,omitempty"`
 	StartTime   time.Time `json:"start_time"`
 	EndTime     time.Time `json:"end_time"`
+	Override    bool      `json:"override"`
+	Reason      string    `json:"reason"`
 }
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Category is a node of the equipment category tree, Path joins the names from the root
+type Category struct {
+	Id        int       `json:"id"`
+	ParentId  *int      `json:"parent_id,omitempty"`
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// EquipmentFilter narrows equipment browsing, zero values match everything. A category matches
+// its subcategories too, every tag has to be present. Results are ordered by name and id,
+// After continues a list after the given position
+type EquipmentFilter struct {
+	CategoryId   int
+	Tags         []string
+	Manufacturer string
+	Room         string
+	After        *EquipmentCursor
+	Limit        int
+}
+
+// EquipmentCursor is the keyset position of the last equipment on a page
+type EquipmentCursor struct {
+	Name string `json:"n"`
+	Id   int    `json:"id"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type CategoryFacet struct {
+	Id    int    `json:"id"`
+	Path  string `json:"path"`
+	Count int    `json:"count"`
+}
+
+// EquipmentFacets counts the equipment per filter value. A facet ignores its own filter, so the
+// other values stay selectable, tags narrow each other and use the whole filter
+type EquipmentFacets struct {
+	Categories    []CategoryFacet `json:"categories"`
+	Tags          []FacetCount    `json:"tags"`
+	Manufacturers []FacetCount    `json:"manufacturers"`
+	Rooms         []FacetCount    `json:"rooms"`
+}
+
+// EquipmentBrowse is a page of equipment with the exact number of matches and the facets of
+// the whole filter
+type EquipmentBrowse struct {
+	Items      []Equipment     `json:"items"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	Total      int             `json:"total"`
+	Facets     EquipmentFacets `json:"facets"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Certification struct {
+	Id           int    `json:"id,omitempty"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	ValidityDays *int   `json:"validity_days,omitempty"`
+}
+
+type UserCertification struct {
+	UserId          uuid.UUID  `json:"user_id"`
+	CertificationId int        `json:"certification_id"`
+	Name            string     `json:"name,omitempty"`
+	GrantedBy       *uuid.UUID `json:"granted_by,omitempty"`
+	GrantedAt       time.Time  `json:"granted_at"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+const (
+	SlotBooked = "booked"
+	SlotInUse  = "in_use"
+)
+
+// Display is a public doorside screen for one equipment or for all equipment in a room
+type Display struct {
+	Id          int        `json:"id"`
+	Name        string     `json:"name"`
+	EquipmentId *int       `json:"equipment_id,omitempty"`
+	Room        string     `json:"room,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+// DisplaySchedule is what a display shows, days are today and tomorrow in TimeZone
+type DisplaySchedule struct {
+	Title       string             `json:"title"`
+	TimeZone    string             `json:"time_zone"`
+	From        time.Time          `json:"from"`
+	To          time.Time          `json:"to"`
+	BookingURL  string             `json:"booking_url"`
+	GeneratedAt time.Time          `json:"generated_at"`
+	Equipment   []DisplayEquipment `json:"equipment"`
+}
+
+type DisplayEquipment struct {
+	Id    int           `json:"id"`
+	Name  string        `json:"name"`
+	Slots []DisplaySlot `json:"slots"`
+}
+
+// DisplaySlot is an anonymous busy slot, displays don't show who booked
+type DisplaySlot struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Status    string    `json:"status"`
+}
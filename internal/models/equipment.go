@@ -1,9 +1,56 @@
 package models
 
+import "time"
+
+const (
+	EquipmentActive      = "active"
+	EquipmentMaintenance = "maintenance"
+	EquipmentOutOfOrder  = "out_of_order"
+	// EquipmentRetired is hidden from the catalog but keeps its booking history
+	EquipmentRetired = "retired"
+)
+
 type Equipment struct {
-	EquipmentId   int    `json:"equipment_id,omitempty"`
-	EquipmentName string `json:"equipment_name" form:"equipment_name"`
-	Manufacturer  string `json:"manufacturer" form:"manufacturer"`
-	Description   string `json:"description" form:"description"`
-	ImageURL      string `json:"image_url,omitempty"`
+	EquipmentId    int    `json:"equipment_id,omitempty"`
+	EquipmentName  string `json:"equipment_name" form:"equipment_name"`
+	Manufacturer   string `json:"manufacturer" form:"manufacturer"`
+	Description    string `json:"description" form:"description"`
+	ImageURL       string `json:"image_url,omitempty"`
+	TimeZone       string `json:"time_zone,omitempty" form:"time_zone"`
+	Room           string `json:"room,omitempty" form:"room"`
+	AllocationMode string `json:"allocation_mode,omitempty"`
+	// RequiresOperator equipment is only booked together with a technician
+	RequiresOperator bool `json:"requires_operator"`
+	// Version grows with every update, it is the equipment's ETag
+	Version    int      `json:"version"`
+	CategoryId *int     `json:"category_id,omitempty"`
+	Tags       []string `json:"tags"`
+	Capacity   *int     `json:"capacity,omitempty"`
+	// only active equipment can be booked
+	Status          string    `json:"status"`
+	StatusReason    string    `json:"status_reason,omitempty"`
+	StatusChangedAt time.Time `json:"status_changed_at"`
+	// ImageURLs link to the primary image
+	ImageURLs *ImageURLs `json:"image_urls,omitempty"`
+	// Images is only filled when a single equipment is requested
+	Images []EquipmentImage `json:"images,omitempty"`
+}
+
+// EquipmentUpdate changes only the fields that are set, Version is the one the change was based on
+type EquipmentUpdate struct {
+	EquipmentName *string
+	Manufacturer  *string
+	Description   *string
+	ImageURL      *string
+	Capacity      *int
+	// Version 0 matches any version, as If-Match: *
+	Version int
+}
+
+// OperatingHours is the daily open window of equipment, weekday is ISO (1 = monday)
+type OperatingHours struct {
+	EquipmentId int    `json:"equipment_id,omitempty"`
+	Weekday     int    `json:"weekday"`
+	OpenTime    string `json:"open_time"`
+	CloseTime   string `json:"close_time"`
 }
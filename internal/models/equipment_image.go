@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// EquipmentImage is a picture of the equipment gallery, images are shown by position
+// and the primary one stands for the equipment in lists
+type EquipmentImage struct {
+	Id          int        `json:"id"`
+	EquipmentId int        `json:"equipment_id"`
+	ObjectName  string     `json:"object_name"`
+	Caption     string     `json:"caption"`
+	Position    int        `json:"position"`
+	Primary     bool       `json:"primary"`
+	CreatedAt   time.Time  `json:"created_at"`
+	URLs        *ImageURLs `json:"urls,omitempty"`
+}
+
+// ImageURLs are expiring signed links to the sizes of an image
+type ImageURLs struct {
+	Original string `json:"original"`
+	Medium   string `json:"medium"`
+	Thumb    string `json:"thumb"`
+}
+
+// EquipmentImageUpdate changes only the fields that are set, an image can't be unset as
+// primary, another one is made primary instead
+type EquipmentImageUpdate struct {
+	Caption *string
+	Primary bool
+}
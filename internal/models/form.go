@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+const (
+	FieldText     = "text"
+	FieldSelect   = "select"
+	FieldNumber   = "number"
+	FieldCheckbox = "checkbox"
+)
+
+// FormField describes one entry of the custom booking form of an equipment
+type FormField struct {
+	Id          int      `json:"id,omitempty"`
+	EquipmentId int      `json:"equipment_id,omitempty"`
+	Name        string   `json:"name"`
+	Label       string   `json:"label"`
+	Type        string   `json:"type"`
+	Required    bool     `json:"required"`
+	Options     []string `json:"options,omitempty"`
+	Position    int      `json:"position"`
+}
+
+type Attachment struct {
+	Id          int       `json:"id"`
+	BookingId   int       `json:"booking_id"`
+	ObjectName  string    `json:"-"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Group struct {
+	Id         int        `json:"id,omitempty"`
+	Name       string     `json:"name"`
+	PIId       *uuid.UUID `json:"pi_id,omitempty"`
+	QuotaHours *float64   `json:"quota_hours,omitempty"`
+	Share      float64    `json:"share,omitempty"`
+}
+
+type GroupMember struct {
+	GroupId  int       `json:"group_id"`
+	UserId   uuid.UUID `json:"user_id"`
+	Username string    `json:"username,omitempty"`
+	IsAdmin  bool      `json:"is_admin"`
+}
+
+type GroupUsage struct {
+	GroupId    int      `json:"group_id"`
+	Month      string   `json:"month"`
+	UsedHours  float64  `json:"used_hours"`
+	QuotaHours *float64 `json:"quota_hours,omitempty"`
+}
+
+// ScheduleEntry is a booking as seen by another user. Owner details are
+// only filled in for the viewer's own bookings and those of group colleagues
+type ScheduleEntry struct {
+	Id          int        `json:"id,omitempty"`
+	EquipmentId int        `json:"equipment_id"`
+	StartTime   time.Time  `json:"start_time"`
+	EndTime     time.Time  `json:"end_time"`
+	Busy        bool       `json:"busy"`
+	UserId      *uuid.UUID `json:"user_id,omitempty"`
+	Username    string     `json:"username,omitempty"`
+	// the list position is kept even when the id is hidden from the viewer
+	Cursor BookingCursor `json:"-"`
+}
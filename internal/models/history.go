@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	HistoryCreated          = "created"
+	HistoryCancelled        = "cancelled"
+	HistoryMoved            = "moved"
+	HistoryPreempted        = "preempted"
+	HistoryOfferAccepted    = "offer_accepted"
+	HistoryAllocated        = "allocated"
+	HistoryTransferProposed = "transfer_proposed"
+	HistoryTransferAccepted = "transfer_accepted"
+	HistoryTransferDeclined = "transfer_declined"
+	HistoryTransferCanceled = "transfer_cancelled"
+)
+
+// BookingEvent is one entry of the booking audit history
+type BookingEvent struct {
+	Id        int64          `json:"id"`
+	BookingId int            `json:"booking_id"`
+	Action    string         `json:"action"`
+	ActorId   *uuid.UUID     `json:"actor_id,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
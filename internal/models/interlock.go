@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	InterlockByBadge = "badge"
+	InterlockByLogin = "login"
+)
+
+// reasons of interlock decisions
+const (
+	InterlockAllowed            = "allowed"
+	InterlockNoBooking          = "no_active_booking"
+	InterlockNotBookingHolder   = "not_booking_holder"
+	InterlockUnknownUser        = "unknown_user"
+	InterlockInvalidCredentials = "invalid_credentials"
+	InterlockTooManyAttempts    = "too_many_attempts"
+)
+
+// Device is the PC attached to an instrument, it authenticates with its own API key
+type Device struct {
+	Id          int        `json:"id"`
+	EquipmentId int        `json:"equipment_id"`
+	Name        string     `json:"name"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastSeenAt  *time.Time `json:"last_seen_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+// InterlockStatus is who may use the equipment right now
+type InterlockStatus struct {
+	EquipmentId int        `json:"equipment_id"`
+	BookingId   int        `json:"booking_id,omitempty"`
+	UserId      *uuid.UUID `json:"user_id,omitempty"`
+	Username    string     `json:"username,omitempty"`
+	OperatorId  *uuid.UUID `json:"operator_id,omitempty"`
+	StartTime   *time.Time `json:"start_time,omitempty"`
+	EndTime     *time.Time `json:"end_time,omitempty"`
+}
+
+// InterlockDecision is a logged answer to a device asking whether a user may unlock it
+type InterlockDecision struct {
+	Id          int64      `json:"id"`
+	DeviceId    *int       `json:"device_id,omitempty"`
+	EquipmentId int        `json:"equipment_id"`
+	Method      string     `json:"method"`
+	Credential  string     `json:"credential,omitempty"`
+	UserId      *uuid.UUID `json:"user_id,omitempty"`
+	BookingId   *int       `json:"booking_id,omitempty"`
+	Allowed     bool       `json:"allowed"`
+	Reason      string     `json:"reason"`
+	CheckedIn   bool       `json:"checked_in"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
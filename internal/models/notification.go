@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	NotificationCertificationExpiring = "certification_expiring"
+	NotificationBookingCreated        = "booking_created"
+	NotificationBookingCancelled      = "booking_cancelled"
+	NotificationBookingMoved          = "booking_moved"
+	NotificationBookingPreempted      = "booking_preempted"
+	NotificationAllocationGranted     = "allocation_granted"
+	NotificationAllocationRejected    = "allocation_rejected"
+	NotificationWaitlistSlotFree      = "waitlist_slot_free"
+)
+
+type Notification struct {
+	Id        int64          `json:"id"`
+	UserId    uuid.UUID      `json:"user_id"`
+	Kind      string         `json:"kind"`
+	Message   string         `json:"message"`
+	Payload   map[string]any `json:"payload,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	ReadAt    *time.Time     `json:"read_at,omitempty"`
+}
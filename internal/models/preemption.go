@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	OfferPending  = "pending"
+	OfferAccepted = "accepted"
+	OfferDeclined = "declined"
+	OfferExpired  = "expired"
+)
+
+// PreemptionRule allows bookings of at least MinPriority to bump bookings that are at least
+// PriorityMargin lower and start no sooner than MinNoticeMinutes from now
+type PreemptionRule struct {
+	EquipmentId      int `json:"equipment_id"`
+	MinPriority      int `json:"min_priority"`
+	PriorityMargin   int `json:"priority_margin"`
+	MinNoticeMinutes int `json:"min_notice_minutes"`
+}
+
+type RolePriority struct {
+	Role     string `json:"role"`
+	Priority int    `json:"priority"`
+}
+
+// SlotOffer is an alternative slot offered to the owner of a preempted booking
+type SlotOffer struct {
+	Id           int        `json:"id"`
+	BookingId    int        `json:"booking_id"`
+	UserId       uuid.UUID  `json:"user_id"`
+	EquipmentId  int        `json:"equipment_id"`
+	StartTime    time.Time  `json:"start_time"`
+	EndTime      time.Time  `json:"end_time"`
+	Status       string     `json:"status"`
+	NewBookingId *int       `json:"new_booking_id,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TierInternal = "internal"
+	TierExternal = "external"
+)
+
+type Project struct {
+	Id        int    `json:"id,omitempty"`
+	GrantCode string `json:"grant_code"`
+	Title     string `json:"title"`
+	Tier      string `json:"tier"`
+	Priority  int    `json:"priority"`
+}
+
+type ProjectMember struct {
+	ProjectId int       `json:"project_id"`
+	UserId    uuid.UUID `json:"user_id"`
+}
+
+// peak hours are [PeakStart, PeakEnd) in hours of the day
+type EquipmentRate struct {
+	Id          int     `json:"id,omitempty"`
+	EquipmentId int     `json:"equipment_id"`
+	Tier        string  `json:"tier"`
+	PeakRate    float64 `json:"peak_rate"`
+	OffPeakRate float64 `json:"off_peak_rate"`
+	PeakStart   int     `json:"peak_start"`
+	PeakEnd     int     `json:"peak_end"`
+}
+
+// ChargeableBooking is a booking joined with its project and the rate that applies to it
+type ChargeableBooking struct {
+	BookingId   int
+	ProjectId   int
+	GrantCode   string
+	Tier        string
+	EquipmentId int
+	Start       time.Time
+	End         time.Time
+	Rate        *EquipmentRate
+	// zone of the equipment, peak windows and months are counted in it
+	TimeZone string
+}
+
+type ProjectCharge struct {
+	ProjectId    int     `json:"project_id"`
+	GrantCode    string  `json:"grant_code"`
+	Month        string  `json:"month"`
+	Bookings     int     `json:"bookings"`
+	PeakHours    float64 `json:"peak_hours"`
+	OffPeakHours float64 `json:"off_peak_hours"`
+	Amount       float64 `json:"amount"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ReportByEquipment = "equipment"
+	ReportByUser      = "user"
+	ReportByProject   = "project"
+)
+
+const (
+	ReportPending = "pending"
+	ReportReady   = "ready"
+	ReportFailed  = "failed"
+)
+
+type ReportRequest struct {
+	Kind   string
+	Format string
+	From   time.Time
+	To     time.Time
+	UserId uuid.UUID
+}
+
+// UsageRow is one line of a usage report, grouped by equipment, user or project
+type UsageRow struct {
+	Key         string
+	Label       string
+	Bookings    int
+	CheckedIn   int
+	BookedHours float64
+	UsedHours   float64
+}
+
+type ReportJob struct {
+	Id          uuid.UUID  `json:"id"`
+	UserId      uuid.UUID  `json:"user_id"`
+	Kind        string     `json:"kind"`
+	Format      string     `json:"format"`
+	From        time.Time  `json:"from"`
+	To          time.Time  `json:"to"`
+	Status      string     `json:"status"`
+	ObjectName  string     `json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	DownloadURL string     `json:"download_url,omitempty"`
+}
+
+type ReportFile struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
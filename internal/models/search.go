@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EquipmentSearchHit is a full-text match. The highlights are HTML escaped with the matched
+// words wrapped in <mark>
+type EquipmentSearchHit struct {
+	Equipment     Equipment `json:"equipment"`
+	Rank          float32   `json:"rank"`
+	NameHighlight string    `json:"name_highlight"`
+	Snippet       string    `json:"snippet"`
+	// Fuzzy hits come from the typo tolerant fallback, Rank is then the trigram similarity
+	Fuzzy bool `json:"fuzzy,omitempty"`
+}
+
+// EquipmentSearchCursor is the position of the last hit on a page, hits are ordered by rank
+// descending and id
+type EquipmentSearchCursor struct {
+	Rank float32 `json:"r"`
+	Id   int     `json:"id"`
+}
+
+const (
+	SuggestEquipment    = "equipment"
+	SuggestManufacturer = "manufacturer"
+	SuggestCategory     = "category"
+)
+
+// Suggestion is an autocomplete entry, Id is set for equipment and categories
+type Suggestion struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+	Id    *int   `json:"id,omitempty"`
+}
+
+// AvailabilityFilter finds the equipment that can be booked from Start to End by UserId. The
+// catalog filters work as in browsing, Query is a full-text query. CertifiedOnly drops equipment
+// the user lacks certifications for, SortByMatch orders by text rank and the closest capacity
+type AvailabilityFilter struct {
+	EquipmentFilter
+	Query         string
+	MinCapacity   int
+	Start         time.Time
+	End           time.Time
+	UserId        uuid.UUID
+	CertifiedOnly bool
+	SortByMatch   bool
+}
+
+// AvailableEquipment is equipment free for the requested window, Certified tells whether the
+// user holds its certifications until the window ends
+type AvailableEquipment struct {
+	Equipment Equipment `json:"equipment"`
+	Rank      float32   `json:"rank"`
+	Certified bool      `json:"certified"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Technician is a staff member who can be reserved to operate equipment during their shifts
+type Technician struct {
+	UserId    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Shift struct {
+	Id           int       `json:"id"`
+	TechnicianId uuid.UUID `json:"technician_id"`
+	StartTime    time.Time `json:"start_time"`
+	EndTime      time.Time `json:"end_time"`
+}
+
+// TechnicianSchedule is what a technician works on in a period: shifts and the bookings
+// they operate
+type TechnicianSchedule struct {
+	Shifts      []Shift   `json:"shifts"`
+	Assignments []Booking `json:"assignments"`
+}
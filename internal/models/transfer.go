@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TransferPending   = "pending"
+	TransferAccepted  = "accepted"
+	TransferDeclined  = "declined"
+	TransferCancelled = "cancelled"
+)
+
+type BookingTransfer struct {
+	Id         int        `json:"id"`
+	BookingId  int        `json:"booking_id"`
+	FromUser   uuid.UUID  `json:"from_user"`
+	ToUser     uuid.UUID  `json:"to_user"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
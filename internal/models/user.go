@@ -8,4 +8,6 @@ type User struct {
 	Role           string    `json:"role"`
 	Email          string    `json:"email"`
 	HashedPassword string    `json:"hashed_password,omitempty"`
+	TimeZone       string    `json:"time_zone,omitempty"`
+	BadgeId        string    `json:"badge_id,omitempty"`
 }
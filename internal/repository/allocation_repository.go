@@ -0,0 +1,438 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrRoundNotFound    = errors.New("allocation round not found")
+	ErrRoundNotOpen     = errors.New("allocation round is not open")
+	ErrRoundChanged     = errors.New("allocation round requests changed")
+	ErrRequestNotFound  = errors.New("allocation request not found")
+	ErrWaitlistNotFound = errors.New("waitlist entry not found")
+)
+
+const (
+	roundColumns    = "id, equipment_id, period_start, period_end, closes_at, status, seed, created_at, allocated_at"
+	requestColumns  = "id, round_id, user_id, project_id, COALESCE(group_id, 0), start_time, end_time, notes, metadata, status, booking_id, created_at"
+	waitlistColumns = "id, equipment_id, user_id, project_id, COALESCE(group_id, 0), request_id, start_time, end_time, status, notified_at, created_at"
+	// layout of times in notification messages
+	noticeTimeLayout = "2006-01-02 15:04"
+)
+
+func scanRound(row pgx.Row, r *models.AllocationRound) error {
+	return row.Scan(&r.Id, &r.EquipmentId, &r.PeriodStart, &r.PeriodEnd, &r.ClosesAt, &r.Status, &r.Seed, &r.CreatedAt, &r.AllocatedAt)
+}
+
+func scanRequest(row pgx.Row, r *models.AllocationRequest) error {
+	return row.Scan(&r.Id, &r.RoundId, &r.UserId, &r.ProjectId, &r.GroupId, &r.StartTime, &r.EndTime, &r.Notes, &r.Metadata,
+		&r.Status, &r.BookingId, &r.CreatedAt)
+}
+
+func scanWaitlistEntry(row pgx.Row, w *models.WaitlistEntry) error {
+	return row.Scan(&w.Id, &w.EquipmentId, &w.UserId, &w.ProjectId, &w.GroupId, &w.RequestId, &w.StartTime, &w.EndTime, &w.Status,
+		&w.NotifiedAt, &w.CreatedAt)
+}
+
+func (p *PostgresBookingRepository) CreateRound(ctx context.Context, round models.AllocationRound) (int, error) {
+	const op = "booking_repository.CreateRound"
+	var id int
+	err := p.db.DB.QueryRow(ctx, "INSERT INTO allocation_rounds (equipment_id, period_start, period_end, closes_at) "+
+		"VALUES($1, $2, $3, $4) RETURNING id", round.EquipmentId, round.PeriodStart, round.PeriodEnd, round.ClosesAt).Scan(&id)
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == "23503" {
+			return 0, fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (p *PostgresBookingRepository) Round(ctx context.Context, roundId int) (*models.AllocationRound, error) {
+	const op = "booking_repository.Round"
+	var round models.AllocationRound
+	err := scanRound(p.db.DB.QueryRow(ctx, "SELECT "+roundColumns+" FROM allocation_rounds WHERE id = $1", roundId), &round)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrRoundNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &round, nil
+}
+
+func (p *PostgresBookingRepository) queryRounds(ctx context.Context, sql string, args ...any) ([]models.AllocationRound, error) {
+	rows, err := p.db.DB.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	rounds := []models.AllocationRound{}
+	for rows.Next() {
+		var round models.AllocationRound
+		err := scanRound(rows, &round)
+		if err != nil {
+			return nil, err
+		}
+		rounds = append(rounds, round)
+	}
+	return rounds, rows.Err()
+}
+
+func (p *PostgresBookingRepository) Rounds(ctx context.Context, equipmentId int) ([]models.AllocationRound, error) {
+	const op = "booking_repository.Rounds"
+	rounds, err := p.queryRounds(ctx, "SELECT "+roundColumns+" FROM allocation_rounds WHERE equipment_id = $1 "+
+		"ORDER BY period_start DESC", equipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return rounds, nil
+}
+
+// DueRounds returns the open rounds whose submission window closed before now
+func (p *PostgresBookingRepository) DueRounds(ctx context.Context, now time.Time) ([]models.AllocationRound, error) {
+	const op = "booking_repository.DueRounds"
+	rounds, err := p.queryRounds(ctx, "SELECT "+roundColumns+" FROM allocation_rounds WHERE status = 'open' AND closes_at <= $1 "+
+		"ORDER BY closes_at", now)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return rounds, nil
+}
+
+// HasOpenRound reports whether the interval lies in the period of a round that wasn't allocated yet
+func (p *PostgresBookingRepository) HasOpenRound(ctx context.Context, equipmentId int, startTime, endTime time.Time) (bool, error) {
+	const op = "booking_repository.HasOpenRound"
+	var ok bool
+	err := p.db.DB.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM allocation_rounds WHERE equipment_id = $1 AND status = 'open' "+
+		"AND period_start < $3 AND period_end > $2)", equipmentId, startTime, endTime).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return ok, nil
+}
+
+// CreateRequest adds the request to its round while the submission window is open. The round
+// row is share locked, so the request can't slip in while the round is being allocated
+func (p *PostgresBookingRepository) CreateRequest(ctx context.Context, req models.AllocationRequest) (int, error) {
+	const op = "booking_repository.CreateRequest"
+	var id int
+	err := p.db.DB.QueryRow(ctx, "INSERT INTO allocation_requests (round_id, user_id, project_id, group_id, start_time, end_time, notes, metadata) "+
+		"SELECT id, $2, $3, NULLIF($4, 0), $5, $6, $7, $8 FROM allocation_rounds WHERE id = $1 AND status = 'open' AND closes_at > now() "+
+		"FOR SHARE RETURNING id", req.RoundId, req.UserId, req.ProjectId, req.GroupId,
+		req.StartTime, req.EndTime, req.Notes, req.Metadata).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, ErrRoundNotOpen)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (p *PostgresBookingRepository) queryRequests(ctx context.Context, sql string, args ...any) ([]models.AllocationRequest, error) {
+	rows, err := p.db.DB.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	requests := []models.AllocationRequest{}
+	for rows.Next() {
+		var req models.AllocationRequest
+		err := scanRequest(rows, &req)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+	return requests, rows.Err()
+}
+
+// RoundRequests returns the round's requests in submission order
+func (p *PostgresBookingRepository) RoundRequests(ctx context.Context, roundId int) ([]models.AllocationRequest, error) {
+	const op = "booking_repository.RoundRequests"
+	requests, err := p.queryRequests(ctx, "SELECT "+requestColumns+" FROM allocation_requests WHERE round_id = $1 ORDER BY id", roundId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return requests, nil
+}
+
+func (p *PostgresBookingRepository) UserRequests(ctx context.Context, uid uuid.UUID) ([]models.AllocationRequest, error) {
+	const op = "booking_repository.UserRequests"
+	requests, err := p.queryRequests(ctx, "SELECT "+requestColumns+" FROM allocation_requests WHERE user_id = $1 "+
+		"ORDER BY created_at DESC", uid)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return requests, nil
+}
+
+// WithdrawRequest deletes the user's request as long as the submission window of its round is
+// open. It fails with ErrRoundNotOpen once the window closed, the requests of a closed round
+// are what its lottery is drawn from
+func (p *PostgresBookingRepository) WithdrawRequest(ctx context.Context, requestId int, uid uuid.UUID) error {
+	const op = "booking_repository.WithdrawRequest"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var open bool
+	err = tx.QueryRow(ctx, "SELECT rd.status = 'open' AND rd.closes_at > now() FROM allocation_requests r "+
+		"JOIN allocation_rounds rd ON rd.id = r.round_id WHERE r.id = $1 AND r.user_id = $2 FOR SHARE OF rd", requestId, uid).Scan(&open)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, ErrRequestNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !open {
+		return fmt.Errorf("%s: %w", op, ErrRoundNotOpen)
+	}
+	_, err = tx.Exec(ctx, "DELETE FROM allocation_requests WHERE id = $1", requestId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// AllocationUsage sums the booked hours on the equipment in [from, to) per group,
+// bookings without a group are summed per user with GroupId 0
+func (p *PostgresBookingRepository) AllocationUsage(ctx context.Context, equipmentId int, from, to time.Time) ([]models.UsageEntry, error) {
+	const op = "booking_repository.AllocationUsage"
+	rows, err := p.db.DB.Query(ctx, `SELECT COALESCE(group_id, 0), CASE WHEN group_id IS NULL THEN user_id END,
+			SUM(EXTRACT(EPOCH FROM LEAST(end_time, $3) - GREATEST(start_time, $2))) / 3600
+		FROM booking WHERE equipment_id = $1 AND status = 'active' AND start_time < $3 AND end_time > $2
+		GROUP BY 1, 2`, equipmentId, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	var usage []models.UsageEntry
+	for rows.Next() {
+		var (
+			entry models.UsageEntry
+			uid   *uuid.UUID
+		)
+		err := rows.Scan(&entry.GroupId, &uid, &entry.Hours)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if uid != nil {
+			entry.UserId = *uid
+		}
+		usage = append(usage, entry)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return usage, nil
+}
+
+// ApplyAllocation publishes the outcome of a round in one transaction: granted requests become
+// bookings, rejected ones waitlist entries, and every requester is notified. A granted slot
+// that was booked directly in the meantime or has no free operator is rejected as well.
+// Equipment that can't be booked at the moment fails with ErrEquipmentUnavailable, the round
+// stays open until it is back. The round is locked and its requests are read again, if they are not the ones the outcome
+// was drawn from ErrRoundChanged is returned and nothing is published
+func (p *PostgresBookingRepository) ApplyAllocation(ctx context.Context, round models.AllocationRound, requests []models.AllocationRequest, seed int64) error {
+	const op = "booking_repository.ApplyAllocation"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	// the equipment is locked before the round, in the order RetireEquipment takes them
+	err = lockBookableEquipment(ctx, tx, round.EquipmentId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	var status string
+	err = tx.QueryRow(ctx, "SELECT status FROM allocation_rounds WHERE id = $1 FOR UPDATE", round.Id).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, ErrRoundNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if status != models.RoundOpen {
+		return fmt.Errorf("%s: %w", op, ErrRoundNotOpen)
+	}
+	current, err := requestIds(ctx, tx, round.Id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if len(current) != len(requests) {
+		return fmt.Errorf("%s: %w", op, ErrRoundChanged)
+	}
+	for _, req := range requests {
+		if !current[req.Id] {
+			return fmt.Errorf("%s: %w", op, ErrRoundChanged)
+		}
+	}
+	_, err = tx.Exec(ctx, "UPDATE allocation_rounds SET status = 'allocated', allocated_at = now(), seed = $2 WHERE id = $1",
+		round.Id, seed)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for _, req := range requests {
+		slot := fmt.Sprintf("equipment #%d from %s to %s", round.EquipmentId, req.StartTime.Format(noticeTimeLayout),
+			req.EndTime.Format(noticeTimeLayout))
+		if req.Status == models.RequestGranted {
+			var taken bool
+			err = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM booking WHERE equipment_id = $1 AND status = 'active' "+
+				"AND start_time <= $3 AND end_time >= $2)", round.EquipmentId, req.StartTime, req.EndTime).Scan(&taken)
+			if err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+			if taken {
+				req.Status = models.RequestRejected
+			}
+		}
+		booking := models.Booking{EquipmentId: round.EquipmentId, UserId: req.UserId, ProjectId: req.ProjectId,
+			GroupId: req.GroupId, Priority: req.Priority, StartTime: req.StartTime, EndTime: req.EndTime, Notes: req.Notes,
+			Metadata: req.Metadata}
+		if req.Status == models.RequestGranted {
+			err = reserveOperator(ctx, tx, &booking)
+			if errors.Is(err, ErrNoOperator) {
+				req.Status = models.RequestRejected
+			} else if err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+		}
+		if req.Status == models.RequestGranted {
+			id, err := insertBooking(ctx, tx, booking)
+			if err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+			_, err = tx.Exec(ctx, "UPDATE allocation_requests SET status = 'granted', booking_id = $2 WHERE id = $1", req.Id, id)
+			if err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+			err = addHistory(ctx, tx, models.BookingEvent{BookingId: id, Action: models.HistoryAllocated, ActorId: &req.UserId,
+				Details: map[string]any{"round_id": round.Id, "request_id": req.Id}})
+			if err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+			err = addNotification(ctx, tx, models.Notification{UserId: req.UserId, Kind: models.NotificationAllocationGranted,
+				Message: "Your request for " + slot + " was granted",
+				Payload: map[string]any{"round_id": round.Id, "request_id": req.Id, "booking_id": id}})
+			if err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+			continue
+		}
+		_, err = tx.Exec(ctx, "UPDATE allocation_requests SET status = 'rejected' WHERE id = $1", req.Id)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		var entryId int
+		err = tx.QueryRow(ctx, "INSERT INTO waitlist_entries (equipment_id, user_id, project_id, group_id, request_id, start_time, end_time) "+
+			"VALUES($1, $2, $3, NULLIF($4, 0), $5, $6, $7) RETURNING id", round.EquipmentId, req.UserId, req.ProjectId, req.GroupId,
+			req.Id, req.StartTime, req.EndTime).Scan(&entryId)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		err = addNotification(ctx, tx, models.Notification{UserId: req.UserId, Kind: models.NotificationAllocationRejected,
+			Message: "Your request for " + slot + " was not granted, you are on the waitlist for it",
+			Payload: map[string]any{"round_id": round.Id, "request_id": req.Id, "waitlist_id": entryId}})
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func requestIds(ctx context.Context, tx pgx.Tx, roundId int) (map[int]bool, error) {
+	rows, err := tx.Query(ctx, "SELECT id FROM allocation_requests WHERE round_id = $1", roundId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+func (p *PostgresBookingRepository) Waitlist(ctx context.Context, uid uuid.UUID) ([]models.WaitlistEntry, error) {
+	const op = "booking_repository.Waitlist"
+	rows, err := p.db.DB.Query(ctx, "SELECT "+waitlistColumns+" FROM waitlist_entries WHERE user_id = $1 AND status = 'waiting' "+
+		"AND end_time > now() ORDER BY start_time", uid)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	entries := []models.WaitlistEntry{}
+	for rows.Next() {
+		var entry models.WaitlistEntry
+		err := scanWaitlistEntry(rows, &entry)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		entries = append(entries, entry)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return entries, nil
+}
+
+func (p *PostgresBookingRepository) CancelWaitlistEntry(ctx context.Context, entryId int, uid uuid.UUID) error {
+	const op = "booking_repository.CancelWaitlistEntry"
+	tag, err := p.db.DB.Exec(ctx, "UPDATE waitlist_entries SET status = 'cancelled' WHERE id = $1 AND user_id = $2 AND status = 'waiting'",
+		entryId, uid)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrWaitlistNotFound)
+	}
+	return nil
+}
+
+// NotifyWaitlist tells the users waiting for a slot in [from, to] that it is entirely free now
+func (p *PostgresBookingRepository) NotifyWaitlist(ctx context.Context, equipmentId int, from, to time.Time) (int64, error) {
+	const op = "booking_repository.NotifyWaitlist"
+	tag, err := p.db.DB.Exec(ctx, "WITH due AS ("+
+		"UPDATE waitlist_entries w SET notified_at = now() "+
+		"WHERE w.equipment_id = $1 AND w.status = 'waiting' AND w.start_time > now() AND w.start_time <= $3 AND w.end_time >= $2 "+
+		"AND NOT EXISTS (SELECT 1 FROM booking b WHERE b.equipment_id = w.equipment_id AND b.status = 'active' "+
+		"AND b.start_time <= w.end_time AND b.end_time >= w.start_time) "+
+		"RETURNING w.id, w.user_id, w.start_time, w.end_time) "+
+		"INSERT INTO notifications (user_id, kind, message, payload) "+
+		"SELECT user_id, $4, format('The slot on equipment #%s from %s to %s you are waitlisted for is free now', $1::text, "+
+		"to_char(start_time, 'YYYY-MM-DD HH24:MI'), to_char(end_time, 'YYYY-MM-DD HH24:MI')), "+
+		"jsonb_build_object('waitlist_id', id, 'equipment_id', $1::int, 'start_time', start_time, 'end_time', end_time) FROM due",
+		equipmentId, from, to, models.NotificationWaitlistSlotFree)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return tag.RowsAffected(), nil
+}
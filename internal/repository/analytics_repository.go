@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/pkg/db"
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrCacheMiss = errors.New("cache miss")
+
+type AnalyticsRepository struct {
+	db      db.PostgresDB
+	redisDB *redis.Client
+}
+
+type AnalyticsRepositoryInterface interface {
+	Utilization(ctx context.Context, equipmentId int, from, to time.Time, topUsers int) (*models.Utilization, error)
+	CachedUtilization(ctx context.Context, key string) (*models.Utilization, error)
+	CacheUtilization(ctx context.Context, key string, utilization *models.Utilization, ttl time.Duration) error
+}
+
+func NewAnalyticsRepository(db db.PostgresDB, redisDB *redis.Client) *AnalyticsRepository {
+	return &AnalyticsRepository{db: db, redisDB: redisDB}
+}
+
+// Utilization aggregates bookings that start in [from, to)
+func (a *AnalyticsRepository) Utilization(ctx context.Context, equipmentId int, from, to time.Time, topUsers int) (*models.Utilization, error) {
+	const op = "analytics_repository.Utilization"
+	u := models.Utilization{EquipmentId: equipmentId, From: from, To: to, TopUsers: []models.UserUsage{}}
+
+	var cancelled, noShows, past int
+	err := a.db.DB.QueryRow(ctx, `SELECT Count(*) FILTER (WHERE status = 'active'),
+			Count(*) FILTER (WHERE status = 'cancelled'),
+			Count(*) FILTER (WHERE status = 'active' AND end_time < now() AND checked_in_at IS NULL),
+			Count(*) FILTER (WHERE status = 'active' AND end_time < now()),
+			COALESCE(SUM(EXTRACT(EPOCH FROM end_time - start_time)) FILTER (WHERE status = 'active'), 0) / 3600,
+			COALESCE(SUM(EXTRACT(EPOCH FROM COALESCE(checked_out_at, end_time) - checked_in_at))
+				FILTER (WHERE status = 'active' AND checked_in_at IS NOT NULL), 0) / 3600
+		FROM booking WHERE equipment_id = $1 AND start_time >= $2 AND start_time < $3`, equipmentId, from, to).Scan(
+		&u.Bookings, &cancelled, &noShows, &past, &u.BookedHours, &u.UsedHours)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if past > 0 {
+		u.NoShowRate = float64(noShows) / float64(past)
+	}
+	if u.Bookings+cancelled > 0 {
+		u.CancellationRate = float64(cancelled) / float64(u.Bookings+cancelled)
+	}
+
+	// equipment without a configured schedule is considered open around the clock. Opening hours
+	// are wall clock times of the equipment's zone, so days around DST changes get 23 or 25 hours
+	err = a.db.DB.QueryRow(ctx, `SELECT CASE
+			WHEN NOT EXISTS (SELECT 1 FROM equipment_operating_hours WHERE equipment_id = $1)
+				THEN EXTRACT(EPOCH FROM $3::timestamptz - $2::timestamptz)
+			ELSE COALESCE((SELECT SUM(EXTRACT(EPOCH FROM LEAST(w.close_at, $3::timestamptz) - GREATEST(w.open_at, $2::timestamptz)))
+				FROM (SELECT (d::date + o.open_time) AT TIME ZONE e.time_zone AS open_at,
+						(d::date + o.close_time) AT TIME ZONE e.time_zone AS close_at
+					FROM equipment e,
+						generate_series(($2::timestamptz AT TIME ZONE e.time_zone)::date, ($3::timestamptz AT TIME ZONE e.time_zone)::date, interval '1 day') d
+					JOIN equipment_operating_hours o ON o.equipment_id = $1 AND o.weekday = EXTRACT(ISODOW FROM d)
+					WHERE e.id = $1) w
+				WHERE LEAST(w.close_at, $3::timestamptz) > GREATEST(w.open_at, $2::timestamptz)), 0)
+		END / 3600`, equipmentId, from, to).Scan(&u.OperatingHours)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if u.OperatingHours > 0 {
+		u.Utilization = u.BookedHours / u.OperatingHours
+	}
+
+	// the heatmap is in the equipment's local weekdays and hours
+	rows, err := a.db.DB.Query(ctx, `SELECT EXTRACT(ISODOW FROM h AT TIME ZONE e.time_zone)::int, EXTRACT(HOUR FROM h AT TIME ZONE e.time_zone)::int, Count(*)
+		FROM booking b JOIN equipment e ON e.id = b.equipment_id,
+			generate_series(date_trunc('hour', b.start_time), b.end_time - interval '1 second', interval '1 hour') h
+		WHERE b.equipment_id = $1 AND b.status = 'active' AND b.start_time >= $2 AND b.start_time < $3
+		GROUP BY 1, 2`, equipmentId, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for rows.Next() {
+		var weekday, hour, count int
+		err := rows.Scan(&weekday, &hour, &count)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		u.Heatmap[weekday-1][hour] = count
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+
+	rows, err = a.db.DB.Query(ctx, `SELECT u.uid, u.username, Count(*), SUM(EXTRACT(EPOCH FROM b.end_time - b.start_time)) / 3600
+		FROM booking b JOIN users u ON u.uid = b.user_id
+		WHERE b.equipment_id = $1 AND b.status = 'active' AND b.start_time >= $2 AND b.start_time < $3
+		GROUP BY u.uid, u.username
+		ORDER BY 4 DESC
+		LIMIT $4`, equipmentId, from, to, topUsers)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var usage models.UserUsage
+		err := rows.Scan(&usage.UserId, &usage.Username, &usage.Bookings, &usage.BookedHours)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		u.TopUsers = append(u.TopUsers, usage)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return &u, nil
+}
+
+func (a *AnalyticsRepository) CachedUtilization(ctx context.Context, key string) (*models.Utilization, error) {
+	const op = "analytics_repository.CachedUtilization"
+	data, err := a.redisDB.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, fmt.Errorf("%s: %w", op, ErrCacheMiss)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	var u models.Utilization
+	err = json.Unmarshal(data, &u)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &u, nil
+}
+
+func (a *AnalyticsRepository) CacheUtilization(ctx context.Context, key string, utilization *models.Utilization, ttl time.Duration) error {
+	const op = "analytics_repository.CacheUtilization"
+	data, err := json.Marshal(utilization)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	err = a.redisDB.Set(ctx, key, data, ttl).Err()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Gergenus/bookingService/internal/models"
+)
+
+// bookingFilterSQL builds the WHERE clause of a booking list, the keyset condition included.
+// prefix is the table alias with its dot
+func bookingFilterSQL(filter models.BookingFilter, prefix string) (string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if filter.EquipmentId != 0 {
+		add(prefix+"equipment_id = $%d", filter.EquipmentId)
+	}
+	if filter.UserId != nil {
+		add(prefix+"user_id = $%d", *filter.UserId)
+	}
+	if filter.From != nil {
+		add(prefix+"end_time > $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add(prefix+"start_time < $%d", *filter.To)
+	}
+	if filter.Status != "" {
+		add(prefix+"status::text = $%d", filter.Status)
+	}
+	if filter.After != nil {
+		op := ">"
+		if filter.Desc {
+			op = "<"
+		}
+		args = append(args, filter.After.StartTime, filter.After.Id)
+		conds = append(conds, fmt.Sprintf("(%sstart_time, %sid) %s ($%d, $%d)", prefix, prefix, op, len(args)-1, len(args)))
+	}
+	if len(conds) == 0 {
+		return "true", args
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+func bookingOrderSQL(filter models.BookingFilter, prefix string) string {
+	dir := "ASC"
+	if filter.Desc {
+		dir = "DESC"
+	}
+	order := fmt.Sprintf(" ORDER BY %sstart_time %s, %sid %s", prefix, dir, prefix, dir)
+	if filter.Limit > 0 {
+		order += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+	return order
+}
+
+func (p *PostgresBookingRepository) SearchBookings(ctx context.Context, filter models.BookingFilter) ([]models.Booking, error) {
+	const op = "booking_repository.SearchBookings"
+	where, args := bookingFilterSQL(filter, "")
+	rows, err := p.db.DB.Query(ctx, "SELECT "+bookingColumns+" FROM booking WHERE "+where+bookingOrderSQL(filter, ""), args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	bookings := []models.Booking{}
+	for rows.Next() {
+		var booking models.Booking
+		err := scanBooking(rows, &booking)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		bookings = append(bookings, booking)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return bookings, nil
+}
+
+// EstimateBookings returns the planner's row estimate for the filter without the cursor,
+// an exact count would scan every matching row on each page
+func (p *PostgresBookingRepository) EstimateBookings(ctx context.Context, filter models.BookingFilter) (int64, error) {
+	const op = "booking_repository.EstimateBookings"
+	filter.After, filter.Limit = nil, 0
+	where, args := bookingFilterSQL(filter, "")
+	var plan []byte
+	err := p.db.DB.QueryRow(ctx, "EXPLAIN (FORMAT JSON) SELECT id FROM booking WHERE "+where, args...).Scan(&plan)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	var explain []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	err = json.Unmarshal(plan, &explain)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(explain) == 0 {
+		return 0, fmt.Errorf("%s: empty plan", op)
+	}
+	return int64(explain[0].Plan.Rows), nil
+}
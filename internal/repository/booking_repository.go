@@ -4,105 +4,288 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/Gergenus/bookingService/internal/models"
 	"github.com/Gergenus/bookingService/pkg/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 var (
 	ErrIntervalInterception = errors.New("interval interception")
+	ErrBookingNotFound      = errors.New("booking not found")
+	ErrAttachmentNotFound   = errors.New("attachment not found")
 )
 
+const bookingColumns = "id, equipment_id, user_id, COALESCE(project_id, 0), COALESCE(group_id, 0), priority, operator_id, booked_by, start_time, end_time, status, notes, metadata, checked_in_at, checked_out_at, cancelled_at"
+
 type PostgresBookingRepository struct {
 	db db.PostgresDB
 }
 
 type BookingRepositoryInterface interface {
-	CreateBooking(ctx context.Context, booking models.Booking) (int, error)
-	Bookings(ctx context.Context, equipmentId int) ([]models.Booking, error)
-	DeleteBooking(ctx context.Context, bookingId int) error
+	CreateBooking(ctx context.Context, booking models.Booking, enforceQuota bool, event models.BookingEvent) (int, error)
+	Bookings(ctx context.Context, filter models.BookingFilter, viewer uuid.UUID) ([]models.ScheduleEntry, error)
+	DeleteBooking(ctx context.Context, bookingId int, event models.BookingEvent) error
 	Booking(ctx context.Context, bookingId int) (*models.Booking, error)
-	ScientistBookings(ctx context.Context, uid string) ([]models.Booking, error)
+	CheckIn(ctx context.Context, bookingId int, at time.Time) error
+	CheckOut(ctx context.Context, bookingId int, at time.Time) error
+	AddAttachment(ctx context.Context, attachment models.Attachment) (int, error)
+	Attachments(ctx context.Context, bookingId int) ([]models.Attachment, error)
+	Attachment(ctx context.Context, attachmentId int) (*models.Attachment, error)
+	DeleteAttachment(ctx context.Context, attachmentId int) error
+	CreateTransfer(ctx context.Context, transfer models.BookingTransfer) (int, error)
+	Transfer(ctx context.Context, transferId int) (*models.BookingTransfer, error)
+	IncomingTransfers(ctx context.Context, uid uuid.UUID) ([]models.BookingTransfer, error)
+	AcceptTransfer(ctx context.Context, transfer models.BookingTransfer, projectId, groupId int) error
+	ResolveTransfer(ctx context.Context, transfer models.BookingTransfer, status string, actor uuid.UUID) error
+	TransferParty(ctx context.Context, bookingId int, uid uuid.UUID) (bool, error)
+	History(ctx context.Context, bookingId int) ([]models.BookingEvent, error)
+	SearchBookings(ctx context.Context, filter models.BookingFilter) ([]models.Booking, error)
+	EstimateBookings(ctx context.Context, filter models.BookingFilter) (int64, error)
+	MoveBooking(ctx context.Context, move models.BookingMove, enforceQuota bool, event models.BookingEvent) error
+	Preempt(ctx context.Context, booking models.Booking, rule models.PreemptionRule, event models.BookingEvent) (int, []models.Booking, error)
+	NearestFreeSlot(ctx context.Context, equipmentId int, duration time.Duration, around, notBefore time.Time) (*time.Time, error)
+	CreateOffer(ctx context.Context, offer models.SlotOffer) (int, error)
+	Offer(ctx context.Context, offerId int) (*models.SlotOffer, error)
+	PendingOffers(ctx context.Context, uid uuid.UUID) ([]models.SlotOffer, error)
+	AcceptOffer(ctx context.Context, offer models.SlotOffer, booking models.Booking) (int, error)
+	DeclineOffer(ctx context.Context, offer models.SlotOffer) error
+	CreateRound(ctx context.Context, round models.AllocationRound) (int, error)
+	Round(ctx context.Context, roundId int) (*models.AllocationRound, error)
+	Rounds(ctx context.Context, equipmentId int) ([]models.AllocationRound, error)
+	DueRounds(ctx context.Context, now time.Time) ([]models.AllocationRound, error)
+	HasOpenRound(ctx context.Context, equipmentId int, startTime, endTime time.Time) (bool, error)
+	CreateRequest(ctx context.Context, req models.AllocationRequest) (int, error)
+	RoundRequests(ctx context.Context, roundId int) ([]models.AllocationRequest, error)
+	UserRequests(ctx context.Context, uid uuid.UUID) ([]models.AllocationRequest, error)
+	WithdrawRequest(ctx context.Context, requestId int, uid uuid.UUID) error
+	AllocationUsage(ctx context.Context, equipmentId int, from, to time.Time) ([]models.UsageEntry, error)
+	ApplyAllocation(ctx context.Context, round models.AllocationRound, requests []models.AllocationRequest, seed int64) error
+	Waitlist(ctx context.Context, uid uuid.UUID) ([]models.WaitlistEntry, error)
+	CancelWaitlistEntry(ctx context.Context, entryId int, uid uuid.UUID) error
+	NotifyWaitlist(ctx context.Context, equipmentId int, from, to time.Time) (int64, error)
 }
 
 func NewPostgresBookingRepository(db db.PostgresDB) PostgresBookingRepository {
 	return PostgresBookingRepository{db: db}
 }
 
-func (p *PostgresBookingRepository) ScientistBookings(ctx context.Context, uid string) ([]models.Booking, error) {
-	var data []models.Booking
-	row, err := p.db.DB.Query(ctx, "SELECT * FROM booking WHERE user_id = $1", uid)
+func scanBooking(row pgx.Row, booking *models.Booking) error {
+	return row.Scan(&booking.Id, &booking.EquipmentId, &booking.UserId, &booking.ProjectId, &booking.GroupId, &booking.Priority, &booking.OperatorId, &booking.BookedBy, &booking.StartTime,
+		&booking.EndTime, &booking.Status, &booking.Notes, &booking.Metadata, &booking.CheckedInAt, &booking.CheckedOutAt, &booking.CancelledAt)
+}
+
+// lockBookableEquipment locks the equipment row and checks that the equipment can be booked.
+// Every transaction that puts a booking on equipment takes this lock first, so bookings of
+// the same equipment are serialized and none slips in while its status changes
+func lockBookableEquipment(ctx context.Context, tx pgx.Tx, equipmentId int) error {
+	var status string
+	err := tx.QueryRow(ctx, "SELECT status FROM equipment WHERE id = $1 FOR UPDATE", equipmentId).Scan(&status)
 	if err != nil {
-		return nil, err
-	}
-	for row.Next() {
-		var booking models.Booking
-		err := row.Scan(&booking.Id, &booking.EquipmentId, &booking.UserId, &booking.StartTime, &booking.EndTime)
-		if err != nil {
-			return nil, err
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrEquipmentNotFound
 		}
-		data = append(data, booking)
+		return err
+	}
+	if status != models.EquipmentActive {
+		return ErrEquipmentUnavailable
 	}
-	return data, nil
+	return nil
 }
 
-func (p *PostgresBookingRepository) checkInterceptions(ctx context.Context, startTime, endTime time.Time, equipmentId int) (bool, error) {
-	const op = "booking_repository.checkInterceprions"
-	var count int
-	err := p.db.DB.QueryRow(ctx, "SELECT Count(*) FROM booking WHERE equipment_id = $1 AND ((start_time <= $3 AND end_time >= $3)"+
-		"OR ($2 >= start_time AND $2 <= end_time) OR ($2 <= start_time AND $3 >= end_time) OR ($2 >= start_time AND $3 <= end_time))", equipmentId, startTime, endTime).Scan(&count)
+// CreateBooking reserves the equipment and, if it requires one, a technician in one transaction
+// that also records the event for the new booking in its history. With enforceQuota the booking
+// has to fit into the quota of its group
+func (p *PostgresBookingRepository) CreateBooking(ctx context.Context, booking models.Booking, enforceQuota bool, event models.BookingEvent) (int, error) {
+	const op = "booking_repository.CreateBooking"
+	tx, err := p.db.DB.Begin(ctx)
 	if err != nil {
-		return false, fmt.Errorf("%s: %w", op, err)
+		return 0, fmt.Errorf("%s: %w", op, err)
 	}
-	return count == 0, nil
-}
+	defer tx.Rollback(ctx)
 
-func (p *PostgresBookingRepository) CreateBooking(ctx context.Context, booking models.Booking) (int, error) {
-	const op = "booking_repository.CreateBooking"
-	ok, err := p.checkInterceptions(ctx, booking.StartTime, booking.EndTime, booking.EquipmentId)
+	err = lockBookableEquipment(ctx, tx, booking.EquipmentId)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	var taken bool
+	err = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM booking WHERE equipment_id = $1 AND status = 'active' "+
+		"AND start_time <= $3 AND end_time >= $2)", booking.EquipmentId, booking.StartTime, booking.EndTime).Scan(&taken)
 	if err != nil {
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
-	if !ok {
+	if taken {
 		return 0, fmt.Errorf("%s: %w", op, ErrIntervalInterception)
 	}
-	var id int
-	err = p.db.DB.QueryRow(ctx, "INSERT INTO booking (equipment_id, user_id, start_time, end_time) VALUES($1, $2, $3, $4) RETURNING id",
-		booking.EquipmentId, booking.UserId, booking.StartTime, booking.EndTime).Scan(&id)
+	if enforceQuota {
+		err = checkGroupQuota(ctx, tx, booking)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	err = reserveOperator(ctx, tx, &booking)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	id, err := insertBooking(ctx, tx, booking)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	event.BookingId = id
+	err = addHistory(ctx, tx, event)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	err = tx.Commit(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 	return id, nil
 }
 
-func (p *PostgresBookingRepository) Bookings(ctx context.Context, equipmentId int) ([]models.Booking, error) {
+// queryer is implemented by both the pool and a transaction
+type queryer interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func insertBooking(ctx context.Context, db queryer, booking models.Booking) (int, error) {
+	var id int
+	err := db.QueryRow(ctx, "INSERT INTO booking (equipment_id, user_id, project_id, group_id, priority, operator_id, booked_by, start_time, end_time, notes, metadata) "+
+		"VALUES($1, $2, $3, NULLIF($4, 0), $5, $6, $7, $8, $9, $10, $11) RETURNING id",
+		booking.EquipmentId, booking.UserId, booking.ProjectId, booking.GroupId, booking.Priority, booking.OperatorId, booking.BookedBy, booking.StartTime,
+		booking.EndTime, booking.Notes, booking.Metadata).Scan(&id)
+	return id, err
+}
+
+// Bookings returns a page of the equipment schedule. Busy is false for bookings the viewer
+// may see in detail: their own ones and those of members of the viewer's groups
+func (p *PostgresBookingRepository) Bookings(ctx context.Context, filter models.BookingFilter, viewer uuid.UUID) ([]models.ScheduleEntry, error) {
 	const op = "booking_repository.Bookings"
-	var bookings []models.Booking
-	rows, err := p.db.DB.Query(ctx, "SELECT * FROM booking WHERE equipment_id = $1", equipmentId)
+	filter.Status = models.BookingActive
+	where, args := bookingFilterSQL(filter, "b.")
+	args = append(args, viewer)
+	rows, err := p.db.DB.Query(ctx, fmt.Sprintf(`SELECT b.id, b.equipment_id, b.start_time, b.end_time, b.user_id, u.username,
+			b.user_id = $%d OR EXISTS (SELECT 1 FROM group_members g1 JOIN group_members g2 ON g1.group_id = g2.group_id
+				WHERE g1.user_id = b.user_id AND g2.user_id = $%d)
+		FROM booking b JOIN users u ON u.uid = b.user_id WHERE `, len(args), len(args))+where+bookingOrderSQL(filter, "b."), args...)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-
+	defer rows.Close()
+	bookings := []models.ScheduleEntry{}
 	for rows.Next() {
-		var booking models.Booking
-		err = rows.Scan(&booking.Id, &booking.EquipmentId, &booking.UserId, &booking.StartTime, &booking.EndTime)
+		var (
+			entry   models.ScheduleEntry
+			uid     uuid.UUID
+			visible bool
+		)
+		err = rows.Scan(&entry.Id, &entry.EquipmentId, &entry.StartTime, &entry.EndTime, &uid, &entry.Username, &visible)
 		if err != nil {
-			rows.Close()
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
-		bookings = append(bookings, booking)
+		entry.Cursor = models.BookingCursor{StartTime: entry.StartTime, Id: entry.Id}
+		if visible {
+			entry.UserId = &uid
+		} else {
+			entry.Id, entry.Username, entry.Busy = 0, "", true
+			// the cursor must not reveal the hidden id either. Active bookings of one equipment
+			// never share a start time, so an id past every other one keeps the position
+			entry.Cursor.Id = math.MaxInt32
+			if filter.Desc {
+				entry.Cursor.Id = 0
+			}
+		}
+		bookings = append(bookings, entry)
 	}
-	rows.Close()
 	if rows.Err() != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
 	}
 	return bookings, nil
 }
 
-func (p *PostgresBookingRepository) DeleteBooking(ctx context.Context, bookingId int) error {
+// DeleteBooking cancels an active booking and its pending transfers, the event is recorded in
+// the history only if the booking was still active
+func (p *PostgresBookingRepository) DeleteBooking(ctx context.Context, bookingId int, event models.BookingEvent) error {
 	const op = "booking_repository.DeleteBooking"
-	_, err := p.db.DB.Exec(ctx, "DELETE FROM booking WHERE id = $1", bookingId)
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, "UPDATE booking SET status = 'cancelled', cancelled_at = now() WHERE id = $1 AND status = 'active'", bookingId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil
+	}
+	err = cancelTransfers(ctx, tx, bookingId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	event.BookingId = bookingId
+	err = addHistory(ctx, tx, event)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// MoveBooking changes the booking's slot if it doesn't intersect any other active booking.
+// The operator is reserved again for the new slot, the current one is kept if they are free.
+// With enforceQuota the new slot has to fit into the quota of the booking's group. The event
+// is recorded in the history in the same transaction
+func (p *PostgresBookingRepository) MoveBooking(ctx context.Context, move models.BookingMove, enforceQuota bool, event models.BookingEvent) error {
+	const op = "booking_repository.MoveBooking"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = lockBookableEquipment(ctx, tx, move.EquipmentId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	var booking models.Booking
+	err = scanBooking(tx.QueryRow(ctx, "UPDATE booking SET equipment_id = $2, start_time = $3, end_time = $4 "+
+		"WHERE id = $1 AND status = 'active' AND NOT EXISTS (SELECT 1 FROM booking o WHERE o.id <> $1 AND o.equipment_id = $2 "+
+		"AND o.status = 'active' AND o.start_time <= $4 AND o.end_time >= $3) RETURNING "+bookingColumns,
+		move.BookingId, move.EquipmentId, move.StartTime, move.EndTime), &booking)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, ErrIntervalInterception)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if enforceQuota {
+		err = checkGroupQuota(ctx, tx, booking)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	err = reserveOperator(ctx, tx, &booking)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	_, err = tx.Exec(ctx, "UPDATE booking SET operator_id = $2 WHERE id = $1", booking.Id, booking.OperatorId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	event.BookingId = booking.Id
+	err = addHistory(ctx, tx, event)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	err = tx.Commit(ctx)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -112,10 +295,96 @@ func (p *PostgresBookingRepository) DeleteBooking(ctx context.Context, bookingId
 func (p *PostgresBookingRepository) Booking(ctx context.Context, bookingId int) (*models.Booking, error) {
 	const op = "booking_repository.Booking"
 	var booking models.Booking
-	err := p.db.DB.QueryRow(ctx, "SELECT * FROM booking WHERE id = $1", bookingId).Scan(&booking.Id, &booking.EquipmentId,
-		&booking.UserId, &booking.StartTime, &booking.EndTime)
+	err := scanBooking(p.db.DB.QueryRow(ctx, "SELECT "+bookingColumns+" FROM booking WHERE id = $1", bookingId), &booking)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrBookingNotFound)
+		}
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	return &booking, nil
 }
+
+func (p *PostgresBookingRepository) CheckIn(ctx context.Context, bookingId int, at time.Time) error {
+	const op = "booking_repository.CheckIn"
+	tag, err := p.db.DB.Exec(ctx, "UPDATE booking SET checked_in_at = $2 WHERE id = $1 AND status = 'active' AND checked_in_at IS NULL", bookingId, at)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrBookingNotFound)
+	}
+	return nil
+}
+
+func (p *PostgresBookingRepository) CheckOut(ctx context.Context, bookingId int, at time.Time) error {
+	const op = "booking_repository.CheckOut"
+	tag, err := p.db.DB.Exec(ctx, "UPDATE booking SET checked_out_at = $2 WHERE id = $1 AND checked_in_at IS NOT NULL AND checked_out_at IS NULL",
+		bookingId, at)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrBookingNotFound)
+	}
+	return nil
+}
+
+func (p *PostgresBookingRepository) AddAttachment(ctx context.Context, attachment models.Attachment) (int, error) {
+	const op = "booking_repository.AddAttachment"
+	var id int
+	err := p.db.DB.QueryRow(ctx, "INSERT INTO booking_attachments (booking_id, object_name, file_name, content_type, size) "+
+		"VALUES($1, $2, $3, $4, $5) RETURNING id", attachment.BookingId, attachment.ObjectName, attachment.FileName,
+		attachment.ContentType, attachment.Size).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (p *PostgresBookingRepository) Attachments(ctx context.Context, bookingId int) ([]models.Attachment, error) {
+	const op = "booking_repository.Attachments"
+	rows, err := p.db.DB.Query(ctx, "SELECT id, booking_id, object_name, file_name, content_type, size, uploaded_at "+
+		"FROM booking_attachments WHERE booking_id = $1 ORDER BY uploaded_at", bookingId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	attachments := []models.Attachment{}
+	for rows.Next() {
+		var a models.Attachment
+		err := rows.Scan(&a.Id, &a.BookingId, &a.ObjectName, &a.FileName, &a.ContentType, &a.Size, &a.UploadedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		attachments = append(attachments, a)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return attachments, nil
+}
+
+func (p *PostgresBookingRepository) Attachment(ctx context.Context, attachmentId int) (*models.Attachment, error) {
+	const op = "booking_repository.Attachment"
+	var a models.Attachment
+	err := p.db.DB.QueryRow(ctx, "SELECT id, booking_id, object_name, file_name, content_type, size, uploaded_at "+
+		"FROM booking_attachments WHERE id = $1", attachmentId).Scan(&a.Id, &a.BookingId, &a.ObjectName, &a.FileName,
+		&a.ContentType, &a.Size, &a.UploadedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrAttachmentNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &a, nil
+}
+
+func (p *PostgresBookingRepository) DeleteAttachment(ctx context.Context, attachmentId int) error {
+	const op = "booking_repository.DeleteAttachment"
+	_, err := p.db.DB.Exec(ctx, "DELETE FROM booking_attachments WHERE id = $1", attachmentId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/pkg/db"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrCategoryNotFound      = errors.New("category not found")
+	ErrCategoryAlreadyExists = errors.New("category already exists")
+	ErrCategoryInUse         = errors.New("category in use")
+)
+
+// categoryTree lists every category with its path from the root
+const categoryTree = "WITH RECURSIVE tree AS (" +
+	"SELECT id, parent_id, name, created_at, name::text AS path FROM categories WHERE parent_id IS NULL " +
+	"UNION ALL SELECT c.id, c.parent_id, c.name, c.created_at, tree.path || ' > ' || c.name " +
+	"FROM categories c JOIN tree ON c.parent_id = tree.id) "
+
+type PostgresCategoryRepository struct {
+	db db.PostgresDB
+}
+
+type CategoryRepositoryInterface interface {
+	CreateCategory(ctx context.Context, category models.Category) (int, error)
+	Categories(ctx context.Context) ([]models.Category, error)
+	RenameCategory(ctx context.Context, categoryId int, name string) error
+	DeleteCategory(ctx context.Context, categoryId int) error
+}
+
+func NewPostgresCategoryRepository(db db.PostgresDB) PostgresCategoryRepository {
+	return PostgresCategoryRepository{db: db}
+}
+
+func (p *PostgresCategoryRepository) CreateCategory(ctx context.Context, category models.Category) (int, error) {
+	const op = "category_repository.CreateCategory"
+	var id int
+	err := p.db.DB.QueryRow(ctx, "INSERT INTO categories (parent_id, name) VALUES($1, $2) RETURNING id",
+		category.ParentId, category.Name).Scan(&id)
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) {
+			switch pgxErr.Code {
+			case "23505":
+				return 0, fmt.Errorf("%s: %w", op, ErrCategoryAlreadyExists)
+			case "23503":
+				return 0, fmt.Errorf("%s: %w", op, ErrCategoryNotFound)
+			}
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+// Categories returns the whole tree ordered by path, parents come before their children
+func (p *PostgresCategoryRepository) Categories(ctx context.Context) ([]models.Category, error) {
+	const op = "category_repository.Categories"
+	rows, err := p.db.DB.Query(ctx, categoryTree+"SELECT id, parent_id, name, path, created_at FROM tree ORDER BY path")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	categories := []models.Category{}
+	for rows.Next() {
+		var c models.Category
+		err := rows.Scan(&c.Id, &c.ParentId, &c.Name, &c.Path, &c.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		categories = append(categories, c)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return categories, nil
+}
+
+func (p *PostgresCategoryRepository) RenameCategory(ctx context.Context, categoryId int, name string) error {
+	const op = "category_repository.RenameCategory"
+	tag, err := p.db.DB.Exec(ctx, "UPDATE categories SET name = $2 WHERE id = $1", categoryId, name)
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == "23505" {
+			return fmt.Errorf("%s: %w", op, ErrCategoryAlreadyExists)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrCategoryNotFound)
+	}
+	return nil
+}
+
+// DeleteCategory is refused while the category has subcategories or equipment
+func (p *PostgresCategoryRepository) DeleteCategory(ctx context.Context, categoryId int) error {
+	const op = "category_repository.DeleteCategory"
+	tag, err := p.db.DB.Exec(ctx, "DELETE FROM categories WHERE id = $1", categoryId)
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == "23503" {
+			return fmt.Errorf("%s: %w", op, ErrCategoryInUse)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrCategoryNotFound)
+	}
+	return nil
+}
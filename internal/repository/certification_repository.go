@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/pkg/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrCertificationAlreadyExists = errors.New("certification already exists")
+	ErrCertificationNotFound      = errors.New("certification not found")
+	ErrEquipmentNotFound          = errors.New("equipment not found")
+)
+
+type PostgresCertificationRepository struct {
+	db db.PostgresDB
+}
+
+type CertificationRepositoryInterface interface {
+	CreateCertification(ctx context.Context, cert models.Certification) (int, error)
+	Certification(ctx context.Context, certId int) (*models.Certification, error)
+	Certifications(ctx context.Context) ([]models.Certification, error)
+	SetEquipmentCertifications(ctx context.Context, equipmentId int, certIds []int) error
+	EquipmentCertifications(ctx context.Context, equipmentId int) ([]models.Certification, error)
+	Grant(ctx context.Context, grant models.UserCertification) error
+	Revoke(ctx context.Context, uid uuid.UUID, certId int) error
+	UserCertifications(ctx context.Context, uid uuid.UUID) ([]models.UserCertification, error)
+	MissingCertifications(ctx context.Context, uid uuid.UUID, equipmentId int, validUntil time.Time) ([]models.Certification, error)
+	NotifyExpiring(ctx context.Context, before time.Time) (int64, error)
+}
+
+func NewPostgresCertificationRepository(db db.PostgresDB) PostgresCertificationRepository {
+	return PostgresCertificationRepository{db: db}
+}
+
+func (p *PostgresCertificationRepository) CreateCertification(ctx context.Context, cert models.Certification) (int, error) {
+	const op = "certification_repository.CreateCertification"
+	var id int
+	err := p.db.DB.QueryRow(ctx, "INSERT INTO certifications (name, description, validity_days) VALUES($1, $2, $3) RETURNING id",
+		cert.Name, cert.Description, cert.ValidityDays).Scan(&id)
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == "23505" {
+			return 0, fmt.Errorf("%s: %w", op, ErrCertificationAlreadyExists)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (p *PostgresCertificationRepository) Certification(ctx context.Context, certId int) (*models.Certification, error) {
+	const op = "certification_repository.Certification"
+	var cert models.Certification
+	err := p.db.DB.QueryRow(ctx, "SELECT id, name, description, validity_days FROM certifications WHERE id = $1", certId).Scan(
+		&cert.Id, &cert.Name, &cert.Description, &cert.ValidityDays)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrCertificationNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &cert, nil
+}
+
+func (p *PostgresCertificationRepository) certifications(ctx context.Context, query string, args ...any) ([]models.Certification, error) {
+	rows, err := p.db.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	certs := []models.Certification{}
+	for rows.Next() {
+		var cert models.Certification
+		err := rows.Scan(&cert.Id, &cert.Name, &cert.Description, &cert.ValidityDays)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, rows.Err()
+}
+
+func (p *PostgresCertificationRepository) Certifications(ctx context.Context) ([]models.Certification, error) {
+	const op = "certification_repository.Certifications"
+	certs, err := p.certifications(ctx, "SELECT id, name, description, validity_days FROM certifications ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return certs, nil
+}
+
+func (p *PostgresCertificationRepository) SetEquipmentCertifications(ctx context.Context, equipmentId int, certIds []int) error {
+	const op = "certification_repository.SetEquipmentCertifications"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, "DELETE FROM equipment_certifications WHERE equipment_id = $1", equipmentId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for _, certId := range certIds {
+		_, err = tx.Exec(ctx, "INSERT INTO equipment_certifications (equipment_id, certification_id) VALUES($1, $2) "+
+			"ON CONFLICT DO NOTHING", equipmentId, certId)
+		if err != nil {
+			var pgxErr *pgconn.PgError
+			if errors.As(err, &pgxErr) && pgxErr.Code == "23503" {
+				if pgxErr.ConstraintName == "equipment_certifications_equipment_id_fkey" {
+					return fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+				}
+				return fmt.Errorf("%s: %w", op, ErrCertificationNotFound)
+			}
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (p *PostgresCertificationRepository) EquipmentCertifications(ctx context.Context, equipmentId int) ([]models.Certification, error) {
+	const op = "certification_repository.EquipmentCertifications"
+	certs, err := p.certifications(ctx, "SELECT c.id, c.name, c.description, c.validity_days FROM certifications c "+
+		"JOIN equipment_certifications ec ON ec.certification_id = c.id WHERE ec.equipment_id = $1 ORDER BY c.name", equipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return certs, nil
+}
+
+// Grant creates or renews a certification, a renewal gets a new expiry notice
+func (p *PostgresCertificationRepository) Grant(ctx context.Context, grant models.UserCertification) error {
+	const op = "certification_repository.Grant"
+	_, err := p.db.DB.Exec(ctx, "INSERT INTO user_certifications (user_id, certification_id, granted_by, expires_at) "+
+		"VALUES($1, $2, $3, $4) ON CONFLICT (user_id, certification_id) DO UPDATE SET granted_by = EXCLUDED.granted_by, "+
+		"granted_at = now(), expires_at = EXCLUDED.expires_at, expiry_notified_at = NULL",
+		grant.UserId, grant.CertificationId, grant.GrantedBy, grant.ExpiresAt)
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == "23503" {
+			if pgxErr.ConstraintName == "user_certifications_certification_id_fkey" {
+				return fmt.Errorf("%s: %w", op, ErrCertificationNotFound)
+			}
+			return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (p *PostgresCertificationRepository) Revoke(ctx context.Context, uid uuid.UUID, certId int) error {
+	const op = "certification_repository.Revoke"
+	tag, err := p.db.DB.Exec(ctx, "DELETE FROM user_certifications WHERE user_id = $1 AND certification_id = $2", uid, certId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrCertificationNotFound)
+	}
+	return nil
+}
+
+func (p *PostgresCertificationRepository) UserCertifications(ctx context.Context, uid uuid.UUID) ([]models.UserCertification, error) {
+	const op = "certification_repository.UserCertifications"
+	rows, err := p.db.DB.Query(ctx, "SELECT uc.user_id, uc.certification_id, c.name, uc.granted_by, uc.granted_at, uc.expires_at "+
+		"FROM user_certifications uc JOIN certifications c ON c.id = uc.certification_id WHERE uc.user_id = $1 ORDER BY c.name", uid)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	certs := []models.UserCertification{}
+	for rows.Next() {
+		var cert models.UserCertification
+		err := rows.Scan(&cert.UserId, &cert.CertificationId, &cert.Name, &cert.GrantedBy, &cert.GrantedAt, &cert.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		certs = append(certs, cert)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return certs, nil
+}
+
+// MissingCertifications returns the certifications the equipment requires that the user
+// doesn't hold or that expire before validUntil
+func (p *PostgresCertificationRepository) MissingCertifications(ctx context.Context, uid uuid.UUID, equipmentId int, validUntil time.Time) ([]models.Certification, error) {
+	const op = "certification_repository.MissingCertifications"
+	certs, err := p.certifications(ctx, "SELECT c.id, c.name, c.description, c.validity_days FROM equipment_certifications ec "+
+		"JOIN certifications c ON c.id = ec.certification_id "+
+		"LEFT JOIN user_certifications uc ON uc.certification_id = c.id AND uc.user_id = $2 AND uc.expires_at >= $3 "+
+		"WHERE ec.equipment_id = $1 AND uc.user_id IS NULL ORDER BY c.name", equipmentId, uid, validUntil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return certs, nil
+}
+
+// NotifyExpiring creates a notification for every certification expiring before the given
+// time that hasn't been notified yet. Marking and notifying happen in one statement, so
+// concurrent runs never notify twice
+func (p *PostgresCertificationRepository) NotifyExpiring(ctx context.Context, before time.Time) (int64, error) {
+	const op = "certification_repository.NotifyExpiring"
+	tag, err := p.db.DB.Exec(ctx, "WITH due AS ("+
+		"UPDATE user_certifications uc SET expiry_notified_at = now() FROM certifications c "+
+		"WHERE c.id = uc.certification_id AND uc.expiry_notified_at IS NULL AND uc.expires_at > now() AND uc.expires_at <= $1 "+
+		"RETURNING uc.user_id, uc.certification_id, c.name, uc.expires_at) "+
+		"INSERT INTO notifications (user_id, kind, message, payload) "+
+		"SELECT user_id, $2, 'Your certification \"' || name || '\" expires on ' || to_char(expires_at, 'YYYY-MM-DD'), "+
+		"jsonb_build_object('certification_id', certification_id, 'expires_at', expires_at) FROM due",
+		before, models.NotificationCertificationExpiring)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return tag.RowsAffected(), nil
+}
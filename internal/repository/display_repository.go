@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/pkg/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var ErrDisplayNotFound = errors.New("display not found")
+
+const displayColumns = "id, name, equipment_id, COALESCE(room, ''), created_at, revoked_at"
+
+type PostgresDisplayRepository struct {
+	db db.PostgresDB
+}
+
+type DisplayRepositoryInterface interface {
+	CreateDisplay(ctx context.Context, display models.Display, tokenHash string) (int, error)
+	Displays(ctx context.Context) ([]models.Display, error)
+	RevokeDisplay(ctx context.Context, displayId int) error
+	DisplayByToken(ctx context.Context, tokenHash string) (*models.Display, error)
+	DisplayEquipment(ctx context.Context, display models.Display) ([]models.Equipment, error)
+	BusySlots(ctx context.Context, equipmentIds []int, from, to time.Time) ([]models.Booking, error)
+}
+
+func NewPostgresDisplayRepository(db db.PostgresDB) PostgresDisplayRepository {
+	return PostgresDisplayRepository{db: db}
+}
+
+func scanDisplay(row pgx.Row, d *models.Display) error {
+	return row.Scan(&d.Id, &d.Name, &d.EquipmentId, &d.Room, &d.CreatedAt, &d.RevokedAt)
+}
+
+func (p *PostgresDisplayRepository) CreateDisplay(ctx context.Context, display models.Display, tokenHash string) (int, error) {
+	const op = "display_repository.CreateDisplay"
+	var id int
+	err := p.db.DB.QueryRow(ctx, "INSERT INTO displays (name, token_hash, equipment_id, room) VALUES($1, $2, $3, NULLIF($4, '')) RETURNING id",
+		display.Name, tokenHash, display.EquipmentId, display.Room).Scan(&id)
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == "23503" {
+			return 0, fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (p *PostgresDisplayRepository) Displays(ctx context.Context) ([]models.Display, error) {
+	const op = "display_repository.Displays"
+	rows, err := p.db.DB.Query(ctx, "SELECT "+displayColumns+" FROM displays ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	displays := []models.Display{}
+	for rows.Next() {
+		var d models.Display
+		err := scanDisplay(rows, &d)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		displays = append(displays, d)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return displays, nil
+}
+
+func (p *PostgresDisplayRepository) RevokeDisplay(ctx context.Context, displayId int) error {
+	const op = "display_repository.RevokeDisplay"
+	tag, err := p.db.DB.Exec(ctx, "UPDATE displays SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL", displayId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrDisplayNotFound)
+	}
+	return nil
+}
+
+func (p *PostgresDisplayRepository) DisplayByToken(ctx context.Context, tokenHash string) (*models.Display, error) {
+	const op = "display_repository.DisplayByToken"
+	var d models.Display
+	err := scanDisplay(p.db.DB.QueryRow(ctx, "SELECT "+displayColumns+" FROM displays WHERE token_hash = $1 AND revoked_at IS NULL",
+		tokenHash), &d)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrDisplayNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &d, nil
+}
+
+// DisplayEquipment returns the equipment shown on the display ordered by name
+func (p *PostgresDisplayRepository) DisplayEquipment(ctx context.Context, display models.Display) ([]models.Equipment, error) {
+	const op = "display_repository.DisplayEquipment"
+	rows, err := p.db.DB.Query(ctx, "SELECT "+equipmentColumns+" FROM equipment WHERE (id = $1 OR room = NULLIF($2, '')) AND "+notRetired+" "+
+		"ORDER BY equipment_name, id", display.EquipmentId, display.Room)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	equipment := []models.Equipment{}
+	for rows.Next() {
+		var eq models.Equipment
+		err := scanEquipment(rows, &eq)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		equipment = append(equipment, eq)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return equipment, nil
+}
+
+// BusySlots returns the active bookings of the equipment intersecting [from, to)
+func (p *PostgresDisplayRepository) BusySlots(ctx context.Context, equipmentIds []int, from, to time.Time) ([]models.Booking, error) {
+	const op = "display_repository.BusySlots"
+	rows, err := p.db.DB.Query(ctx, "SELECT "+bookingColumns+" FROM booking WHERE equipment_id = ANY($1) AND status = 'active' "+
+		"AND start_time < $3 AND end_time > $2 ORDER BY start_time", equipmentIds, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	bookings := []models.Booking{}
+	for rows.Next() {
+		var booking models.Booking
+		err := scanBooking(rows, &booking)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		bookings = append(bookings, booking)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return bookings, nil
+}
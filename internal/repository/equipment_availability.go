@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Gergenus/bookingService/internal/models"
+)
+
+// AvailableEquipment returns the equipment matching the catalog filters that can be booked for
+// the window: it is open for the whole window, no active booking overlaps it, no open allocation
+// round covers it and, when the equipment requires one, a technician is free to operate it
+func (p *PostgresLabRepository) AvailableEquipment(ctx context.Context, filter models.AvailabilityFilter) ([]models.AvailableEquipment, error) {
+	const op = "lab_repository.AvailableEquipment"
+	filter.After = nil
+	where, args := equipmentFilterSQL(filter.EquipmentFilter, "")
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	start, end, uid := arg(filter.Start), arg(filter.End), arg(filter.UserId)
+	conds := []string{where, "equipment.status = 'active'",
+		// overlap is inclusive as in CreateBooking
+		"NOT EXISTS (SELECT 1 FROM booking b WHERE b.equipment_id = equipment.id AND b.status = 'active' " +
+			"AND b.start_time <= " + end + " AND b.end_time >= " + start + ")",
+		"NOT EXISTS (SELECT 1 FROM allocation_rounds r WHERE r.equipment_id = equipment.id AND r.status = 'open' " +
+			"AND r.period_start < " + end + " AND r.period_end > " + start + ")",
+		openDuringSQL("equipment", start+"::timestamptz", end+"::timestamptz"),
+		operatorFreeSQL("equipment", start, end),
+	}
+	certified := "NOT EXISTS (SELECT 1 FROM equipment_certifications ec LEFT JOIN user_certifications uc " +
+		"ON uc.certification_id = ec.certification_id AND uc.user_id = " + uid + " AND uc.expires_at >= " + end + " " +
+		"WHERE ec.equipment_id = equipment.id AND uc.user_id IS NULL)"
+	if filter.CertifiedOnly {
+		conds = append(conds, certified)
+	}
+	rank := "0::real"
+	if filter.Query != "" {
+		q := arg(filter.Query)
+		query := "(websearch_to_tsquery('russian', " + q + ") || websearch_to_tsquery('english', " + q + "))"
+		conds = append(conds, "equipment.search_vector @@ "+query)
+		rank = "ts_rank_cd(equipment.search_vector, " + query + ")"
+	}
+	order := " ORDER BY "
+	if filter.MinCapacity > 0 {
+		c := arg(filter.MinCapacity)
+		conds = append(conds, "equipment.capacity >= "+c)
+		if filter.SortByMatch {
+			order += "rank DESC, equipment.capacity - " + c + ", "
+		}
+	} else if filter.SortByMatch {
+		order += "rank DESC, "
+	}
+	order += "equipment.equipment_name, equipment.id"
+	if filter.Limit > 0 {
+		order += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+	rows, err := p.db.DB.Query(ctx, "SELECT "+equipmentColumns+", "+rank+" AS rank, "+certified+
+		" FROM equipment WHERE "+strings.Join(conds, " AND ")+order, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	available := []models.AvailableEquipment{}
+	for rows.Next() {
+		var a models.AvailableEquipment
+		err := scanEquipment(rows, &a.Equipment, &a.Rank, &a.Certified)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		available = append(available, a)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return available, nil
+}
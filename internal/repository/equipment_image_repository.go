@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrEquipmentImageNotFound = errors.New("equipment image not found")
+	ErrTooManyImages          = errors.New("too many images")
+	ErrInvalidImageOrder      = errors.New("invalid image order")
+)
+
+const equipmentImageColumns = "id, equipment_id, object_name, caption, position, is_primary, created_at"
+
+func scanEquipmentImage(row pgx.Row, img *models.EquipmentImage) error {
+	return row.Scan(&img.Id, &img.EquipmentId, &img.ObjectName, &img.Caption, &img.Position, &img.Primary, &img.CreatedAt)
+}
+
+// lockEquipment serializes the gallery changes of the equipment
+func lockEquipment(ctx context.Context, tx pgx.Tx, equipmentId int) error {
+	var id int
+	err := tx.QueryRow(ctx, "SELECT id FROM equipment WHERE id = $1 FOR UPDATE", equipmentId).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrEquipmentNotFound
+	}
+	return err
+}
+
+// syncPrimaryImage copies the object of the primary image to equipment.image_url, which lists
+// and older clients read
+func syncPrimaryImage(ctx context.Context, tx pgx.Tx, equipmentId int) error {
+	_, err := tx.Exec(ctx, "UPDATE equipment SET image_url = COALESCE((SELECT object_name FROM equipment_images "+
+		"WHERE equipment_id = $1 AND is_primary), ''), version = version + 1 WHERE id = $1", equipmentId)
+	return err
+}
+
+// AddEquipmentImage appends the image to the gallery. The first image becomes primary, as does
+// one added with Primary set
+func (p *PostgresLabRepository) AddEquipmentImage(ctx context.Context, image models.EquipmentImage, maxImages int) (*models.EquipmentImage, error) {
+	const op = "lab_repository.AddEquipmentImage"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = lockEquipment(ctx, tx, image.EquipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	// the gallery of retired equipment was purged, a new image would never be removed
+	var inService bool
+	err = tx.QueryRow(ctx, "SELECT "+notRetired+" FROM equipment WHERE id = $1", image.EquipmentId).Scan(&inService)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !inService {
+		return nil, fmt.Errorf("%s: %w", op, ErrEquipmentRetired)
+	}
+	var (
+		count    int
+		position int
+	)
+	err = tx.QueryRow(ctx, "SELECT COUNT(*), COALESCE(MAX(position) + 1, 0) FROM equipment_images WHERE equipment_id = $1",
+		image.EquipmentId).Scan(&count, &position)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if count >= maxImages {
+		return nil, fmt.Errorf("%s: %w", op, ErrTooManyImages)
+	}
+	primary := image.Primary || count == 0
+	if primary {
+		_, err = tx.Exec(ctx, "UPDATE equipment_images SET is_primary = false WHERE equipment_id = $1 AND is_primary", image.EquipmentId)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	var added models.EquipmentImage
+	err = scanEquipmentImage(tx.QueryRow(ctx, "INSERT INTO equipment_images (equipment_id, object_name, caption, position, is_primary) "+
+		"VALUES($1, $2, $3, $4, $5) RETURNING "+equipmentImageColumns,
+		image.EquipmentId, image.ObjectName, image.Caption, position, primary), &added)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if primary {
+		err = syncPrimaryImage(ctx, tx, image.EquipmentId)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &added, nil
+}
+
+// EquipmentImages returns the gallery in display order
+func (p *PostgresLabRepository) EquipmentImages(ctx context.Context, equipmentId int) ([]models.EquipmentImage, error) {
+	const op = "lab_repository.EquipmentImages"
+	rows, err := p.db.DB.Query(ctx, "SELECT "+equipmentImageColumns+" FROM equipment_images WHERE equipment_id = $1 "+
+		"ORDER BY position, id", equipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	images := []models.EquipmentImage{}
+	for rows.Next() {
+		var img models.EquipmentImage
+		err := scanEquipmentImage(rows, &img)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		images = append(images, img)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return images, nil
+}
+
+// UpdateEquipmentImage changes the caption and makes the image primary if asked to
+func (p *PostgresLabRepository) UpdateEquipmentImage(ctx context.Context, equipmentId, imageId int,
+	update models.EquipmentImageUpdate) (*models.EquipmentImage, error) {
+	const op = "lab_repository.UpdateEquipmentImage"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = lockEquipment(ctx, tx, equipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if update.Primary {
+		_, err = tx.Exec(ctx, "UPDATE equipment_images SET is_primary = false WHERE equipment_id = $1 AND is_primary AND id <> $2",
+			equipmentId, imageId)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	var img models.EquipmentImage
+	err = scanEquipmentImage(tx.QueryRow(ctx, "UPDATE equipment_images SET caption = COALESCE($3, caption), "+
+		"is_primary = is_primary OR $4 WHERE id = $2 AND equipment_id = $1 RETURNING "+equipmentImageColumns,
+		equipmentId, imageId, update.Caption, update.Primary), &img)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrEquipmentImageNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if update.Primary {
+		err = syncPrimaryImage(ctx, tx, equipmentId)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &img, nil
+}
+
+// ReorderEquipmentImages puts the gallery in the order of imageIds, which has to list every
+// image of the equipment exactly once
+func (p *PostgresLabRepository) ReorderEquipmentImages(ctx context.Context, equipmentId int, imageIds []int) error {
+	const op = "lab_repository.ReorderEquipmentImages"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = lockEquipment(ctx, tx, equipmentId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	var matches bool
+	err = tx.QueryRow(ctx, "SELECT COALESCE(ARRAY_AGG(id ORDER BY id), '{}') = "+
+		"(SELECT COALESCE(ARRAY_AGG(DISTINCT i ORDER BY i), '{}') FROM UNNEST($2::int[]) i) AND CARDINALITY($2::int[]) = COUNT(*) "+
+		"FROM equipment_images WHERE equipment_id = $1", equipmentId, imageIds).Scan(&matches)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !matches {
+		return fmt.Errorf("%s: %w", op, ErrInvalidImageOrder)
+	}
+	_, err = tx.Exec(ctx, "UPDATE equipment_images SET position = o.n - 1 FROM UNNEST($2::int[]) WITH ORDINALITY AS o(id, n) "+
+		"WHERE equipment_images.id = o.id AND equipment_images.equipment_id = $1", equipmentId, imageIds)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// DeleteEquipmentImage removes the image from the gallery and returns its object. When the
+// primary image is removed the first remaining one takes its place
+func (p *PostgresLabRepository) DeleteEquipmentImage(ctx context.Context, equipmentId, imageId int) (string, error) {
+	const op = "lab_repository.DeleteEquipmentImage"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = lockEquipment(ctx, tx, equipmentId)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	var (
+		objectName string
+		primary    bool
+	)
+	err = tx.QueryRow(ctx, "DELETE FROM equipment_images WHERE id = $2 AND equipment_id = $1 RETURNING object_name, is_primary",
+		equipmentId, imageId).Scan(&objectName, &primary)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", fmt.Errorf("%s: %w", op, ErrEquipmentImageNotFound)
+		}
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	if primary {
+		_, err = tx.Exec(ctx, "UPDATE equipment_images SET is_primary = true WHERE id = "+
+			"(SELECT id FROM equipment_images WHERE equipment_id = $1 ORDER BY position, id LIMIT 1)", equipmentId)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+		err = syncPrimaryImage(ctx, tx, equipmentId)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	return objectName, nil
+}
+
+// DeleteEquipmentImages empties the gallery and returns the objects of the removed images
+func (p *PostgresLabRepository) DeleteEquipmentImages(ctx context.Context, equipmentId int) ([]string, error) {
+	const op = "lab_repository.DeleteEquipmentImages"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = lockEquipment(ctx, tx, equipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	var objects []string
+	err = tx.QueryRow(ctx, "WITH d AS (DELETE FROM equipment_images WHERE equipment_id = $1 RETURNING object_name) "+
+		"SELECT COALESCE(ARRAY_AGG(object_name), '{}') FROM d", equipmentId).Scan(&objects)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	err = syncPrimaryImage(ctx, tx, equipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return objects, nil
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// filter dimensions a facet can leave out
+const (
+	facetCategory     = "category"
+	facetManufacturer = "manufacturer"
+	facetRoom         = "room"
+)
+
+// equipmentFilterSQL builds the WHERE clause of equipment browsing over the equipment table,
+// skip leaves one dimension out for its facet. Retired equipment never matches
+func equipmentFilterSQL(filter models.EquipmentFilter, skip string) (string, []any) {
+	var (
+		conds = []string{notRetired}
+		args  []any
+	)
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if filter.CategoryId != 0 && skip != facetCategory {
+		add("equipment.category_id IN (WITH RECURSIVE sub AS (SELECT id FROM categories WHERE id = $%d "+
+			"UNION ALL SELECT c.id FROM categories c JOIN sub ON c.parent_id = sub.id) SELECT id FROM sub)", filter.CategoryId)
+	}
+	for _, tag := range filter.Tags {
+		add("EXISTS (SELECT 1 FROM equipment_tags WHERE equipment_tags.equipment_id = equipment.id AND equipment_tags.tag = $%d)", tag)
+	}
+	if filter.Manufacturer != "" && skip != facetManufacturer {
+		add("LOWER(equipment.manufacturer) = LOWER($%d)", filter.Manufacturer)
+	}
+	if filter.Room != "" && skip != facetRoom {
+		add("LOWER(equipment.room) = LOWER($%d)", filter.Room)
+	}
+	if filter.After != nil {
+		args = append(args, filter.After.Name, filter.After.Id)
+		conds = append(conds, fmt.Sprintf("(equipment.equipment_name, equipment.id) > ($%d, $%d)", len(args)-1, len(args)))
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// SetCategory moves the equipment to the category, nil leaves it uncategorized
+func (p *PostgresLabRepository) SetCategory(ctx context.Context, equipmentId int, categoryId *int) error {
+	const op = "lab_repository.SetCategory"
+	tag, err := p.db.DB.Exec(ctx, "UPDATE equipment SET category_id = $2 WHERE id = $1", equipmentId, categoryId)
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == "23503" {
+			return fmt.Errorf("%s: %w", op, ErrCategoryNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+	}
+	return nil
+}
+
+// SetTags replaces all tags of the equipment
+func (p *PostgresLabRepository) SetTags(ctx context.Context, equipmentId int, tags []string) error {
+	const op = "lab_repository.SetTags"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	err = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM equipment WHERE id = $1)", equipmentId).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+	}
+	_, err = tx.Exec(ctx, "DELETE FROM equipment_tags WHERE equipment_id = $1", equipmentId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	_, err = tx.Exec(ctx, "INSERT INTO equipment_tags (equipment_id, tag) SELECT $1, UNNEST($2::text[]) ON CONFLICT DO NOTHING",
+		equipmentId, tags)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// BrowseEquipment returns a page of the matching equipment ordered by name
+func (p *PostgresLabRepository) BrowseEquipment(ctx context.Context, filter models.EquipmentFilter) ([]models.Equipment, error) {
+	const op = "lab_repository.BrowseEquipment"
+	where, args := equipmentFilterSQL(filter, "")
+	query := "SELECT " + equipmentColumns + " FROM equipment WHERE " + where + " ORDER BY equipment.equipment_name, equipment.id"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+	rows, err := p.db.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	equipment := []models.Equipment{}
+	for rows.Next() {
+		var eq models.Equipment
+		err := scanEquipment(rows, &eq)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		equipment = append(equipment, eq)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return equipment, nil
+}
+
+// CountEquipment returns the number of matches of the filter without the cursor
+func (p *PostgresLabRepository) CountEquipment(ctx context.Context, filter models.EquipmentFilter) (int, error) {
+	const op = "lab_repository.CountEquipment"
+	filter.After = nil
+	where, args := equipmentFilterSQL(filter, "")
+	var total int
+	err := p.db.DB.QueryRow(ctx, "SELECT COUNT(*) FROM equipment WHERE "+where, args...).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return total, nil
+}
+
+// EquipmentFacets counts the matching equipment per category, tag, manufacturer and room.
+// A category counts the equipment of its subcategories too
+func (p *PostgresLabRepository) EquipmentFacets(ctx context.Context, filter models.EquipmentFilter) (*models.EquipmentFacets, error) {
+	const op = "lab_repository.EquipmentFacets"
+	facets := &models.EquipmentFacets{}
+	filter.After = nil
+
+	where, args := equipmentFilterSQL(filter, facetCategory)
+	rows, err := p.db.DB.Query(ctx, categoryTree+", closure AS (SELECT id AS root, id FROM categories "+
+		"UNION ALL SELECT closure.root, c.id FROM categories c JOIN closure ON c.parent_id = closure.id) "+
+		"SELECT tree.id, tree.path, COUNT(DISTINCT equipment.id) FROM tree JOIN closure ON closure.root = tree.id "+
+		"JOIN equipment ON equipment.category_id = closure.id WHERE "+where+" GROUP BY tree.id, tree.path ORDER BY tree.path", args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	facets.Categories = []models.CategoryFacet{}
+	for rows.Next() {
+		var f models.CategoryFacet
+		err := rows.Scan(&f.Id, &f.Path, &f.Count)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		facets.Categories = append(facets.Categories, f)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+
+	where, args = equipmentFilterSQL(filter, "")
+	facets.Tags, err = p.facetCounts(ctx, "SELECT equipment_tags.tag, COUNT(*) FROM equipment "+
+		"JOIN equipment_tags ON equipment_tags.equipment_id = equipment.id WHERE "+where+
+		" GROUP BY equipment_tags.tag ORDER BY COUNT(*) DESC, equipment_tags.tag", args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	// manufacturers and rooms are filtered case-insensitively, so their values are grouped the same
+	// way and shown in the most common spelling
+	where, args = equipmentFilterSQL(filter, facetManufacturer)
+	facets.Manufacturers, err = p.facetCounts(ctx, "SELECT mode() WITHIN GROUP (ORDER BY equipment.manufacturer), COUNT(*) "+
+		"FROM equipment WHERE "+where+" AND COALESCE(equipment.manufacturer, '') <> '' "+
+		"GROUP BY LOWER(equipment.manufacturer) ORDER BY LOWER(equipment.manufacturer)", args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	where, args = equipmentFilterSQL(filter, facetRoom)
+	facets.Rooms, err = p.facetCounts(ctx, "SELECT mode() WITHIN GROUP (ORDER BY equipment.room), COUNT(*) FROM equipment WHERE "+where+
+		" AND equipment.room IS NOT NULL GROUP BY LOWER(equipment.room) ORDER BY LOWER(equipment.room)", args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return facets, nil
+}
+
+func (p *PostgresLabRepository) facetCounts(ctx context.Context, query string, args []any) ([]models.FacetCount, error) {
+	rows, err := p.db.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := []models.FacetCount{}
+	for rows.Next() {
+		var f models.FacetCount
+		err := rows.Scan(&f.Value, &f.Count)
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, f)
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// a query matches if it matches in either language
+	searchQuery = "WITH q AS (SELECT websearch_to_tsquery('russian', $1) || websearch_to_tsquery('english', $1) AS query) "
+	// the russian configuration stems latin words with the english stemmer, so one configuration
+	// highlights both languages. The text is escaped before the <mark> tags are added
+	searchHeadline      = "ts_headline('russian', replace(replace(replace(%s, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), q.query, '%s')"
+	nameHeadlineOpts    = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+	snippetHeadlineOpts = `StartSel=<mark>, StopSel=</mark>, MaxWords=25, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "`
+)
+
+// SearchEquipment returns the full-text matches of the query ranked by relevance, after the
+// cursor if one is given
+func (p *PostgresLabRepository) SearchEquipment(ctx context.Context, query string, after *models.EquipmentSearchCursor,
+	limit int) ([]models.EquipmentSearchHit, error) {
+	const op = "lab_repository.SearchEquipment"
+	args := []any{query}
+	cond := ""
+	if after != nil {
+		args = append(args, after.Rank, after.Id)
+		cond = " AND (ts_rank_cd(equipment.search_vector, q.query) < $2::real OR " +
+			"(ts_rank_cd(equipment.search_vector, q.query) = $2::real AND equipment.id > $3))"
+	}
+	rows, err := p.db.DB.Query(ctx, searchQuery+"SELECT "+equipmentColumns+", ts_rank_cd(equipment.search_vector, q.query) AS rank, "+
+		fmt.Sprintf(searchHeadline, "equipment.equipment_name", nameHeadlineOpts)+", "+
+		fmt.Sprintf(searchHeadline, "equipment.description", snippetHeadlineOpts)+
+		" FROM equipment, q WHERE equipment.search_vector @@ q.query AND "+notRetired+cond+
+		fmt.Sprintf(" ORDER BY rank DESC, equipment.id LIMIT %d", limit), args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	hits := []models.EquipmentSearchHit{}
+	for rows.Next() {
+		var (
+			hit models.EquipmentSearchHit
+			eq  = &hit.Equipment
+		)
+		err := scanEquipment(rows, eq, &hit.Rank, &hit.NameHighlight, &hit.Snippet)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		hits = append(hits, hit)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return hits, nil
+}
+
+// CountSearchMatches returns the number of equipment matching the query
+func (p *PostgresLabRepository) CountSearchMatches(ctx context.Context, query string) (int, error) {
+	const op = "lab_repository.CountSearchMatches"
+	var total int
+	err := p.db.DB.QueryRow(ctx, searchQuery+"SELECT COUNT(*) FROM equipment, q WHERE equipment.search_vector @@ q.query AND "+notRetired,
+		query).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return total, nil
+}
+
+// likeEscaper makes user input literal inside a LIKE pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// words of the query have to be at least this similar to a word of the name or the manufacturer
+const fuzzyThreshold = 0.3
+
+// fuzzyTx starts a read transaction where <% matches at fuzzyThreshold. Filtering with the operator
+// instead of comparing word_similarity lets the trigram indexes on the lowercased names be used
+func (p *PostgresLabRepository) fuzzyTx(ctx context.Context) (pgx.Tx, error) {
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(ctx, fmt.Sprintf("SET LOCAL pg_trgm.word_similarity_threshold = %g", fuzzyThreshold))
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, err
+	}
+	return tx, nil
+}
+
+// FuzzySearchEquipment is the typo tolerant fallback of the full-text search, it matches the query
+// against name and manufacturer by trigram word similarity
+func (p *PostgresLabRepository) FuzzySearchEquipment(ctx context.Context, query string, limit int) ([]models.EquipmentSearchHit, error) {
+	const op = "lab_repository.FuzzySearchEquipment"
+	tx, err := p.fuzzyTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, "SELECT "+equipmentColumns+", GREATEST(word_similarity($1, LOWER(equipment_name)), "+
+		"COALESCE(word_similarity($1, LOWER(manufacturer)), 0))::real AS rank, "+
+		"replace(replace(replace(equipment.equipment_name, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), "+
+		"replace(replace(replace(LEFT(equipment.description, 200), '&', '&amp;'), '<', '&lt;'), '>', '&gt;') "+
+		"FROM equipment WHERE ($1 <% LOWER(equipment_name) OR $1 <% LOWER(manufacturer)) AND "+notRetired+
+		fmt.Sprintf(" ORDER BY rank DESC, equipment.id LIMIT %d", limit), strings.ToLower(query))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	hits := []models.EquipmentSearchHit{}
+	for rows.Next() {
+		var (
+			hit = models.EquipmentSearchHit{Fuzzy: true}
+			eq  = &hit.Equipment
+		)
+		err := scanEquipment(rows, eq, &hit.Rank, &hit.NameHighlight, &hit.Snippet)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		hits = append(hits, hit)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return hits, nil
+}
+
+// Suggestions completes the prefix with equipment names, manufacturers and category names.
+// Prefix matches come first, then the closest trigram matches. Every branch filters on its
+// indexed lowercased name, the ranking only looks at the matches
+func (p *PostgresLabRepository) Suggestions(ctx context.Context, prefix string, limit int) ([]models.Suggestion, error) {
+	const op = "lab_repository.Suggestions"
+	tx, err := p.fuzzyTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	key := strings.ToLower(prefix)
+	pattern := likeEscaper.Replace(key) + "%"
+	rows, err := tx.Query(ctx, "SELECT type, value, id FROM ("+
+		"SELECT 'equipment' AS type, equipment_name AS value, id, LOWER(equipment_name) AS key FROM equipment "+
+		"WHERE (LOWER(equipment_name) LIKE $1 OR $2 <% LOWER(equipment_name)) AND "+notRetired+" "+
+		"UNION ALL SELECT DISTINCT ON (LOWER(manufacturer)) 'manufacturer', manufacturer, NULL::int, LOWER(manufacturer) FROM equipment "+
+		"WHERE manufacturer <> '' AND (LOWER(manufacturer) LIKE $1 OR $2 <% LOWER(manufacturer)) AND "+notRetired+" "+
+		"UNION ALL SELECT 'category', name, id, LOWER(name) FROM categories WHERE LOWER(name) LIKE $1 OR $2 <% LOWER(name)) s "+
+		"ORDER BY key LIKE $1 DESC, word_similarity($2, key) DESC, value LIMIT $3", pattern, key, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	suggestions := []models.Suggestion{}
+	for rows.Next() {
+		var s models.Suggestion
+		err := rows.Scan(&s.Type, &s.Value, &s.Id)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		suggestions = append(suggestions, s)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/pkg/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrGroupAlreadyExists = errors.New("group already exists")
+	ErrGroupNotFound      = errors.New("group not found")
+	ErrQuotaExceeded      = errors.New("group booking quota exceeded")
+)
+
+// bookedHoursQuery sums the active bookings of a group in a period, except for the booking $4
+const bookedHoursQuery = "SELECT COALESCE(SUM(EXTRACT(EPOCH FROM end_time - start_time)), 0) / 3600 FROM booking " +
+	"WHERE group_id = $1 AND status = 'active' AND start_time >= $2 AND start_time < $3 AND id <> $4"
+
+type PostgresGroupRepository struct {
+	db db.PostgresDB
+}
+
+type GroupRepositoryInterface interface {
+	CreateGroup(ctx context.Context, group models.Group) (int, error)
+	Group(ctx context.Context, groupId int) (*models.Group, error)
+	UserGroups(ctx context.Context, uid uuid.UUID) ([]models.Group, error)
+	Members(ctx context.Context, groupId int) ([]models.GroupMember, error)
+	AddMember(ctx context.Context, member models.GroupMember) error
+	RemoveMember(ctx context.Context, groupId int, uid uuid.UUID) error
+	Membership(ctx context.Context, groupId int, uid uuid.UUID) (bool, bool, error)
+	IsPIOf(ctx context.Context, pi, member uuid.UUID) (bool, error)
+	SetQuota(ctx context.Context, groupId int, quotaHours *float64) error
+	SetShare(ctx context.Context, groupId int, share float64) error
+	BookedHours(ctx context.Context, groupId int, from, to time.Time) (float64, error)
+}
+
+func NewPostgresGroupRepository(db db.PostgresDB) PostgresGroupRepository {
+	return PostgresGroupRepository{db: db}
+}
+
+// CreateGroup creates the group with its PI as the first group admin
+func (p *PostgresGroupRepository) CreateGroup(ctx context.Context, group models.Group) (int, error) {
+	const op = "group_repository.CreateGroup"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var id int
+	err = tx.QueryRow(ctx, "INSERT INTO research_groups (name, pi_id, quota_hours, share) VALUES($1, $2, $3, $4) RETURNING id",
+		group.Name, group.PIId, group.QuotaHours, group.Share).Scan(&id)
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) {
+			switch pgxErr.Code {
+			case "23505":
+				return 0, fmt.Errorf("%s: %w", op, ErrGroupAlreadyExists)
+			case "23503":
+				return 0, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+			}
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if group.PIId != nil {
+		_, err = tx.Exec(ctx, "INSERT INTO group_members (group_id, user_id, is_admin) VALUES($1, $2, true)", id, group.PIId)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (p *PostgresGroupRepository) Group(ctx context.Context, groupId int) (*models.Group, error) {
+	const op = "group_repository.Group"
+	var group models.Group
+	err := p.db.DB.QueryRow(ctx, "SELECT id, name, pi_id, quota_hours, share FROM research_groups WHERE id = $1", groupId).Scan(
+		&group.Id, &group.Name, &group.PIId, &group.QuotaHours, &group.Share)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrGroupNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &group, nil
+}
+
+func (p *PostgresGroupRepository) UserGroups(ctx context.Context, uid uuid.UUID) ([]models.Group, error) {
+	const op = "group_repository.UserGroups"
+	rows, err := p.db.DB.Query(ctx, "SELECT g.id, g.name, g.pi_id, g.quota_hours, g.share FROM research_groups g "+
+		"JOIN group_members m ON m.group_id = g.id WHERE m.user_id = $1 ORDER BY g.name", uid)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	groups := []models.Group{}
+	for rows.Next() {
+		var group models.Group
+		err := rows.Scan(&group.Id, &group.Name, &group.PIId, &group.QuotaHours, &group.Share)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		groups = append(groups, group)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return groups, nil
+}
+
+func (p *PostgresGroupRepository) Members(ctx context.Context, groupId int) ([]models.GroupMember, error) {
+	const op = "group_repository.Members"
+	rows, err := p.db.DB.Query(ctx, "SELECT m.group_id, m.user_id, u.username, m.is_admin FROM group_members m "+
+		"JOIN users u ON u.uid = m.user_id WHERE m.group_id = $1 ORDER BY u.username", groupId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	members := []models.GroupMember{}
+	for rows.Next() {
+		var member models.GroupMember
+		err := rows.Scan(&member.GroupId, &member.UserId, &member.Username, &member.IsAdmin)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		members = append(members, member)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return members, nil
+}
+
+func (p *PostgresGroupRepository) AddMember(ctx context.Context, member models.GroupMember) error {
+	const op = "group_repository.AddMember"
+	_, err := p.db.DB.Exec(ctx, "INSERT INTO group_members (group_id, user_id, is_admin) VALUES($1, $2, $3) "+
+		"ON CONFLICT (group_id, user_id) DO UPDATE SET is_admin = EXCLUDED.is_admin", member.GroupId, member.UserId, member.IsAdmin)
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == "23503" {
+			return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (p *PostgresGroupRepository) RemoveMember(ctx context.Context, groupId int, uid uuid.UUID) error {
+	const op = "group_repository.RemoveMember"
+	_, err := p.db.DB.Exec(ctx, "DELETE FROM group_members WHERE group_id = $1 AND user_id = $2", groupId, uid)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// returns whether the user is a member and whether they are a group admin
+func (p *PostgresGroupRepository) Membership(ctx context.Context, groupId int, uid uuid.UUID) (bool, bool, error) {
+	const op = "group_repository.Membership"
+	var isAdmin bool
+	err := p.db.DB.QueryRow(ctx, "SELECT is_admin FROM group_members WHERE group_id = $1 AND user_id = $2", groupId, uid).Scan(&isAdmin)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, false, nil
+		}
+		return false, false, fmt.Errorf("%s: %w", op, err)
+	}
+	return true, isAdmin, nil
+}
+
+// IsPIOf reports whether pi is the PI of any group the member belongs to
+func (p *PostgresGroupRepository) IsPIOf(ctx context.Context, pi, member uuid.UUID) (bool, error) {
+	const op = "group_repository.IsPIOf"
+	var ok bool
+	err := p.db.DB.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM research_groups g JOIN group_members m ON m.group_id = g.id "+
+		"WHERE g.pi_id = $1 AND m.user_id = $2)", pi, member).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return ok, nil
+}
+
+func (p *PostgresGroupRepository) SetQuota(ctx context.Context, groupId int, quotaHours *float64) error {
+	const op = "group_repository.SetQuota"
+	tag, err := p.db.DB.Exec(ctx, "UPDATE research_groups SET quota_hours = $2 WHERE id = $1", groupId, quotaHours)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrGroupNotFound)
+	}
+	return nil
+}
+
+func (p *PostgresGroupRepository) SetShare(ctx context.Context, groupId int, share float64) error {
+	const op = "group_repository.SetShare"
+	tag, err := p.db.DB.Exec(ctx, "UPDATE research_groups SET share = $2 WHERE id = $1", groupId, share)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrGroupNotFound)
+	}
+	return nil
+}
+
+func (p *PostgresGroupRepository) BookedHours(ctx context.Context, groupId int, from, to time.Time) (float64, error) {
+	const op = "group_repository.BookedHours"
+	var hours float64
+	err := p.db.DB.QueryRow(ctx, bookedHoursQuery, groupId, from, to, 0).Scan(&hours)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return hours, nil
+}
+
+// checkGroupQuota locks the group the booking is charged to and checks that the booking fits
+// into the group's quota for the month it starts in, a moved booking is counted with its new
+// slot only. Concurrent bookings of the group wait for the lock, so together they can't exceed
+// the quota either
+func checkGroupQuota(ctx context.Context, tx pgx.Tx, booking models.Booking) error {
+	if booking.GroupId == 0 {
+		return nil
+	}
+	var quotaHours *float64
+	err := tx.QueryRow(ctx, "SELECT quota_hours FROM research_groups WHERE id = $1 FOR UPDATE", booking.GroupId).Scan(&quotaHours)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrGroupNotFound
+		}
+		return err
+	}
+	if quotaHours == nil {
+		return nil
+	}
+	start := booking.StartTime
+	monthStart := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location())
+	var used float64
+	err = tx.QueryRow(ctx, bookedHoursQuery, booking.GroupId, monthStart, monthStart.AddDate(0, 1, 0), booking.Id).Scan(&used)
+	if err != nil {
+		return err
+	}
+	if used+booking.EndTime.Sub(booking.StartTime).Hours() > *quotaHours {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
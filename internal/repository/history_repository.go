@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// execer is implemented by both the pool and a transaction
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+func addHistory(ctx context.Context, db execer, event models.BookingEvent) error {
+	details := event.Details
+	if details == nil {
+		details = map[string]any{}
+	}
+	_, err := db.Exec(ctx, "INSERT INTO booking_history (booking_id, action, actor_id, details) VALUES($1, $2, $3, $4)",
+		event.BookingId, event.Action, event.ActorId, details)
+	return err
+}
+
+func (p *PostgresBookingRepository) History(ctx context.Context, bookingId int) ([]models.BookingEvent, error) {
+	const op = "booking_repository.History"
+	rows, err := p.db.DB.Query(ctx, "SELECT id, booking_id, action, actor_id, details, created_at FROM booking_history "+
+		"WHERE booking_id = $1 ORDER BY id", bookingId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	events := []models.BookingEvent{}
+	for rows.Next() {
+		var e models.BookingEvent
+		err := rows.Scan(&e.Id, &e.BookingId, &e.Action, &e.ActorId, &e.Details, &e.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		events = append(events, e)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return events, nil
+}
@@ -1,33 +1,79 @@
 package repository
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
+	"net/http"
+	"net/url"
 	"path/filepath"
+	"strconv"
+	"time"
 
+	"github.com/Gergenus/bookingService/pkg/hash"
+	"github.com/Gergenus/bookingService/pkg/imaging"
 	"github.com/google/uuid"
 	"github.com/minio/minio-go/v7"
 )
 
+var ErrImageNotFound = errors.New("image not found")
+
+// image links are valid for one to two hours, see ImageURL
+const imageURLTTL = time.Hour
+
 type MinioImageRepository struct {
 	minioClient *minio.Client
 	bucketName  string
 	endpoint    string
+	urlSecret   string
 }
 
 type ImageRepositoryInterface interface {
 	AddImage(ctx context.Context, image *multipart.FileHeader) (string, error)
 	DeleteImage(ctx context.Context, objectName string) error
 	SignURL(ctx context.Context, imagePath string) (*minio.Object, error)
+	Image(ctx context.Context, objectName, rendition string) (*minio.Object, minio.ObjectInfo, error)
+	ImageURL(objectName, rendition string) string
+	VerifyImageURL(objectName, rendition string, expires int64, signature string) bool
+	AddAttachment(ctx context.Context, file *multipart.FileHeader) (string, string, error)
 }
 
-func NewMinioImageRepository(minioClient *minio.Client, bucketName string, endpoint string) *MinioImageRepository {
+func NewMinioImageRepository(minioClient *minio.Client, bucketName string, endpoint string, urlSecret string) *MinioImageRepository {
 	return &MinioImageRepository{
 		minioClient: minioClient,
 		bucketName:  bucketName,
 		endpoint:    endpoint,
+		urlSecret:   urlSecret,
+	}
+}
+
+func imageURLMessage(objectName, rendition string, expires int64) string {
+	return objectName + "\n" + rendition + "\n" + strconv.FormatInt(expires, 10)
+}
+
+// ImageURL returns a signed link to the rendition of the image, the original when rendition
+// is empty. The expiry is rounded up to the next full period, so the link stays the same
+// and cacheable for a while and is valid for one to two periods
+func (m *MinioImageRepository) ImageURL(objectName, rendition string) string {
+	expires := time.Now().Truncate(imageURLTTL).Add(2 * imageURLTTL).Unix()
+	query := url.Values{}
+	if rendition != "" {
+		query.Set("size", rendition)
 	}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("sig", hash.Sign(m.urlSecret, imageURLMessage(objectName, rendition, expires)))
+	return "/api/v1/images/" + url.PathEscape(objectName) + "?" + query.Encode()
+}
+
+// VerifyImageURL checks a link made by ImageURL
+func (m *MinioImageRepository) VerifyImageURL(objectName, rendition string, expires int64, signature string) bool {
+	if time.Now().Unix() > expires {
+		return false
+	}
+	return hash.ValidSignature(m.urlSecret, imageURLMessage(objectName, rendition, expires), signature)
 }
 
 func (m *MinioImageRepository) SignURL(ctx context.Context, imagePath string) (*minio.Object, error) {
@@ -39,30 +85,146 @@ func (m *MinioImageRepository) SignURL(ctx context.Context, imagePath string) (*
 	return obj, nil
 }
 
-// returns url
+// renditionObject is where a rendition of the image is stored, next to the original
+func renditionObject(objectName, rendition string) string {
+	return rendition + "/" + objectName
+}
+
+// AddImage validates the upload, strips its metadata and stores it with its renditions.
+// It returns the object name of the original
 func (m *MinioImageRepository) AddImage(ctx context.Context, image *multipart.FileHeader) (string, error) {
 	const op = "image_repository.AddImage"
+	if image.Size > imaging.MaxSize {
+		return "", fmt.Errorf("%s: %w", op, imaging.ErrTooLarge)
+	}
 	fileToAdd, err := image.Open()
 	if err != nil {
 		return "", fmt.Errorf("%s: %w", op, err)
 	}
+	defer fileToAdd.Close()
+	data, err := io.ReadAll(io.LimitReader(fileToAdd, imaging.MaxSize+1))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	processed, err := imaging.Process(data)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
 
-	ext := filepath.Ext(image.Filename)
-	objectName := fmt.Sprintf("%s%s", uuid.NewString(), ext)
-	_, err = m.minioClient.PutObject(ctx, m.bucketName, objectName, fileToAdd, image.Size, minio.PutObjectOptions{
-		ContentType: ".jpg",
-	})
+	objectName := uuid.NewString() + processed.Original.Ext
+	err = m.putObject(ctx, objectName, processed.Original)
 	if err != nil {
 		return "", fmt.Errorf("%s: %w", op, err)
 	}
+	for _, r := range imaging.Renditions {
+		err = m.putObject(ctx, renditionObject(objectName, r.Name), processed.Renditions[r.Name])
+		if err != nil {
+			m.DeleteImage(ctx, objectName)
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+	}
 	return objectName, nil
 }
 
+func (m *MinioImageRepository) putObject(ctx context.Context, objectName string, img imaging.Image) error {
+	_, err := m.minioClient.PutObject(ctx, m.bucketName, objectName, bytes.NewReader(img.Data), int64(len(img.Data)),
+		minio.PutObjectOptions{ContentType: img.ContentType})
+	return err
+}
+
+// Image opens the rendition of the image, the original when rendition is empty. Images stored
+// before renditions were made fall back to the original
+func (m *MinioImageRepository) Image(ctx context.Context, objectName, rendition string) (*minio.Object, minio.ObjectInfo, error) {
+	const op = "image_repository.Image"
+	if rendition != "" {
+		obj, info, err := m.statObject(ctx, renditionObject(objectName, rendition))
+		if err == nil {
+			return obj, info, nil
+		}
+		if minio.ToErrorResponse(err).Code != minio.NoSuchKey {
+			return nil, minio.ObjectInfo{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	obj, info, err := m.statObject(ctx, objectName)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+			return nil, minio.ObjectInfo{}, fmt.Errorf("%s: %w", op, ErrImageNotFound)
+		}
+		return nil, minio.ObjectInfo{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return obj, info, nil
+}
+
+func (m *MinioImageRepository) statObject(ctx context.Context, objectName string) (*minio.Object, minio.ObjectInfo, error) {
+	obj, err := m.minioClient.GetObject(ctx, m.bucketName, objectName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, minio.ObjectInfo{}, err
+	}
+	info, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, minio.ObjectInfo{}, err
+	}
+	return obj, info, nil
+}
+
+// DeleteImage removes the object with its renditions, objects without renditions are fine
 func (m *MinioImageRepository) DeleteImage(ctx context.Context, objectName string) error {
 	const op = "image_repository.DeleteImage"
+	for _, r := range imaging.Renditions {
+		err := m.minioClient.RemoveObject(ctx, m.bucketName, renditionObject(objectName, r.Name), minio.RemoveObjectOptions{})
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
 	err := m.minioClient.RemoveObject(ctx, m.bucketName, objectName, minio.RemoveObjectOptions{})
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 	return nil
 }
+
+// attachment types detected from the content that are stored as such, anything else, HTML, SVG
+// and scripts among it, is stored as application/octet-stream
+var attachmentTypes = map[string]bool{
+	"application/pdf":           true,
+	"application/zip":           true,
+	"image/gif":                 true,
+	"image/jpeg":                true,
+	"image/png":                 true,
+	"image/webp":                true,
+	"text/plain; charset=utf-8": true,
+}
+
+// returns object name and content type, attachments are kept under their own prefix. The type
+// the client sent is ignored, it is detected from the first bytes of the file
+func (m *MinioImageRepository) AddAttachment(ctx context.Context, file *multipart.FileHeader) (string, string, error) {
+	const op = "image_repository.AddAttachment"
+	fileToAdd, err := file.Open()
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+	defer fileToAdd.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(fileToAdd, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+	contentType := http.DetectContentType(head[:n])
+	if !attachmentTypes[contentType] {
+		contentType = "application/octet-stream"
+	}
+	_, err = fileToAdd.Seek(0, io.SeekStart)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+	objectName := fmt.Sprintf("attachments/%s%s", uuid.NewString(), filepath.Ext(file.Filename))
+	_, err = m.minioClient.PutObject(ctx, m.bucketName, objectName, fileToAdd, file.Size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+	return objectName, contentType, nil
+}
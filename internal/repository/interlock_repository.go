@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/pkg/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var ErrDeviceNotFound = errors.New("device not found")
+
+const deviceColumns = "id, equipment_id, name, created_at, last_seen_at, revoked_at"
+
+type PostgresInterlockRepository struct {
+	db db.PostgresDB
+}
+
+type InterlockRepositoryInterface interface {
+	CreateDevice(ctx context.Context, device models.Device, keyHash string) (int, error)
+	Devices(ctx context.Context, equipmentId int) ([]models.Device, error)
+	RevokeDevice(ctx context.Context, equipmentId, deviceId int) error
+	DeviceByKey(ctx context.Context, keyHash string) (*models.Device, error)
+	ActiveBooking(ctx context.Context, equipmentId int, at time.Time, advance time.Duration) (*models.Booking, error)
+	LogDecision(ctx context.Context, decision models.InterlockDecision) (int64, error)
+	Decisions(ctx context.Context, equipmentId int, from, to time.Time) ([]models.InterlockDecision, error)
+	FailedLogins(ctx context.Context, deviceId int, login string, since time.Time) (int, int, error)
+}
+
+func NewPostgresInterlockRepository(db db.PostgresDB) PostgresInterlockRepository {
+	return PostgresInterlockRepository{db: db}
+}
+
+func scanDevice(row pgx.Row, d *models.Device) error {
+	return row.Scan(&d.Id, &d.EquipmentId, &d.Name, &d.CreatedAt, &d.LastSeenAt, &d.RevokedAt)
+}
+
+func (p *PostgresInterlockRepository) CreateDevice(ctx context.Context, device models.Device, keyHash string) (int, error) {
+	const op = "interlock_repository.CreateDevice"
+	var id int
+	err := p.db.DB.QueryRow(ctx, "INSERT INTO interlock_devices (equipment_id, name, key_hash) VALUES($1, $2, $3) RETURNING id",
+		device.EquipmentId, device.Name, keyHash).Scan(&id)
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == "23503" {
+			return 0, fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (p *PostgresInterlockRepository) Devices(ctx context.Context, equipmentId int) ([]models.Device, error) {
+	const op = "interlock_repository.Devices"
+	rows, err := p.db.DB.Query(ctx, "SELECT "+deviceColumns+" FROM interlock_devices WHERE equipment_id = $1 ORDER BY id", equipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	devices := []models.Device{}
+	for rows.Next() {
+		var d models.Device
+		err := scanDevice(rows, &d)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		devices = append(devices, d)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return devices, nil
+}
+
+func (p *PostgresInterlockRepository) RevokeDevice(ctx context.Context, equipmentId, deviceId int) error {
+	const op = "interlock_repository.RevokeDevice"
+	tag, err := p.db.DB.Exec(ctx, "UPDATE interlock_devices SET revoked_at = now() WHERE id = $1 AND equipment_id = $2 "+
+		"AND revoked_at IS NULL", deviceId, equipmentId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrDeviceNotFound)
+	}
+	return nil
+}
+
+// DeviceByKey finds the active device with the key digest and marks it as seen
+func (p *PostgresInterlockRepository) DeviceByKey(ctx context.Context, keyHash string) (*models.Device, error) {
+	const op = "interlock_repository.DeviceByKey"
+	var d models.Device
+	err := scanDevice(p.db.DB.QueryRow(ctx, "UPDATE interlock_devices SET last_seen_at = now() WHERE key_hash = $1 "+
+		"AND revoked_at IS NULL RETURNING "+deviceColumns, keyHash), &d)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrDeviceNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &d, nil
+}
+
+// ActiveBooking returns the active booking of the equipment running at the given time or
+// starting within advance of it, the running one wins
+func (p *PostgresInterlockRepository) ActiveBooking(ctx context.Context, equipmentId int, at time.Time, advance time.Duration) (*models.Booking, error) {
+	const op = "interlock_repository.ActiveBooking"
+	var booking models.Booking
+	err := scanBooking(p.db.DB.QueryRow(ctx, "SELECT "+bookingColumns+" FROM booking WHERE equipment_id = $1 AND status = 'active' "+
+		"AND start_time - $3::interval <= $2 AND end_time > $2 ORDER BY start_time LIMIT 1", equipmentId, at, advance), &booking)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrBookingNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &booking, nil
+}
+
+func (p *PostgresInterlockRepository) LogDecision(ctx context.Context, decision models.InterlockDecision) (int64, error) {
+	const op = "interlock_repository.LogDecision"
+	var id int64
+	err := p.db.DB.QueryRow(ctx, "INSERT INTO interlock_decisions (device_id, equipment_id, method, credential, user_id, booking_id, "+
+		"allowed, reason, checked_in) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id", decision.DeviceId, decision.EquipmentId,
+		decision.Method, decision.Credential, decision.UserId, decision.BookingId, decision.Allowed, decision.Reason,
+		decision.CheckedIn).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+// Decisions returns the access decisions logged for the equipment in [from, to), newest first
+func (p *PostgresInterlockRepository) Decisions(ctx context.Context, equipmentId int, from, to time.Time) ([]models.InterlockDecision, error) {
+	const op = "interlock_repository.Decisions"
+	rows, err := p.db.DB.Query(ctx, "SELECT id, device_id, equipment_id, method, credential, user_id, booking_id, allowed, reason, "+
+		"checked_in, created_at FROM interlock_decisions WHERE equipment_id = $1 AND created_at >= $2 AND created_at < $3 "+
+		"ORDER BY created_at DESC, id DESC", equipmentId, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	decisions := []models.InterlockDecision{}
+	for rows.Next() {
+		var d models.InterlockDecision
+		err := rows.Scan(&d.Id, &d.DeviceId, &d.EquipmentId, &d.Method, &d.Credential, &d.UserId, &d.BookingId, &d.Allowed,
+			&d.Reason, &d.CheckedIn, &d.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		decisions = append(decisions, d)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return decisions, nil
+}
+
+// FailedLogins counts the logins refused for invalid credentials since the given time, at the
+// device and for the login at any device
+func (p *PostgresInterlockRepository) FailedLogins(ctx context.Context, deviceId int, login string, since time.Time) (int, int, error) {
+	const op = "interlock_repository.FailedLogins"
+	var byDevice, byLogin int
+	err := p.db.DB.QueryRow(ctx, "SELECT "+
+		"(SELECT count(*) FROM interlock_decisions WHERE device_id = $1 AND method = 'login' AND reason = 'invalid_credentials' AND created_at >= $3), "+
+		"(SELECT count(*) FROM interlock_decisions WHERE credential = $2 AND method = 'login' AND reason = 'invalid_credentials' AND created_at >= $3)",
+		deviceId, login, since).Scan(&byDevice, &byLogin)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return byDevice, byLogin, nil
+}
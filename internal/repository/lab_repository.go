@@ -2,35 +2,110 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/Gergenus/bookingService/internal/models"
 	"github.com/Gergenus/bookingService/pkg/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+var (
+	ErrPreemptionRuleNotFound = errors.New("preemption rule not found")
+	ErrEquipmentModified      = errors.New("equipment modified")
+	ErrEquipmentHasBookings   = errors.New("equipment has future bookings")
+	ErrEquipmentUnavailable   = errors.New("equipment unavailable")
+	ErrEquipmentRetired       = errors.New("equipment retired")
+)
+
+const equipmentColumns = "equipment.id, equipment_name, manufacturer, description, image_url, time_zone, COALESCE(room, ''), allocation_mode, " +
+	"requires_operator, version, category_id, ARRAY(SELECT tag FROM equipment_tags WHERE equipment_tags.equipment_id = equipment.id ORDER BY tag), " +
+	"capacity, status, COALESCE(status_reason, ''), status_changed_at"
+
+// notRetired hides retired equipment from the catalog, it is still found by id for its history
+const notRetired = "equipment.status <> 'retired'"
+
 type PostgresLabRepository struct {
 	db db.PostgresDB
 }
 
 type LabRepositroy interface {
-	CreateEquipment(ctx context.Context, equipment models.Equipment) (int, error)
+	CreateEquipment(ctx context.Context, equipment models.Equipment, images []string) (int, error)
 	Equipment(ctx context.Context, equipment_id int) (*models.Equipment, error)
-	DeleteEquipment(ctx context.Context, equipment_id int) error
-	UpdateEquipment(ctx context.Context, equipment models.Equipment) error
+	SetEquipmentStatus(ctx context.Context, equipmentId int, status, reason string) error
+	AddEquipmentImage(ctx context.Context, image models.EquipmentImage, maxImages int) (*models.EquipmentImage, error)
+	EquipmentImages(ctx context.Context, equipmentId int) ([]models.EquipmentImage, error)
+	UpdateEquipmentImage(ctx context.Context, equipmentId, imageId int, update models.EquipmentImageUpdate) (*models.EquipmentImage, error)
+	ReorderEquipmentImages(ctx context.Context, equipmentId int, imageIds []int) error
+	DeleteEquipmentImage(ctx context.Context, equipmentId, imageId int) (string, error)
+	DeleteEquipmentImages(ctx context.Context, equipmentId int) ([]string, error)
+	RetireEquipment(ctx context.Context, equipmentId int, admin uuid.UUID, reason string, cancelBookings bool) ([]models.Booking, error)
+	UpdateEquipment(ctx context.Context, equipmentId int, update models.EquipmentUpdate) (*models.Equipment, string, error)
 	EquipmentByName(ctx context.Context, equipmentName string) ([]models.Equipment, error)
+	SetOperatingHours(ctx context.Context, equipmentId int, hours []models.OperatingHours) error
+	OperatingHours(ctx context.Context, equipmentId int) ([]models.OperatingHours, error)
+	SetFormFields(ctx context.Context, equipmentId int, fields []models.FormField) error
+	FormFields(ctx context.Context, equipmentId int) ([]models.FormField, error)
+	SetTimeZone(ctx context.Context, equipmentId int, timeZone string) error
+	SetAllocationMode(ctx context.Context, equipmentId int, mode string) error
+	SetRequiresOperator(ctx context.Context, equipmentId int, required bool) error
+	SetRoom(ctx context.Context, equipmentId int, room string) error
+	SetCategory(ctx context.Context, equipmentId int, categoryId *int) error
+	SetTags(ctx context.Context, equipmentId int, tags []string) error
+	BrowseEquipment(ctx context.Context, filter models.EquipmentFilter) ([]models.Equipment, error)
+	CountEquipment(ctx context.Context, filter models.EquipmentFilter) (int, error)
+	EquipmentFacets(ctx context.Context, filter models.EquipmentFilter) (*models.EquipmentFacets, error)
+	SearchEquipment(ctx context.Context, query string, after *models.EquipmentSearchCursor, limit int) ([]models.EquipmentSearchHit, error)
+	CountSearchMatches(ctx context.Context, query string) (int, error)
+	FuzzySearchEquipment(ctx context.Context, query string, limit int) ([]models.EquipmentSearchHit, error)
+	Suggestions(ctx context.Context, prefix string, limit int) ([]models.Suggestion, error)
+	AvailableEquipment(ctx context.Context, filter models.AvailabilityFilter) ([]models.AvailableEquipment, error)
+	SetPreemptionRule(ctx context.Context, rule models.PreemptionRule) error
+	PreemptionRule(ctx context.Context, equipmentId int) (*models.PreemptionRule, error)
+	DeletePreemptionRule(ctx context.Context, equipmentId int) error
 }
 
 func NewPostgresLabRepository(db db.PostgresDB) PostgresLabRepository {
 	return PostgresLabRepository{db: db}
 }
 
+// scanEquipment scans equipmentColumns, extra receives the columns selected after them
+func scanEquipment(row pgx.Row, eq *models.Equipment, extra ...any) error {
+	return row.Scan(append([]any{&eq.EquipmentId, &eq.EquipmentName, &eq.Manufacturer, &eq.Description, &eq.ImageURL, &eq.TimeZone,
+		&eq.Room, &eq.AllocationMode, &eq.RequiresOperator, &eq.Version, &eq.CategoryId, &eq.Tags, &eq.Capacity, &eq.Status,
+		&eq.StatusReason, &eq.StatusChangedAt}, extra...)...)
+}
+
 // TODO обработку sql ошибок
 
-func (p *PostgresLabRepository) CreateEquipment(ctx context.Context, equipment models.Equipment) (int, error) {
+// CreateEquipment adds the equipment with its gallery, the first image is the primary one
+func (p *PostgresLabRepository) CreateEquipment(ctx context.Context, equipment models.Equipment, images []string) (int, error) {
 	const op = "lab_repository.CreateEquipment"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	primary := ""
+	if len(images) > 0 {
+		primary = images[0]
+	}
 	var id int
-	err := p.db.DB.QueryRow(ctx, "INSERT INTO equipment (equipment_name, manufacturer, description, image_url) VALUES($1, $2, $3, $4) RETURNING id", equipment.EquipmentName,
-		equipment.Manufacturer, equipment.Description, equipment.ImageURL).Scan(&id)
+	err = tx.QueryRow(ctx, "INSERT INTO equipment (equipment_name, manufacturer, description, image_url, time_zone, room) "+
+		"VALUES($1, $2, $3, $4, $5, NULLIF($6, '')) RETURNING id",
+		equipment.EquipmentName, equipment.Manufacturer, equipment.Description, primary, equipment.TimeZone, equipment.Room).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	_, err = tx.Exec(ctx, "INSERT INTO equipment_images (equipment_id, object_name, position, is_primary) "+
+		"SELECT $1, i.object_name, i.n - 1, i.n = 1 FROM UNNEST($2::text[]) WITH ORDINALITY AS i(object_name, n)", id, images)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	err = tx.Commit(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
@@ -40,9 +115,11 @@ func (p *PostgresLabRepository) CreateEquipment(ctx context.Context, equipment m
 func (p *PostgresLabRepository) Equipment(ctx context.Context, equipment_id int) (*models.Equipment, error) {
 	const op = "lab_repository.Equipment"
 	var equipment models.Equipment
-	err := p.db.DB.QueryRow(ctx, "SELECT * FROM equipment WHERE id = $1", equipment_id).Scan(&equipment.EquipmentId,
-		&equipment.EquipmentName, &equipment.Manufacturer, &equipment.Description, &equipment.ImageURL)
+	err := scanEquipment(p.db.DB.QueryRow(ctx, "SELECT "+equipmentColumns+" FROM equipment WHERE id = $1", equipment_id), &equipment)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	return &equipment, nil
@@ -52,15 +129,15 @@ func (p *PostgresLabRepository) EquipmentByName(ctx context.Context, equipmentNa
 	const op = "lab_repository.EquipmentByName"
 	var equipment []models.Equipment
 	equipmentName = "%" + equipmentName + "%"
-	rows, err := p.db.DB.Query(ctx, "SELECT * FROM equipment WHERE LOWER(equipment_name) LIKE LOWER($1)", equipmentName)
+	rows, err := p.db.DB.Query(ctx, "SELECT "+equipmentColumns+" FROM equipment WHERE LOWER(equipment_name) LIKE LOWER($1) AND "+notRetired,
+		equipmentName)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
 	for rows.Next() {
 		var eq models.Equipment
-		err := rows.Scan(&eq.EquipmentId,
-			&eq.EquipmentName, &eq.Manufacturer, &eq.Description, &eq.ImageURL)
+		err := scanEquipment(rows, &eq)
 		equipment = append(equipment, eq)
 		if err != nil {
 			rows.Close()
@@ -74,15 +151,419 @@ func (p *PostgresLabRepository) EquipmentByName(ctx context.Context, equipmentNa
 	return equipment, nil
 }
 
-func (p *PostgresLabRepository) DeleteEquipment(ctx context.Context, equipment_id int) error {
-	const op = "lab_repository.DeleteEquipment"
-	_, err := p.db.DB.Exec(ctx, "DELETE FROM equipment WHERE id = $1", equipment_id)
+// SetEquipmentStatus moves the equipment between the bookable and the temporarily unavailable
+// states. Retiring is final, retired equipment keeps its status
+func (p *PostgresLabRepository) SetEquipmentStatus(ctx context.Context, equipmentId int, status, reason string) error {
+	const op = "lab_repository.SetEquipmentStatus"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var current string
+	err = tx.QueryRow(ctx, "SELECT status FROM equipment WHERE id = $1 FOR UPDATE", equipmentId).Scan(&current)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if current == models.EquipmentRetired {
+		return fmt.Errorf("%s: %w", op, ErrEquipmentRetired)
+	}
+	_, err = tx.Exec(ctx, "UPDATE equipment SET status = $2, status_reason = NULLIF($3, ''), status_changed_at = now(), "+
+		"version = version + 1 WHERE id = $1", equipmentId, status, reason)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// RetireEquipment takes the equipment out of service for good while keeping it and its bookings
+// for the history. Bookings that haven't ended yet block it unless cancelBookings is set, then
+// they are cancelled and their owners notified in the same transaction. Pending slot offers expire
+// and open allocation rounds are cancelled with their requests. It returns the cancelled bookings
+func (p *PostgresLabRepository) RetireEquipment(ctx context.Context, equipmentId int, admin uuid.UUID, reason string,
+	cancelBookings bool) ([]models.Booking, error) {
+	const op = "lab_repository.RetireEquipment"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	// the equipment row lock keeps new bookings out until the retirement is committed
+	var name string
+	err = tx.QueryRow(ctx, "SELECT equipment_name FROM equipment WHERE id = $1 FOR UPDATE", equipmentId).Scan(&name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	rows, err := tx.Query(ctx, "SELECT "+bookingColumns+" FROM booking WHERE equipment_id = $1 AND status = 'active' "+
+		"AND end_time > now() ORDER BY start_time", equipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	bookings := []models.Booking{}
+	for rows.Next() {
+		var booking models.Booking
+		err := scanBooking(rows, &booking)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		bookings = append(bookings, booking)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	if len(bookings) > 0 && !cancelBookings {
+		return nil, fmt.Errorf("%s: %w", op, ErrEquipmentHasBookings)
+	}
+	for _, booking := range bookings {
+		_, err = tx.Exec(ctx, "UPDATE booking SET status = 'cancelled', cancelled_at = now() WHERE id = $1", booking.Id)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		err = cancelTransfers(ctx, tx, booking.Id)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		err = addHistory(ctx, tx, models.BookingEvent{BookingId: booking.Id, Action: models.HistoryCancelled, ActorId: &admin,
+			Details: map[string]any{"by_admin": true, "reason": reason, "equipment_retired": true}})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		message := fmt.Sprintf("Your booking of %s from %s was cancelled because the equipment was retired", name,
+			booking.StartTime.Format(noticeTimeLayout))
+		if reason != "" {
+			message += ": " + reason
+		}
+		err = addNotification(ctx, tx, models.Notification{UserId: booking.UserId, Kind: models.NotificationBookingCancelled,
+			Message: message, Payload: map[string]any{"booking_id": booking.Id, "reason": reason}})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	// pending offers and open rounds would otherwise book the equipment later
+	_, err = tx.Exec(ctx, "UPDATE slot_offers SET status = 'expired', resolved_at = now() WHERE equipment_id = $1 AND status = 'pending'",
+		equipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	requests, err := tx.Query(ctx, "WITH rounds AS (UPDATE allocation_rounds SET status = 'cancelled' WHERE equipment_id = $1 "+
+		"AND status = 'open' RETURNING id) UPDATE allocation_requests SET status = 'rejected' WHERE round_id IN (SELECT id FROM rounds) "+
+		"RETURNING id, round_id, user_id, start_time", equipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	var rejected []models.AllocationRequest
+	for requests.Next() {
+		var req models.AllocationRequest
+		err := requests.Scan(&req.Id, &req.RoundId, &req.UserId, &req.StartTime)
+		if err != nil {
+			requests.Close()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		rejected = append(rejected, req)
+	}
+	requests.Close()
+	if requests.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, requests.Err())
+	}
+	for _, req := range rejected {
+		err = addNotification(ctx, tx, models.Notification{UserId: req.UserId, Kind: models.NotificationAllocationRejected,
+			Message: fmt.Sprintf("Your request for %s from %s was not granted because the equipment was retired", name,
+				req.StartTime.Format(noticeTimeLayout)),
+			Payload: map[string]any{"round_id": req.RoundId, "request_id": req.Id, "reason": reason}})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	_, err = tx.Exec(ctx, "UPDATE equipment SET status = 'retired', status_reason = NULLIF($2, ''), status_changed_at = now(), "+
+		"version = version + 1 WHERE id = $1", equipmentId, reason)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return bookings, nil
+}
+
+// UpdateEquipment changes the set fields of the equipment if it is still at update.Version, or
+// at any version when it is 0, and bumps the version. It returns the updated equipment and the image it had before
+func (p *PostgresLabRepository) UpdateEquipment(ctx context.Context, equipmentId int, update models.EquipmentUpdate) (*models.Equipment, string, error) {
+	const op = "lab_repository.UpdateEquipment"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		oldImage string
+		version  int
+		status   string
+	)
+	err = tx.QueryRow(ctx, "SELECT image_url, version, status FROM equipment WHERE id = $1 FOR UPDATE", equipmentId).Scan(&oldImage,
+		&version, &status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, "", fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+	if update.Version != 0 && version != update.Version {
+		return nil, "", fmt.Errorf("%s: %w", op, ErrEquipmentModified)
+	}
+	// the gallery of retired equipment was purged, a new image would never be removed
+	if update.ImageURL != nil && status == models.EquipmentRetired {
+		return nil, "", fmt.Errorf("%s: %w", op, ErrEquipmentRetired)
+	}
+	var equipment models.Equipment
+	err = scanEquipment(tx.QueryRow(ctx, "UPDATE equipment SET equipment_name = COALESCE($2, equipment_name), "+
+		"manufacturer = COALESCE($3, manufacturer), description = COALESCE($4, description), image_url = COALESCE($5, image_url), "+
+		"capacity = COALESCE($6, capacity), version = version + 1 WHERE id = $1 RETURNING "+equipmentColumns,
+		equipmentId, update.EquipmentName, update.Manufacturer, update.Description, update.ImageURL, update.Capacity), &equipment)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+	if update.ImageURL != nil {
+		// the new image replaces the primary one of the gallery
+		tag, err := tx.Exec(ctx, "UPDATE equipment_images SET object_name = $2 WHERE equipment_id = $1 AND is_primary",
+			equipmentId, *update.ImageURL)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", op, err)
+		}
+		if tag.RowsAffected() == 0 {
+			_, err = tx.Exec(ctx, "INSERT INTO equipment_images (equipment_id, object_name, position, is_primary) VALUES($1, $2, 0, true)",
+				equipmentId, *update.ImageURL)
+			if err != nil {
+				return nil, "", fmt.Errorf("%s: %w", op, err)
+			}
+		}
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+	return &equipment, oldImage, nil
+}
+
+// openDuringSQL is the condition that the slot from start to end lies within one opening period
+// of the equipment, whose id and time_zone columns are read from the equipment alias. Opening
+// hours are wall clock times of the equipment's zone, equipment without them is always open
+func openDuringSQL(equipment, start, end string) string {
+	zone := equipment + ".time_zone"
+	day := "((" + start + ") AT TIME ZONE " + zone + ")"
+	return "(NOT EXISTS (SELECT 1 FROM equipment_operating_hours o WHERE o.equipment_id = " + equipment + ".id) " +
+		"OR EXISTS (SELECT 1 FROM equipment_operating_hours o WHERE o.equipment_id = " + equipment + ".id " +
+		"AND o.weekday = EXTRACT(ISODOW FROM " + day + ") " +
+		"AND (" + day + "::date + o.open_time) AT TIME ZONE " + zone + " <= (" + start + ") " +
+		"AND (" + day + "::date + o.close_time) AT TIME ZONE " + zone + " >= (" + end + ")))"
+}
+
+// SetOperatingHours replaces the whole weekly schedule of the equipment
+func (p *PostgresLabRepository) SetOperatingHours(ctx context.Context, equipmentId int, hours []models.OperatingHours) error {
+	const op = "lab_repository.SetOperatingHours"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, "DELETE FROM equipment_operating_hours WHERE equipment_id = $1", equipmentId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for _, h := range hours {
+		_, err = tx.Exec(ctx, "INSERT INTO equipment_operating_hours (equipment_id, weekday, open_time, close_time) VALUES($1, $2, $3, $4)",
+			equipmentId, h.Weekday, h.OpenTime, h.CloseTime)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (p *PostgresLabRepository) OperatingHours(ctx context.Context, equipmentId int) ([]models.OperatingHours, error) {
+	const op = "lab_repository.OperatingHours"
+	rows, err := p.db.DB.Query(ctx, "SELECT equipment_id, weekday, to_char(open_time, 'HH24:MI'), to_char(close_time, 'HH24:MI') "+
+		"FROM equipment_operating_hours WHERE equipment_id = $1 ORDER BY weekday", equipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	var hours []models.OperatingHours
+	for rows.Next() {
+		var h models.OperatingHours
+		err := rows.Scan(&h.EquipmentId, &h.Weekday, &h.OpenTime, &h.CloseTime)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		hours = append(hours, h)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return hours, nil
+}
+
+// SetFormFields replaces the booking form of the equipment
+func (p *PostgresLabRepository) SetFormFields(ctx context.Context, equipmentId int, fields []models.FormField) error {
+	const op = "lab_repository.SetFormFields"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, "DELETE FROM equipment_form_fields WHERE equipment_id = $1", equipmentId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for _, f := range fields {
+		options := f.Options
+		if options == nil {
+			options = []string{}
+		}
+		_, err = tx.Exec(ctx, "INSERT INTO equipment_form_fields (equipment_id, name, label, field_type, required, options, position) "+
+			"VALUES($1, $2, $3, $4, $5, $6, $7)", equipmentId, f.Name, f.Label, f.Type, f.Required, options, f.Position)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (p *PostgresLabRepository) FormFields(ctx context.Context, equipmentId int) ([]models.FormField, error) {
+	const op = "lab_repository.FormFields"
+	rows, err := p.db.DB.Query(ctx, "SELECT id, equipment_id, name, label, field_type, required, options, position "+
+		"FROM equipment_form_fields WHERE equipment_id = $1 ORDER BY position, id", equipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	fields := []models.FormField{}
+	for rows.Next() {
+		var f models.FormField
+		err := rows.Scan(&f.Id, &f.EquipmentId, &f.Name, &f.Label, &f.Type, &f.Required, &f.Options, &f.Position)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		fields = append(fields, f)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return fields, nil
+}
+
+func (p *PostgresLabRepository) SetTimeZone(ctx context.Context, equipmentId int, timeZone string) error {
+	const op = "lab_repository.SetTimeZone"
+	tag, err := p.db.DB.Exec(ctx, "UPDATE equipment SET time_zone = $2 WHERE id = $1", equipmentId, timeZone)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+	}
 	return nil
 }
 
-func (p *PostgresLabRepository) UpdateEquipment(ctx context.Context, equipment models.Equipment) error {
-	panic("implement me")
+func (p *PostgresLabRepository) SetAllocationMode(ctx context.Context, equipmentId int, mode string) error {
+	const op = "lab_repository.SetAllocationMode"
+	tag, err := p.db.DB.Exec(ctx, "UPDATE equipment SET allocation_mode = $2 WHERE id = $1", equipmentId, mode)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+	}
+	return nil
+}
+
+func (p *PostgresLabRepository) SetRequiresOperator(ctx context.Context, equipmentId int, required bool) error {
+	const op = "lab_repository.SetRequiresOperator"
+	tag, err := p.db.DB.Exec(ctx, "UPDATE equipment SET requires_operator = $2 WHERE id = $1", equipmentId, required)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+	}
+	return nil
+}
+
+// SetRoom moves the equipment to a room, an empty room removes it from any
+func (p *PostgresLabRepository) SetRoom(ctx context.Context, equipmentId int, room string) error {
+	const op = "lab_repository.SetRoom"
+	tag, err := p.db.DB.Exec(ctx, "UPDATE equipment SET room = NULLIF($2, '') WHERE id = $1", equipmentId, room)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+	}
+	return nil
+}
+
+func (p *PostgresLabRepository) SetPreemptionRule(ctx context.Context, rule models.PreemptionRule) error {
+	const op = "lab_repository.SetPreemptionRule"
+	_, err := p.db.DB.Exec(ctx, "INSERT INTO preemption_rules (equipment_id, min_priority, priority_margin, min_notice_minutes) "+
+		"VALUES($1, $2, $3, $4) ON CONFLICT (equipment_id) DO UPDATE SET min_priority = EXCLUDED.min_priority, "+
+		"priority_margin = EXCLUDED.priority_margin, min_notice_minutes = EXCLUDED.min_notice_minutes",
+		rule.EquipmentId, rule.MinPriority, rule.PriorityMargin, rule.MinNoticeMinutes)
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == "23503" {
+			return fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (p *PostgresLabRepository) PreemptionRule(ctx context.Context, equipmentId int) (*models.PreemptionRule, error) {
+	const op = "lab_repository.PreemptionRule"
+	var rule models.PreemptionRule
+	err := p.db.DB.QueryRow(ctx, "SELECT equipment_id, min_priority, priority_margin, min_notice_minutes FROM preemption_rules "+
+		"WHERE equipment_id = $1", equipmentId).Scan(&rule.EquipmentId, &rule.MinPriority, &rule.PriorityMargin, &rule.MinNoticeMinutes)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrPreemptionRuleNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &rule, nil
+}
+
+func (p *PostgresLabRepository) DeletePreemptionRule(ctx context.Context, equipmentId int) error {
+	const op = "lab_repository.DeletePreemptionRule"
+	tag, err := p.db.DB.Exec(ctx, "DELETE FROM preemption_rules WHERE equipment_id = $1", equipmentId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrPreemptionRuleNotFound)
+	}
+	return nil
 }
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/pkg/db"
+	"github.com/google/uuid"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+type PostgresNotificationRepository struct {
+	db db.PostgresDB
+}
+
+type NotificationRepositoryInterface interface {
+	Notify(ctx context.Context, n models.Notification) error
+	Notifications(ctx context.Context, uid uuid.UUID, unreadOnly bool, beforeId int64, limit int) ([]models.Notification, error)
+	CountNotifications(ctx context.Context, uid uuid.UUID, unreadOnly bool) (int64, error)
+	MarkRead(ctx context.Context, id int64, uid uuid.UUID) error
+}
+
+func NewPostgresNotificationRepository(db db.PostgresDB) PostgresNotificationRepository {
+	return PostgresNotificationRepository{db: db}
+}
+
+// addNotification lets other repositories notify users inside their own transactions
+func addNotification(ctx context.Context, db execer, n models.Notification) error {
+	payload := n.Payload
+	if payload == nil {
+		payload = map[string]any{}
+	}
+	_, err := db.Exec(ctx, "INSERT INTO notifications (user_id, kind, message, payload) VALUES($1, $2, $3, $4)",
+		n.UserId, n.Kind, n.Message, payload)
+	return err
+}
+
+func (p *PostgresNotificationRepository) Notify(ctx context.Context, n models.Notification) error {
+	const op = "notification_repository.Notify"
+	err := addNotification(ctx, p.db.DB, n)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// Notifications returns the newest notifications first, beforeId continues after the given one
+func (p *PostgresNotificationRepository) Notifications(ctx context.Context, uid uuid.UUID, unreadOnly bool, beforeId int64, limit int) ([]models.Notification, error) {
+	const op = "notification_repository.Notifications"
+	rows, err := p.db.DB.Query(ctx, "SELECT id, user_id, kind, message, payload, created_at, read_at FROM notifications "+
+		"WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL) AND ($3 = 0 OR id < $3) ORDER BY id DESC LIMIT $4",
+		uid, unreadOnly, beforeId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	notifications := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		err := rows.Scan(&n.Id, &n.UserId, &n.Kind, &n.Message, &n.Payload, &n.CreatedAt, &n.ReadAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		notifications = append(notifications, n)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return notifications, nil
+}
+
+func (p *PostgresNotificationRepository) CountNotifications(ctx context.Context, uid uuid.UUID, unreadOnly bool) (int64, error) {
+	const op = "notification_repository.CountNotifications"
+	var count int64
+	err := p.db.DB.QueryRow(ctx, "SELECT count(*) FROM notifications WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)",
+		uid, unreadOnly).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return count, nil
+}
+
+func (p *PostgresNotificationRepository) MarkRead(ctx context.Context, id int64, uid uuid.UUID) error {
+	const op = "notification_repository.MarkRead"
+	tag, err := p.db.DB.Exec(ctx, "UPDATE notifications SET read_at = COALESCE(read_at, now()) WHERE id = $1 AND user_id = $2", id, uid)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrNotificationNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrOfferNotFound   = errors.New("offer not found")
+	ErrOfferNotPending = errors.New("offer is not pending")
+)
+
+const offerColumns = "id, booking_id, user_id, equipment_id, start_time, end_time, status, new_booking_id, created_at, resolved_at"
+
+// bookings can't touch each other, alternative slots keep this gap to their neighbours
+const slotGap = "interval '1 minute'"
+
+func scanOffer(row pgx.Row, o *models.SlotOffer) error {
+	return row.Scan(&o.Id, &o.BookingId, &o.UserId, &o.EquipmentId, &o.StartTime, &o.EndTime, &o.Status, &o.NewBookingId,
+		&o.CreatedAt, &o.ResolvedAt)
+}
+
+// Preempt books the slot in place of the active bookings it intersects. All of them have to be
+// bumpable under the rule, otherwise nothing changes and ErrIntervalInterception is returned.
+// The bumped bookings are cancelled in the same transaction and returned. The event is recorded
+// for the new booking with the ids of the bumped ones
+func (p *PostgresBookingRepository) Preempt(ctx context.Context, booking models.Booking, rule models.PreemptionRule, event models.BookingEvent) (int, []models.Booking, error) {
+	const op = "booking_repository.Preempt"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	// the equipment row serializes preemption with CreateBooking and other preemptions, so no
+	// booking can appear in the slot after the intersecting ones are read
+	err = lockBookableEquipment(ctx, tx, booking.EquipmentId)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	rows, err := tx.Query(ctx, "SELECT "+bookingColumns+" FROM booking WHERE equipment_id = $1 AND status = 'active' "+
+		"AND start_time <= $3 AND end_time >= $2 ORDER BY start_time FOR UPDATE", booking.EquipmentId, booking.StartTime, booking.EndTime)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	bumped := []models.Booking{}
+	for rows.Next() {
+		var b models.Booking
+		err := scanBooking(rows, &b)
+		if err != nil {
+			rows.Close()
+			return 0, nil, fmt.Errorf("%s: %w", op, err)
+		}
+		bumped = append(bumped, b)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return 0, nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	notice := time.Now().Add(time.Duration(rule.MinNoticeMinutes) * time.Minute)
+	ids := make([]int, 0, len(bumped))
+	for _, b := range bumped {
+		if b.Priority > booking.Priority-rule.PriorityMargin || b.CheckedInAt != nil || b.StartTime.Before(notice) {
+			return 0, nil, fmt.Errorf("%s: %w", op, ErrIntervalInterception)
+		}
+		ids = append(ids, b.Id)
+	}
+	_, err = tx.Exec(ctx, "UPDATE booking SET status = 'cancelled', cancelled_at = now() WHERE id = ANY($1)", ids)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	err = cancelTransfers(ctx, tx, ids...)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	err = checkGroupQuota(ctx, tx, booking)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	err = reserveOperator(ctx, tx, &booking)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	id, err := insertBooking(ctx, tx, booking)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	actor := booking.UserId
+	if booking.BookedBy != nil {
+		actor = *booking.BookedBy
+	}
+	for _, b := range bumped {
+		err = addHistory(ctx, tx, models.BookingEvent{
+			BookingId: b.Id,
+			Action:    models.HistoryPreempted,
+			ActorId:   &actor,
+			Details:   map[string]any{"by_booking": id, "priority": b.Priority, "by_priority": booking.Priority},
+		})
+		if err != nil {
+			return 0, nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	event.BookingId = id
+	event.Details = maps.Clone(event.Details)
+	if event.Details == nil {
+		event.Details = map[string]any{}
+	}
+	event.Details["preempted"] = ids
+	err = addHistory(ctx, tx, event)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, bumped, nil
+}
+
+// NearestFreeSlot finds the free slot of the given duration starting closest to around and not
+// before notBefore. Candidates start right after or end right before existing bookings, or sit
+// at the edges of opening periods and operator shifts. The slot has to lie within the operating
+// hours and, if the equipment requires one, have a technician free to operate it. Slots offered
+// to other users count as taken until their offers are resolved or have started, so the users
+// bumped by one preemption are not all offered the same slot
+func (p *PostgresBookingRepository) NearestFreeSlot(ctx context.Context, equipmentId int, duration time.Duration, around, notBefore time.Time) (*time.Time, error) {
+	const op = "booking_repository.NearestFreeSlot"
+	var start time.Time
+	err := p.db.DB.QueryRow(ctx, `WITH e AS (
+			SELECT id, time_zone, requires_operator FROM equipment WHERE id = $1
+		), busy AS (
+			SELECT start_time, end_time FROM booking WHERE equipment_id = $1 AND status = 'active' AND end_time >= $4
+			UNION ALL SELECT start_time, end_time FROM slot_offers WHERE equipment_id = $1 AND status = 'pending' AND start_time > now()
+		), opening AS (
+			SELECT (d::date + o.open_time) AT TIME ZONE e.time_zone AS open_at,
+				(d::date + o.close_time) AT TIME ZONE e.time_zone AS close_at
+			FROM e, generate_series(($4::timestamptz AT TIME ZONE e.time_zone)::date,
+				(GREATEST($3::timestamptz, (SELECT max(end_time) FROM busy)) AT TIME ZONE e.time_zone)::date + 7, interval '1 day') d
+			JOIN equipment_operating_hours o ON o.equipment_id = e.id AND o.weekday = EXTRACT(ISODOW FROM d)
+		), candidates AS (
+			SELECT $3::timestamptz AS s
+			UNION SELECT end_time + `+slotGap+` FROM busy
+			UNION SELECT start_time - `+slotGap+` - $2::interval FROM busy
+			UNION SELECT open_at FROM opening
+			UNION SELECT close_at - $2::interval FROM opening
+			UNION SELECT s.start_time FROM technician_shifts s, e WHERE e.requires_operator AND s.end_time >= $4
+			UNION SELECT s.end_time - $2::interval FROM technician_shifts s, e WHERE e.requires_operator AND s.end_time >= $4
+		)
+		SELECT c.s FROM candidates c, e WHERE c.s >= $4 AND NOT EXISTS (
+			SELECT 1 FROM busy b WHERE b.start_time <= c.s + $2::interval AND b.end_time >= c.s)
+		AND `+openDuringSQL("e", "c.s", "c.s + $2::interval")+`
+		AND `+operatorFreeSQL("e", "c.s", "c.s + $2::interval")+`
+		ORDER BY abs(extract(epoch FROM c.s - $3)) LIMIT 1`, equipmentId, duration, around, notBefore).Scan(&start)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &start, nil
+}
+
+func (p *PostgresBookingRepository) CreateOffer(ctx context.Context, offer models.SlotOffer) (int, error) {
+	const op = "booking_repository.CreateOffer"
+	var id int
+	err := p.db.DB.QueryRow(ctx, "INSERT INTO slot_offers (booking_id, user_id, equipment_id, start_time, end_time) "+
+		"VALUES($1, $2, $3, $4, $5) RETURNING id", offer.BookingId, offer.UserId, offer.EquipmentId, offer.StartTime, offer.EndTime).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (p *PostgresBookingRepository) Offer(ctx context.Context, offerId int) (*models.SlotOffer, error) {
+	const op = "booking_repository.Offer"
+	var o models.SlotOffer
+	err := scanOffer(p.db.DB.QueryRow(ctx, "SELECT "+offerColumns+" FROM slot_offers WHERE id = $1", offerId), &o)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrOfferNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &o, nil
+}
+
+// PendingOffers returns the user's open offers whose slot hasn't started yet
+func (p *PostgresBookingRepository) PendingOffers(ctx context.Context, uid uuid.UUID) ([]models.SlotOffer, error) {
+	const op = "booking_repository.PendingOffers"
+	rows, err := p.db.DB.Query(ctx, "SELECT "+offerColumns+" FROM slot_offers WHERE user_id = $1 AND status = 'pending' "+
+		"AND start_time > now() ORDER BY start_time", uid)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	offers := []models.SlotOffer{}
+	for rows.Next() {
+		var o models.SlotOffer
+		err := scanOffer(rows, &o)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		offers = append(offers, o)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return offers, nil
+}
+
+// AcceptOffer books the offered slot in one transaction. It fails with ErrIntervalInterception
+// when the slot was taken since the offer was made and with ErrQuotaExceeded when the booking
+// no longer fits into the quota of its group
+func (p *PostgresBookingRepository) AcceptOffer(ctx context.Context, offer models.SlotOffer, booking models.Booking) (int, error) {
+	const op = "booking_repository.AcceptOffer"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, "UPDATE slot_offers SET status = 'accepted', resolved_at = now() WHERE id = $1 AND status = 'pending'", offer.Id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return 0, fmt.Errorf("%s: %w", op, ErrOfferNotPending)
+	}
+	err = lockBookableEquipment(ctx, tx, booking.EquipmentId)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	err = checkGroupQuota(ctx, tx, booking)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	var taken bool
+	err = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM booking WHERE equipment_id = $1 AND status = 'active' "+
+		"AND start_time <= $3 AND end_time >= $2)", booking.EquipmentId, booking.StartTime, booking.EndTime).Scan(&taken)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if taken {
+		return 0, fmt.Errorf("%s: %w", op, ErrIntervalInterception)
+	}
+	err = reserveOperator(ctx, tx, &booking)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	id, err := insertBooking(ctx, tx, booking)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	_, err = tx.Exec(ctx, "UPDATE slot_offers SET new_booking_id = $2 WHERE id = $1", offer.Id, id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	err = addHistory(ctx, tx, models.BookingEvent{
+		BookingId: id,
+		Action:    models.HistoryOfferAccepted,
+		ActorId:   &offer.UserId,
+		Details:   map[string]any{"offer_id": offer.Id, "preempted_booking": offer.BookingId},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (p *PostgresBookingRepository) DeclineOffer(ctx context.Context, offer models.SlotOffer) error {
+	const op = "booking_repository.DeclineOffer"
+	tag, err := p.db.DB.Exec(ctx, "UPDATE slot_offers SET status = 'declined', resolved_at = now() WHERE id = $1 AND status = 'pending'", offer.Id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrOfferNotPending)
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/pkg/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrProjectAlreadyExists = errors.New("project already exists")
+	ErrProjectNotFound      = errors.New("project not found")
+)
+
+type PostgresProjectRepository struct {
+	db db.PostgresDB
+}
+
+type ProjectRepositoryInterface interface {
+	CreateProject(ctx context.Context, project models.Project) (int, error)
+	Project(ctx context.Context, projectId int) (*models.Project, error)
+	Projects(ctx context.Context) ([]models.Project, error)
+	UserProjects(ctx context.Context, uid uuid.UUID) ([]models.Project, error)
+	AddMember(ctx context.Context, projectId int, uid uuid.UUID) error
+	RemoveMember(ctx context.Context, projectId int, uid uuid.UUID) error
+	IsMember(ctx context.Context, projectId int, uid uuid.UUID) (bool, error)
+	SetRate(ctx context.Context, rate models.EquipmentRate) (int, error)
+	Rates(ctx context.Context, equipmentId int) ([]models.EquipmentRate, error)
+	ChargeableBookings(ctx context.Context, from, to time.Time, projectId int) ([]models.ChargeableBooking, error)
+	SetPriority(ctx context.Context, projectId int, priority int) error
+	SetRolePriority(ctx context.Context, rp models.RolePriority) error
+	RolePriorities(ctx context.Context) ([]models.RolePriority, error)
+	BookingPriority(ctx context.Context, projectId int, uid uuid.UUID) (int, error)
+}
+
+func NewPostgresProjectRepository(db db.PostgresDB) PostgresProjectRepository {
+	return PostgresProjectRepository{db: db}
+}
+
+func (p *PostgresProjectRepository) CreateProject(ctx context.Context, project models.Project) (int, error) {
+	const op = "project_repository.CreateProject"
+	var id int
+	err := p.db.DB.QueryRow(ctx, "INSERT INTO projects (grant_code, title, tier, priority) VALUES($1, $2, $3, $4) RETURNING id",
+		project.GrantCode, project.Title, project.Tier, project.Priority).Scan(&id)
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == "23505" {
+			return 0, fmt.Errorf("%s: %w", op, ErrProjectAlreadyExists)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (p *PostgresProjectRepository) Project(ctx context.Context, projectId int) (*models.Project, error) {
+	const op = "project_repository.Project"
+	var project models.Project
+	err := p.db.DB.QueryRow(ctx, "SELECT id, grant_code, title, tier, priority FROM projects WHERE id = $1", projectId).Scan(&project.Id,
+		&project.GrantCode, &project.Title, &project.Tier, &project.Priority)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrProjectNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &project, nil
+}
+
+func (p *PostgresProjectRepository) Projects(ctx context.Context) ([]models.Project, error) {
+	const op = "project_repository.Projects"
+	rows, err := p.db.DB.Query(ctx, "SELECT id, grant_code, title, tier, priority FROM projects ORDER BY grant_code")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	projects, err := scanProjects(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return projects, nil
+}
+
+func (p *PostgresProjectRepository) UserProjects(ctx context.Context, uid uuid.UUID) ([]models.Project, error) {
+	const op = "project_repository.UserProjects"
+	rows, err := p.db.DB.Query(ctx, "SELECT p.id, p.grant_code, p.title, p.tier, p.priority FROM projects p "+
+		"JOIN project_members m ON m.project_id = p.id WHERE m.user_id = $1 ORDER BY p.grant_code", uid)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	projects, err := scanProjects(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return projects, nil
+}
+
+func scanProjects(rows pgx.Rows) ([]models.Project, error) {
+	defer rows.Close()
+	var projects []models.Project
+	for rows.Next() {
+		var project models.Project
+		err := rows.Scan(&project.Id, &project.GrantCode, &project.Title, &project.Tier, &project.Priority)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+	return projects, rows.Err()
+}
+
+func (p *PostgresProjectRepository) AddMember(ctx context.Context, projectId int, uid uuid.UUID) error {
+	const op = "project_repository.AddMember"
+	_, err := p.db.DB.Exec(ctx, "INSERT INTO project_members (project_id, user_id) VALUES($1, $2) ON CONFLICT DO NOTHING", projectId, uid)
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == "23503" {
+			return fmt.Errorf("%s: %w", op, ErrProjectNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (p *PostgresProjectRepository) RemoveMember(ctx context.Context, projectId int, uid uuid.UUID) error {
+	const op = "project_repository.RemoveMember"
+	_, err := p.db.DB.Exec(ctx, "DELETE FROM project_members WHERE project_id = $1 AND user_id = $2", projectId, uid)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (p *PostgresProjectRepository) IsMember(ctx context.Context, projectId int, uid uuid.UUID) (bool, error) {
+	const op = "project_repository.IsMember"
+	var ok bool
+	err := p.db.DB.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM project_members WHERE project_id = $1 AND user_id = $2)",
+		projectId, uid).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return ok, nil
+}
+
+func (p *PostgresProjectRepository) SetRate(ctx context.Context, rate models.EquipmentRate) (int, error) {
+	const op = "project_repository.SetRate"
+	var id int
+	err := p.db.DB.QueryRow(ctx, "INSERT INTO equipment_rates (equipment_id, tier, peak_rate, off_peak_rate, peak_start, peak_end) "+
+		"VALUES($1, $2, $3, $4, $5, $6) ON CONFLICT (equipment_id, tier) DO UPDATE SET peak_rate = EXCLUDED.peak_rate, "+
+		"off_peak_rate = EXCLUDED.off_peak_rate, peak_start = EXCLUDED.peak_start, peak_end = EXCLUDED.peak_end RETURNING id",
+		rate.EquipmentId, rate.Tier, rate.PeakRate, rate.OffPeakRate, rate.PeakStart, rate.PeakEnd).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (p *PostgresProjectRepository) Rates(ctx context.Context, equipmentId int) ([]models.EquipmentRate, error) {
+	const op = "project_repository.Rates"
+	rows, err := p.db.DB.Query(ctx, "SELECT id, equipment_id, tier, peak_rate, off_peak_rate, peak_start, peak_end "+
+		"FROM equipment_rates WHERE equipment_id = $1 ORDER BY tier", equipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	var rates []models.EquipmentRate
+	for rows.Next() {
+		var rate models.EquipmentRate
+		err := rows.Scan(&rate.Id, &rate.EquipmentId, &rate.Tier, &rate.PeakRate, &rate.OffPeakRate, &rate.PeakStart, &rate.PeakEnd)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		rates = append(rates, rate)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return rates, nil
+}
+
+// ChargeableBookings returns bookings of the [from, to) period with actual check-in/out times
+// where present and booked times otherwise. projectId = 0 means all projects
+func (p *PostgresProjectRepository) ChargeableBookings(ctx context.Context, from, to time.Time, projectId int) ([]models.ChargeableBooking, error) {
+	const op = "project_repository.ChargeableBookings"
+	rows, err := p.db.DB.Query(ctx, `SELECT b.id, p.id, p.grant_code, p.tier, b.equipment_id,
+			COALESCE(b.checked_in_at, b.start_time), COALESCE(b.checked_out_at, b.end_time),
+			r.id, r.peak_rate, r.off_peak_rate, r.peak_start, r.peak_end, e.time_zone
+		FROM booking b
+		JOIN projects p ON p.id = b.project_id
+		JOIN equipment e ON e.id = b.equipment_id
+		LEFT JOIN equipment_rates r ON r.equipment_id = b.equipment_id AND r.tier = p.tier
+		WHERE b.status = 'active' AND COALESCE(b.checked_in_at, b.start_time) >= $1 AND COALESCE(b.checked_in_at, b.start_time) < $2
+			AND ($3 = 0 OR p.id = $3)
+		ORDER BY p.grant_code, b.start_time`, from, to, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	var bookings []models.ChargeableBooking
+	for rows.Next() {
+		var (
+			b                     models.ChargeableBooking
+			rateId                *int
+			peakRate, offPeakRate *float64
+			peakStart, peakEnd    *int
+		)
+		err := rows.Scan(&b.BookingId, &b.ProjectId, &b.GrantCode, &b.Tier, &b.EquipmentId, &b.Start, &b.End,
+			&rateId, &peakRate, &offPeakRate, &peakStart, &peakEnd, &b.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if rateId != nil {
+			b.Rate = &models.EquipmentRate{
+				Id:          *rateId,
+				EquipmentId: b.EquipmentId,
+				Tier:        b.Tier,
+				PeakRate:    *peakRate,
+				OffPeakRate: *offPeakRate,
+				PeakStart:   *peakStart,
+				PeakEnd:     *peakEnd,
+			}
+		}
+		bookings = append(bookings, b)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return bookings, nil
+}
+
+func (p *PostgresProjectRepository) SetPriority(ctx context.Context, projectId int, priority int) error {
+	const op = "project_repository.SetPriority"
+	tag, err := p.db.DB.Exec(ctx, "UPDATE projects SET priority = $2 WHERE id = $1", projectId, priority)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrProjectNotFound)
+	}
+	return nil
+}
+
+func (p *PostgresProjectRepository) SetRolePriority(ctx context.Context, rp models.RolePriority) error {
+	const op = "project_repository.SetRolePriority"
+	_, err := p.db.DB.Exec(ctx, "INSERT INTO role_priorities (role, priority) VALUES($1, $2) "+
+		"ON CONFLICT (role) DO UPDATE SET priority = EXCLUDED.priority", rp.Role, rp.Priority)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (p *PostgresProjectRepository) RolePriorities(ctx context.Context) ([]models.RolePriority, error) {
+	const op = "project_repository.RolePriorities"
+	rows, err := p.db.DB.Query(ctx, "SELECT role, priority FROM role_priorities ORDER BY priority DESC, role")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	priorities := []models.RolePriority{}
+	for rows.Next() {
+		var rp models.RolePriority
+		err := rows.Scan(&rp.Role, &rp.Priority)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		priorities = append(priorities, rp)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return priorities, nil
+}
+
+// BookingPriority is the higher of the project's priority and the priority of the user's role
+func (p *PostgresProjectRepository) BookingPriority(ctx context.Context, projectId int, uid uuid.UUID) (int, error) {
+	const op = "project_repository.BookingPriority"
+	var priority int
+	err := p.db.DB.QueryRow(ctx, `SELECT GREATEST(
+			COALESCE((SELECT priority FROM projects WHERE id = $1), 0),
+			COALESCE((SELECT r.priority FROM role_priorities r JOIN users u ON u.role = r.role WHERE u.uid = $2), 0))`,
+		projectId, uid).Scan(&priority)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return priority, nil
+}
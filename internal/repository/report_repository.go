@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/pkg/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrUnknownReportKind = errors.New("unknown report kind")
+	ErrReportNotFound    = errors.New("report not found")
+)
+
+// group by expression and label for every report kind
+var usageGroups = map[string][2]string{
+	models.ReportByEquipment: {"e.id::text", "e.equipment_name"},
+	models.ReportByUser:      {"u.uid::text", "u.username"},
+	models.ReportByProject:   {"COALESCE(p.id::text, '')", "COALESCE(p.grant_code, 'no project')"},
+}
+
+type PostgresReportRepository struct {
+	db db.PostgresDB
+}
+
+type ReportRepositoryInterface interface {
+	CountBookings(ctx context.Context, from, to time.Time) (int, error)
+	Usage(ctx context.Context, kind string, from, to time.Time) ([]models.UsageRow, error)
+	CreateJob(ctx context.Context, job models.ReportJob) error
+	FinishJob(ctx context.Context, id uuid.UUID, status, objectName string) error
+	FailStaleJobs(ctx context.Context, createdBefore time.Time) (int, error)
+	Job(ctx context.Context, id uuid.UUID) (*models.ReportJob, error)
+}
+
+func NewPostgresReportRepository(db db.PostgresDB) PostgresReportRepository {
+	return PostgresReportRepository{db: db}
+}
+
+func (p *PostgresReportRepository) CountBookings(ctx context.Context, from, to time.Time) (int, error) {
+	const op = "report_repository.CountBookings"
+	var count int
+	err := p.db.DB.QueryRow(ctx, "SELECT Count(*) FROM booking WHERE status = 'active' AND start_time >= $1 AND start_time < $2", from, to).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return count, nil
+}
+
+func (p *PostgresReportRepository) Usage(ctx context.Context, kind string, from, to time.Time) ([]models.UsageRow, error) {
+	const op = "report_repository.Usage"
+	group, ok := usageGroups[kind]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, ErrUnknownReportKind)
+	}
+	query := fmt.Sprintf(`SELECT %[1]s, %[2]s, Count(*), Count(b.checked_in_at),
+			COALESCE(SUM(EXTRACT(EPOCH FROM b.end_time - b.start_time)), 0) / 3600,
+			COALESCE(SUM(EXTRACT(EPOCH FROM COALESCE(b.checked_out_at, b.end_time) - b.checked_in_at)), 0) / 3600
+		FROM booking b
+		JOIN equipment e ON e.id = b.equipment_id
+		JOIN users u ON u.uid = b.user_id
+		LEFT JOIN projects p ON p.id = b.project_id
+		WHERE b.status = 'active' AND b.start_time >= $1 AND b.start_time < $2
+		GROUP BY 1, 2
+		ORDER BY 2`, group[0], group[1])
+	rows, err := p.db.DB.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	var usage []models.UsageRow
+	for rows.Next() {
+		var row models.UsageRow
+		err := rows.Scan(&row.Key, &row.Label, &row.Bookings, &row.CheckedIn, &row.BookedHours, &row.UsedHours)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		usage = append(usage, row)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return usage, nil
+}
+
+func (p *PostgresReportRepository) CreateJob(ctx context.Context, job models.ReportJob) error {
+	const op = "report_repository.CreateJob"
+	_, err := p.db.DB.Exec(ctx, "INSERT INTO report_jobs (id, user_id, kind, format, date_from, date_to, status) VALUES($1, $2, $3, $4, $5, $6, $7)",
+		job.Id, job.UserId, job.Kind, job.Format, job.From, job.To, job.Status)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (p *PostgresReportRepository) FinishJob(ctx context.Context, id uuid.UUID, status, objectName string) error {
+	const op = "report_repository.FinishJob"
+	_, err := p.db.DB.Exec(ctx, "UPDATE report_jobs SET status = $2, object_name = NULLIF($3, ''), finished_at = now() WHERE id = $1",
+		id, status, objectName)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// FailStaleJobs marks the jobs still pending since before createdBefore as failed
+func (p *PostgresReportRepository) FailStaleJobs(ctx context.Context, createdBefore time.Time) (int, error) {
+	const op = "report_repository.FailStaleJobs"
+	tag, err := p.db.DB.Exec(ctx, "UPDATE report_jobs SET status = 'failed', finished_at = now() WHERE status = 'pending' AND created_at < $1",
+		createdBefore)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func (p *PostgresReportRepository) Job(ctx context.Context, id uuid.UUID) (*models.ReportJob, error) {
+	const op = "report_repository.Job"
+	var job models.ReportJob
+	err := p.db.DB.QueryRow(ctx, "SELECT id, user_id, kind, format, date_from, date_to, status, COALESCE(object_name, ''), created_at, finished_at "+
+		"FROM report_jobs WHERE id = $1", id).Scan(&job.Id, &job.UserId, &job.Kind, &job.Format, &job.From, &job.To, &job.Status,
+		&job.ObjectName, &job.CreatedAt, &job.FinishedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrReportNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &job, nil
+}
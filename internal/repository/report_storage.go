@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/minio/minio-go/v7"
+)
+
+const reportPrefix = "reports/"
+
+type MinioReportStorage struct {
+	minioClient *minio.Client
+	bucketName  string
+}
+
+type ReportStorageInterface interface {
+	PutReport(ctx context.Context, name string, data []byte, contentType string) (string, error)
+	Report(ctx context.Context, objectName string) (*minio.Object, error)
+}
+
+func NewMinioReportStorage(minioClient *minio.Client, bucketName string) *MinioReportStorage {
+	return &MinioReportStorage{minioClient: minioClient, bucketName: bucketName}
+}
+
+// returns object name
+func (m *MinioReportStorage) PutReport(ctx context.Context, name string, data []byte, contentType string) (string, error) {
+	const op = "report_storage.PutReport"
+	objectName := reportPrefix + name
+	_, err := m.minioClient.PutObject(ctx, m.bucketName, objectName, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	return objectName, nil
+}
+
+func (m *MinioReportStorage) Report(ctx context.Context, objectName string) (*minio.Object, error) {
+	const op = "report_storage.Report"
+	obj, err := m.minioClient.GetObject(ctx, m.bucketName, objectName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return obj, nil
+}
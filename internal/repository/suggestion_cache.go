@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// suggestions are cached under the current generation, bumping it invalidates all of them at once
+// and the old entries expire on their own
+const suggestionGenerationKey = "equipment:suggest:gen"
+
+type RedisSuggestionCache struct {
+	redisDB *redis.Client
+}
+
+type SuggestionCacheInterface interface {
+	SuggestionsKey(ctx context.Context, prefix string, limit int) (string, error)
+	CachedSuggestions(ctx context.Context, key string) ([]models.Suggestion, error)
+	CacheSuggestions(ctx context.Context, key string, suggestions []models.Suggestion, ttl time.Duration) error
+	InvalidateSuggestions(ctx context.Context) error
+}
+
+func NewRedisSuggestionCache(redisDB *redis.Client) *RedisSuggestionCache {
+	return &RedisSuggestionCache{redisDB: redisDB}
+}
+
+// SuggestionsKey binds the key to the current generation. Take it before reading the database,
+// then an invalidation racing with the read leaves the result under the old generation
+func (r *RedisSuggestionCache) SuggestionsKey(ctx context.Context, prefix string, limit int) (string, error) {
+	const op = "suggestion_cache.SuggestionsKey"
+	gen, err := r.redisDB.Get(ctx, suggestionGenerationKey).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	return fmt.Sprintf("equipment:suggest:%d:%d:%s", gen, limit, prefix), nil
+}
+
+func (r *RedisSuggestionCache) CachedSuggestions(ctx context.Context, key string) ([]models.Suggestion, error) {
+	const op = "suggestion_cache.CachedSuggestions"
+	data, err := r.redisDB.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, fmt.Errorf("%s: %w", op, ErrCacheMiss)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	var suggestions []models.Suggestion
+	err = json.Unmarshal(data, &suggestions)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return suggestions, nil
+}
+
+func (r *RedisSuggestionCache) CacheSuggestions(ctx context.Context, key string, suggestions []models.Suggestion, ttl time.Duration) error {
+	const op = "suggestion_cache.CacheSuggestions"
+	data, err := json.Marshal(suggestions)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	err = r.redisDB.Set(ctx, key, data, ttl).Err()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *RedisSuggestionCache) InvalidateSuggestions(ctx context.Context) error {
+	const op = "suggestion_cache.InvalidateSuggestions"
+	err := r.redisDB.Incr(ctx, suggestionGenerationKey).Err()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
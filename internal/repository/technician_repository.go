@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/pkg/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrTechnicianAlreadyExists = errors.New("technician already exists")
+	ErrTechnicianNotFound      = errors.New("technician not found")
+	ErrTechnicianAssigned      = errors.New("technician has upcoming assignments")
+	ErrShiftNotFound           = errors.New("shift not found")
+	ErrShiftOverlap            = errors.New("shift overlaps another shift")
+	ErrShiftHasAssignments     = errors.New("shift has upcoming assignments")
+	ErrNoOperator              = errors.New("no operator available")
+)
+
+// operatorCandidates selects the technicians who can operate equipment $1 from $2 to $3: they
+// are on a shift covering the slot, hold the equipment's certifications until its end and
+// don't operate another booking at that time. Booking $4 is ignored so a moved booking
+// doesn't conflict with itself
+const operatorCandidates = `FROM technicians t
+	JOIN technician_shifts s ON s.technician_id = t.user_id AND s.start_time <= $2 AND s.end_time >= $3
+	WHERE NOT EXISTS (SELECT 1 FROM booking b WHERE b.operator_id = t.user_id AND b.status = 'active' AND b.id <> $4
+		AND b.start_time <= $3 AND b.end_time >= $2)
+	AND NOT EXISTS (SELECT 1 FROM equipment_certifications ec LEFT JOIN user_certifications uc
+		ON uc.certification_id = ec.certification_id AND uc.user_id = t.user_id AND uc.expires_at >= $3
+		WHERE ec.equipment_id = $1 AND uc.user_id IS NULL)`
+
+// operatorFreeSQL is the condition that the equipment alias doesn't require an operator or some
+// technician could be reserved for it from start to end, as operatorCandidates selects them
+func operatorFreeSQL(equipment, start, end string) string {
+	return "(NOT " + equipment + ".requires_operator OR EXISTS (SELECT 1 FROM technicians t " +
+		"JOIN technician_shifts s ON s.technician_id = t.user_id AND s.start_time <= (" + start + ") AND s.end_time >= (" + end + ") " +
+		"WHERE NOT EXISTS (SELECT 1 FROM booking b WHERE b.operator_id = t.user_id AND b.status = 'active' " +
+		"AND b.start_time <= (" + end + ") AND b.end_time >= (" + start + ")) " +
+		"AND NOT EXISTS (SELECT 1 FROM equipment_certifications ec LEFT JOIN user_certifications uc " +
+		"ON uc.certification_id = ec.certification_id AND uc.user_id = t.user_id AND uc.expires_at >= (" + end + ") " +
+		"WHERE ec.equipment_id = " + equipment + ".id AND uc.user_id IS NULL)))"
+}
+
+// operatorOrder prefers technician $5, then the one with the fewest assignments in the shift
+const operatorOrder = ` ORDER BY COALESCE(t.user_id = $5, false) DESC, (SELECT count(*) FROM booking b WHERE b.operator_id = t.user_id
+	AND b.status = 'active' AND b.start_time >= s.start_time AND b.end_time <= s.end_time), t.user_id`
+
+// reserveOperator assigns a free technician to the booking if its equipment requires one,
+// the booking's OperatorId is kept when that technician is free. Candidates are locked
+// and checked again, so concurrent transactions can't reserve the same technician twice
+func reserveOperator(ctx context.Context, tx pgx.Tx, booking *models.Booking) error {
+	var required bool
+	err := tx.QueryRow(ctx, "SELECT requires_operator FROM equipment WHERE id = $1", booking.EquipmentId).Scan(&required)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrEquipmentNotFound
+		}
+		return err
+	}
+	if !required {
+		booking.OperatorId = nil
+		return nil
+	}
+	args := []any{booking.EquipmentId, booking.StartTime, booking.EndTime, booking.Id, booking.OperatorId}
+	candidates, err := operatorIds(ctx, tx, "SELECT t.user_id "+operatorCandidates+operatorOrder, args...)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		return ErrNoOperator
+	}
+	// locking in a fixed order keeps concurrent reservations from deadlocking
+	_, err = tx.Exec(ctx, "SELECT 1 FROM technicians WHERE user_id = ANY($1) ORDER BY user_id FOR UPDATE", candidates)
+	if err != nil {
+		return err
+	}
+	free, err := operatorIds(ctx, tx, "SELECT t.user_id "+operatorCandidates+" AND t.user_id = ANY($6)"+operatorOrder,
+		append(args, candidates)...)
+	if err != nil {
+		return err
+	}
+	if len(free) == 0 {
+		return ErrNoOperator
+	}
+	booking.OperatorId = &free[0]
+	return nil
+}
+
+func operatorIds(ctx context.Context, tx pgx.Tx, query string, args ...any) ([]uuid.UUID, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+type PostgresTechnicianRepository struct {
+	db db.PostgresDB
+}
+
+type TechnicianRepositoryInterface interface {
+	AddTechnician(ctx context.Context, uid uuid.UUID) error
+	Technician(ctx context.Context, uid uuid.UUID) (*models.Technician, error)
+	Technicians(ctx context.Context) ([]models.Technician, error)
+	RemoveTechnician(ctx context.Context, uid uuid.UUID) error
+	AddShift(ctx context.Context, shift models.Shift) (int, error)
+	Shifts(ctx context.Context, uid uuid.UUID, from, to time.Time) ([]models.Shift, error)
+	DeleteShift(ctx context.Context, uid uuid.UUID, shiftId int) error
+	Assignments(ctx context.Context, uid uuid.UUID, from, to time.Time) ([]models.Booking, error)
+}
+
+func NewPostgresTechnicianRepository(db db.PostgresDB) PostgresTechnicianRepository {
+	return PostgresTechnicianRepository{db: db}
+}
+
+func (p *PostgresTechnicianRepository) AddTechnician(ctx context.Context, uid uuid.UUID) error {
+	const op = "technician_repository.AddTechnician"
+	_, err := p.db.DB.Exec(ctx, "INSERT INTO technicians (user_id) VALUES($1)", uid)
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) {
+			switch pgxErr.Code {
+			case "23505":
+				return fmt.Errorf("%s: %w", op, ErrTechnicianAlreadyExists)
+			case "23503":
+				return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+			}
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (p *PostgresTechnicianRepository) Technician(ctx context.Context, uid uuid.UUID) (*models.Technician, error) {
+	const op = "technician_repository.Technician"
+	var t models.Technician
+	err := p.db.DB.QueryRow(ctx, "SELECT t.user_id, u.username, u.email, t.created_at FROM technicians t "+
+		"JOIN users u ON u.uid = t.user_id WHERE t.user_id = $1", uid).Scan(&t.UserId, &t.Username, &t.Email, &t.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrTechnicianNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &t, nil
+}
+
+func (p *PostgresTechnicianRepository) Technicians(ctx context.Context) ([]models.Technician, error) {
+	const op = "technician_repository.Technicians"
+	rows, err := p.db.DB.Query(ctx, "SELECT t.user_id, u.username, u.email, t.created_at FROM technicians t "+
+		"JOIN users u ON u.uid = t.user_id ORDER BY u.username")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	technicians := []models.Technician{}
+	for rows.Next() {
+		var t models.Technician
+		err := rows.Scan(&t.UserId, &t.Username, &t.Email, &t.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		technicians = append(technicians, t)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return technicians, nil
+}
+
+// RemoveTechnician fails with ErrTechnicianAssigned while the technician operates bookings
+// that haven't ended yet
+func (p *PostgresTechnicianRepository) RemoveTechnician(ctx context.Context, uid uuid.UUID) error {
+	const op = "technician_repository.RemoveTechnician"
+	var exists, assigned bool
+	err := p.db.DB.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM technicians WHERE user_id = $1), "+
+		"EXISTS(SELECT 1 FROM booking WHERE operator_id = $1 AND status = 'active' AND end_time > now())", uid).Scan(&exists, &assigned)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return fmt.Errorf("%s: %w", op, ErrTechnicianNotFound)
+	}
+	if assigned {
+		return fmt.Errorf("%s: %w", op, ErrTechnicianAssigned)
+	}
+	_, err = p.db.DB.Exec(ctx, "DELETE FROM technicians WHERE user_id = $1", uid)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// AddShift adds a working shift, shifts of one technician can't overlap
+func (p *PostgresTechnicianRepository) AddShift(ctx context.Context, shift models.Shift) (int, error) {
+	const op = "technician_repository.AddShift"
+	var id int
+	err := p.db.DB.QueryRow(ctx, "INSERT INTO technician_shifts (technician_id, start_time, end_time) "+
+		"SELECT $1, $2, $3 WHERE NOT EXISTS (SELECT 1 FROM technician_shifts WHERE technician_id = $1 "+
+		"AND start_time < $3 AND end_time > $2) RETURNING id", shift.TechnicianId, shift.StartTime, shift.EndTime).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, ErrShiftOverlap)
+		}
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == "23503" {
+			return 0, fmt.Errorf("%s: %w", op, ErrTechnicianNotFound)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+// Shifts returns the technician's shifts intersecting [from, to)
+func (p *PostgresTechnicianRepository) Shifts(ctx context.Context, uid uuid.UUID, from, to time.Time) ([]models.Shift, error) {
+	const op = "technician_repository.Shifts"
+	rows, err := p.db.DB.Query(ctx, "SELECT id, technician_id, start_time, end_time FROM technician_shifts "+
+		"WHERE technician_id = $1 AND start_time < $3 AND end_time > $2 ORDER BY start_time", uid, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	shifts := []models.Shift{}
+	for rows.Next() {
+		var s models.Shift
+		err := rows.Scan(&s.Id, &s.TechnicianId, &s.StartTime, &s.EndTime)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		shifts = append(shifts, s)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return shifts, nil
+}
+
+// DeleteShift fails with ErrShiftHasAssignments while the technician operates bookings in the
+// shift that haven't ended yet
+func (p *PostgresTechnicianRepository) DeleteShift(ctx context.Context, uid uuid.UUID, shiftId int) error {
+	const op = "technician_repository.DeleteShift"
+	var assigned bool
+	err := p.db.DB.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM booking b WHERE b.operator_id = s.technician_id "+
+		"AND b.status = 'active' AND b.end_time > now() AND b.start_time >= s.start_time AND b.end_time <= s.end_time) "+
+		"FROM technician_shifts s WHERE s.id = $1 AND s.technician_id = $2", shiftId, uid).Scan(&assigned)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, ErrShiftNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if assigned {
+		return fmt.Errorf("%s: %w", op, ErrShiftHasAssignments)
+	}
+	_, err = p.db.DB.Exec(ctx, "DELETE FROM technician_shifts WHERE id = $1", shiftId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// Assignments returns the active bookings the technician operates intersecting [from, to)
+func (p *PostgresTechnicianRepository) Assignments(ctx context.Context, uid uuid.UUID, from, to time.Time) ([]models.Booking, error) {
+	const op = "technician_repository.Assignments"
+	rows, err := p.db.DB.Query(ctx, "SELECT "+bookingColumns+" FROM booking WHERE operator_id = $1 AND status = 'active' "+
+		"AND start_time < $3 AND end_time > $2 ORDER BY start_time", uid, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	bookings := []models.Booking{}
+	for rows.Next() {
+		var booking models.Booking
+		err := scanBooking(rows, &booking)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		bookings = append(bookings, booking)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return bookings, nil
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrTransferPending    = errors.New("booking already has a pending transfer")
+	ErrTransferNotFound   = errors.New("transfer not found")
+	ErrTransferNotPending = errors.New("transfer is not pending")
+	ErrUserNotFound       = errors.New("user not found")
+)
+
+const transferColumns = "id, booking_id, from_user, to_user, status, created_at, resolved_at"
+
+func scanTransfer(row pgx.Row, t *models.BookingTransfer) error {
+	return row.Scan(&t.Id, &t.BookingId, &t.FromUser, &t.ToUser, &t.Status, &t.CreatedAt, &t.ResolvedAt)
+}
+
+// cancelTransfers closes the pending transfers of cancelled bookings, there is nothing left to hand over
+func cancelTransfers(ctx context.Context, db execer, bookingIds ...int) error {
+	_, err := db.Exec(ctx, "UPDATE booking_transfers SET status = 'cancelled', resolved_at = now() "+
+		"WHERE booking_id = ANY($1) AND status = 'pending'", bookingIds)
+	return err
+}
+
+func (p *PostgresBookingRepository) CreateTransfer(ctx context.Context, transfer models.BookingTransfer) (int, error) {
+	const op = "booking_repository.CreateTransfer"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var id int
+	err = tx.QueryRow(ctx, "INSERT INTO booking_transfers (booking_id, from_user, to_user) VALUES($1, $2, $3) RETURNING id",
+		transfer.BookingId, transfer.FromUser, transfer.ToUser).Scan(&id)
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) {
+			switch pgxErr.Code {
+			case "23505":
+				return 0, fmt.Errorf("%s: %w", op, ErrTransferPending)
+			case "23503":
+				return 0, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+			}
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	err = addHistory(ctx, tx, models.BookingEvent{
+		BookingId: transfer.BookingId,
+		Action:    models.HistoryTransferProposed,
+		ActorId:   &transfer.FromUser,
+		Details:   map[string]any{"transfer_id": id, "from": transfer.FromUser, "to": transfer.ToUser},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (p *PostgresBookingRepository) Transfer(ctx context.Context, transferId int) (*models.BookingTransfer, error) {
+	const op = "booking_repository.Transfer"
+	var t models.BookingTransfer
+	err := scanTransfer(p.db.DB.QueryRow(ctx, "SELECT "+transferColumns+" FROM booking_transfers WHERE id = $1", transferId), &t)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrTransferNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &t, nil
+}
+
+func (p *PostgresBookingRepository) IncomingTransfers(ctx context.Context, uid uuid.UUID) ([]models.BookingTransfer, error) {
+	const op = "booking_repository.IncomingTransfers"
+	rows, err := p.db.DB.Query(ctx, "SELECT "+transferColumns+" FROM booking_transfers WHERE to_user = $1 AND status = 'pending' "+
+		"ORDER BY created_at", uid)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+	transfers := []models.BookingTransfer{}
+	for rows.Next() {
+		var t models.BookingTransfer
+		err := scanTransfer(rows, &t)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		transfers = append(transfers, t)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+	return transfers, nil
+}
+
+// AcceptTransfer moves the booking to the recipient, their project and group in one
+// transaction, the booking has to fit into the quota of the new group. It fails with
+// ErrBookingNotFound when the booking was cancelled, changed hands or ended in the meantime,
+// the transfer is cancelled then
+func (p *PostgresBookingRepository) AcceptTransfer(ctx context.Context, transfer models.BookingTransfer, projectId, groupId int) error {
+	const op = "booking_repository.AcceptTransfer"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, "UPDATE booking_transfers SET status = 'accepted', resolved_at = now() WHERE id = $1 AND status = 'pending'",
+		transfer.Id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrTransferNotPending)
+	}
+	var booking models.Booking
+	err = scanBooking(tx.QueryRow(ctx, "UPDATE booking SET user_id = $2, project_id = $3, group_id = NULLIF($5, 0) "+
+		"WHERE id = $1 AND user_id = $4 AND status = 'active' AND end_time > now() RETURNING "+bookingColumns,
+		transfer.BookingId, transfer.ToUser, projectId, transfer.FromUser, groupId), &booking)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		_, err = tx.Exec(ctx, "UPDATE booking_transfers SET status = 'cancelled' WHERE id = $1", transfer.Id)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		err = tx.Commit(ctx)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return fmt.Errorf("%s: %w", op, ErrBookingNotFound)
+	}
+	err = checkGroupQuota(ctx, tx, booking)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	err = addHistory(ctx, tx, models.BookingEvent{
+		BookingId: transfer.BookingId,
+		Action:    models.HistoryTransferAccepted,
+		ActorId:   &transfer.ToUser,
+		Details: map[string]any{"transfer_id": transfer.Id, "from": transfer.FromUser, "to": transfer.ToUser, "project_id": projectId,
+			"group_id": groupId},
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// ResolveTransfer closes a pending transfer with the declined or cancelled status
+func (p *PostgresBookingRepository) ResolveTransfer(ctx context.Context, transfer models.BookingTransfer, status string, actor uuid.UUID) error {
+	const op = "booking_repository.ResolveTransfer"
+	tx, err := p.db.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, "UPDATE booking_transfers SET status = $2, resolved_at = now() WHERE id = $1 AND status = 'pending'",
+		transfer.Id, status)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrTransferNotPending)
+	}
+	action := models.HistoryTransferDeclined
+	if status == models.TransferCancelled {
+		action = models.HistoryTransferCanceled
+	}
+	err = addHistory(ctx, tx, models.BookingEvent{
+		BookingId: transfer.BookingId,
+		Action:    action,
+		ActorId:   &actor,
+		Details:   map[string]any{"transfer_id": transfer.Id, "from": transfer.FromUser, "to": transfer.ToUser},
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// TransferParty tells whether the user proposed or received a transfer of the booking
+func (p *PostgresBookingRepository) TransferParty(ctx context.Context, bookingId int, uid uuid.UUID) (bool, error) {
+	const op = "booking_repository.TransferParty"
+	var ok bool
+	err := p.db.DB.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM booking_transfers WHERE booking_id = $1 AND (from_user = $2 OR to_user = $2))",
+		bookingId, uid).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return ok, nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/Gergenus/bookingService/internal/models"
 	"github.com/Gergenus/bookingService/pkg/db"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/redis/go-redis/v9"
 )
@@ -17,8 +18,11 @@ import (
 var (
 	ErrUserAlreadyExists = errors.New("user already exists")
 	ErrNoSessionFound    = errors.New("no session found")
+	ErrBadgeTaken        = errors.New("badge is assigned to another user")
 )
 
+const userColumns = "uid, username, role, email, hashed_password, COALESCE(time_zone, ''), COALESCE(badge_id, '')"
+
 type UserRepository struct {
 	db      db.PostgresDB
 	redisDB *redis.Client
@@ -30,6 +34,9 @@ type UserRepositoryInterface interface {
 	UserByEmail(ctx context.Context, email string) (*models.User, error)
 	CreateJWTSession(ctx context.Context, uuid, refreshToken uuid.UUID, fingerprint, ip string, expiresIn int64, RefreshTTL time.Duration) error
 	RefreshSession(ctx context.Context, oldRefresh uuid.UUID) (*models.RefreshSession, error)
+	SetTimeZone(ctx context.Context, uid uuid.UUID, timeZone string) error
+	SetBadge(ctx context.Context, uid uuid.UUID, badgeId string) error
+	UserByBadge(ctx context.Context, badgeId string) (*models.User, error)
 }
 
 func NewUserRepository(db db.PostgresDB, redisDB *redis.Client) *UserRepository {
@@ -57,8 +64,8 @@ func (u *UserRepository) CreateUser(ctx context.Context, user *models.User) (*uu
 func (u *UserRepository) User(ctx context.Context, uuid uuid.UUID) (*models.User, error) {
 	const op = "user_repository.User"
 	var user models.User
-	err := u.db.DB.QueryRow(ctx, "SELECT * FROM users WHERE uid = $1", uuid.String()).Scan(&user.UUID, &user.Username,
-		&user.Role, &user.Email, &user.HashedPassword)
+	err := u.db.DB.QueryRow(ctx, "SELECT "+userColumns+" FROM users WHERE uid = $1", uuid.String()).Scan(&user.UUID, &user.Username,
+		&user.Role, &user.Email, &user.HashedPassword, &user.TimeZone, &user.BadgeId)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -68,9 +75,26 @@ func (u *UserRepository) User(ctx context.Context, uuid uuid.UUID) (*models.User
 func (u *UserRepository) UserByEmail(ctx context.Context, email string) (*models.User, error) {
 	const op = "repository.UserByEmail"
 	var user models.User
-	err := u.db.DB.QueryRow(ctx, "SELECT * FROM users WHERE email = $1", email).Scan(&user.UUID, &user.Username,
-		&user.Role, &user.Email, &user.HashedPassword)
+	err := u.db.DB.QueryRow(ctx, "SELECT "+userColumns+" FROM users WHERE email = $1", email).Scan(&user.UUID, &user.Username,
+		&user.Role, &user.Email, &user.HashedPassword, &user.TimeZone, &user.BadgeId)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &user, nil
+}
+
+func (u *UserRepository) UserByBadge(ctx context.Context, badgeId string) (*models.User, error) {
+	const op = "user_repository.UserByBadge"
+	var user models.User
+	err := u.db.DB.QueryRow(ctx, "SELECT "+userColumns+" FROM users WHERE badge_id = $1", badgeId).Scan(&user.UUID, &user.Username,
+		&user.Role, &user.Email, &user.HashedPassword, &user.TimeZone, &user.BadgeId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	return &user, nil
@@ -121,3 +145,33 @@ func (u *UserRepository) RefreshSession(ctx context.Context, oldRefresh uuid.UUI
 	}
 	return &resSession, nil
 }
+
+// SetTimeZone stores the preferred zone, an empty one resets it to UTC
+func (u *UserRepository) SetTimeZone(ctx context.Context, uid uuid.UUID, timeZone string) error {
+	const op = "user_repository.SetTimeZone"
+	tag, err := u.db.DB.Exec(ctx, "UPDATE users SET time_zone = NULLIF($2, '') WHERE uid = $1", uid, timeZone)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+	}
+	return nil
+}
+
+// SetBadge links the user to a badge for instrument interlocks, an empty id removes it
+func (u *UserRepository) SetBadge(ctx context.Context, uid uuid.UUID, badgeId string) error {
+	const op = "user_repository.SetBadge"
+	tag, err := u.db.DB.Exec(ctx, "UPDATE users SET badge_id = NULLIF($2, '') WHERE uid = $1", uid, badgeId)
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == "23505" {
+			return fmt.Errorf("%s: %w", op, ErrBadgeTaken)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+	}
+	return nil
+}
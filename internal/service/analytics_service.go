@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+)
+
+const (
+	utilizationCacheTTL = 10 * time.Minute
+	topUsersLimit       = 5
+)
+
+type AnalyticsService struct {
+	repo repository.AnalyticsRepositoryInterface
+	log  *slog.Logger
+}
+
+type AnalyticsServiceInterface interface {
+	Utilization(ctx context.Context, equipmentId int, from, to time.Time) (*models.Utilization, error)
+}
+
+func NewAnalyticsService(repo repository.AnalyticsRepositoryInterface, log *slog.Logger) AnalyticsService {
+	return AnalyticsService{repo: repo, log: log}
+}
+
+func (a *AnalyticsService) Utilization(ctx context.Context, equipmentId int, from, to time.Time) (*models.Utilization, error) {
+	const op = "analytics_service.Utilization"
+	log := a.log.With(slog.String("op", op))
+	log.Info("getting utilization", slog.Int("equipment_id", equipmentId), slog.Time("from", from), slog.Time("to", to))
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidPeriod)
+	}
+	key := fmt.Sprintf("analytics:utilization:%d:%d:%d", equipmentId, from.Unix(), to.Unix())
+	cached, err := a.repo.CachedUtilization(ctx, key)
+	if err == nil {
+		return cached, nil
+	}
+	if !errors.Is(err, repository.ErrCacheMiss) {
+		log.Warn("reading utilization cache error", slog.String("error", err.Error()))
+	}
+
+	utilization, err := a.repo.Utilization(ctx, equipmentId, from, to, topUsersLimit)
+	if err != nil {
+		log.Error("computing utilization error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	err = a.repo.CacheUtilization(ctx, key, utilization, utilizationCacheTTL)
+	if err != nil {
+		log.Warn("caching utilization error", slog.String("error", err.Error()))
+	}
+	return utilization, nil
+}
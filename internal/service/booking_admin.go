@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrReasonRequired  = errors.New("reason required")
+	ErrInvalidInterval = errors.New("invalid interval")
+)
+
+const timeLayout = "2006-01-02 15:04"
+
+// notify informs a user about a change made by somebody else, failures are logged only
+func (b *BookingService) notify(ctx context.Context, n models.Notification) {
+	err := b.notifyRepo.Notify(ctx, n)
+	if err != nil {
+		b.log.Error("notifying user error", slog.String("user_id", n.UserId.String()),
+			slog.String("kind", n.Kind), slog.String("error", err.Error()))
+	}
+}
+
+func (b *BookingService) AdminHistory(ctx context.Context, bookingId int) ([]models.BookingEvent, error) {
+	const op = "booking_service.AdminHistory"
+	_, err := b.Booking(ctx, bookingId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	events, err := b.bookingRepo.History(ctx, bookingId)
+	if err != nil {
+		b.log.Error("getting booking history error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return events, nil
+}
+
+// AdminCreateBooking books for any scientist. With override the booking policies are
+// skipped, the violated ones and the mandatory reason are kept in the booking history
+func (b *BookingService) AdminCreateBooking(ctx context.Context, booking models.Booking, admin uuid.UUID, override bool, reason string) (int, error) {
+	const op = "booking_service.AdminCreateBooking"
+	log := b.log.With(slog.String("op", op))
+	log.Info("creating booking on behalf", slog.Int("equipment_id", booking.EquipmentId),
+		slog.String("user_id", booking.UserId.String()), slog.String("admin", admin.String()))
+	if override && reason == "" {
+		return 0, fmt.Errorf("%s: %w", op, ErrReasonRequired)
+	}
+	if !booking.StartTime.Before(booking.EndTime) {
+		return 0, fmt.Errorf("%s: %w", op, ErrInvalidInterval)
+	}
+	booking.BookedBy = &admin
+	violations, err := b.validateBooking(ctx, log, &booking, override)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	booking.Priority, err = b.projectRepo.BookingPriority(ctx, booking.ProjectId, booking.UserId)
+	if err != nil {
+		log.Error("getting booking priority error", slog.String("error", err.Error()))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	details := map[string]any{"user_id": booking.UserId, "by_admin": true}
+	if override {
+		details["override"] = true
+		details["reason"] = reason
+		details["violated_policies"] = violations
+	}
+	event := models.BookingEvent{Action: models.HistoryCreated, ActorId: &admin, Details: details}
+	id, err := b.bookingRepo.CreateBooking(ctx, booking, !override, event)
+	if err != nil {
+		if errors.Is(err, repository.ErrIntervalInterception) {
+			return 0, fmt.Errorf("%s: %w", op, ErrIntervalInterception)
+		}
+		if errors.Is(err, repository.ErrQuotaExceeded) {
+			return 0, fmt.Errorf("%s: %w", op, ErrQuotaExceeded)
+		}
+		if errors.Is(err, repository.ErrNoOperator) {
+			return 0, fmt.Errorf("%s: %w", op, ErrNoOperatorAvailable)
+		}
+		if errors.Is(err, repository.ErrEquipmentUnavailable) {
+			return 0, fmt.Errorf("%s: %w", op, ErrEquipmentUnavailable)
+		}
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return 0, fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		log.Error("creating booking error", slog.String("error", err.Error()))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if booking.UserId != admin {
+		b.notify(ctx, models.Notification{UserId: booking.UserId, Kind: models.NotificationBookingCreated,
+			Message: fmt.Sprintf("An administrator booked equipment #%d for you from %s to %s", booking.EquipmentId,
+				booking.StartTime.Format(timeLayout), booking.EndTime.Format(timeLayout)),
+			Payload: map[string]any{"booking_id": id}})
+	}
+	return id, nil
+}
+
+func (b *BookingService) AdminCancelBooking(ctx context.Context, bookingId int, admin uuid.UUID, reason string) error {
+	const op = "booking_service.AdminCancelBooking"
+	log := b.log.With(slog.String("op", op))
+	log.Info("cancelling booking", slog.Int("booking_id", bookingId), slog.String("admin", admin.String()))
+	if reason == "" {
+		return fmt.Errorf("%s: %w", op, ErrReasonRequired)
+	}
+	booking, err := b.Booking(ctx, bookingId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if booking.Status != models.BookingActive {
+		return fmt.Errorf("%s: %w", op, ErrBookingNotFound)
+	}
+	err = b.bookingRepo.DeleteBooking(ctx, bookingId, models.BookingEvent{Action: models.HistoryCancelled, ActorId: &admin,
+		Details: map[string]any{"by_admin": true, "reason": reason}})
+	if err != nil {
+		log.Error("cancelling booking error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	b.notify(ctx, models.Notification{UserId: booking.UserId, Kind: models.NotificationBookingCancelled,
+		Message: fmt.Sprintf("Your booking of equipment #%d from %s was cancelled by an administrator: %s",
+			booking.EquipmentId, booking.StartTime.Format(timeLayout), reason),
+		Payload: map[string]any{"booking_id": bookingId, "reason": reason}})
+	b.notifyWaitlist(ctx, booking)
+	return nil
+}
+
+// MoveBooking moves a booking to another slot or instrument. The moved booking has to pass the
+// same checks as a new one on the target equipment, with override the policy violations are
+// recorded in the history instead
+func (b *BookingService) MoveBooking(ctx context.Context, move models.BookingMove, admin uuid.UUID) error {
+	const op = "booking_service.MoveBooking"
+	log := b.log.With(slog.String("op", op))
+	log.Info("moving booking", slog.Int("booking_id", move.BookingId), slog.String("admin", admin.String()))
+	if move.Reason == "" {
+		return fmt.Errorf("%s: %w", op, ErrReasonRequired)
+	}
+	if !move.StartTime.Before(move.EndTime) {
+		return fmt.Errorf("%s: %w", op, ErrInvalidInterval)
+	}
+	booking, err := b.Booking(ctx, move.BookingId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if booking.Status != models.BookingActive {
+		return fmt.Errorf("%s: %w", op, ErrBookingNotFound)
+	}
+	if move.EquipmentId == 0 {
+		move.EquipmentId = booking.EquipmentId
+	}
+	moved := *booking
+	moved.EquipmentId, moved.StartTime, moved.EndTime = move.EquipmentId, move.StartTime, move.EndTime
+	violations, err := b.validateBooking(ctx, log, &moved, move.Override)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	details := map[string]any{
+		"reason":            move.Reason,
+		"from_equipment":    booking.EquipmentId,
+		"from_start":        booking.StartTime,
+		"from_end":          booking.EndTime,
+		"to_equipment":      move.EquipmentId,
+		"to_start":          move.StartTime,
+		"to_end":            move.EndTime,
+		"override":          move.Override,
+		"violated_policies": violations,
+	}
+	event := models.BookingEvent{Action: models.HistoryMoved, ActorId: &admin, Details: details}
+	err = b.bookingRepo.MoveBooking(ctx, move, !move.Override, event)
+	if err != nil {
+		if errors.Is(err, repository.ErrIntervalInterception) {
+			return fmt.Errorf("%s: %w", op, ErrIntervalInterception)
+		}
+		if errors.Is(err, repository.ErrQuotaExceeded) {
+			return fmt.Errorf("%s: %w", op, ErrQuotaExceeded)
+		}
+		if errors.Is(err, repository.ErrNoOperator) {
+			return fmt.Errorf("%s: %w", op, ErrNoOperatorAvailable)
+		}
+		if errors.Is(err, repository.ErrEquipmentUnavailable) {
+			return fmt.Errorf("%s: %w", op, ErrEquipmentUnavailable)
+		}
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		log.Error("moving booking error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	b.notify(ctx, models.Notification{UserId: booking.UserId, Kind: models.NotificationBookingMoved,
+		Message: fmt.Sprintf("Your booking of equipment #%d was moved by an administrator to equipment #%d from %s to %s: %s",
+			booking.EquipmentId, move.EquipmentId, move.StartTime.Format(timeLayout), move.EndTime.Format(timeLayout), move.Reason),
+		Payload: map[string]any{"booking_id": move.BookingId, "reason": move.Reason}})
+	b.notifyWaitlist(ctx, booking)
+	return nil
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (f *fakeBookingRepo) HasOpenRound(ctx context.Context, equipmentId int, startTime, endTime time.Time) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeBookingRepo) CreateBooking(ctx context.Context, booking models.Booking, enforceQuota bool, event models.BookingEvent) (int, error) {
+	if f.storeErr != nil {
+		return 0, f.storeErr
+	}
+	event.BookingId = 100
+	f.stored = append(f.stored, booking)
+	f.events = append(f.events, event)
+	return 100, nil
+}
+
+func (f *fakeBookingRepo) DeleteBooking(ctx context.Context, bookingId int, event models.BookingEvent) error {
+	if f.storeErr != nil {
+		return f.storeErr
+	}
+	event.BookingId = bookingId
+	f.events = append(f.events, event)
+	return nil
+}
+
+func (f *fakeBookingRepo) MoveBooking(ctx context.Context, move models.BookingMove, enforceQuota bool, event models.BookingEvent) error {
+	if f.storeErr != nil {
+		return f.storeErr
+	}
+	event.BookingId = move.BookingId
+	f.events = append(f.events, event)
+	return nil
+}
+
+func (f *fakeBookingRepo) NotifyWaitlist(ctx context.Context, equipmentId int, from, to time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeProjectRepo) BookingPriority(ctx context.Context, projectId int, uid uuid.UUID) (int, error) {
+	return 1, nil
+}
+
+type fakeNotifyRepo struct {
+	repository.NotificationRepositoryInterface
+	sent []models.Notification
+}
+
+func (f *fakeNotifyRepo) Notify(ctx context.Context, n models.Notification) error {
+	f.sent = append(f.sent, n)
+	return nil
+}
+
+// adminTestService is set up with a certified project member and an untrained outsider
+func adminTestService(repo *fakeBookingRepo, member, outsider uuid.UUID) BookingService {
+	certs := &fakeCertRepo{missing: map[uuid.UUID][]models.Certification{outsider: {{Id: 1, Name: "SEM basic"}}}}
+	return BookingService{bookingRepo: repo, projectRepo: &fakeProjectRepo{members: map[int][]uuid.UUID{10: {member}}},
+		labRepo: &fakeLabRepo{}, groupRepo: &fakeGroupRepo{}, certRepo: certs, notifyRepo: &fakeNotifyRepo{},
+		log: slog.New(slog.DiscardHandler)}
+}
+
+func TestAdminCreateBooking(t *testing.T) {
+	admin, member, outsider := uuid.New(), uuid.New(), uuid.New()
+	start := time.Now().Add(time.Hour)
+	tests := []struct {
+		name            string
+		uid             uuid.UUID
+		override        bool
+		reason          string
+		storeErr        error
+		expectedErr     error
+		expectedDetails map[string]any
+	}{
+		{
+			name:            "policies pass",
+			uid:             member,
+			expectedDetails: map[string]any{"user_id": member, "by_admin": true},
+		},
+		{
+			name:     "override records the violated policies",
+			uid:      outsider,
+			override: true,
+			reason:   "beam time granted by the director",
+			expectedDetails: map[string]any{"user_id": outsider, "by_admin": true, "override": true,
+				"reason":            "beam time granted by the director",
+				"violated_policies": []string{ErrNotProjectMember.Error(), "certification required: SEM basic"}},
+		},
+		{
+			name:        "policy violation without override",
+			uid:         outsider,
+			expectedErr: ErrNotProjectMember,
+		},
+		{
+			name:        "override without a reason",
+			uid:         outsider,
+			override:    true,
+			expectedErr: ErrReasonRequired,
+		},
+		{
+			name:        "no technician available",
+			uid:         member,
+			storeErr:    repository.ErrNoOperator,
+			expectedErr: ErrNoOperatorAvailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeBookingRepo{storeErr: tt.storeErr}
+			srv := adminTestService(repo, member, outsider)
+			booking := models.Booking{EquipmentId: 5, UserId: tt.uid, ProjectId: 10, StartTime: start, EndTime: start.Add(time.Hour)}
+			id, err := srv.AdminCreateBooking(context.Background(), booking, admin, tt.override, tt.reason)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, repo.events)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 100, id)
+			require.Len(t, repo.events, 1)
+			event := repo.events[0]
+			assert.Equal(t, models.HistoryCreated, event.Action)
+			assert.Equal(t, &admin, event.ActorId)
+			assert.Equal(t, tt.expectedDetails, event.Details)
+		})
+	}
+}
+
+func TestAdminCancelBooking(t *testing.T) {
+	admin, member := uuid.New(), uuid.New()
+	bookings := map[int]models.Booking{
+		1: {Id: 1, EquipmentId: 5, UserId: member, Status: models.BookingActive},
+		2: {Id: 2, EquipmentId: 5, UserId: member, Status: models.BookingCancelled},
+	}
+	tests := []struct {
+		name        string
+		bookingId   int
+		reason      string
+		expectedErr error
+	}{
+		{
+			name:      "records the reason",
+			bookingId: 1,
+			reason:    "maintenance",
+		},
+		{
+			name:        "without a reason",
+			bookingId:   1,
+			expectedErr: ErrReasonRequired,
+		},
+		{
+			name:        "already cancelled",
+			bookingId:   2,
+			reason:      "maintenance",
+			expectedErr: ErrBookingNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeBookingRepo{bookings: bookings}
+			srv := adminTestService(repo, member, uuid.New())
+			err := srv.AdminCancelBooking(context.Background(), tt.bookingId, admin, tt.reason)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, repo.events)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []models.BookingEvent{{BookingId: tt.bookingId, Action: models.HistoryCancelled, ActorId: &admin,
+				Details: map[string]any{"by_admin": true, "reason": tt.reason}}}, repo.events)
+		})
+	}
+}
+
+func TestMoveBooking(t *testing.T) {
+	admin, member, outsider := uuid.New(), uuid.New(), uuid.New()
+	start := time.Now().Add(time.Hour)
+	booking := func(id int, uid uuid.UUID) models.Booking {
+		return models.Booking{Id: id, EquipmentId: 5, UserId: uid, ProjectId: 10, Status: models.BookingActive,
+			StartTime: start, EndTime: start.Add(time.Hour)}
+	}
+	bookings := map[int]models.Booking{1: booking(1, member), 2: booking(2, outsider)}
+	tests := []struct {
+		name               string
+		bookingId          int
+		override           bool
+		expectedErr        error
+		expectedViolations []string
+	}{
+		{
+			name:      "moves within the policies",
+			bookingId: 1,
+		},
+		{
+			name:               "override records the violated policies",
+			bookingId:          2,
+			override:           true,
+			expectedViolations: []string{ErrNotProjectMember.Error(), "certification required: SEM basic"},
+		},
+		{
+			name:        "policy violation without override",
+			bookingId:   2,
+			expectedErr: ErrNotProjectMember,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeBookingRepo{bookings: bookings}
+			srv := adminTestService(repo, member, outsider)
+			move := models.BookingMove{BookingId: tt.bookingId, EquipmentId: 6, StartTime: start.Add(2 * time.Hour),
+				EndTime: start.Add(3 * time.Hour), Override: tt.override, Reason: "detector replaced"}
+			err := srv.MoveBooking(context.Background(), move, admin)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, repo.events)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, repo.events, 1)
+			event := repo.events[0]
+			assert.Equal(t, tt.bookingId, event.BookingId)
+			assert.Equal(t, models.HistoryMoved, event.Action)
+			assert.Equal(t, &admin, event.ActorId)
+			assert.Equal(t, "detector replaced", event.Details["reason"])
+			assert.Equal(t, 5, event.Details["from_equipment"])
+			assert.Equal(t, 6, event.Details["to_equipment"])
+			assert.Equal(t, tt.override, event.Details["override"])
+			assert.Equal(t, tt.expectedViolations, event.Details["violated_policies"])
+		})
+	}
+}
@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	allocationScanInterval = time.Minute
+	// usage on the equipment over this window before a round's period lowers its lottery weights
+	fairShareWindow = 90 * 24 * time.Hour
+)
+
+var (
+	ErrAllocationPending   = errors.New("slot is assigned by an allocation round")
+	ErrNotLotteryEquipment = errors.New("equipment is not in lottery mode")
+	ErrInvalidRound        = errors.New("invalid allocation round")
+	ErrRoundNotFound       = errors.New("allocation round not found")
+	ErrRoundClosed         = errors.New("allocation round is closed")
+	ErrRoundNotAllocated   = errors.New("allocation results are not published yet")
+	ErrRequestNotFound     = errors.New("allocation request not found")
+	ErrWaitlistNotFound    = errors.New("waitlist entry not found")
+)
+
+// OpenRound starts collecting requests for a future period of lottery equipment
+func (b *BookingService) OpenRound(ctx context.Context, round models.AllocationRound) (int, error) {
+	const op = "booking_service.OpenRound"
+	log := b.log.With(slog.String("op", op))
+	log.Info("opening allocation round", slog.Int("equipment_id", round.EquipmentId), slog.Time("period_start", round.PeriodStart))
+	if !round.PeriodStart.Before(round.PeriodEnd) || !round.ClosesAt.After(time.Now()) || round.ClosesAt.After(round.PeriodStart) {
+		return 0, fmt.Errorf("%s: %w", op, ErrInvalidRound)
+	}
+	equipment, err := b.labRepo.Equipment(ctx, round.EquipmentId)
+	if err != nil {
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return 0, fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		log.Error("getting equipment error", slog.String("error", err.Error()))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if equipment.AllocationMode != models.AllocationLottery {
+		return 0, fmt.Errorf("%s: %w", op, ErrNotLotteryEquipment)
+	}
+	id, err := b.bookingRepo.CreateRound(ctx, round)
+	if err != nil {
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return 0, fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		log.Error("creating allocation round error", slog.String("error", err.Error()))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (b *BookingService) Rounds(ctx context.Context, equipmentId int) ([]models.AllocationRound, error) {
+	const op = "booking_service.Rounds"
+	rounds, err := b.bookingRepo.Rounds(ctx, equipmentId)
+	if err != nil {
+		b.log.Error("getting allocation rounds error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return rounds, nil
+}
+
+func (b *BookingService) round(ctx context.Context, roundId int) (*models.AllocationRound, error) {
+	round, err := b.bookingRepo.Round(ctx, roundId)
+	if err != nil {
+		if errors.Is(err, repository.ErrRoundNotFound) {
+			return nil, ErrRoundNotFound
+		}
+		return nil, err
+	}
+	return round, nil
+}
+
+// SubmitRequest adds a request to an open round. The booking policies are checked now,
+// the slot itself is only assigned when the round is allocated
+func (b *BookingService) SubmitRequest(ctx context.Context, req models.AllocationRequest) (int, error) {
+	const op = "booking_service.SubmitRequest"
+	log := b.log.With(slog.String("op", op))
+	log.Info("submitting allocation request", slog.Int("round_id", req.RoundId), slog.String("user_id", req.UserId.String()))
+	if req.ProjectId == 0 {
+		return 0, fmt.Errorf("%s: %w", op, ErrProjectRequired)
+	}
+	round, err := b.round(ctx, req.RoundId)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if round.Status != models.RoundOpen || !time.Now().Before(round.ClosesAt) {
+		return 0, fmt.Errorf("%s: %w", op, ErrRoundClosed)
+	}
+	if !req.StartTime.Before(req.EndTime) || req.StartTime.Before(round.PeriodStart) || req.EndTime.After(round.PeriodEnd) {
+		return 0, fmt.Errorf("%s: %w", op, ErrInvalidInterval)
+	}
+	booking := models.Booking{EquipmentId: round.EquipmentId, UserId: req.UserId, ProjectId: req.ProjectId, GroupId: req.GroupId,
+		StartTime: req.StartTime, EndTime: req.EndTime, Notes: req.Notes, Metadata: req.Metadata}
+	_, err = b.checkPolicies(ctx, &booking, false)
+	if err != nil {
+		if !isPolicyError(err) {
+			log.Error("checking booking policies error", slog.String("error", err.Error()))
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	req.GroupId, req.Metadata = booking.GroupId, booking.Metadata
+	id, err := b.bookingRepo.CreateRequest(ctx, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrRoundNotOpen) {
+			return 0, fmt.Errorf("%s: %w", op, ErrRoundClosed)
+		}
+		log.Error("creating allocation request error", slog.String("error", err.Error()))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (b *BookingService) MyRequests(ctx context.Context, uid uuid.UUID) ([]models.AllocationRequest, error) {
+	const op = "booking_service.MyRequests"
+	requests, err := b.bookingRepo.UserRequests(ctx, uid)
+	if err != nil {
+		b.log.Error("getting allocation requests error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return requests, nil
+}
+
+func (b *BookingService) WithdrawRequest(ctx context.Context, requestId int, uid uuid.UUID) error {
+	const op = "booking_service.WithdrawRequest"
+	err := b.bookingRepo.WithdrawRequest(ctx, requestId, uid)
+	if err != nil {
+		if errors.Is(err, repository.ErrRequestNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrRequestNotFound)
+		}
+		if errors.Is(err, repository.ErrRoundNotOpen) {
+			return fmt.Errorf("%s: %w", op, ErrRoundClosed)
+		}
+		b.log.Error("withdrawing allocation request error", slog.String("op", op), slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// RoundResults is available to everyone once the round is allocated, requesters
+// see their own requests in it
+func (b *BookingService) RoundResults(ctx context.Context, roundId int, uid uuid.UUID) (*models.RoundResults, error) {
+	const op = "booking_service.RoundResults"
+	round, err := b.round(ctx, roundId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if round.Status != models.RoundAllocated {
+		return nil, fmt.Errorf("%s: %w", op, ErrRoundNotAllocated)
+	}
+	requests, err := b.bookingRepo.RoundRequests(ctx, roundId)
+	if err != nil {
+		b.log.Error("getting round requests error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	results := models.RoundResults{Round: *round, Requests: []models.AllocationRequest{}}
+	for _, req := range requests {
+		if req.Status == models.RequestGranted {
+			results.Granted++
+		} else {
+			results.Rejected++
+		}
+		if req.UserId == uid {
+			results.Requests = append(results.Requests, req)
+		}
+	}
+	return &results, nil
+}
+
+func (b *BookingService) Waitlist(ctx context.Context, uid uuid.UUID) ([]models.WaitlistEntry, error) {
+	const op = "booking_service.Waitlist"
+	entries, err := b.bookingRepo.Waitlist(ctx, uid)
+	if err != nil {
+		b.log.Error("getting waitlist error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return entries, nil
+}
+
+func (b *BookingService) CancelWaitlistEntry(ctx context.Context, entryId int, uid uuid.UUID) error {
+	const op = "booking_service.CancelWaitlistEntry"
+	err := b.bookingRepo.CancelWaitlistEntry(ctx, entryId, uid)
+	if err != nil {
+		if errors.Is(err, repository.ErrWaitlistNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrWaitlistNotFound)
+		}
+		b.log.Error("cancelling waitlist entry error", slog.String("op", op), slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// notifyWaitlist tells waiting users about a freed slot, failures are logged only
+func (b *BookingService) notifyWaitlist(ctx context.Context, booking *models.Booking) {
+	n, err := b.bookingRepo.NotifyWaitlist(ctx, booking.EquipmentId, booking.StartTime, booking.EndTime)
+	if err != nil {
+		b.log.Error("notifying waitlist error", slog.Int("booking_id", booking.Id), slog.String("error", err.Error()))
+		return
+	}
+	if n > 0 {
+		b.log.Info("waitlist notified", slog.Int("booking_id", booking.Id), slog.Int64("count", n))
+	}
+}
+
+// RunAllocator periodically allocates the rounds whose submission window closed.
+// It blocks until ctx is cancelled
+func (b *BookingService) RunAllocator(ctx context.Context) {
+	const op = "booking_service.RunAllocator"
+	log := b.log.With(slog.String("op", op))
+	ticker := time.NewTicker(allocationScanInterval)
+	defer ticker.Stop()
+	for {
+		rounds, err := b.bookingRepo.DueRounds(ctx, time.Now())
+		if err != nil {
+			log.Error("getting due rounds error", slog.String("error", err.Error()))
+		}
+		for _, round := range rounds {
+			err := b.allocateRound(ctx, round)
+			if err != nil {
+				log.Error("allocating round error", slog.Int("round_id", round.Id), slog.String("error", err.Error()))
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (b *BookingService) allocateRound(ctx context.Context, round models.AllocationRound) error {
+	requests, err := b.bookingRepo.RoundRequests(ctx, round.Id)
+	if err != nil {
+		return err
+	}
+	busy, err := b.bookingRepo.SearchBookings(ctx, models.BookingFilter{EquipmentId: round.EquipmentId, From: &round.PeriodStart,
+		To: &round.PeriodEnd, Status: models.BookingActive})
+	if err != nil {
+		return err
+	}
+	usage, err := b.bookingRepo.AllocationUsage(ctx, round.EquipmentId, round.PeriodStart.Add(-fairShareWindow), round.PeriodStart)
+	if err != nil {
+		return err
+	}
+	pastUsage := make(map[string]float64, len(usage))
+	for _, entry := range usage {
+		pastUsage[requesterKey(entry.GroupId, entry.UserId)] += entry.Hours
+	}
+	shares := make(map[string]float64)
+	for _, req := range requests {
+		key := requesterKey(req.GroupId, req.UserId)
+		if _, ok := shares[key]; ok {
+			continue
+		}
+		shares[key] = 1
+		if req.GroupId != 0 {
+			group, err := b.groupRepo.Group(ctx, req.GroupId)
+			if err != nil && !errors.Is(err, repository.ErrGroupNotFound) {
+				return err
+			}
+			if group != nil {
+				shares[key] = group.Share
+			}
+		}
+	}
+	seed := rand.Int64()
+	requests = fairShare(requests, busy, pastUsage, shares, rand.New(rand.NewPCG(uint64(seed), uint64(round.Id))))
+	for i := range requests {
+		if requests[i].Status != models.RequestGranted {
+			continue
+		}
+		requests[i].Priority, err = b.projectRepo.BookingPriority(ctx, requests[i].ProjectId, requests[i].UserId)
+		if err != nil {
+			return err
+		}
+	}
+	err = b.bookingRepo.ApplyAllocation(ctx, round, requests, seed)
+	if err != nil {
+		if errors.Is(err, repository.ErrRoundChanged) {
+			// a request came in or was withdrawn right at the deadline, the next scan draws again
+			b.log.Info("allocation round changed while drawing", slog.Int("round_id", round.Id))
+			return nil
+		}
+		if errors.Is(err, repository.ErrEquipmentUnavailable) {
+			// the round is allocated once the equipment is back in service
+			b.log.Info("allocation round equipment not in service", slog.Int("round_id", round.Id))
+			return nil
+		}
+		return err
+	}
+	b.log.Info("allocation round published", slog.Int("round_id", round.Id), slog.Int("requests", len(requests)),
+		slog.Int64("seed", seed))
+	return nil
+}
+
+// requesterKey identifies who competes in a lottery: a group, or a user without a group
+func requesterKey(groupId int, uid uuid.UUID) string {
+	if groupId != 0 {
+		return fmt.Sprintf("group:%d", groupId)
+	}
+	return "user:" + uid.String()
+}
+
+// fairShare decides which requests of a round are granted. Requesters are ordered by a
+// weighted lottery, the weight halves for every fair share of past usage a requester
+// consumed. In that order requesters take turns granting one request at a time, in
+// submission order, skipping requests that intersect busy slots or earlier grants
+func fairShare(requests []models.AllocationRequest, busy []models.Booking, usage, shares map[string]float64, rng *rand.Rand) []models.AllocationRequest {
+	var totalUsage, totalShares float64
+	for _, hours := range usage {
+		totalUsage += hours
+	}
+	queues := make(map[string][]int)
+	var keys []string
+	for i, req := range requests {
+		key := requesterKey(req.GroupId, req.UserId)
+		if _, ok := queues[key]; !ok {
+			keys = append(keys, key)
+			totalShares += shares[key]
+		}
+		queues[key] = append(queues[key], i)
+		requests[i].Status = models.RequestRejected
+	}
+	// weighted sampling without replacement: the larger u^(1/w) draws first
+	draws := make(map[string]float64, len(keys))
+	for _, key := range keys {
+		weight := 1.0
+		if totalUsage > 0 && totalShares > 0 {
+			weight = math.Exp2(-(usage[key] / totalUsage) / (shares[key] / totalShares))
+		}
+		draws[key] = math.Pow(rng.Float64(), 1/weight)
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return draws[keys[i]] > draws[keys[j]] })
+
+	taken := make([][2]time.Time, 0, len(busy)+len(requests))
+	for _, booking := range busy {
+		taken = append(taken, [2]time.Time{booking.StartTime, booking.EndTime})
+	}
+	free := func(req models.AllocationRequest) bool {
+		for _, slot := range taken {
+			if !req.StartTime.After(slot[1]) && !req.EndTime.Before(slot[0]) {
+				return false
+			}
+		}
+		return true
+	}
+	for pending := true; pending; {
+		pending = false
+		for _, key := range keys {
+			for len(queues[key]) > 0 {
+				i := queues[key][0]
+				queues[key] = queues[key][1:]
+				if free(requests[i]) {
+					requests[i].Status = models.RequestGranted
+					taken = append(taken, [2]time.Time{requests[i].StartTime, requests[i].EndTime})
+					break
+				}
+			}
+			pending = pending || len(queues[key]) > 0
+		}
+	}
+	return requests
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFairShare(t *testing.T) {
+	day := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	slot := func(from, to int) (time.Time, time.Time) {
+		return day.Add(time.Duration(from) * time.Hour), day.Add(time.Duration(to) * time.Hour)
+	}
+	request := func(id int, uid uuid.UUID, groupId, from, to int) models.AllocationRequest {
+		start, end := slot(from, to)
+		return models.AllocationRequest{Id: id, UserId: uid, GroupId: groupId, StartTime: start, EndTime: end}
+	}
+	busy := func(from, to int) models.Booking {
+		start, end := slot(from, to)
+		return models.Booking{StartTime: start, EndTime: end}
+	}
+	alice, bob, carol := uuid.New(), uuid.New(), uuid.New()
+	tests := []struct {
+		name            string
+		requests        []models.AllocationRequest
+		busy            []models.Booking
+		expectedGranted []int
+	}{
+		{
+			name:            "no requests",
+			expectedGranted: []int{},
+		},
+		{
+			name:            "disjoint requests are all granted",
+			requests:        []models.AllocationRequest{request(1, alice, 0, 9, 10), request(2, bob, 0, 11, 12), request(3, alice, 0, 13, 14)},
+			expectedGranted: []int{1, 2, 3},
+		},
+		{
+			name:            "busy slots are never granted",
+			requests:        []models.AllocationRequest{request(1, alice, 0, 9, 11), request(2, bob, 0, 12, 13)},
+			busy:            []models.Booking{busy(10, 12)},
+			expectedGranted: []int{},
+		},
+		{
+			name:            "touching a busy slot counts as overlap",
+			requests:        []models.AllocationRequest{request(1, alice, 0, 8, 10), request(2, alice, 0, 15, 16)},
+			busy:            []models.Booking{busy(10, 12)},
+			expectedGranted: []int{2},
+		},
+		{
+			name:            "a requester's own requests don't overlap",
+			requests:        []models.AllocationRequest{request(1, alice, 0, 9, 11), request(2, alice, 0, 10, 12), request(3, alice, 0, 12, 13)},
+			expectedGranted: []int{1, 3},
+		},
+		{
+			name:            "group members compete as one requester",
+			requests:        []models.AllocationRequest{request(1, alice, 7, 9, 11), request(2, bob, 7, 9, 11), request(3, carol, 0, 14, 15)},
+			expectedGranted: []int{1, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := uint64(0); seed < 20; seed++ {
+				requests := append([]models.AllocationRequest(nil), tt.requests...)
+				result := fairShare(requests, tt.busy, nil, nil, rand.New(rand.NewPCG(seed, 1)))
+				granted := []int{}
+				for _, req := range result {
+					if req.Status == models.RequestGranted {
+						granted = append(granted, req.Id)
+					} else {
+						assert.Equal(t, models.RequestRejected, req.Status)
+					}
+				}
+				assert.Equal(t, tt.expectedGranted, granted, "seed %d", seed)
+			}
+		})
+	}
+}
+
+func TestFairShareTakesTurns(t *testing.T) {
+	day := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	alice, bob := uuid.New(), uuid.New()
+	var requests []models.AllocationRequest
+	// both want the same four hours, one at a time
+	for hour := 0; hour < 4; hour++ {
+		start := day.Add(time.Duration(hour) * time.Hour)
+		end := start.Add(30 * time.Minute)
+		requests = append(requests,
+			models.AllocationRequest{Id: len(requests) + 1, UserId: alice, StartTime: start, EndTime: end},
+			models.AllocationRequest{Id: len(requests) + 2, UserId: bob, StartTime: start, EndTime: end})
+	}
+	result := fairShare(requests, nil, nil, nil, rand.New(rand.NewPCG(1, 1)))
+	granted := map[uuid.UUID]int{}
+	for _, req := range result {
+		if req.Status == models.RequestGranted {
+			granted[req.UserId]++
+		}
+	}
+	assert.Equal(t, 2, granted[alice])
+	assert.Equal(t, 2, granted[bob])
+}
+
+func TestFairShareWeighsPastUsage(t *testing.T) {
+	day := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	heavy, light := uuid.New(), uuid.New()
+	usage := map[string]float64{requesterKey(0, heavy): 90, requesterKey(0, light): 10}
+	shares := map[string]float64{requesterKey(0, heavy): 1, requesterKey(0, light): 1}
+	tests := []struct {
+		name      string
+		usage     map[string]float64
+		shares    map[string]float64
+		lightWins func(wins int) bool
+	}{
+		{
+			name:      "no usage is a fair coin",
+			lightWins: func(wins int) bool { return wins > 400 && wins < 600 },
+		},
+		{
+			name:      "less past usage wins more often",
+			usage:     usage,
+			shares:    shares,
+			lightWins: func(wins int) bool { return wins > 600 },
+		},
+		{
+			name:      "a larger share makes up for the usage",
+			usage:     usage,
+			shares:    map[string]float64{requesterKey(0, heavy): 9, requesterKey(0, light): 1},
+			lightWins: func(wins int) bool { return wins > 400 && wins < 600 },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wins := 0
+			for seed := uint64(0); seed < 1000; seed++ {
+				requests := []models.AllocationRequest{
+					{Id: 1, UserId: heavy, StartTime: day, EndTime: day.Add(time.Hour)},
+					{Id: 2, UserId: light, StartTime: day, EndTime: day.Add(time.Hour)},
+				}
+				result := fairShare(requests, nil, tt.usage, tt.shares, rand.New(rand.NewPCG(seed, 7)))
+				if result[1].Status == models.RequestGranted {
+					wins++
+				}
+			}
+			assert.True(t, tt.lightWins(wins), "light requester won %d of 1000", wins)
+		})
+	}
+}
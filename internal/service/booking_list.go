@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidFilter = errors.New("invalid filter")
+
+func validateFilter(filter models.BookingFilter) error {
+	switch filter.Status {
+	case "", models.BookingActive, models.BookingCancelled:
+	default:
+		return ErrInvalidFilter
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return ErrInvalidFilter
+	}
+	if filter.Limit <= 0 || filter.Limit > pagination.MaxLimit {
+		return ErrInvalidFilter
+	}
+	return nil
+}
+
+// page cuts the extra row fetched to detect whether there is a next page
+func page[T any](items []T, limit int, position func(T) models.BookingCursor) (*pagination.Page[T], error) {
+	p := pagination.Page[T]{Items: items}
+	if len(items) > limit {
+		p.Items = items[:limit]
+		cursor, err := pagination.EncodeCursor(position(items[limit-1]))
+		if err != nil {
+			return nil, err
+		}
+		p.NextCursor = cursor
+	}
+	return &p, nil
+}
+
+func (b *BookingService) searchBookings(ctx context.Context, filter models.BookingFilter) (*pagination.Page[models.Booking], error) {
+	err := validateFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	limit := filter.Limit
+	filter.Limit++
+	bookings, err := b.bookingRepo.SearchBookings(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	result, err := page(bookings, limit, func(booking models.Booking) models.BookingCursor {
+		return models.BookingCursor{StartTime: booking.StartTime, Id: booking.Id}
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.TotalEstimate, err = b.bookingRepo.EstimateBookings(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (b *BookingService) ScientistBookings(ctx context.Context, uid uuid.UUID, filter models.BookingFilter) (*pagination.Page[models.Booking], error) {
+	const op = "booking_service.ScientistBookings"
+	filter.UserId = &uid
+	bookings, err := b.searchBookings(ctx, filter)
+	if err != nil {
+		if !errors.Is(err, ErrInvalidFilter) {
+			b.log.Error("getting scientist bookings error", slog.String("op", op), slog.String("error", err.Error()))
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return bookings, nil
+}
+
+func (b *BookingService) AdminBookings(ctx context.Context, filter models.BookingFilter) (*pagination.Page[models.Booking], error) {
+	const op = "booking_service.AdminBookings"
+	bookings, err := b.searchBookings(ctx, filter)
+	if err != nil {
+		if !errors.Is(err, ErrInvalidFilter) {
+			b.log.Error("searching bookings error", slog.String("op", op), slog.String("error", err.Error()))
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return bookings, nil
+}
+
+// Bookings returns the schedule of filter.EquipmentId as seen by viewer
+func (b *BookingService) Bookings(ctx context.Context, filter models.BookingFilter, viewer uuid.UUID) (*pagination.Page[models.ScheduleEntry], error) {
+	const op = "booking_service.Bookings"
+	log := b.log.With(slog.String("op", op))
+	log.Info("getting bookings", slog.Int("equipment_id", filter.EquipmentId))
+	filter.UserId, filter.Status = nil, ""
+	err := validateFilter(filter)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	limit := filter.Limit
+	filter.Limit++
+	entries, err := b.bookingRepo.Bookings(ctx, filter, viewer)
+	if err != nil {
+		log.Error("getting bookings error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	result, err := page(entries, limit, func(entry models.ScheduleEntry) models.BookingCursor {
+		return entry.Cursor
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	filter.Status = models.BookingActive
+	result.TotalEstimate, err = b.bookingRepo.EstimateBookings(ctx, filter)
+	if err != nil {
+		log.Error("estimating bookings error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPreemptionNotAllowed = errors.New("slot is taken by bookings that can't be preempted")
+	ErrOfferNotFound        = errors.New("offer not found")
+	ErrOfferNotPending      = errors.New("offer is not pending")
+	ErrOfferExpired         = errors.New("offer expired")
+)
+
+// preempt takes the slot from the intersecting bookings under the equipment's preemption rule
+func (b *BookingService) preempt(ctx context.Context, booking models.Booking, event models.BookingEvent) (int, []models.Booking, error) {
+	rule, err := b.labRepo.PreemptionRule(ctx, booking.EquipmentId)
+	if err != nil {
+		if errors.Is(err, repository.ErrPreemptionRuleNotFound) {
+			return 0, nil, ErrPreemptionNotAllowed
+		}
+		return 0, nil, err
+	}
+	if booking.Priority < rule.MinPriority {
+		return 0, nil, ErrPreemptionNotAllowed
+	}
+	id, bumped, err := b.bookingRepo.Preempt(ctx, booking, *rule, event)
+	if err != nil {
+		if errors.Is(err, repository.ErrIntervalInterception) {
+			return 0, nil, ErrPreemptionNotAllowed
+		}
+		if errors.Is(err, repository.ErrNoOperator) {
+			return 0, nil, ErrNoOperatorAvailable
+		}
+		return 0, nil, err
+	}
+	return id, bumped, nil
+}
+
+// offerAlternatives offers the owners of bumped bookings the nearest free slot of the same
+// length and notifies them. Failures are logged, the preemption itself already happened
+func (b *BookingService) offerAlternatives(ctx context.Context, bumped []models.Booking) {
+	for _, booking := range bumped {
+		n := models.Notification{
+			UserId: booking.UserId,
+			Kind:   models.NotificationBookingPreempted,
+			Message: fmt.Sprintf("Your booking of equipment #%d from %s to %s was preempted by a higher priority booking.",
+				booking.EquipmentId, booking.StartTime.Format(timeLayout), booking.EndTime.Format(timeLayout)),
+			Payload: map[string]any{"booking_id": booking.Id},
+		}
+		duration := booking.EndTime.Sub(booking.StartTime)
+		start, err := b.bookingRepo.NearestFreeSlot(ctx, booking.EquipmentId, duration, booking.StartTime, time.Now())
+		if err != nil {
+			b.log.Error("searching free slot error", slog.Int("booking_id", booking.Id), slog.String("error", err.Error()))
+		}
+		if start != nil {
+			offer := models.SlotOffer{BookingId: booking.Id, UserId: booking.UserId, EquipmentId: booking.EquipmentId,
+				StartTime: *start, EndTime: start.Add(duration)}
+			offer.Id, err = b.bookingRepo.CreateOffer(ctx, offer)
+			if err != nil {
+				b.log.Error("creating slot offer error", slog.Int("booking_id", booking.Id), slog.String("error", err.Error()))
+			} else {
+				n.Message += fmt.Sprintf(" The nearest free slot from %s to %s is offered to you instead.",
+					offer.StartTime.Format(timeLayout), offer.EndTime.Format(timeLayout))
+				n.Payload["offer_id"] = offer.Id
+				n.Payload["start_time"] = offer.StartTime
+				n.Payload["end_time"] = offer.EndTime
+			}
+		}
+		b.notify(ctx, n)
+	}
+}
+
+func (b *BookingService) Offers(ctx context.Context, uid uuid.UUID) ([]models.SlotOffer, error) {
+	const op = "booking_service.Offers"
+	offers, err := b.bookingRepo.PendingOffers(ctx, uid)
+	if err != nil {
+		b.log.Error("getting slot offers error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return offers, nil
+}
+
+func (b *BookingService) pendingOffer(ctx context.Context, offerId int, uid uuid.UUID) (*models.SlotOffer, error) {
+	offer, err := b.bookingRepo.Offer(ctx, offerId)
+	if err != nil {
+		if errors.Is(err, repository.ErrOfferNotFound) {
+			return nil, ErrOfferNotFound
+		}
+		return nil, err
+	}
+	if offer.UserId != uid {
+		return nil, ErrOfferNotFound
+	}
+	if offer.Status != models.OfferPending {
+		return nil, ErrOfferNotPending
+	}
+	return offer, nil
+}
+
+// AcceptOffer books the offered slot with the project, group and form data of the preempted
+// booking. The booking policies are checked again for the new slot
+func (b *BookingService) AcceptOffer(ctx context.Context, offerId int, uid uuid.UUID) (int, error) {
+	const op = "booking_service.AcceptOffer"
+	log := b.log.With(slog.String("op", op))
+	log.Info("accepting slot offer", slog.Int("offer_id", offerId), slog.String("user_id", uid.String()))
+	offer, err := b.pendingOffer(ctx, offerId, uid)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if !offer.StartTime.After(time.Now()) {
+		return 0, fmt.Errorf("%s: %w", op, ErrOfferExpired)
+	}
+	original, err := b.Booking(ctx, offer.BookingId)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	booking := models.Booking{
+		EquipmentId: offer.EquipmentId,
+		UserId:      offer.UserId,
+		ProjectId:   original.ProjectId,
+		GroupId:     original.GroupId,
+		Priority:    original.Priority,
+		StartTime:   offer.StartTime,
+		EndTime:     offer.EndTime,
+		Notes:       original.Notes,
+		Metadata:    original.Metadata,
+	}
+	_, err = b.checkPolicies(ctx, &booking, false)
+	if err != nil {
+		if !isPolicyError(err) {
+			log.Error("checking booking policies error", slog.String("error", err.Error()))
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	id, err := b.bookingRepo.AcceptOffer(ctx, *offer, booking)
+	if err != nil {
+		if errors.Is(err, repository.ErrOfferNotPending) {
+			return 0, fmt.Errorf("%s: %w", op, ErrOfferNotPending)
+		}
+		if errors.Is(err, repository.ErrIntervalInterception) {
+			return 0, fmt.Errorf("%s: %w", op, ErrIntervalInterception)
+		}
+		if errors.Is(err, repository.ErrNoOperator) {
+			return 0, fmt.Errorf("%s: %w", op, ErrNoOperatorAvailable)
+		}
+		if errors.Is(err, repository.ErrEquipmentUnavailable) {
+			return 0, fmt.Errorf("%s: %w", op, ErrEquipmentUnavailable)
+		}
+		if errors.Is(err, repository.ErrQuotaExceeded) {
+			return 0, fmt.Errorf("%s: %w", op, ErrQuotaExceeded)
+		}
+		log.Error("accepting slot offer error", slog.String("error", err.Error()))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (b *BookingService) DeclineOffer(ctx context.Context, offerId int, uid uuid.UUID) error {
+	const op = "booking_service.DeclineOffer"
+	offer, err := b.pendingOffer(ctx, offerId, uid)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	err = b.bookingRepo.DeclineOffer(ctx, *offer)
+	if err != nil {
+		if errors.Is(err, repository.ErrOfferNotPending) {
+			return fmt.Errorf("%s: %w", op, ErrOfferNotPending)
+		}
+		b.log.Error("declining slot offer error", slog.String("op", op), slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
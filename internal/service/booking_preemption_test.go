@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (f *fakeBookingRepo) Offer(ctx context.Context, offerId int) (*models.SlotOffer, error) {
+	offer, ok := f.offers[offerId]
+	if !ok {
+		return nil, repository.ErrOfferNotFound
+	}
+	return &offer, nil
+}
+
+func (f *fakeBookingRepo) AcceptOffer(ctx context.Context, offer models.SlotOffer, booking models.Booking) (int, error) {
+	if f.storeErr != nil {
+		return 0, f.storeErr
+	}
+	f.stored = append(f.stored, booking)
+	return 100, nil
+}
+
+type fakeLabRepo struct {
+	repository.LabRepositroy
+}
+
+func (f *fakeLabRepo) FormFields(ctx context.Context, equipmentId int) ([]models.FormField, error) {
+	return nil, nil
+}
+
+func TestAcceptOffer(t *testing.T) {
+	bumped, busy, stranger := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+	original := models.Booking{Id: 1, EquipmentId: 5, UserId: bumped, ProjectId: 10, GroupId: 20, Priority: 1,
+		Status: models.BookingCancelled, StartTime: now.Add(time.Hour), EndTime: now.Add(2 * time.Hour)}
+	offer := func(id int, uid uuid.UUID, from, to time.Duration, status string) models.SlotOffer {
+		return models.SlotOffer{Id: id, BookingId: 1, UserId: uid, EquipmentId: 5, StartTime: now.Add(from), EndTime: now.Add(to), Status: status}
+	}
+	offers := map[int]models.SlotOffer{
+		1: offer(1, bumped, 3*time.Hour, 4*time.Hour, models.OfferPending),
+		2: offer(2, bumped, -time.Hour, 0, models.OfferPending),
+		3: offer(3, bumped, 3*time.Hour, 4*time.Hour, models.OfferDeclined),
+		4: offer(4, busy, 3*time.Hour, 4*time.Hour, models.OfferPending),
+	}
+	quota := 10.0
+	tests := []struct {
+		name        string
+		offerId     int
+		uid         uuid.UUID
+		booked      float64
+		storeErr    error
+		expectedErr error
+	}{
+		{
+			name:    "books the offered slot",
+			offerId: 1,
+			uid:     bumped,
+		},
+		{
+			name:        "group quota exceeded",
+			offerId:     1,
+			uid:         bumped,
+			booked:      9.5,
+			expectedErr: ErrQuotaExceeded,
+		},
+		{
+			name:        "group quota exceeded when storing",
+			offerId:     1,
+			uid:         bumped,
+			storeErr:    repository.ErrQuotaExceeded,
+			expectedErr: ErrQuotaExceeded,
+		},
+		{
+			name:        "no technician available",
+			offerId:     1,
+			uid:         bumped,
+			storeErr:    repository.ErrNoOperator,
+			expectedErr: ErrNoOperatorAvailable,
+		},
+		{
+			name:        "slot taken since",
+			offerId:     1,
+			uid:         bumped,
+			storeErr:    repository.ErrIntervalInterception,
+			expectedErr: ErrIntervalInterception,
+		},
+		{
+			name:        "accepted concurrently",
+			offerId:     1,
+			uid:         bumped,
+			storeErr:    repository.ErrOfferNotPending,
+			expectedErr: ErrOfferNotPending,
+		},
+		{
+			name:        "offered slot already started",
+			offerId:     2,
+			uid:         bumped,
+			expectedErr: ErrOfferExpired,
+		},
+		{
+			name:        "declined offer",
+			offerId:     3,
+			uid:         bumped,
+			expectedErr: ErrOfferNotPending,
+		},
+		{
+			name:        "offer to another user",
+			offerId:     4,
+			uid:         stranger,
+			expectedErr: ErrOfferNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeBookingRepo{bookings: map[int]models.Booking{1: original}, offers: offers, storeErr: tt.storeErr}
+			groups := &fakeGroupRepo{
+				groups:  map[int]models.Group{20: {Id: 20, QuotaHours: &quota}},
+				members: map[int][]uuid.UUID{20: {bumped, busy}},
+				booked:  map[int]float64{20: tt.booked},
+			}
+			srv := BookingService{bookingRepo: repo, projectRepo: &fakeProjectRepo{members: map[int][]uuid.UUID{10: {bumped, busy}}},
+				labRepo: &fakeLabRepo{}, groupRepo: groups, certRepo: &fakeCertRepo{}, log: slog.New(slog.DiscardHandler)}
+			id, err := srv.AcceptOffer(context.Background(), tt.offerId, tt.uid)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, repo.stored)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 100, id)
+			require.Len(t, repo.stored, 1)
+			booking := repo.stored[0]
+			assert.Equal(t, offers[tt.offerId].StartTime, booking.StartTime)
+			assert.Equal(t, offers[tt.offerId].EndTime, booking.EndTime)
+			assert.Equal(t, bumped, booking.UserId)
+			assert.Equal(t, 10, booking.ProjectId)
+			assert.Equal(t, 20, booking.GroupId)
+			assert.Equal(t, 1, booking.Priority)
+		})
+	}
+}
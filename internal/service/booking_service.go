@@ -5,76 +5,333 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"mime/multipart"
+	"time"
 
 	"github.com/Gergenus/bookingService/internal/models"
 	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	// bookings can be checked in this long before their start time
+	checkInAdvance    = 15 * time.Minute
+	maxAttachmentSize = 20 << 20
 )
 
 var (
 	ErrIntervalInterception = errors.New("interval interception")
+	ErrProjectRequired      = errors.New("project required")
+	ErrNotProjectMember     = errors.New("user is not a project member")
+	ErrBookingNotFound      = errors.New("booking not found")
+	ErrInvalidOwner         = errors.New("invalid owner")
+	ErrCheckInWindow        = errors.New("booking is not open for check-in")
+	ErrAlreadyCheckedIn     = errors.New("already checked in")
+	ErrNotCheckedIn         = errors.New("not checked in")
+	ErrAttachmentNotFound   = errors.New("attachment not found")
+	ErrAttachmentTooLarge   = errors.New("attachment too large")
+	ErrTransferNotFound     = errors.New("transfer not found")
+	ErrTransferPending      = errors.New("booking already has a pending transfer")
+	ErrTransferNotPending   = errors.New("transfer is not pending")
+	ErrInvalidRecipient     = errors.New("invalid recipient")
+	ErrBookingFinished      = errors.New("booking already finished")
+	ErrGroupRequired        = errors.New("group required")
+	ErrNotGroupMember       = errors.New("user is not a group member")
+	ErrQuotaExceeded        = errors.New("group booking quota exceeded")
+	ErrCannotBookOnBehalf   = errors.New("cannot book on behalf of this user")
 )
 
 type BookingService struct {
 	bookingRepo repository.BookingRepositoryInterface
+	projectRepo repository.ProjectRepositoryInterface
+	labRepo     repository.LabRepositroy
+	groupRepo   repository.GroupRepositoryInterface
+	certRepo    repository.CertificationRepositoryInterface
+	notifyRepo  repository.NotificationRepositoryInterface
+	mini        repository.ImageRepositoryInterface
 	log         *slog.Logger
 }
 
 type BookingServiceInterface interface {
-	CreateBooking(ctx context.Context, booking models.Booking) (int, error)
-	Bookings(ctx context.Context, equipmentId int) ([]models.Booking, error)
-	DeleteBooking(ctx context.Context, bookingId int) error
+	CreateBooking(ctx context.Context, booking models.Booking, preempt bool, sysAdmin bool) (int, error)
+	Bookings(ctx context.Context, filter models.BookingFilter, viewer uuid.UUID) (*pagination.Page[models.ScheduleEntry], error)
+	DeleteBooking(ctx context.Context, bookingId int, actor uuid.UUID) error
 	Booking(ctx context.Context, bookingId int) (*models.Booking, error)
-	ScientistBookings(ctx context.Context, uid string) ([]models.Booking, error)
-}
-
-func NewBookingService(bookingRepo repository.BookingRepositoryInterface, log *slog.Logger) BookingService {
-	return BookingService{bookingRepo: bookingRepo, log: log}
+	ScientistBookings(ctx context.Context, uid uuid.UUID, filter models.BookingFilter) (*pagination.Page[models.Booking], error)
+	CheckIn(ctx context.Context, bookingId int, uid uuid.UUID) error
+	CheckOut(ctx context.Context, bookingId int, uid uuid.UUID) error
+	AddAttachment(ctx context.Context, bookingId int, uid uuid.UUID, file *multipart.FileHeader) (int, error)
+	Attachments(ctx context.Context, bookingId int, uid uuid.UUID) ([]models.Attachment, error)
+	Attachment(ctx context.Context, bookingId, attachmentId int, uid uuid.UUID) (*models.Attachment, *minio.Object, error)
+	DeleteAttachment(ctx context.Context, bookingId, attachmentId int, uid uuid.UUID) error
+	ProposeTransfer(ctx context.Context, bookingId int, owner, recipient uuid.UUID) (int, error)
+	IncomingTransfers(ctx context.Context, uid uuid.UUID) ([]models.BookingTransfer, error)
+	AcceptTransfer(ctx context.Context, transferId int, uid uuid.UUID, projectId, groupId int) error
+	DeclineTransfer(ctx context.Context, transferId int, uid uuid.UUID) error
+	CancelTransfer(ctx context.Context, transferId int, uid uuid.UUID) error
+	History(ctx context.Context, bookingId int, uid uuid.UUID) ([]models.BookingEvent, error)
+	AdminBookings(ctx context.Context, filter models.BookingFilter) (*pagination.Page[models.Booking], error)
+	AdminHistory(ctx context.Context, bookingId int) ([]models.BookingEvent, error)
+	AdminCreateBooking(ctx context.Context, booking models.Booking, admin uuid.UUID, override bool, reason string) (int, error)
+	AdminCancelBooking(ctx context.Context, bookingId int, admin uuid.UUID, reason string) error
+	MoveBooking(ctx context.Context, move models.BookingMove, admin uuid.UUID) error
+	Offers(ctx context.Context, uid uuid.UUID) ([]models.SlotOffer, error)
+	AcceptOffer(ctx context.Context, offerId int, uid uuid.UUID) (int, error)
+	DeclineOffer(ctx context.Context, offerId int, uid uuid.UUID) error
+	OpenRound(ctx context.Context, round models.AllocationRound) (int, error)
+	Rounds(ctx context.Context, equipmentId int) ([]models.AllocationRound, error)
+	SubmitRequest(ctx context.Context, req models.AllocationRequest) (int, error)
+	MyRequests(ctx context.Context, uid uuid.UUID) ([]models.AllocationRequest, error)
+	WithdrawRequest(ctx context.Context, requestId int, uid uuid.UUID) error
+	RoundResults(ctx context.Context, roundId int, uid uuid.UUID) (*models.RoundResults, error)
+	Waitlist(ctx context.Context, uid uuid.UUID) ([]models.WaitlistEntry, error)
+	CancelWaitlistEntry(ctx context.Context, entryId int, uid uuid.UUID) error
 }
 
-func (b *BookingService) ScientistBookings(ctx context.Context, uid string) ([]models.Booking, error) {
-	booking, err := b.bookingRepo.ScientistBookings(ctx, uid)
-	if err != nil {
-		return nil, err
-	}
-	return booking, nil
+func NewBookingService(bookingRepo repository.BookingRepositoryInterface, projectRepo repository.ProjectRepositoryInterface,
+	labRepo repository.LabRepositroy, groupRepo repository.GroupRepositoryInterface, certRepo repository.CertificationRepositoryInterface,
+	notifyRepo repository.NotificationRepositoryInterface, mini repository.ImageRepositoryInterface, log *slog.Logger) BookingService {
+	return BookingService{bookingRepo: bookingRepo, projectRepo: projectRepo, labRepo: labRepo, groupRepo: groupRepo,
+		certRepo: certRepo, notifyRepo: notifyRepo, mini: mini, log: log}
 }
 
-func (b *BookingService) CreateBooking(ctx context.Context, booking models.Booking) (int, error) {
+// CreateBooking books a free slot. With preempt a taken slot is claimed from lower priority
+// bookings if the equipment's preemption rule allows it. Only the PI of one of the user's
+// groups or a sysAdmin may book on behalf of the user
+func (b *BookingService) CreateBooking(ctx context.Context, booking models.Booking, preempt bool, sysAdmin bool) (int, error) {
 	const op = "booking_service.CreateBooking"
 	log := b.log.With(slog.String("op", op))
 	log.Info("creating booking", slog.Int("equipment_id", booking.EquipmentId), slog.String("user_id", booking.UserId.String()))
-	id, err := b.bookingRepo.CreateBooking(ctx, booking)
+	if booking.BookedBy != nil && *booking.BookedBy != booking.UserId && !sysAdmin {
+		ok, err := b.groupRepo.IsPIOf(ctx, *booking.BookedBy, booking.UserId)
+		if err != nil {
+			log.Error("checking group PI error", slog.String("error", err.Error()))
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+		if !ok {
+			return 0, fmt.Errorf("%s: %w", op, ErrCannotBookOnBehalf)
+		}
+	}
+	_, err := b.validateBooking(ctx, log, &booking, false)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	booking.Priority, err = b.projectRepo.BookingPriority(ctx, booking.ProjectId, booking.UserId)
+	if err != nil {
+		log.Error("getting booking priority error", slog.String("error", err.Error()))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	actor := booking.UserId
+	if booking.BookedBy != nil {
+		actor = *booking.BookedBy
+	}
+	event := models.BookingEvent{Action: models.HistoryCreated, ActorId: &actor,
+		Details: map[string]any{"user_id": booking.UserId, "priority": booking.Priority}}
+	var bumped []models.Booking
+	id, err := b.bookingRepo.CreateBooking(ctx, booking, true, event)
+	if errors.Is(err, repository.ErrIntervalInterception) && preempt {
+		id, bumped, err = b.preempt(ctx, booking, event)
+	}
 	if err != nil {
 		if errors.Is(err, repository.ErrIntervalInterception) {
 			return 0, fmt.Errorf("%s: %w", op, ErrIntervalInterception)
 		}
+		if errors.Is(err, ErrPreemptionNotAllowed) || errors.Is(err, ErrNoOperatorAvailable) {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+		if errors.Is(err, repository.ErrQuotaExceeded) {
+			return 0, fmt.Errorf("%s: %w", op, ErrQuotaExceeded)
+		}
+		if errors.Is(err, repository.ErrNoOperator) {
+			return 0, fmt.Errorf("%s: %w", op, ErrNoOperatorAvailable)
+		}
+		if errors.Is(err, repository.ErrEquipmentUnavailable) {
+			return 0, fmt.Errorf("%s: %w", op, ErrEquipmentUnavailable)
+		}
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return 0, fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
 		log.Error("creating booking error", slog.String("error", err.Error()))
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
+	if len(bumped) > 0 {
+		ids := make([]int, 0, len(bumped))
+		for _, bumpedBooking := range bumped {
+			ids = append(ids, bumpedBooking.Id)
+		}
+		log.Info("bookings preempted", slog.Int("booking_id", id), slog.Any("preempted", ids))
+	}
+	b.offerAlternatives(ctx, bumped)
 	return id, nil
 }
 
-func (b *BookingService) Bookings(ctx context.Context, equipmentId int) ([]models.Booking, error) {
-	const op = "booking_service.Bookings"
-	log := b.log.With(slog.String("op", op))
-	log.Info("getting bookings", slog.Int("equipment_id", equipmentId))
-	bookings, err := b.bookingRepo.Bookings(ctx, equipmentId)
+// validateBooking runs the checks every new or moved booking goes through: it needs a project,
+// can't be in a lottery period that wasn't allocated yet and has to pass the booking policies,
+// see checkPolicies. Errors other than rule violations are logged
+func (b *BookingService) validateBooking(ctx context.Context, log *slog.Logger, booking *models.Booking, override bool) ([]string, error) {
+	if booking.ProjectId == 0 {
+		return nil, ErrProjectRequired
+	}
+	// slots in rounds that weren't allocated yet can only be requested
+	pending, err := b.bookingRepo.HasOpenRound(ctx, booking.EquipmentId, booking.StartTime, booking.EndTime)
 	if err != nil {
-		log.Error("getting bookings error", slog.String("error", err.Error()))
-		return nil, fmt.Errorf("%s: %w", op, err)
+		log.Error("checking allocation rounds error", slog.String("error", err.Error()))
+		return nil, err
+	}
+	if pending {
+		return nil, ErrAllocationPending
+	}
+	violations, err := b.checkPolicies(ctx, booking, override)
+	if err != nil {
+		if !isPolicyError(err) {
+			log.Error("checking booking policies error", slog.String("error", err.Error()))
+		}
+		return nil, err
+	}
+	return violations, nil
+}
+
+// policyErrors are the booking rules an admin may override
+var policyErrors = []error{ErrNotProjectMember, ErrCertificationRequired, ErrInvalidMetadata, ErrGroupRequired,
+	ErrNotGroupMember, ErrQuotaExceeded}
+
+func isPolicyError(err error) bool {
+	for _, policyErr := range policyErrors {
+		if errors.Is(err, policyErr) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPolicies runs the booking rules: project membership, certifications, the booking
+// form and the group quota. It stops at the first violation unless override is set, then
+// it collects all violations so they can be written to the audit trail
+func (b *BookingService) checkPolicies(ctx context.Context, booking *models.Booking, override bool) ([]string, error) {
+	checks := []func() error{
+		func() error {
+			member, err := b.projectRepo.IsMember(ctx, booking.ProjectId, booking.UserId)
+			if err != nil {
+				return err
+			}
+			if !member {
+				return ErrNotProjectMember
+			}
+			return nil
+		},
+		// certifications have to stay valid until the end of the booking
+		func() error {
+			missing, err := b.certRepo.MissingCertifications(ctx, booking.UserId, booking.EquipmentId, booking.EndTime)
+			if err != nil {
+				return err
+			}
+			if len(missing) > 0 {
+				return &MissingCertificationError{Certifications: missing}
+			}
+			return nil
+		},
+		func() error {
+			fields, err := b.labRepo.FormFields(ctx, booking.EquipmentId)
+			if err != nil {
+				return err
+			}
+			if booking.Metadata == nil {
+				booking.Metadata = map[string]any{}
+			}
+			return validateMetadata(fields, booking.Metadata)
+		},
+		func() error {
+			return b.checkGroupQuota(ctx, booking)
+		},
+	}
+	var violations []string
+	for _, check := range checks {
+		err := check()
+		if err == nil {
+			continue
+		}
+		if !override || !isPolicyError(err) {
+			return nil, err
+		}
+		violations = append(violations, err.Error())
+	}
+	return violations, nil
+}
+
+// checkGroupQuota resolves the group the booking is charged to and checks its monthly quota.
+// Users in exactly one group don't have to name it, users without a group have no quota. The
+// quota is checked again when the booking is stored, this check only finds the violation early
+func (b *BookingService) checkGroupQuota(ctx context.Context, booking *models.Booking) error {
+	if booking.GroupId == 0 {
+		groups, err := b.groupRepo.UserGroups(ctx, booking.UserId)
+		if err != nil {
+			return err
+		}
+		switch len(groups) {
+		case 0:
+			return nil
+		case 1:
+			booking.GroupId = groups[0].Id
+		default:
+			return ErrGroupRequired
+		}
+	} else {
+		member, _, err := b.groupRepo.Membership(ctx, booking.GroupId, booking.UserId)
+		if err != nil {
+			return err
+		}
+		if !member {
+			return ErrNotGroupMember
+		}
+	}
+	group, err := b.groupRepo.Group(ctx, booking.GroupId)
+	if err != nil {
+		return err
+	}
+	if group.QuotaHours == nil {
+		return nil
+	}
+	start := booking.StartTime
+	monthStart := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location())
+	monthEnd := monthStart.AddDate(0, 1, 0)
+	used, err := b.groupRepo.BookedHours(ctx, group.Id, monthStart, monthEnd)
+	if err != nil {
+		return err
+	}
+	// a booking that is moved is counted with its current slot already
+	if booking.Id != 0 {
+		current, err := b.bookingRepo.Booking(ctx, booking.Id)
+		if err != nil {
+			return err
+		}
+		if current.Status == models.BookingActive && current.GroupId == group.Id &&
+			!current.StartTime.Before(monthStart) && current.StartTime.Before(monthEnd) {
+			used -= current.EndTime.Sub(current.StartTime).Hours()
+		}
 	}
-	return bookings, nil
+	if used+booking.EndTime.Sub(booking.StartTime).Hours() > *group.QuotaHours {
+		return ErrQuotaExceeded
+	}
+	return nil
 }
 
-func (b *BookingService) DeleteBooking(ctx context.Context, bookingId int) error {
+func (b *BookingService) DeleteBooking(ctx context.Context, bookingId int, actor uuid.UUID) error {
 	const op = "booking_service.DeleteBooking"
 	log := b.log.With(slog.String("op", op))
 	log.Info("deleting bookings", slog.Int("booking_id", bookingId))
-	err := b.bookingRepo.DeleteBooking(ctx, bookingId)
+	err := b.bookingRepo.DeleteBooking(ctx, bookingId, models.BookingEvent{Action: models.HistoryCancelled, ActorId: &actor})
 	if err != nil {
 		log.Error("deleting bookings error", slog.Int("booking_id", bookingId))
 		return fmt.Errorf("%s: %w", op, err)
 	}
+	booking, err := b.bookingRepo.Booking(ctx, bookingId)
+	if err != nil {
+		log.Error("getting cancelled booking error", slog.Int("booking_id", bookingId), slog.String("error", err.Error()))
+		return nil
+	}
+	b.notifyWaitlist(ctx, booking)
 	return nil
 }
 
@@ -84,8 +341,367 @@ func (b *BookingService) Booking(ctx context.Context, bookingId int) (*models.Bo
 	log.Info("getting booking", slog.Int("booking_id", bookingId))
 	booking, err := b.bookingRepo.Booking(ctx, bookingId)
 	if err != nil {
+		if errors.Is(err, repository.ErrBookingNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, ErrBookingNotFound)
+		}
 		log.Error("getting bookings error", slog.Int("booking_id", bookingId))
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	return booking, nil
 }
+
+func (b *BookingService) CheckIn(ctx context.Context, bookingId int, uid uuid.UUID) error {
+	const op = "booking_service.CheckIn"
+	log := b.log.With(slog.String("op", op))
+	log.Info("checking in", slog.Int("booking_id", bookingId), slog.String("user_id", uid.String()))
+	booking, err := b.Booking(ctx, bookingId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if booking.UserId != uid {
+		return fmt.Errorf("%s: %w", op, ErrInvalidOwner)
+	}
+	if booking.Status == models.BookingCancelled {
+		return fmt.Errorf("%s: %w", op, ErrBookingNotFound)
+	}
+	if booking.CheckedInAt != nil {
+		return fmt.Errorf("%s: %w", op, ErrAlreadyCheckedIn)
+	}
+	now := time.Now()
+	if now.Before(booking.StartTime.Add(-checkInAdvance)) || !now.Before(booking.EndTime) {
+		return fmt.Errorf("%s: %w", op, ErrCheckInWindow)
+	}
+	err = b.bookingRepo.CheckIn(ctx, bookingId, now)
+	if err != nil {
+		if errors.Is(err, repository.ErrBookingNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrAlreadyCheckedIn)
+		}
+		log.Error("checking in error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (b *BookingService) CheckOut(ctx context.Context, bookingId int, uid uuid.UUID) error {
+	const op = "booking_service.CheckOut"
+	log := b.log.With(slog.String("op", op))
+	log.Info("checking out", slog.Int("booking_id", bookingId), slog.String("user_id", uid.String()))
+	booking, err := b.Booking(ctx, bookingId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if booking.UserId != uid {
+		return fmt.Errorf("%s: %w", op, ErrInvalidOwner)
+	}
+	if booking.CheckedInAt == nil || booking.CheckedOutAt != nil {
+		return fmt.Errorf("%s: %w", op, ErrNotCheckedIn)
+	}
+	err = b.bookingRepo.CheckOut(ctx, bookingId, time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrBookingNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrNotCheckedIn)
+		}
+		log.Error("checking out error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// ownBooking returns the booking if it belongs to uid
+func (b *BookingService) ownBooking(ctx context.Context, bookingId int, uid uuid.UUID) (*models.Booking, error) {
+	booking, err := b.Booking(ctx, bookingId)
+	if err != nil {
+		return nil, err
+	}
+	if booking.UserId != uid {
+		return nil, ErrInvalidOwner
+	}
+	return booking, nil
+}
+
+func (b *BookingService) AddAttachment(ctx context.Context, bookingId int, uid uuid.UUID, file *multipart.FileHeader) (int, error) {
+	const op = "booking_service.AddAttachment"
+	log := b.log.With(slog.String("op", op))
+	log.Info("adding attachment", slog.Int("booking_id", bookingId), slog.String("file", file.Filename))
+	_, err := b.ownBooking(ctx, bookingId, uid)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if file.Size > maxAttachmentSize {
+		return 0, fmt.Errorf("%s: %w", op, ErrAttachmentTooLarge)
+	}
+	objectName, contentType, err := b.mini.AddAttachment(ctx, file)
+	if err != nil {
+		log.Error("adding attachment to s3 storage error", slog.String("error", err.Error()))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	id, err := b.bookingRepo.AddAttachment(ctx, models.Attachment{
+		BookingId:   bookingId,
+		ObjectName:  objectName,
+		FileName:    file.Filename,
+		ContentType: contentType,
+		Size:        file.Size,
+	})
+	if err != nil {
+		log.Error("saving attachment error", slog.String("error", err.Error()))
+		if err := b.mini.DeleteImage(ctx, objectName); err != nil {
+			log.Error("deleting orphaned attachment error", slog.String("error", err.Error()))
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (b *BookingService) Attachments(ctx context.Context, bookingId int, uid uuid.UUID) ([]models.Attachment, error) {
+	const op = "booking_service.Attachments"
+	_, err := b.ownBooking(ctx, bookingId, uid)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	attachments, err := b.bookingRepo.Attachments(ctx, bookingId)
+	if err != nil {
+		b.log.Error("getting attachments error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return attachments, nil
+}
+
+func (b *BookingService) attachment(ctx context.Context, bookingId, attachmentId int, uid uuid.UUID) (*models.Attachment, error) {
+	_, err := b.ownBooking(ctx, bookingId, uid)
+	if err != nil {
+		return nil, err
+	}
+	attachment, err := b.bookingRepo.Attachment(ctx, attachmentId)
+	if err != nil {
+		if errors.Is(err, repository.ErrAttachmentNotFound) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	if attachment.BookingId != bookingId {
+		return nil, ErrAttachmentNotFound
+	}
+	return attachment, nil
+}
+
+func (b *BookingService) Attachment(ctx context.Context, bookingId, attachmentId int, uid uuid.UUID) (*models.Attachment, *minio.Object, error) {
+	const op = "booking_service.Attachment"
+	attachment, err := b.attachment(ctx, bookingId, attachmentId, uid)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	obj, err := b.mini.SignURL(ctx, attachment.ObjectName)
+	if err != nil {
+		b.log.Error("getting attachment object error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return attachment, obj, nil
+}
+
+func (b *BookingService) DeleteAttachment(ctx context.Context, bookingId, attachmentId int, uid uuid.UUID) error {
+	const op = "booking_service.DeleteAttachment"
+	log := b.log.With(slog.String("op", op))
+	log.Info("deleting attachment", slog.Int("booking_id", bookingId), slog.Int("attachment_id", attachmentId))
+	attachment, err := b.attachment(ctx, bookingId, attachmentId, uid)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	err = b.bookingRepo.DeleteAttachment(ctx, attachmentId)
+	if err != nil {
+		log.Error("deleting attachment error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	err = b.mini.DeleteImage(ctx, attachment.ObjectName)
+	if err != nil {
+		log.Error("deleting attachment in miniO error", slog.String("error", err.Error()))
+	}
+	return nil
+}
+
+func (b *BookingService) ProposeTransfer(ctx context.Context, bookingId int, owner, recipient uuid.UUID) (int, error) {
+	const op = "booking_service.ProposeTransfer"
+	log := b.log.With(slog.String("op", op))
+	log.Info("proposing booking transfer", slog.Int("booking_id", bookingId), slog.String("recipient", recipient.String()))
+	booking, err := b.ownBooking(ctx, bookingId, owner)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if booking.Status == models.BookingCancelled {
+		return 0, fmt.Errorf("%s: %w", op, ErrBookingNotFound)
+	}
+	if !booking.EndTime.After(time.Now()) {
+		return 0, fmt.Errorf("%s: %w", op, ErrBookingFinished)
+	}
+	if recipient == owner || recipient == uuid.Nil {
+		return 0, fmt.Errorf("%s: %w", op, ErrInvalidRecipient)
+	}
+	id, err := b.bookingRepo.CreateTransfer(ctx, models.BookingTransfer{BookingId: bookingId, FromUser: owner, ToUser: recipient})
+	if err != nil {
+		if errors.Is(err, repository.ErrTransferPending) {
+			return 0, fmt.Errorf("%s: %w", op, ErrTransferPending)
+		}
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return 0, fmt.Errorf("%s: %w", op, ErrInvalidRecipient)
+		}
+		log.Error("creating transfer error", slog.String("error", err.Error()))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (b *BookingService) IncomingTransfers(ctx context.Context, uid uuid.UUID) ([]models.BookingTransfer, error) {
+	const op = "booking_service.IncomingTransfers"
+	transfers, err := b.bookingRepo.IncomingTransfers(ctx, uid)
+	if err != nil {
+		b.log.Error("getting incoming transfers error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return transfers, nil
+}
+
+func (b *BookingService) pendingTransfer(ctx context.Context, transferId int) (*models.BookingTransfer, error) {
+	transfer, err := b.bookingRepo.Transfer(ctx, transferId)
+	if err != nil {
+		if errors.Is(err, repository.ErrTransferNotFound) {
+			return nil, ErrTransferNotFound
+		}
+		return nil, err
+	}
+	if transfer.Status != models.TransferPending {
+		return nil, ErrTransferNotPending
+	}
+	return transfer, nil
+}
+
+// AcceptTransfer hands the booking over to the recipient. The booking keeps its project
+// unless projectId is set, either way the recipient has to be a member of it and hold the
+// certifications the equipment requires. The booking is charged to the recipient's group,
+// resolved and checked against its quota like a new booking
+func (b *BookingService) AcceptTransfer(ctx context.Context, transferId int, uid uuid.UUID, projectId, groupId int) error {
+	const op = "booking_service.AcceptTransfer"
+	log := b.log.With(slog.String("op", op))
+	log.Info("accepting booking transfer", slog.Int("transfer_id", transferId), slog.String("user_id", uid.String()))
+	transfer, err := b.pendingTransfer(ctx, transferId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if transfer.ToUser != uid {
+		return fmt.Errorf("%s: %w", op, ErrTransferNotFound)
+	}
+	booking, err := b.Booking(ctx, transfer.BookingId)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	// the transfer lapses with the booking, there is nothing left to hand over
+	if !booking.EndTime.After(time.Now()) {
+		return fmt.Errorf("%s: %w", op, ErrTransferNotPending)
+	}
+	if projectId == 0 {
+		projectId = booking.ProjectId
+	}
+	member, err := b.projectRepo.IsMember(ctx, projectId, uid)
+	if err != nil {
+		log.Error("checking project membership error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !member {
+		return fmt.Errorf("%s: %w", op, ErrNotProjectMember)
+	}
+	missing, err := b.certRepo.MissingCertifications(ctx, uid, booking.EquipmentId, booking.EndTime)
+	if err != nil {
+		log.Error("checking certifications error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s: %w", op, &MissingCertificationError{Certifications: missing})
+	}
+	moved := *booking
+	moved.UserId, moved.ProjectId, moved.GroupId = uid, projectId, groupId
+	err = b.checkGroupQuota(ctx, &moved)
+	if err != nil {
+		if !isPolicyError(err) {
+			log.Error("checking group quota error", slog.String("error", err.Error()))
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	err = b.bookingRepo.AcceptTransfer(ctx, *transfer, projectId, moved.GroupId)
+	if err != nil {
+		if errors.Is(err, repository.ErrTransferNotPending) {
+			return fmt.Errorf("%s: %w", op, ErrTransferNotPending)
+		}
+		if errors.Is(err, repository.ErrQuotaExceeded) {
+			return fmt.Errorf("%s: %w", op, ErrQuotaExceeded)
+		}
+		if errors.Is(err, repository.ErrBookingNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrBookingNotFound)
+		}
+		log.Error("accepting transfer error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (b *BookingService) DeclineTransfer(ctx context.Context, transferId int, uid uuid.UUID) error {
+	const op = "booking_service.DeclineTransfer"
+	err := b.resolveTransfer(ctx, transferId, uid, models.TransferDeclined)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (b *BookingService) CancelTransfer(ctx context.Context, transferId int, uid uuid.UUID) error {
+	const op = "booking_service.CancelTransfer"
+	err := b.resolveTransfer(ctx, transferId, uid, models.TransferCancelled)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// the recipient declines a transfer, the owner cancels it
+func (b *BookingService) resolveTransfer(ctx context.Context, transferId int, uid uuid.UUID, status string) error {
+	b.log.Info("resolving booking transfer", slog.Int("transfer_id", transferId), slog.String("status", status))
+	transfer, err := b.pendingTransfer(ctx, transferId)
+	if err != nil {
+		return err
+	}
+	if (status == models.TransferDeclined && transfer.ToUser != uid) || (status == models.TransferCancelled && transfer.FromUser != uid) {
+		return ErrTransferNotFound
+	}
+	err = b.bookingRepo.ResolveTransfer(ctx, *transfer, status, uid)
+	if err != nil {
+		if errors.Is(err, repository.ErrTransferNotPending) {
+			return ErrTransferNotPending
+		}
+		b.log.Error("resolving transfer error", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// History is shown to the owner and to both parties of the booking's transfers, so a
+// previous owner can still see what happened to the booking
+func (b *BookingService) History(ctx context.Context, bookingId int, uid uuid.UUID) ([]models.BookingEvent, error) {
+	const op = "booking_service.History"
+	booking, err := b.Booking(ctx, bookingId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if booking.UserId != uid {
+		party, err := b.bookingRepo.TransferParty(ctx, bookingId, uid)
+		if err != nil {
+			b.log.Error("checking transfer parties error", slog.String("op", op), slog.String("error", err.Error()))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if !party {
+			return nil, fmt.Errorf("%s: %w", op, ErrInvalidOwner)
+		}
+	}
+	events, err := b.bookingRepo.History(ctx, bookingId)
+	if err != nil {
+		b.log.Error("getting booking history error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return events, nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (f *fakeBookingRepo) Preempt(ctx context.Context, booking models.Booking, rule models.PreemptionRule, event models.BookingEvent) (int, []models.Booking, error) {
+	if f.preemptErr != nil {
+		return 0, nil, f.preemptErr
+	}
+	event.BookingId = 101
+	f.stored = append(f.stored, booking)
+	f.events = append(f.events, event)
+	return 101, nil, nil
+}
+
+func (f *fakeLabRepo) PreemptionRule(ctx context.Context, equipmentId int) (*models.PreemptionRule, error) {
+	return &models.PreemptionRule{EquipmentId: equipmentId}, nil
+}
+
+func TestCreateBooking(t *testing.T) {
+	scientist := uuid.New()
+	start := time.Now().Add(time.Hour)
+	tests := []struct {
+		name        string
+		preempt     bool
+		storeErr    error
+		preemptErr  error
+		expectedErr error
+		expectedId  int
+	}{
+		{
+			name:       "books the slot with its operator",
+			expectedId: 100,
+		},
+		{
+			name:        "no technician free in the slot",
+			storeErr:    repository.ErrNoOperator,
+			expectedErr: ErrNoOperatorAvailable,
+		},
+		{
+			name:        "group quota exceeded when storing",
+			storeErr:    repository.ErrQuotaExceeded,
+			expectedErr: ErrQuotaExceeded,
+		},
+		{
+			name:        "equipment out of service",
+			storeErr:    repository.ErrEquipmentUnavailable,
+			expectedErr: ErrEquipmentUnavailable,
+		},
+		{
+			name:        "slot taken",
+			storeErr:    repository.ErrIntervalInterception,
+			expectedErr: ErrIntervalInterception,
+		},
+		{
+			name:       "slot taken and preempted",
+			preempt:    true,
+			storeErr:   repository.ErrIntervalInterception,
+			expectedId: 101,
+		},
+		{
+			name:        "no technician free for the preempted slot",
+			preempt:     true,
+			storeErr:    repository.ErrIntervalInterception,
+			preemptErr:  repository.ErrNoOperator,
+			expectedErr: ErrNoOperatorAvailable,
+		},
+		{
+			name:        "bookings in the slot can't be preempted",
+			preempt:     true,
+			storeErr:    repository.ErrIntervalInterception,
+			preemptErr:  repository.ErrIntervalInterception,
+			expectedErr: ErrPreemptionNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeBookingRepo{storeErr: tt.storeErr, preemptErr: tt.preemptErr}
+			srv := BookingService{bookingRepo: repo, projectRepo: &fakeProjectRepo{members: map[int][]uuid.UUID{10: {scientist}}},
+				labRepo: &fakeLabRepo{}, groupRepo: &fakeGroupRepo{}, certRepo: &fakeCertRepo{}, log: slog.New(slog.DiscardHandler)}
+			booking := models.Booking{EquipmentId: 5, UserId: scientist, ProjectId: 10, StartTime: start, EndTime: start.Add(time.Hour)}
+			id, err := srv.CreateBooking(context.Background(), booking, tt.preempt, false)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, repo.events)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedId, id)
+			require.Len(t, repo.events, 1)
+			assert.Equal(t, models.BookingEvent{BookingId: tt.expectedId, Action: models.HistoryCreated, ActorId: &scientist,
+				Details: map[string]any{"user_id": scientist, "priority": 1}}, repo.events[0])
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBookingRepo struct {
+	repository.BookingRepositoryInterface
+	bookings  map[int]models.Booking
+	transfers map[int]models.BookingTransfer
+	accepted  map[int]acceptance
+	offers    map[int]models.SlotOffer
+	// stored are the bookings passed to the repository with the history events of the writes,
+	// storeErr fails storing them
+	stored   []models.Booking
+	events   []models.BookingEvent
+	storeErr error
+	// preemptErr fails preempting the bookings in a taken slot
+	preemptErr error
+}
+
+// acceptance is where an accepted transfer moved the booking to
+type acceptance struct {
+	projectId, groupId int
+}
+
+func (f *fakeBookingRepo) Booking(ctx context.Context, bookingId int) (*models.Booking, error) {
+	booking, ok := f.bookings[bookingId]
+	if !ok {
+		return nil, repository.ErrBookingNotFound
+	}
+	return &booking, nil
+}
+
+func (f *fakeBookingRepo) Transfer(ctx context.Context, transferId int) (*models.BookingTransfer, error) {
+	transfer, ok := f.transfers[transferId]
+	if !ok {
+		return nil, repository.ErrTransferNotFound
+	}
+	return &transfer, nil
+}
+
+func (f *fakeBookingRepo) AcceptTransfer(ctx context.Context, transfer models.BookingTransfer, projectId, groupId int) error {
+	if f.accepted == nil {
+		f.accepted = map[int]acceptance{}
+	}
+	f.accepted[transfer.Id] = acceptance{projectId: projectId, groupId: groupId}
+	return nil
+}
+
+func (f *fakeBookingRepo) TransferParty(ctx context.Context, bookingId int, uid uuid.UUID) (bool, error) {
+	for _, t := range f.transfers {
+		if t.BookingId == bookingId && (t.FromUser == uid || t.ToUser == uid) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeBookingRepo) History(ctx context.Context, bookingId int) ([]models.BookingEvent, error) {
+	return []models.BookingEvent{{BookingId: bookingId, Action: models.HistoryCreated}}, nil
+}
+
+type fakeProjectRepo struct {
+	repository.ProjectRepositoryInterface
+	members map[int][]uuid.UUID
+}
+
+func (f *fakeProjectRepo) IsMember(ctx context.Context, projectId int, uid uuid.UUID) (bool, error) {
+	return slices.Contains(f.members[projectId], uid), nil
+}
+
+type fakeGroupRepo struct {
+	repository.GroupRepositoryInterface
+	groups  map[int]models.Group
+	members map[int][]uuid.UUID
+	// hours booked in the current month
+	booked map[int]float64
+}
+
+func (f *fakeGroupRepo) UserGroups(ctx context.Context, uid uuid.UUID) ([]models.Group, error) {
+	groups := []models.Group{}
+	for id, members := range f.members {
+		if slices.Contains(members, uid) {
+			groups = append(groups, f.groups[id])
+		}
+	}
+	return groups, nil
+}
+
+func (f *fakeGroupRepo) Membership(ctx context.Context, groupId int, uid uuid.UUID) (bool, bool, error) {
+	return slices.Contains(f.members[groupId], uid), false, nil
+}
+
+func (f *fakeGroupRepo) Group(ctx context.Context, groupId int) (*models.Group, error) {
+	group, ok := f.groups[groupId]
+	if !ok {
+		return nil, repository.ErrGroupNotFound
+	}
+	return &group, nil
+}
+
+func (f *fakeGroupRepo) BookedHours(ctx context.Context, groupId int, from, to time.Time) (float64, error) {
+	return f.booked[groupId], nil
+}
+
+type fakeCertRepo struct {
+	repository.CertificationRepositoryInterface
+	missing map[uuid.UUID][]models.Certification
+}
+
+func (f *fakeCertRepo) MissingCertifications(ctx context.Context, uid uuid.UUID, equipmentId int, validUntil time.Time) ([]models.Certification, error) {
+	return f.missing[uid], nil
+}
+
+func TestAcceptTransfer(t *testing.T) {
+	owner, colleague, outsider, visitor, member, stranger := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	trainee := uuid.New()
+	now := time.Now()
+	bookings := map[int]models.Booking{
+		1: {Id: 1, EquipmentId: 5, UserId: owner, ProjectId: 10, GroupId: 20, Status: models.BookingActive,
+			StartTime: now.Add(time.Hour), EndTime: now.Add(2 * time.Hour)},
+		2: {Id: 2, EquipmentId: 5, UserId: owner, ProjectId: 10, GroupId: 20, Status: models.BookingActive,
+			StartTime: now.Add(-2 * time.Hour), EndTime: now.Add(-time.Hour)},
+	}
+	transfer := func(id, bookingId int, recipient uuid.UUID, status string) models.BookingTransfer {
+		return models.BookingTransfer{Id: id, BookingId: bookingId, FromUser: owner, ToUser: recipient, Status: status}
+	}
+	transfers := map[int]models.BookingTransfer{
+		1: transfer(1, 1, colleague, models.TransferPending),
+		2: transfer(2, 2, colleague, models.TransferPending),
+		3: transfer(3, 1, colleague, models.TransferDeclined),
+		4: transfer(4, 1, outsider, models.TransferPending),
+		5: transfer(5, 1, visitor, models.TransferPending),
+		6: transfer(6, 1, member, models.TransferPending),
+		7: transfer(7, 1, trainee, models.TransferPending),
+	}
+	projects := map[int][]uuid.UUID{10: {owner, colleague, outsider, visitor, member, trainee}, 11: {colleague}, 12: {owner}}
+	certs := &fakeCertRepo{missing: map[uuid.UUID][]models.Certification{trainee: {{Id: 1, Name: "SEM basic"}}}}
+	quota := func(hours float64) *float64 { return &hours }
+	groups := &fakeGroupRepo{
+		groups:  map[int]models.Group{20: {Id: 20, QuotaHours: quota(10)}, 21: {Id: 21}, 22: {Id: 22}, 23: {Id: 23, QuotaHours: quota(5)}},
+		members: map[int][]uuid.UUID{20: {owner, colleague}, 21: {outsider}, 22: {outsider}, 23: {member}},
+		// the group of the owner and colleague has used up its quota, booking 1 included
+		booked: map[int]float64{20: 10, 23: 4.5},
+	}
+	tests := []struct {
+		name        string
+		transferId  int
+		uid         uuid.UUID
+		projectId   int
+		groupId     int
+		expectedErr error
+		expected    acceptance
+	}{
+		{
+			name:       "keeps the project and the group",
+			transferId: 1,
+			uid:        colleague,
+			expected:   acceptance{projectId: 10, groupId: 20},
+		},
+		{
+			name:       "moves to another project",
+			transferId: 1,
+			uid:        colleague,
+			projectId:  11,
+			expected:   acceptance{projectId: 11, groupId: 20},
+		},
+		{
+			name:       "recipient without a group",
+			transferId: 5,
+			uid:        visitor,
+			expected:   acceptance{projectId: 10},
+		},
+		{
+			name:       "recipient names one of their groups",
+			transferId: 4,
+			uid:        outsider,
+			groupId:    22,
+			expected:   acceptance{projectId: 10, groupId: 22},
+		},
+		{
+			name:        "recipient in several groups has to name one",
+			transferId:  4,
+			uid:         outsider,
+			expectedErr: ErrGroupRequired,
+		},
+		{
+			name:        "recipient names a foreign group",
+			transferId:  4,
+			uid:         outsider,
+			groupId:     20,
+			expectedErr: ErrNotGroupMember,
+		},
+		{
+			name:        "recipient's group quota exceeded",
+			transferId:  6,
+			uid:         member,
+			expectedErr: ErrQuotaExceeded,
+		},
+		{
+			name:        "recipient is not a member of the project",
+			transferId:  1,
+			uid:         colleague,
+			projectId:   12,
+			expectedErr: ErrNotProjectMember,
+		},
+		{
+			name:        "recipient lacks a certification",
+			transferId:  7,
+			uid:         trainee,
+			expectedErr: ErrCertificationRequired,
+		},
+		{
+			name:        "not the recipient",
+			transferId:  1,
+			uid:         stranger,
+			expectedErr: ErrTransferNotFound,
+		},
+		{
+			name:        "unknown transfer",
+			transferId:  8,
+			uid:         colleague,
+			expectedErr: ErrTransferNotFound,
+		},
+		{
+			name:        "declined transfer",
+			transferId:  3,
+			uid:         colleague,
+			expectedErr: ErrTransferNotPending,
+		},
+		{
+			name:        "booking already ended",
+			transferId:  2,
+			uid:         colleague,
+			expectedErr: ErrTransferNotPending,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeBookingRepo{bookings: bookings, transfers: transfers}
+			srv := BookingService{bookingRepo: repo, projectRepo: &fakeProjectRepo{members: projects}, groupRepo: groups,
+				certRepo: certs, log: slog.New(slog.DiscardHandler)}
+			err := srv.AcceptTransfer(context.Background(), tt.transferId, tt.uid, tt.projectId, tt.groupId)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, repo.accepted)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, map[int]acceptance{tt.transferId: tt.expected}, repo.accepted)
+		})
+	}
+}
+
+func TestHistory(t *testing.T) {
+	previous, owner, declined, stranger := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	repo := &fakeBookingRepo{
+		bookings: map[int]models.Booking{1: {Id: 1, UserId: owner}},
+		transfers: map[int]models.BookingTransfer{
+			1: {Id: 1, BookingId: 1, FromUser: previous, ToUser: owner, Status: models.TransferAccepted},
+			2: {Id: 2, BookingId: 1, FromUser: owner, ToUser: declined, Status: models.TransferDeclined},
+			3: {Id: 3, BookingId: 2, FromUser: stranger, ToUser: owner, Status: models.TransferPending},
+		},
+	}
+	tests := []struct {
+		name        string
+		bookingId   int
+		uid         uuid.UUID
+		expectedErr error
+	}{
+		{
+			name:      "owner",
+			bookingId: 1,
+			uid:       owner,
+		},
+		{
+			name:      "previous owner",
+			bookingId: 1,
+			uid:       previous,
+		},
+		{
+			name:      "recipient of a declined transfer",
+			bookingId: 1,
+			uid:       declined,
+		},
+		{
+			name:        "party of another booking's transfer",
+			bookingId:   1,
+			uid:         stranger,
+			expectedErr: ErrInvalidOwner,
+		},
+		{
+			name:        "unknown booking",
+			bookingId:   2,
+			uid:         owner,
+			expectedErr: ErrBookingNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := BookingService{bookingRepo: repo, log: slog.New(slog.DiscardHandler)}
+			events, err := srv.History(context.Background(), tt.bookingId, tt.uid)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, events, 1)
+		})
+	}
+}
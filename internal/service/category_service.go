@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+)
+
+const maxCategoryNameLength = 100
+
+var (
+	ErrInvalidCategory       = errors.New("invalid category")
+	ErrCategoryNotFound      = errors.New("category not found")
+	ErrCategoryAlreadyExists = errors.New("category already exists")
+	ErrCategoryInUse         = errors.New("category in use")
+)
+
+type CategoryService struct {
+	repo  repository.CategoryRepositoryInterface
+	cache repository.SuggestionCacheInterface
+	log   *slog.Logger
+}
+
+type CategoryServiceInterface interface {
+	CreateCategory(ctx context.Context, category models.Category) (int, error)
+	Categories(ctx context.Context) ([]models.Category, error)
+	RenameCategory(ctx context.Context, categoryId int, name string) error
+	DeleteCategory(ctx context.Context, categoryId int) error
+}
+
+func NewCategoryService(repo repository.CategoryRepositoryInterface, cache repository.SuggestionCacheInterface, log *slog.Logger) CategoryService {
+	return CategoryService{repo: repo, cache: cache, log: log}
+}
+
+func validCategoryName(name string) bool {
+	return name != "" && len(name) <= maxCategoryNameLength && !strings.Contains(name, ">")
+}
+
+// CreateCategory adds a root category or, with a parent, a subcategory
+func (c *CategoryService) CreateCategory(ctx context.Context, category models.Category) (int, error) {
+	const op = "category_service.CreateCategory"
+	log := c.log.With(slog.String("op", op))
+	category.Name = strings.TrimSpace(category.Name)
+	log.Info("creating category", slog.String("name", category.Name))
+	if !validCategoryName(category.Name) {
+		return 0, fmt.Errorf("%s: %w", op, ErrInvalidCategory)
+	}
+	id, err := c.repo.CreateCategory(ctx, category)
+	if err != nil {
+		if errors.Is(err, repository.ErrCategoryAlreadyExists) {
+			return 0, fmt.Errorf("%s: %w", op, ErrCategoryAlreadyExists)
+		}
+		if errors.Is(err, repository.ErrCategoryNotFound) {
+			return 0, fmt.Errorf("%s: %w", op, ErrCategoryNotFound)
+		}
+		log.Error("creating category error", slog.String("error", err.Error()))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	invalidateSuggestions(ctx, c.cache, log)
+	return id, nil
+}
+
+func (c *CategoryService) Categories(ctx context.Context) ([]models.Category, error) {
+	const op = "category_service.Categories"
+	categories, err := c.repo.Categories(ctx)
+	if err != nil {
+		c.log.Error("getting categories error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return categories, nil
+}
+
+func (c *CategoryService) RenameCategory(ctx context.Context, categoryId int, name string) error {
+	const op = "category_service.RenameCategory"
+	log := c.log.With(slog.String("op", op))
+	name = strings.TrimSpace(name)
+	log.Info("renaming category", slog.Int("category_id", categoryId), slog.String("name", name))
+	if !validCategoryName(name) {
+		return fmt.Errorf("%s: %w", op, ErrInvalidCategory)
+	}
+	err := c.repo.RenameCategory(ctx, categoryId, name)
+	if err != nil {
+		if errors.Is(err, repository.ErrCategoryAlreadyExists) {
+			return fmt.Errorf("%s: %w", op, ErrCategoryAlreadyExists)
+		}
+		if errors.Is(err, repository.ErrCategoryNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrCategoryNotFound)
+		}
+		log.Error("renaming category error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	invalidateSuggestions(ctx, c.cache, log)
+	return nil
+}
+
+// DeleteCategory is refused while the category has subcategories or equipment
+func (c *CategoryService) DeleteCategory(ctx context.Context, categoryId int) error {
+	const op = "category_service.DeleteCategory"
+	log := c.log.With(slog.String("op", op))
+	log.Info("deleting category", slog.Int("category_id", categoryId))
+	err := c.repo.DeleteCategory(ctx, categoryId)
+	if err != nil {
+		if errors.Is(err, repository.ErrCategoryNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrCategoryNotFound)
+		}
+		if errors.Is(err, repository.ErrCategoryInUse) {
+			return fmt.Errorf("%s: %w", op, ErrCategoryInUse)
+		}
+		log.Error("deleting category error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	invalidateSuggestions(ctx, c.cache, log)
+	return nil
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	// users are notified this long before a certification expires
+	certificationExpiryNotice = 14 * 24 * time.Hour
+	certificationScanInterval = time.Hour
+)
+
+var (
+	ErrCertificationAlreadyExists = errors.New("certification already exists")
+	ErrCertificationNotFound      = errors.New("certification not found")
+	ErrCertificationRequired      = errors.New("certification required")
+	ErrExpiryRequired             = errors.New("expiry date required")
+	ErrInvalidCertification       = errors.New("invalid certification")
+)
+
+// MissingCertificationError lists the trainings a user needs before booking the equipment
+type MissingCertificationError struct {
+	Certifications []models.Certification
+}
+
+func (m *MissingCertificationError) Error() string {
+	names := make([]string, 0, len(m.Certifications))
+	for _, c := range m.Certifications {
+		names = append(names, c.Name)
+	}
+	return fmt.Sprintf("%s: %s", ErrCertificationRequired, strings.Join(names, ", "))
+}
+
+func (m *MissingCertificationError) Unwrap() error {
+	return ErrCertificationRequired
+}
+
+type CertificationService struct {
+	repo repository.CertificationRepositoryInterface
+	log  *slog.Logger
+}
+
+type CertificationServiceInterface interface {
+	CreateCertification(ctx context.Context, cert models.Certification) (int, error)
+	Certifications(ctx context.Context) ([]models.Certification, error)
+	SetEquipmentCertifications(ctx context.Context, equipmentId int, certIds []int) error
+	EquipmentCertifications(ctx context.Context, equipmentId int) ([]models.Certification, error)
+	Grant(ctx context.Context, grant models.UserCertification) error
+	Revoke(ctx context.Context, uid uuid.UUID, certId int) error
+	UserCertifications(ctx context.Context, uid uuid.UUID) ([]models.UserCertification, error)
+}
+
+func NewCertificationService(repo repository.CertificationRepositoryInterface, log *slog.Logger) CertificationService {
+	return CertificationService{repo: repo, log: log}
+}
+
+func (c *CertificationService) CreateCertification(ctx context.Context, cert models.Certification) (int, error) {
+	const op = "certification_service.CreateCertification"
+	log := c.log.With(slog.String("op", op))
+	log.Info("creating certification", slog.String("name", cert.Name))
+	if cert.ValidityDays != nil && *cert.ValidityDays <= 0 {
+		return 0, fmt.Errorf("%s: %w", op, ErrInvalidCertification)
+	}
+	id, err := c.repo.CreateCertification(ctx, cert)
+	if err != nil {
+		if errors.Is(err, repository.ErrCertificationAlreadyExists) {
+			return 0, fmt.Errorf("%s: %w", op, ErrCertificationAlreadyExists)
+		}
+		log.Error("creating certification error", slog.String("error", err.Error()))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (c *CertificationService) Certifications(ctx context.Context) ([]models.Certification, error) {
+	const op = "certification_service.Certifications"
+	certs, err := c.repo.Certifications(ctx)
+	if err != nil {
+		c.log.Error("getting certifications error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return certs, nil
+}
+
+func (c *CertificationService) SetEquipmentCertifications(ctx context.Context, equipmentId int, certIds []int) error {
+	const op = "certification_service.SetEquipmentCertifications"
+	log := c.log.With(slog.String("op", op))
+	log.Info("setting equipment certifications", slog.Int("equipment_id", equipmentId))
+	err := c.repo.SetEquipmentCertifications(ctx, equipmentId, certIds)
+	if err != nil {
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		if errors.Is(err, repository.ErrCertificationNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrCertificationNotFound)
+		}
+		log.Error("setting equipment certifications error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (c *CertificationService) EquipmentCertifications(ctx context.Context, equipmentId int) ([]models.Certification, error) {
+	const op = "certification_service.EquipmentCertifications"
+	certs, err := c.repo.EquipmentCertifications(ctx, equipmentId)
+	if err != nil {
+		c.log.Error("getting equipment certifications error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return certs, nil
+}
+
+// Grant falls back to the certification's validity period when no expiry is given
+func (c *CertificationService) Grant(ctx context.Context, grant models.UserCertification) error {
+	const op = "certification_service.Grant"
+	log := c.log.With(slog.String("op", op))
+	log.Info("granting certification", slog.Int("certification_id", grant.CertificationId), slog.String("user_id", grant.UserId.String()))
+	if grant.ExpiresAt.IsZero() {
+		cert, err := c.repo.Certification(ctx, grant.CertificationId)
+		if err != nil {
+			if errors.Is(err, repository.ErrCertificationNotFound) {
+				return fmt.Errorf("%s: %w", op, ErrCertificationNotFound)
+			}
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if cert.ValidityDays == nil {
+			return fmt.Errorf("%s: %w", op, ErrExpiryRequired)
+		}
+		grant.ExpiresAt = time.Now().AddDate(0, 0, *cert.ValidityDays)
+	}
+	if !grant.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("%s: %w", op, ErrExpiryRequired)
+	}
+	err := c.repo.Grant(ctx, grant)
+	if err != nil {
+		if errors.Is(err, repository.ErrCertificationNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrCertificationNotFound)
+		}
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+		log.Error("granting certification error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (c *CertificationService) Revoke(ctx context.Context, uid uuid.UUID, certId int) error {
+	const op = "certification_service.Revoke"
+	log := c.log.With(slog.String("op", op))
+	log.Info("revoking certification", slog.Int("certification_id", certId), slog.String("user_id", uid.String()))
+	err := c.repo.Revoke(ctx, uid, certId)
+	if err != nil {
+		if errors.Is(err, repository.ErrCertificationNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrCertificationNotFound)
+		}
+		log.Error("revoking certification error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (c *CertificationService) UserCertifications(ctx context.Context, uid uuid.UUID) ([]models.UserCertification, error) {
+	const op = "certification_service.UserCertifications"
+	certs, err := c.repo.UserCertifications(ctx, uid)
+	if err != nil {
+		c.log.Error("getting user certifications error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return certs, nil
+}
+
+// RunExpiryNotifier periodically notifies users about certifications that expire soon.
+// It blocks until ctx is cancelled
+func (c *CertificationService) RunExpiryNotifier(ctx context.Context) {
+	const op = "certification_service.RunExpiryNotifier"
+	log := c.log.With(slog.String("op", op))
+	ticker := time.NewTicker(certificationScanInterval)
+	defer ticker.Stop()
+	for {
+		n, err := c.repo.NotifyExpiring(ctx, time.Now().Add(certificationExpiryNotice))
+		if err != nil {
+			log.Error("notifying expiring certifications error", slog.String("error", err.Error()))
+		} else if n > 0 {
+			log.Info("expiring certifications notified", slog.Int64("count", n))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
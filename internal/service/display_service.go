@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/Gergenus/bookingService/pkg/hash"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	// display tokens are made of this many random bytes
+	displayTokenBytes = 32
+	// side of the booking QR code in pixels
+	displayQRSize = 256
+)
+
+var (
+	ErrInvalidDisplayToken = errors.New("invalid display token")
+	ErrDisplayNotFound     = errors.New("display not found")
+	ErrInvalidDisplay      = errors.New("invalid display")
+)
+
+type DisplayService struct {
+	repo       repository.DisplayRepositoryInterface
+	log        *slog.Logger
+	bookingURL string
+}
+
+type DisplayServiceInterface interface {
+	CreateDisplay(ctx context.Context, display models.Display) (*models.Display, string, error)
+	Displays(ctx context.Context) ([]models.Display, error)
+	RevokeDisplay(ctx context.Context, displayId int) error
+	Schedule(ctx context.Context, token string) (*models.DisplaySchedule, error)
+	BookingQR(schedule models.DisplaySchedule) ([]byte, error)
+}
+
+// bookingURL is the frontend base, the QR code on a display links to the equipment or room page there
+func NewDisplayService(repo repository.DisplayRepositoryInterface, log *slog.Logger, bookingURL string) DisplayService {
+	return DisplayService{repo: repo, log: log, bookingURL: strings.TrimRight(bookingURL, "/")}
+}
+
+// CreateDisplay registers a display for one equipment or one room and returns its token.
+// Only a digest of the token is stored, so it can't be shown again
+func (d *DisplayService) CreateDisplay(ctx context.Context, display models.Display) (*models.Display, string, error) {
+	const op = "display_service.CreateDisplay"
+	log := d.log.With(slog.String("op", op))
+	display.Name, display.Room = strings.TrimSpace(display.Name), strings.TrimSpace(display.Room)
+	log.Info("creating display", slog.String("name", display.Name), slog.String("room", display.Room))
+	if display.Name == "" || len(display.Name) > 255 || len(display.Room) > maxRoomLength ||
+		(display.EquipmentId == nil) == (display.Room == "") {
+		return nil, "", fmt.Errorf("%s: %w", op, ErrInvalidDisplay)
+	}
+	token, err := hash.NewToken(displayTokenBytes)
+	if err != nil {
+		log.Error("generating display token error", slog.String("error", err.Error()))
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+	display.Id, err = d.repo.CreateDisplay(ctx, display, hash.HashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return nil, "", fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		log.Error("creating display error", slog.String("error", err.Error()))
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+	display.CreatedAt = time.Now()
+	return &display, token, nil
+}
+
+func (d *DisplayService) Displays(ctx context.Context) ([]models.Display, error) {
+	const op = "display_service.Displays"
+	displays, err := d.repo.Displays(ctx)
+	if err != nil {
+		d.log.Error("getting displays error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return displays, nil
+}
+
+func (d *DisplayService) RevokeDisplay(ctx context.Context, displayId int) error {
+	const op = "display_service.RevokeDisplay"
+	log := d.log.With(slog.String("op", op))
+	log.Info("revoking display", slog.Int("display_id", displayId))
+	err := d.repo.RevokeDisplay(ctx, displayId)
+	if err != nil {
+		if errors.Is(err, repository.ErrDisplayNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrDisplayNotFound)
+		}
+		log.Error("revoking display error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// Schedule returns the busy slots of today and tomorrow for the display behind the token.
+// Days follow the equipment time zone, a room display uses the zone of its first equipment
+func (d *DisplayService) Schedule(ctx context.Context, token string) (*models.DisplaySchedule, error) {
+	const op = "display_service.Schedule"
+	log := d.log.With(slog.String("op", op))
+	if token == "" {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidDisplayToken)
+	}
+	display, err := d.repo.DisplayByToken(ctx, hash.HashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrDisplayNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, ErrInvalidDisplayToken)
+		}
+		log.Error("getting display error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	equipment, err := d.repo.DisplayEquipment(ctx, *display)
+	if err != nil {
+		log.Error("getting display equipment error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	loc := time.UTC
+	if len(equipment) > 0 {
+		if l, err := time.LoadLocation(equipment[0].TimeZone); err == nil {
+			loc = l
+		}
+	}
+	now := time.Now().In(loc)
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	schedule := &models.DisplaySchedule{
+		Title:       display.Name,
+		TimeZone:    loc.String(),
+		From:        from,
+		To:          from.AddDate(0, 0, 2),
+		BookingURL:  d.displayBookingURL(*display),
+		GeneratedAt: now,
+		Equipment:   make([]models.DisplayEquipment, 0, len(equipment)),
+	}
+	if len(equipment) == 0 {
+		return schedule, nil
+	}
+	ids := make([]int, 0, len(equipment))
+	index := make(map[int]int, len(equipment))
+	for i, eq := range equipment {
+		ids = append(ids, eq.EquipmentId)
+		index[eq.EquipmentId] = i
+		schedule.Equipment = append(schedule.Equipment, models.DisplayEquipment{Id: eq.EquipmentId, Name: eq.EquipmentName,
+			Slots: []models.DisplaySlot{}})
+	}
+	bookings, err := d.repo.BusySlots(ctx, ids, schedule.From, schedule.To)
+	if err != nil {
+		log.Error("getting busy slots error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for _, booking := range bookings {
+		slot := models.DisplaySlot{StartTime: booking.StartTime, EndTime: booking.EndTime, Status: models.SlotBooked}
+		if booking.CheckedInAt != nil && booking.CheckedOutAt == nil {
+			slot.Status = models.SlotInUse
+		}
+		eq := &schedule.Equipment[index[booking.EquipmentId]]
+		eq.Slots = append(eq.Slots, slot)
+	}
+	return schedule, nil
+}
+
+// BookingQR renders the schedule's booking link as a PNG QR code
+func (d *DisplayService) BookingQR(schedule models.DisplaySchedule) ([]byte, error) {
+	const op = "display_service.BookingQR"
+	png, err := qrcode.Encode(schedule.BookingURL, qrcode.Medium, displayQRSize)
+	if err != nil {
+		d.log.Error("encoding booking qr code error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return png, nil
+}
+
+func (d *DisplayService) displayBookingURL(display models.Display) string {
+	if display.EquipmentId != nil {
+		return d.bookingURL + "/equipment/" + strconv.Itoa(*display.EquipmentId)
+	}
+	return d.bookingURL + "/equipment?room=" + url.QueryEscape(display.Room)
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+)
+
+// AvailableEquipment answers "which equipment is free then": the equipment matching the catalog
+// filters that can be booked for the whole window
+func (e *EquipmentService) AvailableEquipment(ctx context.Context, filter models.AvailabilityFilter) ([]models.AvailableEquipment, error) {
+	const op = "equipment_service.AvailableEquipment"
+	log := e.log.With(slog.String("op", op))
+	if !filter.Start.Before(filter.End) || !filter.End.After(time.Now()) || filter.End.Sub(filter.Start) > maxSchedulePeriod {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidInterval)
+	}
+	filter.Query = strings.TrimSpace(filter.Query)
+	if utf8.RuneCountInString(filter.Query) > maxSearchQueryLength {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidSearchQuery)
+	}
+	if filter.Limit <= 0 || filter.Limit > pagination.MaxLimit || filter.MinCapacity < 0 {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidFilter)
+	}
+	tags, err := normalizeTags(filter.Tags)
+	if err != nil || len(tags) > maxTags {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidFilter)
+	}
+	filter.Tags = tags
+	available, err := e.repo.AvailableEquipment(ctx, filter)
+	if err != nil {
+		log.Error("searching available equipment error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for i := range available {
+		e.signImages(&available[i].Equipment)
+	}
+	return available, nil
+}
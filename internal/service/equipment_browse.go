@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+)
+
+const (
+	maxTagLength = 50
+	maxTags      = 20
+)
+
+// normalizeTags lowercases and trims the tags and drops the duplicates, it fails on empty
+// or too long tags
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || len(tag) > maxTagLength {
+			return nil, ErrInvalidTags
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized, nil
+}
+
+func (e *EquipmentService) SetCategory(ctx context.Context, equipmentId int, categoryId *int) error {
+	const op = "equipment_service.SetCategory"
+	log := e.log.With(slog.String("op", op))
+	log.Info("setting equipment category", slog.Int("equipment_id", equipmentId))
+	err := e.repo.SetCategory(ctx, equipmentId, categoryId)
+	if err != nil {
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		if errors.Is(err, repository.ErrCategoryNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrCategoryNotFound)
+		}
+		log.Error("setting equipment category error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// SetTags replaces the tags of the equipment
+func (e *EquipmentService) SetTags(ctx context.Context, equipmentId int, tags []string) error {
+	const op = "equipment_service.SetTags"
+	log := e.log.With(slog.String("op", op))
+	log.Info("setting equipment tags", slog.Int("equipment_id", equipmentId), slog.Int("tags", len(tags)))
+	tags, err := normalizeTags(tags)
+	if err != nil || len(tags) > maxTags {
+		return fmt.Errorf("%s: %w", op, ErrInvalidTags)
+	}
+	err = e.repo.SetTags(ctx, equipmentId, tags)
+	if err != nil {
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		log.Error("setting equipment tags error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// BrowseEquipment returns a page of the equipment matching the filter together with the facet
+// counts a filter sidebar needs
+func (e *EquipmentService) BrowseEquipment(ctx context.Context, filter models.EquipmentFilter) (*models.EquipmentBrowse, error) {
+	const op = "equipment_service.BrowseEquipment"
+	log := e.log.With(slog.String("op", op))
+	if filter.Limit <= 0 || filter.Limit > pagination.MaxLimit {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidFilter)
+	}
+	tags, err := normalizeTags(filter.Tags)
+	if err != nil || len(tags) > maxTags {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidFilter)
+	}
+	filter.Tags = tags
+	limit := filter.Limit
+	filter.Limit++
+	equipment, err := e.repo.BrowseEquipment(ctx, filter)
+	if err != nil {
+		log.Error("browsing equipment error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	result := &models.EquipmentBrowse{Items: equipment}
+	if len(equipment) > limit {
+		result.Items = equipment[:limit]
+		last := equipment[limit-1]
+		result.NextCursor, err = pagination.EncodeCursor(models.EquipmentCursor{Name: last.EquipmentName, Id: last.EquipmentId})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	result.Total, err = e.repo.CountEquipment(ctx, filter)
+	if err != nil {
+		log.Error("counting equipment error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	facets, err := e.repo.EquipmentFacets(ctx, filter)
+	if err != nil {
+		log.Error("getting equipment facets error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	result.Facets = *facets
+	for i := range result.Items {
+		e.signImages(&result.Items[i])
+	}
+	return result, nil
+}
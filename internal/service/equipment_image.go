@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime/multipart"
+	"strings"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/Gergenus/bookingService/pkg/imaging"
+)
+
+const (
+	maxEquipmentImages    = 20
+	maxImageCaptionLength = 255
+)
+
+var (
+	ErrImageNotFound     = errors.New("image not found")
+	ErrTooManyImages     = errors.New("too many images")
+	ErrInvalidImageOrder = errors.New("invalid image order")
+	ErrInvalidCaption    = errors.New("invalid caption")
+	ErrUnsupportedImage  = errors.New("unsupported image")
+	ErrImageTooLarge     = errors.New("image too large")
+	ErrInvalidImageSize  = errors.New("invalid image size")
+	// ErrInvalidImageSignature is returned for unsigned, tampered and expired image links
+	ErrInvalidImageSignature = errors.New("invalid image signature")
+)
+
+// imageURLs signs links to every size of the image
+func (e *EquipmentService) imageURLs(objectName string) *models.ImageURLs {
+	if objectName == "" {
+		return nil
+	}
+	return &models.ImageURLs{
+		Original: e.mini.ImageURL(objectName, ""),
+		Medium:   e.mini.ImageURL(objectName, "medium"),
+		Thumb:    e.mini.ImageURL(objectName, "thumb"),
+	}
+}
+
+// signImages fills the image links of the equipment and of its gallery
+func (e *EquipmentService) signImages(eq *models.Equipment) {
+	eq.ImageURLs = e.imageURLs(eq.ImageURL)
+	for i := range eq.Images {
+		eq.Images[i].URLs = e.imageURLs(eq.Images[i].ObjectName)
+	}
+}
+
+// AddImage uploads the file and appends it to the gallery, the object is removed again
+// if the image can't be added
+func (e *EquipmentService) AddImage(ctx context.Context, image models.EquipmentImage, file *multipart.FileHeader) (*models.EquipmentImage, error) {
+	const op = "equipment_service.AddImage"
+	log := e.log.With(slog.String("op", op))
+	log.Info("adding equipment image", slog.Int("equipment_id", image.EquipmentId), slog.String("image", file.Filename))
+	image.Caption = strings.TrimSpace(image.Caption)
+	if len(image.Caption) > maxImageCaptionLength {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidCaption)
+	}
+	objectName, err := e.mini.AddImage(ctx, file)
+	if err != nil {
+		if errors.Is(err, imaging.ErrTooLarge) {
+			return nil, fmt.Errorf("%s: %w", op, ErrImageTooLarge)
+		}
+		if errors.Is(err, imaging.ErrUnsupportedType) || errors.Is(err, imaging.ErrInvalidImage) {
+			return nil, fmt.Errorf("%s: %w", op, ErrUnsupportedImage)
+		}
+		log.Error("adding image to s3 storage error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	image.ObjectName = objectName
+	added, err := e.repo.AddEquipmentImage(ctx, image, maxEquipmentImages)
+	if err != nil {
+		e.removeImage(ctx, log, objectName)
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		if errors.Is(err, repository.ErrTooManyImages) {
+			return nil, fmt.Errorf("%s: %w", op, ErrTooManyImages)
+		}
+		if errors.Is(err, repository.ErrEquipmentRetired) {
+			return nil, fmt.Errorf("%s: %w", op, ErrEquipmentRetired)
+		}
+		log.Error("adding equipment image error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	added.URLs = e.imageURLs(added.ObjectName)
+	return added, nil
+}
+
+func (e *EquipmentService) Images(ctx context.Context, equipmentId int) ([]models.EquipmentImage, error) {
+	const op = "equipment_service.Images"
+	_, err := e.repo.Equipment(ctx, equipmentId)
+	if err != nil {
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		e.log.Error("getting equipment error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	images, err := e.repo.EquipmentImages(ctx, equipmentId)
+	if err != nil {
+		e.log.Error("getting equipment images error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for i := range images {
+		images[i].URLs = e.imageURLs(images[i].ObjectName)
+	}
+	return images, nil
+}
+
+// UpdateImage changes the caption of the image or makes it the primary one
+func (e *EquipmentService) UpdateImage(ctx context.Context, equipmentId, imageId int, update models.EquipmentImageUpdate) (*models.EquipmentImage, error) {
+	const op = "equipment_service.UpdateImage"
+	log := e.log.With(slog.String("op", op))
+	log.Info("updating equipment image", slog.Int("equipment_id", equipmentId), slog.Int("image_id", imageId))
+	if update.Caption != nil {
+		caption := strings.TrimSpace(*update.Caption)
+		if len(caption) > maxImageCaptionLength {
+			return nil, fmt.Errorf("%s: %w", op, ErrInvalidCaption)
+		}
+		update.Caption = &caption
+	}
+	image, err := e.repo.UpdateEquipmentImage(ctx, equipmentId, imageId, update)
+	if err != nil {
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		if errors.Is(err, repository.ErrEquipmentImageNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, ErrImageNotFound)
+		}
+		log.Error("updating equipment image error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	image.URLs = e.imageURLs(image.ObjectName)
+	return image, nil
+}
+
+// ReorderImages puts the gallery in the given order, every image has to be listed once
+func (e *EquipmentService) ReorderImages(ctx context.Context, equipmentId int, imageIds []int) error {
+	const op = "equipment_service.ReorderImages"
+	log := e.log.With(slog.String("op", op))
+	log.Info("reordering equipment images", slog.Int("equipment_id", equipmentId))
+	if len(imageIds) > maxEquipmentImages {
+		return fmt.Errorf("%s: %w", op, ErrInvalidImageOrder)
+	}
+	err := e.repo.ReorderEquipmentImages(ctx, equipmentId, imageIds)
+	if err != nil {
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		if errors.Is(err, repository.ErrInvalidImageOrder) {
+			return fmt.Errorf("%s: %w", op, ErrInvalidImageOrder)
+		}
+		log.Error("reordering equipment images error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// DeleteImage removes the image from the gallery and then its object from the storage
+func (e *EquipmentService) DeleteImage(ctx context.Context, equipmentId, imageId int) error {
+	const op = "equipment_service.DeleteImage"
+	log := e.log.With(slog.String("op", op))
+	log.Info("deleting equipment image", slog.Int("equipment_id", equipmentId), slog.Int("image_id", imageId))
+	objectName, err := e.repo.DeleteEquipmentImage(ctx, equipmentId, imageId)
+	if err != nil {
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		if errors.Is(err, repository.ErrEquipmentImageNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrImageNotFound)
+		}
+		log.Error("deleting equipment image error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	e.removeImage(ctx, log, objectName)
+	return nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+)
+
+const maxSearchQueryLength = 200
+
+var ErrInvalidSearchQuery = errors.New("invalid search query")
+
+// SearchEquipment runs a full-text search over name, manufacturer, description and tags in russian
+// and english. The query accepts web search syntax: quoted phrases, "or" and -word. When nothing
+// matches, the first page falls back to a typo tolerant search by name and manufacturer
+func (e *EquipmentService) SearchEquipment(ctx context.Context, query string, after *models.EquipmentSearchCursor,
+	limit int) (*pagination.Page[models.EquipmentSearchHit], error) {
+	const op = "equipment_service.SearchEquipment"
+	log := e.log.With(slog.String("op", op))
+	query = strings.TrimSpace(query)
+	if query == "" || utf8.RuneCountInString(query) > maxSearchQueryLength {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidSearchQuery)
+	}
+	if limit <= 0 || limit > pagination.MaxLimit {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidFilter)
+	}
+	hits, err := e.repo.SearchEquipment(ctx, query, after, limit+1)
+	if err != nil {
+		log.Error("searching equipment error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(hits) == 0 && after == nil {
+		hits, err = e.repo.FuzzySearchEquipment(ctx, query, limit)
+		if err != nil {
+			log.Error("fuzzy searching equipment error", slog.String("error", err.Error()))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		for i := range hits {
+			e.signImages(&hits[i].Equipment)
+		}
+		return &pagination.Page[models.EquipmentSearchHit]{Items: hits, TotalEstimate: int64(len(hits))}, nil
+	}
+	result := &pagination.Page[models.EquipmentSearchHit]{Items: hits}
+	if len(hits) > limit {
+		result.Items = hits[:limit]
+		last := hits[limit-1]
+		result.NextCursor, err = pagination.EncodeCursor(models.EquipmentSearchCursor{Rank: last.Rank, Id: last.Equipment.EquipmentId})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	total, err := e.repo.CountSearchMatches(ctx, query)
+	if err != nil {
+		log.Error("counting search matches error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	result.TotalEstimate = int64(total)
+	for i := range result.Items {
+		e.signImages(&result.Items[i].Equipment)
+	}
+	return result, nil
+}
+
+const (
+	maxSuggestionPrefixLength = 100
+	suggestionCacheTTL        = 5 * time.Minute
+)
+
+// Suggest completes what the user is typing with equipment names, manufacturers and categories.
+// Results are cached, equipment and category changes invalidate the cache
+func (e *EquipmentService) Suggest(ctx context.Context, prefix string, limit int) ([]models.Suggestion, error) {
+	const op = "equipment_service.Suggest"
+	log := e.log.With(slog.String("op", op))
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" || utf8.RuneCountInString(prefix) > maxSuggestionPrefixLength {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidSearchQuery)
+	}
+	// a broken cache only makes suggestions slower
+	key, err := e.cache.SuggestionsKey(ctx, prefix, limit)
+	if err != nil {
+		log.Warn("getting suggestions cache key error", slog.String("error", err.Error()))
+	} else {
+		cached, err := e.cache.CachedSuggestions(ctx, key)
+		if err == nil {
+			return cached, nil
+		}
+		if !errors.Is(err, repository.ErrCacheMiss) {
+			log.Warn("reading suggestions cache error", slog.String("error", err.Error()))
+		}
+	}
+	suggestions, err := e.repo.Suggestions(ctx, prefix, limit)
+	if err != nil {
+		log.Error("getting suggestions error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if key != "" {
+		err = e.cache.CacheSuggestions(ctx, key, suggestions, suggestionCacheTTL)
+		if err != nil {
+			log.Warn("caching suggestions error", slog.String("error", err.Error()))
+		}
+	}
+	return suggestions, nil
+}
+
+// invalidateSuggestions is called after a committed change of equipment or categories. A failure is
+// only logged, the cached suggestions then expire with their TTL
+func invalidateSuggestions(ctx context.Context, cache repository.SuggestionCacheInterface, log *slog.Logger) {
+	err := cache.InvalidateSuggestions(ctx)
+	if err != nil {
+		log.Warn("invalidating suggestions cache error", slog.String("error", err.Error()))
+	}
+}
@@ -2,63 +2,162 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"mime/multipart"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/Gergenus/bookingService/internal/models"
 	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/Gergenus/bookingService/pkg/imaging"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+	"github.com/google/uuid"
 	"github.com/minio/minio-go/v7"
 )
 
+const (
+	maxRoomLength = 100
+	// column sizes of the equipment table
+	maxEquipmentNameLength = 50
+	maxManufacturerLength  = 255
+	maxDescriptionLength   = 500
+	maxStatusReasonLength  = 500
+)
+
+var (
+	ErrInvalidOperatingHours  = errors.New("invalid operating hours")
+	ErrEquipmentNotFound      = errors.New("equipment not found")
+	ErrInvalidTimeZone        = errors.New("invalid time zone")
+	ErrInvalidPreemptionRule  = errors.New("invalid preemption rule")
+	ErrInvalidAllocationMode  = errors.New("invalid allocation mode")
+	ErrInvalidRoom            = errors.New("invalid room")
+	ErrPreemptionRuleNotFound = errors.New("preemption rule not found")
+	ErrInvalidEquipment       = errors.New("invalid equipment")
+	ErrEquipmentModified      = errors.New("equipment modified")
+	ErrInvalidTags            = errors.New("invalid tags")
+	ErrInvalidStatus          = errors.New("invalid equipment status")
+	ErrEquipmentHasBookings   = errors.New("equipment has future bookings")
+	ErrEquipmentRetired       = errors.New("equipment retired")
+	ErrEquipmentUnavailable   = errors.New("equipment unavailable")
+)
+
 type EquipmentService struct {
-	log  *slog.Logger
-	repo repository.LabRepositroy
-	mini repository.ImageRepositoryInterface
+	log   *slog.Logger
+	repo  repository.LabRepositroy
+	mini  repository.ImageRepositoryInterface
+	cache repository.SuggestionCacheInterface
 }
 
 type EquipmentServiceInterface interface {
-	CreateEquipment(ctx context.Context, equipment models.Equipment, image *multipart.FileHeader) (int, error)
+	CreateEquipment(ctx context.Context, equipment models.Equipment, images []*multipart.FileHeader) (int, error)
 	Equipment(ctx context.Context, equipment_id int) (*models.Equipment, error)
 	EquipmentByName(ctx context.Context, equipmentName string) ([]models.Equipment, error)
-	DeleteEquipment(ctx context.Context, equipment_id int) error
-	UpdateEquipment(ctx context.Context, equipment models.Equipment) error
-	SignURL(ctx context.Context, imagePath string) (*minio.Object, error)
+	DeleteEquipment(ctx context.Context, equipmentId int, admin uuid.UUID, reason string, cancelBookings bool) (int, error)
+	SetStatus(ctx context.Context, equipmentId int, status, reason string) error
+	AddImage(ctx context.Context, image models.EquipmentImage, file *multipart.FileHeader) (*models.EquipmentImage, error)
+	Images(ctx context.Context, equipmentId int) ([]models.EquipmentImage, error)
+	UpdateImage(ctx context.Context, equipmentId, imageId int, update models.EquipmentImageUpdate) (*models.EquipmentImage, error)
+	ReorderImages(ctx context.Context, equipmentId int, imageIds []int) error
+	DeleteImage(ctx context.Context, equipmentId, imageId int) error
+	UpdateEquipment(ctx context.Context, equipmentId int, update models.EquipmentUpdate, image *multipart.FileHeader) (*models.Equipment, error)
+	Image(ctx context.Context, objectName, size string, expires int64, signature string) (*minio.Object, minio.ObjectInfo, error)
+	SetOperatingHours(ctx context.Context, equipmentId int, hours []models.OperatingHours) error
+	OperatingHours(ctx context.Context, equipmentId int) ([]models.OperatingHours, error)
+	SetForm(ctx context.Context, equipmentId int, fields []models.FormField) error
+	Form(ctx context.Context, equipmentId int) ([]models.FormField, error)
+	SetTimeZone(ctx context.Context, equipmentId int, timeZone string) error
+	SetAllocationMode(ctx context.Context, equipmentId int, mode string) error
+	SetRequiresOperator(ctx context.Context, equipmentId int, required bool) error
+	SetRoom(ctx context.Context, equipmentId int, room string) error
+	SetCategory(ctx context.Context, equipmentId int, categoryId *int) error
+	SetTags(ctx context.Context, equipmentId int, tags []string) error
+	BrowseEquipment(ctx context.Context, filter models.EquipmentFilter) (*models.EquipmentBrowse, error)
+	SearchEquipment(ctx context.Context, query string, after *models.EquipmentSearchCursor, limit int) (*pagination.Page[models.EquipmentSearchHit], error)
+	Suggest(ctx context.Context, prefix string, limit int) ([]models.Suggestion, error)
+	AvailableEquipment(ctx context.Context, filter models.AvailabilityFilter) ([]models.AvailableEquipment, error)
+	SetPreemptionRule(ctx context.Context, rule models.PreemptionRule) error
+	PreemptionRule(ctx context.Context, equipmentId int) (*models.PreemptionRule, error)
+	DeletePreemptionRule(ctx context.Context, equipmentId int) error
 }
 
-func NewEquipmentService(log *slog.Logger, repo repository.LabRepositroy, mini repository.ImageRepositoryInterface) EquipmentService {
-	return EquipmentService{log: log, repo: repo, mini: mini}
+func NewEquipmentService(log *slog.Logger, repo repository.LabRepositroy, mini repository.ImageRepositoryInterface,
+	cache repository.SuggestionCacheInterface) EquipmentService {
+	return EquipmentService{log: log, repo: repo, mini: mini, cache: cache}
 }
 
-func (e *EquipmentService) SignURL(ctx context.Context, imagePath string) (*minio.Object, error) {
-	const op = "equipment_service.SignURL"
-	obj, err := e.mini.SignURL(ctx, imagePath)
+// Image opens the image in the requested size: thumb, medium or the original when empty.
+// Only links signed by ImageURL that haven't expired are served
+func (e *EquipmentService) Image(ctx context.Context, objectName, size string, expires int64, signature string) (*minio.Object, minio.ObjectInfo, error) {
+	const op = "equipment_service.Image"
+	if size == "original" {
+		size = ""
+	}
+	if size != "" && !slices.ContainsFunc(imaging.Renditions, func(r imaging.Rendition) bool { return r.Name == size }) {
+		return nil, minio.ObjectInfo{}, fmt.Errorf("%s: %w", op, ErrInvalidImageSize)
+	}
+	if !e.mini.VerifyImageURL(objectName, size, expires, signature) {
+		return nil, minio.ObjectInfo{}, fmt.Errorf("%s: %w", op, ErrInvalidImageSignature)
+	}
+	obj, info, err := e.mini.Image(ctx, objectName, size)
 	if err != nil {
-		e.log.Error("signing image to s3 storage error", slog.String("error", err.Error()))
-		return nil, fmt.Errorf("%s, %w", op, err)
+		if errors.Is(err, repository.ErrImageNotFound) {
+			return nil, minio.ObjectInfo{}, fmt.Errorf("%s: %w", op, ErrImageNotFound)
+		}
+		e.log.Error("getting image from s3 storage error", slog.String("error", err.Error()))
+		return nil, minio.ObjectInfo{}, fmt.Errorf("%s, %w", op, err)
 	}
-	return obj, nil
+	return obj, info, nil
 }
 
-func (e *EquipmentService) CreateEquipment(ctx context.Context, equipment models.Equipment, image *multipart.FileHeader) (int, error) {
+// CreateEquipment adds the equipment with an optional gallery, the first image is the primary one
+func (e *EquipmentService) CreateEquipment(ctx context.Context, equipment models.Equipment, images []*multipart.FileHeader) (int, error) {
 	const op = "equipment_service.CreateEquipment"
 	e.log.Info("creating equipment", slog.String("equipment_name", equipment.EquipmentName))
-	e.log.Info("adding image to s3 storage", slog.String("image", image.Filename))
-	url, err := e.mini.AddImage(ctx, image)
-	if err != nil {
-		e.log.Error("adding image to s3 storage error", slog.String("error", err.Error()))
-		return 0, fmt.Errorf("%s, %w", op, err)
+	if equipment.TimeZone == "" {
+		equipment.TimeZone = "UTC"
+	}
+	if !validTimeZone(equipment.TimeZone) {
+		return 0, fmt.Errorf("%s: %w", op, ErrInvalidTimeZone)
+	}
+	equipment.Room = strings.TrimSpace(equipment.Room)
+	if len(equipment.Room) > maxRoomLength {
+		return 0, fmt.Errorf("%s: %w", op, ErrInvalidRoom)
+	}
+	if len(images) > maxEquipmentImages {
+		return 0, fmt.Errorf("%s: %w", op, ErrTooManyImages)
+	}
+	objects := make([]string, 0, len(images))
+	for _, image := range images {
+		e.log.Info("adding image to s3 storage", slog.String("image", image.Filename))
+		url, err := e.mini.AddImage(ctx, image)
+		if err != nil {
+			for _, object := range objects {
+				e.removeImage(ctx, e.log, object)
+			}
+			if errors.Is(err, imaging.ErrTooLarge) {
+				return 0, fmt.Errorf("%s: %w", op, ErrImageTooLarge)
+			}
+			if errors.Is(err, imaging.ErrUnsupportedType) || errors.Is(err, imaging.ErrInvalidImage) {
+				return 0, fmt.Errorf("%s: %w", op, ErrUnsupportedImage)
+			}
+			e.log.Error("adding image to s3 storage error", slog.String("error", err.Error()))
+			return 0, fmt.Errorf("%s, %w", op, err)
+		}
+		objects = append(objects, url)
 	}
-	/*
-		Логика замены equipment.ImageURL url из минио
-	*/
-	equipment.ImageURL = url
 
-	id, err := e.repo.CreateEquipment(ctx, equipment)
+	id, err := e.repo.CreateEquipment(ctx, equipment, objects)
 	if err != nil {
 		e.log.Error("creating equipment error", slog.String("error", err.Error()))
+		for _, object := range objects {
+			e.removeImage(ctx, e.log, object)
+		}
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
+	invalidateSuggestions(ctx, e.cache, e.log)
 	return id, nil
 }
 
@@ -70,41 +169,158 @@ func (e *EquipmentService) Equipment(ctx context.Context, equipment_id int) (*mo
 		e.log.Error("getting equipment error", slog.String("error", err.Error()))
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	eq.Images, err = e.repo.EquipmentImages(ctx, equipment_id)
+	if err != nil {
+		e.log.Error("getting equipment images error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	e.signImages(eq)
 	return eq, nil
 }
 
-func (e *EquipmentService) DeleteEquipment(ctx context.Context, equipment_id int) error {
+// DeleteEquipment retires the equipment for good, its row and bookings stay for the history
+// while its images are removed from the storage.
+// It is refused while bookings haven't ended unless cancelBookings is set, then they are
+// cancelled and their owners notified. It returns the number of cancelled bookings
+func (e *EquipmentService) DeleteEquipment(ctx context.Context, equipmentId int, admin uuid.UUID, reason string,
+	cancelBookings bool) (int, error) {
 	const op = "equipment_service.DeleteEquipment"
-	e.log.Info("deleting equipment", slog.Int("equipment_id", equipment_id))
-
-	eq, err := e.repo.Equipment(ctx, equipment_id)
+	log := e.log.With(slog.String("op", op))
+	log.Info("retiring equipment", slog.Int("equipment_id", equipmentId), slog.String("admin", admin.String()),
+		slog.Bool("cancel_bookings", cancelBookings))
+	reason = strings.TrimSpace(reason)
+	if len(reason) > maxStatusReasonLength {
+		return 0, fmt.Errorf("%s: %w", op, ErrInvalidStatus)
+	}
+	cancelled, err := e.repo.RetireEquipment(ctx, equipmentId, admin, reason, cancelBookings)
 	if err != nil {
-		e.log.Error("getting equipment error", slog.String("error", err.Error()))
-		return fmt.Errorf("%s: %w", op, err)
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return 0, fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		if errors.Is(err, repository.ErrEquipmentHasBookings) {
+			return 0, fmt.Errorf("%s: %w", op, ErrEquipmentHasBookings)
+		}
+		log.Error("retiring equipment error", slog.String("error", err.Error()))
+		return 0, fmt.Errorf("%s: %w", op, err)
 	}
-
-	err = e.mini.DeleteImage(ctx, eq.ImageURL)
+	if len(cancelled) > 0 {
+		log.Info("bookings cancelled", slog.Int("equipment_id", equipmentId), slog.Int("count", len(cancelled)))
+	}
+	// the equipment is retired already, images left behind are only garbage in the bucket
+	objects, err := e.repo.DeleteEquipmentImages(ctx, equipmentId)
 	if err != nil {
-		e.log.Error("deleting image in miniO error", slog.String("error", err.Error()))
-		return fmt.Errorf("%s: %w", op, err)
+		log.Error("deleting equipment images error", slog.String("error", err.Error()))
 	}
-	err = e.repo.DeleteEquipment(ctx, equipment_id)
+	for _, object := range objects {
+		e.removeImage(ctx, log, object)
+	}
+	invalidateSuggestions(ctx, e.cache, e.log)
+	return len(cancelled), nil
+}
+
+// SetStatus marks the equipment as active, under maintenance or out of order, only active
+// equipment can be booked. Retiring goes through DeleteEquipment, which takes care of the bookings,
+// and is final
+func (e *EquipmentService) SetStatus(ctx context.Context, equipmentId int, status, reason string) error {
+	const op = "equipment_service.SetStatus"
+	log := e.log.With(slog.String("op", op))
+	log.Info("setting equipment status", slog.Int("equipment_id", equipmentId), slog.String("status", status))
+	reason = strings.TrimSpace(reason)
+	switch status {
+	case models.EquipmentActive, models.EquipmentMaintenance, models.EquipmentOutOfOrder:
+	default:
+		return fmt.Errorf("%s: %w", op, ErrInvalidStatus)
+	}
+	if len(reason) > maxStatusReasonLength {
+		return fmt.Errorf("%s: %w", op, ErrInvalidStatus)
+	}
+	err := e.repo.SetEquipmentStatus(ctx, equipmentId, status, reason)
 	if err != nil {
-		e.log.Error("deleting equipment error", slog.String("error", err.Error()))
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		if errors.Is(err, repository.ErrEquipmentRetired) {
+			return fmt.Errorf("%s: %w", op, ErrEquipmentRetired)
+		}
+		log.Error("setting equipment status error", slog.String("error", err.Error()))
 		return fmt.Errorf("%s: %w", op, err)
 	}
 	return nil
 }
 
-func (e *EquipmentService) UpdateEquipment(ctx context.Context, equipment models.Equipment) error {
+// UpdateEquipment applies a partial update based on update.Version. A new image is uploaded
+// before the update and the old one is only removed once the update is committed, so the
+// equipment never points to a missing object
+func (e *EquipmentService) UpdateEquipment(ctx context.Context, equipmentId int, update models.EquipmentUpdate,
+	image *multipart.FileHeader) (*models.Equipment, error) {
 	const op = "equipment_service.UpdateEquipment"
-	e.log.Info("updating equipment", slog.Int("equipment_id", equipment.EquipmentId))
-	err := e.repo.UpdateEquipment(ctx, equipment)
+	log := e.log.With(slog.String("op", op))
+	log.Info("updating equipment", slog.Int("equipment_id", equipmentId), slog.Int("version", update.Version))
+	if update.EquipmentName != nil {
+		name := strings.TrimSpace(*update.EquipmentName)
+		if name == "" || len(name) > maxEquipmentNameLength {
+			return nil, fmt.Errorf("%s: %w", op, ErrInvalidEquipment)
+		}
+		update.EquipmentName = &name
+	}
+	if (update.Manufacturer != nil && len(*update.Manufacturer) > maxManufacturerLength) ||
+		(update.Description != nil && len(*update.Description) > maxDescriptionLength) ||
+		(update.Capacity != nil && *update.Capacity <= 0) {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidEquipment)
+	}
+	if update.EquipmentName == nil && update.Manufacturer == nil && update.Description == nil && update.Capacity == nil &&
+		image == nil {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidEquipment)
+	}
+	update.ImageURL = nil
+	if image != nil {
+		log.Info("adding image to s3 storage", slog.String("image", image.Filename))
+		url, err := e.mini.AddImage(ctx, image)
+		if err != nil {
+			if errors.Is(err, imaging.ErrTooLarge) {
+				return nil, fmt.Errorf("%s: %w", op, ErrImageTooLarge)
+			}
+			if errors.Is(err, imaging.ErrUnsupportedType) || errors.Is(err, imaging.ErrInvalidImage) {
+				return nil, fmt.Errorf("%s: %w", op, ErrUnsupportedImage)
+			}
+			log.Error("adding image to s3 storage error", slog.String("error", err.Error()))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		update.ImageURL = &url
+	}
+	equipment, oldImage, err := e.repo.UpdateEquipment(ctx, equipmentId, update)
 	if err != nil {
-		e.log.Error("updating equipment error", slog.String("error", err.Error()))
-		return fmt.Errorf("%s: %w", op, err)
+		if update.ImageURL != nil {
+			e.removeImage(ctx, log, *update.ImageURL)
+		}
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		if errors.Is(err, repository.ErrEquipmentModified) {
+			return nil, fmt.Errorf("%s: %w", op, ErrEquipmentModified)
+		}
+		if errors.Is(err, repository.ErrEquipmentRetired) {
+			return nil, fmt.Errorf("%s: %w", op, ErrEquipmentRetired)
+		}
+		log.Error("updating equipment error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if update.ImageURL != nil && oldImage != "" && oldImage != *update.ImageURL {
+		e.removeImage(ctx, log, oldImage)
+	}
+	if update.EquipmentName != nil || update.Manufacturer != nil {
+		invalidateSuggestions(ctx, e.cache, log)
+	}
+	e.signImages(equipment)
+	return equipment, nil
+}
+
+// removeImage deletes an object nothing refers to anymore, a failure only leaves garbage in the bucket
+func (e *EquipmentService) removeImage(ctx context.Context, log *slog.Logger, objectName string) {
+	err := e.mini.DeleteImage(ctx, objectName)
+	if err != nil {
+		log.Error("deleting image in miniO error", slog.String("image", objectName), slog.String("error", err.Error()))
 	}
-	return nil
 }
 
 func (e *EquipmentService) EquipmentByName(ctx context.Context, equipmentName string) ([]models.Equipment, error) {
@@ -116,5 +332,201 @@ func (e *EquipmentService) EquipmentByName(ctx context.Context, equipmentName st
 		log.Error("getting eq equipment by name error", slog.String("error", err.Error()))
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	for i := range eqs {
+		e.signImages(&eqs[i])
+	}
 	return eqs, nil
 }
+
+func (e *EquipmentService) SetOperatingHours(ctx context.Context, equipmentId int, hours []models.OperatingHours) error {
+	const op = "equipment_service.SetOperatingHours"
+	log := e.log.With(slog.String("op", op))
+	log.Info("setting operating hours", slog.Int("equipment_id", equipmentId))
+	seen := make(map[int]bool)
+	for _, h := range hours {
+		open, err := time.Parse("15:04", h.OpenTime)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, ErrInvalidOperatingHours)
+		}
+		closing, err := time.Parse("15:04", h.CloseTime)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, ErrInvalidOperatingHours)
+		}
+		if h.Weekday < 1 || h.Weekday > 7 || seen[h.Weekday] || !closing.After(open) {
+			return fmt.Errorf("%s: %w", op, ErrInvalidOperatingHours)
+		}
+		seen[h.Weekday] = true
+	}
+	err := e.repo.SetOperatingHours(ctx, equipmentId, hours)
+	if err != nil {
+		log.Error("setting operating hours error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (e *EquipmentService) OperatingHours(ctx context.Context, equipmentId int) ([]models.OperatingHours, error) {
+	const op = "equipment_service.OperatingHours"
+	hours, err := e.repo.OperatingHours(ctx, equipmentId)
+	if err != nil {
+		e.log.Error("getting operating hours error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return hours, nil
+}
+
+func (e *EquipmentService) SetForm(ctx context.Context, equipmentId int, fields []models.FormField) error {
+	const op = "equipment_service.SetForm"
+	log := e.log.With(slog.String("op", op))
+	log.Info("setting booking form", slog.Int("equipment_id", equipmentId), slog.Int("fields", len(fields)))
+	err := validateForm(fields)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	err = e.repo.SetFormFields(ctx, equipmentId, fields)
+	if err != nil {
+		log.Error("setting booking form error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (e *EquipmentService) Form(ctx context.Context, equipmentId int) ([]models.FormField, error) {
+	const op = "equipment_service.Form"
+	fields, err := e.repo.FormFields(ctx, equipmentId)
+	if err != nil {
+		e.log.Error("getting booking form error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return fields, nil
+}
+
+// validTimeZone accepts IANA zone names like Europe/Moscow
+func validTimeZone(name string) bool {
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+func (e *EquipmentService) SetTimeZone(ctx context.Context, equipmentId int, timeZone string) error {
+	const op = "equipment_service.SetTimeZone"
+	log := e.log.With(slog.String("op", op))
+	log.Info("setting equipment time zone", slog.Int("equipment_id", equipmentId), slog.String("time_zone", timeZone))
+	if !validTimeZone(timeZone) {
+		return fmt.Errorf("%s: %w", op, ErrInvalidTimeZone)
+	}
+	err := e.repo.SetTimeZone(ctx, equipmentId, timeZone)
+	if err != nil {
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		log.Error("setting equipment time zone error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (e *EquipmentService) SetAllocationMode(ctx context.Context, equipmentId int, mode string) error {
+	const op = "equipment_service.SetAllocationMode"
+	log := e.log.With(slog.String("op", op))
+	log.Info("setting allocation mode", slog.Int("equipment_id", equipmentId), slog.String("mode", mode))
+	if mode != models.AllocationDirect && mode != models.AllocationLottery {
+		return fmt.Errorf("%s: %w", op, ErrInvalidAllocationMode)
+	}
+	err := e.repo.SetAllocationMode(ctx, equipmentId, mode)
+	if err != nil {
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		log.Error("setting allocation mode error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// SetRequiresOperator only affects new bookings, existing ones keep their operator or lack of one
+func (e *EquipmentService) SetRequiresOperator(ctx context.Context, equipmentId int, required bool) error {
+	const op = "equipment_service.SetRequiresOperator"
+	log := e.log.With(slog.String("op", op))
+	log.Info("setting operator requirement", slog.Int("equipment_id", equipmentId), slog.Bool("required", required))
+	err := e.repo.SetRequiresOperator(ctx, equipmentId, required)
+	if err != nil {
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		log.Error("setting operator requirement error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (e *EquipmentService) SetRoom(ctx context.Context, equipmentId int, room string) error {
+	const op = "equipment_service.SetRoom"
+	log := e.log.With(slog.String("op", op))
+	log.Info("setting equipment room", slog.Int("equipment_id", equipmentId), slog.String("room", room))
+	room = strings.TrimSpace(room)
+	if len(room) > maxRoomLength {
+		return fmt.Errorf("%s: %w", op, ErrInvalidRoom)
+	}
+	err := e.repo.SetRoom(ctx, equipmentId, room)
+	if err != nil {
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		log.Error("setting equipment room error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// SetPreemptionRule enables preemption on the equipment, the priority margin defaults to 1
+func (e *EquipmentService) SetPreemptionRule(ctx context.Context, rule models.PreemptionRule) error {
+	const op = "equipment_service.SetPreemptionRule"
+	log := e.log.With(slog.String("op", op))
+	log.Info("setting preemption rule", slog.Int("equipment_id", rule.EquipmentId), slog.Int("min_priority", rule.MinPriority))
+	if rule.PriorityMargin == 0 {
+		rule.PriorityMargin = 1
+	}
+	if rule.MinPriority < 0 || rule.PriorityMargin < 0 || rule.MinNoticeMinutes < 0 {
+		return fmt.Errorf("%s: %w", op, ErrInvalidPreemptionRule)
+	}
+	err := e.repo.SetPreemptionRule(ctx, rule)
+	if err != nil {
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		log.Error("setting preemption rule error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (e *EquipmentService) PreemptionRule(ctx context.Context, equipmentId int) (*models.PreemptionRule, error) {
+	const op = "equipment_service.PreemptionRule"
+	rule, err := e.repo.PreemptionRule(ctx, equipmentId)
+	if err != nil {
+		if errors.Is(err, repository.ErrPreemptionRuleNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, ErrPreemptionRuleNotFound)
+		}
+		e.log.Error("getting preemption rule error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return rule, nil
+}
+
+func (e *EquipmentService) DeletePreemptionRule(ctx context.Context, equipmentId int) error {
+	const op = "equipment_service.DeletePreemptionRule"
+	log := e.log.With(slog.String("op", op))
+	log.Info("deleting preemption rule", slog.Int("equipment_id", equipmentId))
+	err := e.repo.DeletePreemptionRule(ctx, equipmentId)
+	if err != nil {
+		if errors.Is(err, repository.ErrPreemptionRuleNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrPreemptionRuleNotFound)
+		}
+		log.Error("deleting preemption rule error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
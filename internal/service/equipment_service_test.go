@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEquipmentRepo struct {
+	repository.LabRepositroy
+	status    map[int]string
+	images    map[int][]string
+	cancelled []models.Booking
+	imagesErr error
+}
+
+func (f *fakeEquipmentRepo) RetireEquipment(ctx context.Context, equipmentId int, admin uuid.UUID, reason string,
+	cancelBookings bool) ([]models.Booking, error) {
+	if _, ok := f.status[equipmentId]; !ok {
+		return nil, repository.ErrEquipmentNotFound
+	}
+	if len(f.cancelled) > 0 && !cancelBookings {
+		return nil, repository.ErrEquipmentHasBookings
+	}
+	f.status[equipmentId] = models.EquipmentRetired
+	return f.cancelled, nil
+}
+
+func (f *fakeEquipmentRepo) DeleteEquipmentImages(ctx context.Context, equipmentId int) ([]string, error) {
+	if f.imagesErr != nil {
+		return nil, f.imagesErr
+	}
+	objects := f.images[equipmentId]
+	delete(f.images, equipmentId)
+	return objects, nil
+}
+
+func (f *fakeEquipmentRepo) SetEquipmentStatus(ctx context.Context, equipmentId int, status, reason string) error {
+	current, ok := f.status[equipmentId]
+	if !ok {
+		return repository.ErrEquipmentNotFound
+	}
+	if current == models.EquipmentRetired {
+		return repository.ErrEquipmentRetired
+	}
+	f.status[equipmentId] = status
+	return nil
+}
+
+type fakeImageRepo struct {
+	repository.ImageRepositoryInterface
+	deleted []string
+}
+
+func (f *fakeImageRepo) DeleteImage(ctx context.Context, objectName string) error {
+	f.deleted = append(f.deleted, objectName)
+	return nil
+}
+
+type fakeSuggestionCache struct {
+	repository.SuggestionCacheInterface
+	invalidated int
+}
+
+func (f *fakeSuggestionCache) InvalidateSuggestions(ctx context.Context) error {
+	f.invalidated++
+	return nil
+}
+
+func TestDeleteEquipment(t *testing.T) {
+	gallery := []string{"a.jpg", "b.png", "c.webp"}
+	cases := []struct {
+		name             string
+		equipmentId      int
+		cancelled        []models.Booking
+		cancelBookings   bool
+		imagesErr        error
+		expectedErr      error
+		expectedCount    int
+		expectedDeletion []string
+	}{
+		{name: "removes every gallery object", equipmentId: 1, expectedDeletion: gallery},
+		{name: "cancels bookings", equipmentId: 1, cancelled: []models.Booking{{Id: 7}, {Id: 8}}, cancelBookings: true,
+			expectedCount: 2, expectedDeletion: gallery},
+		{name: "bookings left", equipmentId: 1, cancelled: []models.Booking{{Id: 7}}, expectedErr: ErrEquipmentHasBookings},
+		{name: "not found", equipmentId: 2, expectedErr: ErrEquipmentNotFound},
+		{name: "gallery error still retires", equipmentId: 1, imagesErr: errors.New("db down")},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeEquipmentRepo{status: map[int]string{1: models.EquipmentActive},
+				images: map[int][]string{1: gallery}, cancelled: tt.cancelled, imagesErr: tt.imagesErr}
+			mini := &fakeImageRepo{}
+			cache := &fakeSuggestionCache{}
+			srv := NewEquipmentService(slog.New(slog.DiscardHandler), repo, mini, cache)
+			count, err := srv.DeleteEquipment(context.Background(), tt.equipmentId, uuid.New(), "broken", tt.cancelBookings)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, mini.deleted)
+				assert.Equal(t, models.EquipmentActive, repo.status[1])
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCount, count)
+			assert.Equal(t, models.EquipmentRetired, repo.status[tt.equipmentId])
+			assert.Equal(t, tt.expectedDeletion, mini.deleted)
+			assert.Equal(t, 1, cache.invalidated)
+		})
+	}
+}
+
+func TestSetStatus(t *testing.T) {
+	cases := []struct {
+		name        string
+		equipmentId int
+		status      string
+		expectedErr error
+	}{
+		{name: "maintenance", equipmentId: 1, status: models.EquipmentMaintenance},
+		{name: "back to active", equipmentId: 2, status: models.EquipmentActive},
+		{name: "retiring", equipmentId: 1, status: models.EquipmentRetired, expectedErr: ErrInvalidStatus},
+		{name: "unknown status", equipmentId: 1, status: "lost", expectedErr: ErrInvalidStatus},
+		{name: "retired stays retired", equipmentId: 3, status: models.EquipmentActive, expectedErr: ErrEquipmentRetired},
+		{name: "not found", equipmentId: 4, status: models.EquipmentActive, expectedErr: ErrEquipmentNotFound},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeEquipmentRepo{status: map[int]string{1: models.EquipmentActive, 2: models.EquipmentOutOfOrder,
+				3: models.EquipmentRetired}}
+			before := repo.status[tt.equipmentId]
+			srv := NewEquipmentService(slog.New(slog.DiscardHandler), repo, &fakeImageRepo{}, &fakeSuggestionCache{})
+			err := srv.SetStatus(context.Background(), tt.equipmentId, tt.status, "")
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Equal(t, before, repo.status[tt.equipmentId])
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, repo.status[tt.equipmentId])
+		})
+	}
+}
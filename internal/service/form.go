@@ -0,0 +1,105 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/Gergenus/bookingService/internal/models"
+)
+
+// sizes of the form field columns, in characters
+const (
+	maxFieldNameLength  = 50
+	maxFieldLabelLength = 255
+)
+
+var (
+	ErrInvalidForm     = errors.New("invalid form")
+	ErrInvalidMetadata = errors.New("invalid metadata")
+)
+
+// FieldError tells which booking form field was rejected and why
+type FieldError struct {
+	Field  string
+	Reason string
+}
+
+func (f *FieldError) Error() string {
+	return fmt.Sprintf("%s: field %s %s", ErrInvalidMetadata, f.Field, f.Reason)
+}
+
+func (f *FieldError) Unwrap() error {
+	return ErrInvalidMetadata
+}
+
+func validateForm(fields []models.FormField) error {
+	names := make(map[string]bool)
+	for _, f := range fields {
+		if f.Name == "" || f.Label == "" || names[f.Name] || utf8.RuneCountInString(f.Name) > maxFieldNameLength ||
+			utf8.RuneCountInString(f.Label) > maxFieldLabelLength {
+			return ErrInvalidForm
+		}
+		names[f.Name] = true
+		switch f.Type {
+		case models.FieldText, models.FieldNumber, models.FieldCheckbox:
+		case models.FieldSelect:
+			if len(f.Options) == 0 {
+				return ErrInvalidForm
+			}
+		default:
+			return ErrInvalidForm
+		}
+	}
+	return nil
+}
+
+// validateMetadata checks booking metadata against the equipment form. Values
+// come from decoded JSON, so numbers are float64
+func validateMetadata(fields []models.FormField, metadata map[string]any) error {
+	known := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		known[f.Name] = true
+		value, ok := metadata[f.Name]
+		if !ok || value == nil {
+			if f.Required {
+				return &FieldError{Field: f.Name, Reason: "is required"}
+			}
+			continue
+		}
+		switch f.Type {
+		case models.FieldText:
+			s, ok := value.(string)
+			if !ok {
+				return &FieldError{Field: f.Name, Reason: "must be a string"}
+			}
+			if f.Required && s == "" {
+				return &FieldError{Field: f.Name, Reason: "is required"}
+			}
+		case models.FieldNumber:
+			if _, ok := value.(float64); !ok {
+				return &FieldError{Field: f.Name, Reason: "must be a number"}
+			}
+		case models.FieldCheckbox:
+			checked, ok := value.(bool)
+			if !ok {
+				return &FieldError{Field: f.Name, Reason: "must be a boolean"}
+			}
+			if f.Required && !checked {
+				return &FieldError{Field: f.Name, Reason: "must be checked"}
+			}
+		case models.FieldSelect:
+			s, ok := value.(string)
+			if !ok || !slices.Contains(f.Options, s) {
+				return &FieldError{Field: f.Name, Reason: "must be one of the options"}
+			}
+		}
+	}
+	for name := range metadata {
+		if !known[name] {
+			return &FieldError{Field: name, Reason: "is unknown"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateForm(t *testing.T) {
+	field := func(name, label, kind string, options ...string) models.FormField {
+		return models.FormField{Name: name, Label: label, Type: kind, Options: options}
+	}
+	tests := []struct {
+		name        string
+		fields      []models.FormField
+		expectedErr error
+	}{
+		{
+			name:   "empty form",
+			fields: nil,
+		},
+		{
+			name: "valid",
+			fields: []models.FormField{field("sample", "Образец", models.FieldText), field("stain", "Stain", models.FieldSelect, "none", "gold"),
+				field("dose", "Dose", models.FieldNumber), field("safety", "Safety briefing", models.FieldCheckbox)},
+		},
+		{
+			name:   "longest name and label",
+			fields: []models.FormField{field(strings.Repeat("н", maxFieldNameLength), strings.Repeat("л", maxFieldLabelLength), models.FieldText)},
+		},
+		{
+			name:        "name too long",
+			fields:      []models.FormField{field(strings.Repeat("n", maxFieldNameLength+1), "Label", models.FieldText)},
+			expectedErr: ErrInvalidForm,
+		},
+		{
+			name:        "label too long",
+			fields:      []models.FormField{field("name", strings.Repeat("l", maxFieldLabelLength+1), models.FieldText)},
+			expectedErr: ErrInvalidForm,
+		},
+		{
+			name:        "missing label",
+			fields:      []models.FormField{field("name", "", models.FieldText)},
+			expectedErr: ErrInvalidForm,
+		},
+		{
+			name:        "duplicate name",
+			fields:      []models.FormField{field("name", "One", models.FieldText), field("name", "Two", models.FieldNumber)},
+			expectedErr: ErrInvalidForm,
+		},
+		{
+			name:        "select without options",
+			fields:      []models.FormField{field("stain", "Stain", models.FieldSelect)},
+			expectedErr: ErrInvalidForm,
+		},
+		{
+			name:        "unknown type",
+			fields:      []models.FormField{field("file", "File", "file")},
+			expectedErr: ErrInvalidForm,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateForm(tt.fields)
+			if tt.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrGroupAlreadyExists = errors.New("group already exists")
+	ErrGroupNotFound      = errors.New("group not found")
+	ErrNotGroupAdmin      = errors.New("group admin access required")
+	ErrNotGroupPI         = errors.New("group PI access required")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrInvalidQuota       = errors.New("invalid quota")
+	ErrInvalidShare       = errors.New("invalid share")
+)
+
+type GroupService struct {
+	repo repository.GroupRepositoryInterface
+	log  *slog.Logger
+}
+
+// sysAdmin marks callers with the admin role, they may manage every group
+type GroupServiceInterface interface {
+	CreateGroup(ctx context.Context, group models.Group) (int, error)
+	UserGroups(ctx context.Context, uid uuid.UUID) ([]models.Group, error)
+	Members(ctx context.Context, groupId int, actor uuid.UUID, sysAdmin bool) ([]models.GroupMember, error)
+	AddMember(ctx context.Context, member models.GroupMember, actor uuid.UUID, sysAdmin bool) error
+	RemoveMember(ctx context.Context, groupId int, uid uuid.UUID, actor uuid.UUID, sysAdmin bool) error
+	SetQuota(ctx context.Context, groupId int, quotaHours *float64) error
+	SetShare(ctx context.Context, groupId int, share float64) error
+	Usage(ctx context.Context, groupId int, month time.Time, actor uuid.UUID, sysAdmin bool) (*models.GroupUsage, error)
+}
+
+func NewGroupService(repo repository.GroupRepositoryInterface, log *slog.Logger) GroupService {
+	return GroupService{repo: repo, log: log}
+}
+
+func (g *GroupService) CreateGroup(ctx context.Context, group models.Group) (int, error) {
+	const op = "group_service.CreateGroup"
+	log := g.log.With(slog.String("op", op))
+	log.Info("creating group", slog.String("name", group.Name))
+	if group.QuotaHours != nil && *group.QuotaHours < 0 {
+		return 0, fmt.Errorf("%s: %w", op, ErrInvalidQuota)
+	}
+	if group.Share == 0 {
+		group.Share = 1
+	}
+	if group.Share < 0 {
+		return 0, fmt.Errorf("%s: %w", op, ErrInvalidShare)
+	}
+	id, err := g.repo.CreateGroup(ctx, group)
+	if err != nil {
+		if errors.Is(err, repository.ErrGroupAlreadyExists) {
+			return 0, fmt.Errorf("%s: %w", op, ErrGroupAlreadyExists)
+		}
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return 0, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+		log.Error("creating group error", slog.String("error", err.Error()))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (g *GroupService) UserGroups(ctx context.Context, uid uuid.UUID) ([]models.Group, error) {
+	const op = "group_service.UserGroups"
+	groups, err := g.repo.UserGroups(ctx, uid)
+	if err != nil {
+		g.log.Error("getting user groups error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return groups, nil
+}
+
+// authorize checks that actor may see (adminOnly = false) or manage the group
+func (g *GroupService) authorize(ctx context.Context, groupId int, actor uuid.UUID, sysAdmin, adminOnly bool) error {
+	if sysAdmin {
+		_, err := g.repo.Group(ctx, groupId)
+		if errors.Is(err, repository.ErrGroupNotFound) {
+			return ErrGroupNotFound
+		}
+		return err
+	}
+	member, isAdmin, err := g.repo.Membership(ctx, groupId, actor)
+	if err != nil {
+		return err
+	}
+	if !member {
+		return ErrGroupNotFound
+	}
+	if adminOnly && !isAdmin {
+		return ErrNotGroupAdmin
+	}
+	return nil
+}
+
+// authorizeAdminChange checks that actor may grant or revoke the group admin rights of uid,
+// which is up to the PI. Other group admins only manage plain members
+func (g *GroupService) authorizeAdminChange(ctx context.Context, groupId int, uid uuid.UUID, grant bool, actor uuid.UUID, sysAdmin bool) error {
+	if sysAdmin {
+		return nil
+	}
+	_, isAdmin, err := g.repo.Membership(ctx, groupId, uid)
+	if err != nil {
+		return err
+	}
+	if !grant && !isAdmin {
+		return nil
+	}
+	group, err := g.repo.Group(ctx, groupId)
+	if err != nil {
+		if errors.Is(err, repository.ErrGroupNotFound) {
+			return ErrGroupNotFound
+		}
+		return err
+	}
+	if group.PIId == nil || *group.PIId != actor {
+		return ErrNotGroupPI
+	}
+	return nil
+}
+
+func (g *GroupService) Members(ctx context.Context, groupId int, actor uuid.UUID, sysAdmin bool) ([]models.GroupMember, error) {
+	const op = "group_service.Members"
+	err := g.authorize(ctx, groupId, actor, sysAdmin, false)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	members, err := g.repo.Members(ctx, groupId)
+	if err != nil {
+		g.log.Error("getting group members error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return members, nil
+}
+
+func (g *GroupService) AddMember(ctx context.Context, member models.GroupMember, actor uuid.UUID, sysAdmin bool) error {
+	const op = "group_service.AddMember"
+	log := g.log.With(slog.String("op", op))
+	log.Info("adding group member", slog.Int("group_id", member.GroupId), slog.String("user_id", member.UserId.String()))
+	err := g.authorize(ctx, member.GroupId, actor, sysAdmin, true)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	err = g.authorizeAdminChange(ctx, member.GroupId, member.UserId, member.IsAdmin, actor, sysAdmin)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	err = g.repo.AddMember(ctx, member)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+		log.Error("adding group member error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (g *GroupService) RemoveMember(ctx context.Context, groupId int, uid uuid.UUID, actor uuid.UUID, sysAdmin bool) error {
+	const op = "group_service.RemoveMember"
+	log := g.log.With(slog.String("op", op))
+	log.Info("removing group member", slog.Int("group_id", groupId), slog.String("user_id", uid.String()))
+	err := g.authorize(ctx, groupId, actor, sysAdmin, true)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	err = g.authorizeAdminChange(ctx, groupId, uid, false, actor, sysAdmin)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	err = g.repo.RemoveMember(ctx, groupId, uid)
+	if err != nil {
+		log.Error("removing group member error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (g *GroupService) SetQuota(ctx context.Context, groupId int, quotaHours *float64) error {
+	const op = "group_service.SetQuota"
+	log := g.log.With(slog.String("op", op))
+	log.Info("setting group quota", slog.Int("group_id", groupId))
+	if quotaHours != nil && *quotaHours < 0 {
+		return fmt.Errorf("%s: %w", op, ErrInvalidQuota)
+	}
+	err := g.repo.SetQuota(ctx, groupId, quotaHours)
+	if err != nil {
+		if errors.Is(err, repository.ErrGroupNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrGroupNotFound)
+		}
+		log.Error("setting group quota error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// SetShare sets the group's weight in the fair-share lottery
+func (g *GroupService) SetShare(ctx context.Context, groupId int, share float64) error {
+	const op = "group_service.SetShare"
+	log := g.log.With(slog.String("op", op))
+	log.Info("setting group share", slog.Int("group_id", groupId), slog.Float64("share", share))
+	if share <= 0 {
+		return fmt.Errorf("%s: %w", op, ErrInvalidShare)
+	}
+	err := g.repo.SetShare(ctx, groupId, share)
+	if err != nil {
+		if errors.Is(err, repository.ErrGroupNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrGroupNotFound)
+		}
+		log.Error("setting group share error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (g *GroupService) Usage(ctx context.Context, groupId int, month time.Time, actor uuid.UUID, sysAdmin bool) (*models.GroupUsage, error) {
+	const op = "group_service.Usage"
+	err := g.authorize(ctx, groupId, actor, sysAdmin, false)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	group, err := g.repo.Group(ctx, groupId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	hours, err := g.repo.BookedHours(ctx, groupId, from, from.AddDate(0, 1, 0))
+	if err != nil {
+		g.log.Error("getting group booked hours error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &models.GroupUsage{GroupId: groupId, Month: from.Format("2006-01"), UsedHours: hours, QuotaHours: group.QuotaHours}, nil
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/Gergenus/bookingService/pkg/hash"
+)
+
+const (
+	// device keys are made of this many random bytes
+	deviceKeyBytes = 32
+	// logins with a password are refused without checking it after this many failures within
+	// loginFailureWindow, per device and per login
+	maxDeviceLoginFailures = 20
+	maxLoginFailures       = 5
+	loginFailureWindow     = 15 * time.Minute
+)
+
+var (
+	ErrInvalidDeviceKey    = errors.New("invalid device key")
+	ErrDeviceNotFound      = errors.New("device not found")
+	ErrInvalidDevice       = errors.New("invalid device")
+	ErrCredentialsRequired = errors.New("badge id or login required")
+)
+
+type InterlockService struct {
+	repo        repository.InterlockRepositoryInterface
+	userRepo    repository.UserRepositoryInterface
+	bookingRepo repository.BookingRepositoryInterface
+	log         *slog.Logger
+}
+
+type InterlockServiceInterface interface {
+	CreateDevice(ctx context.Context, device models.Device) (*models.Device, string, error)
+	Devices(ctx context.Context, equipmentId int) ([]models.Device, error)
+	RevokeDevice(ctx context.Context, equipmentId, deviceId int) error
+	AuthenticateDevice(ctx context.Context, key string) (*models.Device, error)
+	Status(ctx context.Context, device models.Device) (*models.InterlockStatus, error)
+	Verify(ctx context.Context, device models.Device, badgeId, login, password string) (*models.InterlockDecision, error)
+	Decisions(ctx context.Context, equipmentId int, from, to time.Time) ([]models.InterlockDecision, error)
+}
+
+func NewInterlockService(repo repository.InterlockRepositoryInterface, userRepo repository.UserRepositoryInterface,
+	bookingRepo repository.BookingRepositoryInterface, log *slog.Logger) InterlockService {
+	return InterlockService{repo: repo, userRepo: userRepo, bookingRepo: bookingRepo, log: log}
+}
+
+// CreateDevice registers an interlock PC and returns its key. Only a digest of the key is
+// stored, so it can't be shown again
+func (i *InterlockService) CreateDevice(ctx context.Context, device models.Device) (*models.Device, string, error) {
+	const op = "interlock_service.CreateDevice"
+	log := i.log.With(slog.String("op", op))
+	log.Info("creating interlock device", slog.Int("equipment_id", device.EquipmentId), slog.String("name", device.Name))
+	if device.Name == "" || len(device.Name) > 255 {
+		return nil, "", fmt.Errorf("%s: %w", op, ErrInvalidDevice)
+	}
+	key, err := hash.NewToken(deviceKeyBytes)
+	if err != nil {
+		log.Error("generating device key error", slog.String("error", err.Error()))
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+	device.Id, err = i.repo.CreateDevice(ctx, device, hash.HashToken(key))
+	if err != nil {
+		if errors.Is(err, repository.ErrEquipmentNotFound) {
+			return nil, "", fmt.Errorf("%s: %w", op, ErrEquipmentNotFound)
+		}
+		log.Error("creating interlock device error", slog.String("error", err.Error()))
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+	device.CreatedAt = time.Now()
+	return &device, key, nil
+}
+
+func (i *InterlockService) Devices(ctx context.Context, equipmentId int) ([]models.Device, error) {
+	const op = "interlock_service.Devices"
+	devices, err := i.repo.Devices(ctx, equipmentId)
+	if err != nil {
+		i.log.Error("getting interlock devices error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return devices, nil
+}
+
+func (i *InterlockService) RevokeDevice(ctx context.Context, equipmentId, deviceId int) error {
+	const op = "interlock_service.RevokeDevice"
+	log := i.log.With(slog.String("op", op))
+	log.Info("revoking interlock device", slog.Int("equipment_id", equipmentId), slog.Int("device_id", deviceId))
+	err := i.repo.RevokeDevice(ctx, equipmentId, deviceId)
+	if err != nil {
+		if errors.Is(err, repository.ErrDeviceNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrDeviceNotFound)
+		}
+		log.Error("revoking interlock device error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (i *InterlockService) AuthenticateDevice(ctx context.Context, key string) (*models.Device, error) {
+	const op = "interlock_service.AuthenticateDevice"
+	if key == "" {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidDeviceKey)
+	}
+	device, err := i.repo.DeviceByKey(ctx, hash.HashToken(key))
+	if err != nil {
+		if errors.Is(err, repository.ErrDeviceNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, ErrInvalidDeviceKey)
+		}
+		i.log.Error("authenticating device error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return device, nil
+}
+
+// Status tells the device whose booking is running now or opens for check-in soon.
+// The status is empty when the equipment is free
+func (i *InterlockService) Status(ctx context.Context, device models.Device) (*models.InterlockStatus, error) {
+	const op = "interlock_service.Status"
+	log := i.log.With(slog.String("op", op))
+	status := &models.InterlockStatus{EquipmentId: device.EquipmentId}
+	booking, err := i.repo.ActiveBooking(ctx, device.EquipmentId, time.Now(), checkInAdvance)
+	if err != nil {
+		if errors.Is(err, repository.ErrBookingNotFound) {
+			return status, nil
+		}
+		log.Error("getting active booking error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	user, err := i.userRepo.User(ctx, booking.UserId)
+	if err != nil {
+		log.Error("getting booking owner error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	status.BookingId, status.UserId, status.Username = booking.Id, &booking.UserId, user.Username
+	status.OperatorId, status.StartTime, status.EndTime = booking.OperatorId, &booking.StartTime, &booking.EndTime
+	return status, nil
+}
+
+// Verify decides whether the user presenting a badge or a login may unlock the equipment:
+// they have to own the active booking or operate it. An allowed unlock checks the booking
+// in. Every decision is logged, if logging fails the unlock is refused with an error. The
+// logged failures throttle logins, so a device can't be used to guess passwords
+func (i *InterlockService) Verify(ctx context.Context, device models.Device, badgeId, login, password string) (*models.InterlockDecision, error) {
+	const op = "interlock_service.Verify"
+	log := i.log.With(slog.String("op", op))
+	if (badgeId == "") == (login == "") {
+		return nil, fmt.Errorf("%s: %w", op, ErrCredentialsRequired)
+	}
+	decision := models.InterlockDecision{DeviceId: &device.Id, EquipmentId: device.EquipmentId, CreatedAt: time.Now()}
+	err := i.decide(ctx, &decision, badgeId, login, password)
+	if err != nil {
+		log.Error("verifying interlock access error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	decision.Id, err = i.repo.LogDecision(ctx, decision)
+	if err != nil {
+		log.Error("logging interlock decision error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	log.Info("interlock decision", slog.Int("equipment_id", device.EquipmentId), slog.Int("device_id", device.Id),
+		slog.Bool("allowed", decision.Allowed), slog.String("reason", decision.Reason))
+	return &decision, nil
+}
+
+// decide fills the decision in, errors are only returned for failures, not for denials
+func (i *InterlockService) decide(ctx context.Context, decision *models.InterlockDecision, badgeId, login, password string) error {
+	var (
+		user *models.User
+		err  error
+	)
+	if badgeId != "" {
+		decision.Method, decision.Credential = models.InterlockByBadge, badgeId
+		user, err = i.userRepo.UserByBadge(ctx, badgeId)
+		if errors.Is(err, repository.ErrUserNotFound) {
+			decision.Reason = models.InterlockUnknownUser
+			return nil
+		}
+	} else {
+		decision.Method, decision.Credential = models.InterlockByLogin, login
+		var byDevice, byLogin int
+		byDevice, byLogin, err = i.repo.FailedLogins(ctx, *decision.DeviceId, login, decision.CreatedAt.Add(-loginFailureWindow))
+		if err != nil {
+			return err
+		}
+		if byDevice >= maxDeviceLoginFailures || byLogin >= maxLoginFailures {
+			decision.Reason = models.InterlockTooManyAttempts
+			return nil
+		}
+		user, err = i.userRepo.UserByEmail(ctx, login)
+		if errors.Is(err, repository.ErrUserNotFound) {
+			decision.Reason = models.InterlockInvalidCredentials
+			return nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+	decision.UserId = &user.UUID
+	if decision.Method == models.InterlockByLogin && !hash.CheckPassword(user.HashedPassword, password) {
+		decision.Reason = models.InterlockInvalidCredentials
+		return nil
+	}
+	now := time.Now()
+	booking, err := i.repo.ActiveBooking(ctx, decision.EquipmentId, now, checkInAdvance)
+	if err != nil {
+		if errors.Is(err, repository.ErrBookingNotFound) {
+			decision.Reason = models.InterlockNoBooking
+			return nil
+		}
+		return err
+	}
+	decision.BookingId = &booking.Id
+	if booking.UserId != user.UUID && (booking.OperatorId == nil || *booking.OperatorId != user.UUID) {
+		decision.Reason = models.InterlockNotBookingHolder
+		return nil
+	}
+	decision.Allowed, decision.Reason = true, models.InterlockAllowed
+	if booking.CheckedInAt == nil {
+		err = i.bookingRepo.CheckIn(ctx, booking.Id, now)
+		if err != nil && !errors.Is(err, repository.ErrBookingNotFound) {
+			return err
+		}
+		// ErrBookingNotFound means another unlock checked the booking in first
+		decision.CheckedIn = err == nil
+	}
+	return nil
+}
+
+func (i *InterlockService) Decisions(ctx context.Context, equipmentId int, from, to time.Time) ([]models.InterlockDecision, error) {
+	const op = "interlock_service.Decisions"
+	if !from.Before(to) || to.Sub(from) > maxSchedulePeriod {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidPeriod)
+	}
+	decisions, err := i.repo.Decisions(ctx, equipmentId, from, to)
+	if err != nil {
+		i.log.Error("getting interlock decisions error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return decisions, nil
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/Gergenus/bookingService/pkg/hash"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeInterlockRepo struct {
+	repository.InterlockRepositoryInterface
+	byDevice, byLogin int
+	failedErr         error
+	booking           *models.Booking
+}
+
+func (f *fakeInterlockRepo) FailedLogins(ctx context.Context, deviceId int, login string, since time.Time) (int, int, error) {
+	return f.byDevice, f.byLogin, f.failedErr
+}
+
+func (f *fakeInterlockRepo) ActiveBooking(ctx context.Context, equipmentId int, at time.Time, advance time.Duration) (*models.Booking, error) {
+	if f.booking == nil {
+		return nil, repository.ErrBookingNotFound
+	}
+	return f.booking, nil
+}
+
+func (f *fakeInterlockRepo) LogDecision(ctx context.Context, decision models.InterlockDecision) (int64, error) {
+	return 1, nil
+}
+
+type fakeUserRepo struct {
+	repository.UserRepositoryInterface
+	users map[string]*models.User
+	err   error
+}
+
+func (f *fakeUserRepo) user(key string) (*models.User, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	user, ok := f.users[key]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (f *fakeUserRepo) UserByBadge(ctx context.Context, badgeId string) (*models.User, error) {
+	return f.user(badgeId)
+}
+
+func (f *fakeUserRepo) UserByEmail(ctx context.Context, email string) (*models.User, error) {
+	return f.user(email)
+}
+
+type fakeCheckInRepo struct {
+	repository.BookingRepositoryInterface
+	checkedIn []int
+}
+
+func (f *fakeCheckInRepo) CheckIn(ctx context.Context, bookingId int, at time.Time) error {
+	f.checkedIn = append(f.checkedIn, bookingId)
+	return nil
+}
+
+func TestVerify(t *testing.T) {
+	hashed, err := hash.HashPassword("secret")
+	require.NoError(t, err)
+	owner := &models.User{UUID: uuid.New(), Email: "owner@lab.org", BadgeId: "B-1", HashedPassword: hashed}
+	operator := &models.User{UUID: uuid.New(), Email: "operator@lab.org", BadgeId: "B-2", HashedPassword: hashed}
+	stranger := &models.User{UUID: uuid.New(), Email: "stranger@lab.org", BadgeId: "B-3", HashedPassword: hashed}
+	users := map[string]*models.User{}
+	for _, u := range []*models.User{owner, operator, stranger} {
+		users[u.Email], users[u.BadgeId] = u, u
+	}
+	booking := func() *models.Booking {
+		return &models.Booking{Id: 7, UserId: owner.UUID, OperatorId: &operator.UUID}
+	}
+	checkedIn := time.Now()
+	dbErr := errors.New("connection reset")
+	tests := []struct {
+		name                     string
+		badgeId, login, password string
+		repo                     *fakeInterlockRepo
+		userErr                  error
+		expectedErr              error
+		expectedAllowed          bool
+		expectedReason           string
+		expectedUser             *models.User
+		expectedCheckedIn        bool
+	}{
+		{
+			name:        "no credentials",
+			repo:        &fakeInterlockRepo{},
+			expectedErr: ErrCredentialsRequired,
+		},
+		{
+			name:        "badge and login",
+			badgeId:     owner.BadgeId,
+			login:       owner.Email,
+			repo:        &fakeInterlockRepo{},
+			expectedErr: ErrCredentialsRequired,
+		},
+		{
+			name:              "owner badge checks in",
+			badgeId:           owner.BadgeId,
+			repo:              &fakeInterlockRepo{booking: booking()},
+			expectedAllowed:   true,
+			expectedReason:    models.InterlockAllowed,
+			expectedUser:      owner,
+			expectedCheckedIn: true,
+		},
+		{
+			name:              "operator badge",
+			badgeId:           operator.BadgeId,
+			repo:              &fakeInterlockRepo{booking: booking()},
+			expectedAllowed:   true,
+			expectedReason:    models.InterlockAllowed,
+			expectedUser:      operator,
+			expectedCheckedIn: true,
+		},
+		{
+			name:            "checked in booking",
+			badgeId:         owner.BadgeId,
+			repo:            &fakeInterlockRepo{booking: &models.Booking{Id: 7, UserId: owner.UUID, CheckedInAt: &checkedIn}},
+			expectedAllowed: true,
+			expectedReason:  models.InterlockAllowed,
+			expectedUser:    owner,
+		},
+		{
+			name:           "stranger badge",
+			badgeId:        stranger.BadgeId,
+			repo:           &fakeInterlockRepo{booking: booking()},
+			expectedReason: models.InterlockNotBookingHolder,
+			expectedUser:   stranger,
+		},
+		{
+			name:           "unknown badge",
+			badgeId:        "B-404",
+			repo:           &fakeInterlockRepo{booking: booking()},
+			expectedReason: models.InterlockUnknownUser,
+		},
+		{
+			name:           "no active booking",
+			badgeId:        owner.BadgeId,
+			repo:           &fakeInterlockRepo{},
+			expectedReason: models.InterlockNoBooking,
+			expectedUser:   owner,
+		},
+		{
+			name:        "badge lookup error",
+			badgeId:     owner.BadgeId,
+			repo:        &fakeInterlockRepo{booking: booking()},
+			userErr:     dbErr,
+			expectedErr: dbErr,
+		},
+		{
+			name:              "owner login",
+			login:             owner.Email,
+			password:          "secret",
+			repo:              &fakeInterlockRepo{booking: booking()},
+			expectedAllowed:   true,
+			expectedReason:    models.InterlockAllowed,
+			expectedUser:      owner,
+			expectedCheckedIn: true,
+		},
+		{
+			name:           "wrong password",
+			login:          owner.Email,
+			password:       "guess",
+			repo:           &fakeInterlockRepo{booking: booking()},
+			expectedReason: models.InterlockInvalidCredentials,
+			expectedUser:   owner,
+		},
+		{
+			name:           "unknown login",
+			login:          "nobody@lab.org",
+			password:       "secret",
+			repo:           &fakeInterlockRepo{booking: booking()},
+			expectedReason: models.InterlockInvalidCredentials,
+		},
+		{
+			name:           "too many failures of the login",
+			login:          owner.Email,
+			password:       "secret",
+			repo:           &fakeInterlockRepo{booking: booking(), byLogin: maxLoginFailures},
+			expectedReason: models.InterlockTooManyAttempts,
+		},
+		{
+			name:           "too many failures on the device",
+			login:          owner.Email,
+			password:       "secret",
+			repo:           &fakeInterlockRepo{booking: booking(), byDevice: maxDeviceLoginFailures},
+			expectedReason: models.InterlockTooManyAttempts,
+		},
+		{
+			name:        "failed logins error",
+			login:       owner.Email,
+			password:    "secret",
+			repo:        &fakeInterlockRepo{booking: booking(), failedErr: dbErr},
+			expectedErr: dbErr,
+		},
+		{
+			name:        "login lookup error",
+			login:       owner.Email,
+			password:    "secret",
+			repo:        &fakeInterlockRepo{booking: booking()},
+			userErr:     dbErr,
+			expectedErr: dbErr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bookingRepo := &fakeCheckInRepo{}
+			srv := NewInterlockService(tt.repo, &fakeUserRepo{users: users, err: tt.userErr}, bookingRepo, slog.New(slog.DiscardHandler))
+			decision, err := srv.Verify(context.Background(), models.Device{Id: 3, EquipmentId: 5}, tt.badgeId, tt.login, tt.password)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, bookingRepo.checkedIn)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedAllowed, decision.Allowed)
+			assert.Equal(t, tt.expectedReason, decision.Reason)
+			if tt.expectedUser == nil {
+				assert.Nil(t, decision.UserId)
+			} else if assert.NotNil(t, decision.UserId) {
+				assert.Equal(t, tt.expectedUser.UUID, *decision.UserId)
+			}
+			assert.Equal(t, tt.expectedCheckedIn, decision.CheckedIn)
+			if tt.expectedCheckedIn {
+				assert.Equal(t, []int{7}, bookingRepo.checkedIn)
+			} else {
+				assert.Empty(t, bookingRepo.checkedIn)
+			}
+		})
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"mime/multipart"
 
 	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -39,9 +42,219 @@ func (_m *MockEquipmentServiceInterface) EXPECT() *MockEquipmentServiceInterface
 	return &MockEquipmentServiceInterface_Expecter{mock: &_m.Mock}
 }
 
+// AddImage provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) AddImage(ctx context.Context, image models.EquipmentImage, file *multipart.FileHeader) (*models.EquipmentImage, error) {
+	ret := _mock.Called(ctx, image, file)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddImage")
+	}
+
+	var r0 *models.EquipmentImage
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.EquipmentImage, *multipart.FileHeader) (*models.EquipmentImage, error)); ok {
+		return returnFunc(ctx, image, file)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.EquipmentImage, *multipart.FileHeader) *models.EquipmentImage); ok {
+		r0 = returnFunc(ctx, image, file)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.EquipmentImage)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, models.EquipmentImage, *multipart.FileHeader) error); ok {
+		r1 = returnFunc(ctx, image, file)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockEquipmentServiceInterface_AddImage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddImage'
+type MockEquipmentServiceInterface_AddImage_Call struct {
+	*mock.Call
+}
+
+// AddImage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - image models.EquipmentImage
+//   - file *multipart.FileHeader
+func (_e *MockEquipmentServiceInterface_Expecter) AddImage(ctx interface{}, image interface{}, file interface{}) *MockEquipmentServiceInterface_AddImage_Call {
+	return &MockEquipmentServiceInterface_AddImage_Call{Call: _e.mock.On("AddImage", ctx, image, file)}
+}
+
+func (_c *MockEquipmentServiceInterface_AddImage_Call) Run(run func(ctx context.Context, image models.EquipmentImage, file *multipart.FileHeader)) *MockEquipmentServiceInterface_AddImage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 models.EquipmentImage
+		if args[1] != nil {
+			arg1 = args[1].(models.EquipmentImage)
+		}
+		var arg2 *multipart.FileHeader
+		if args[2] != nil {
+			arg2 = args[2].(*multipart.FileHeader)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_AddImage_Call) Return(equipmentImage *models.EquipmentImage, err error) *MockEquipmentServiceInterface_AddImage_Call {
+	_c.Call.Return(equipmentImage, err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_AddImage_Call) RunAndReturn(run func(ctx context.Context, image models.EquipmentImage, file *multipart.FileHeader) (*models.EquipmentImage, error)) *MockEquipmentServiceInterface_AddImage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AvailableEquipment provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) AvailableEquipment(ctx context.Context, filter models.AvailabilityFilter) ([]models.AvailableEquipment, error) {
+	ret := _mock.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AvailableEquipment")
+	}
+
+	var r0 []models.AvailableEquipment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.AvailabilityFilter) ([]models.AvailableEquipment, error)); ok {
+		return returnFunc(ctx, filter)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.AvailabilityFilter) []models.AvailableEquipment); ok {
+		r0 = returnFunc(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.AvailableEquipment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, models.AvailabilityFilter) error); ok {
+		r1 = returnFunc(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockEquipmentServiceInterface_AvailableEquipment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AvailableEquipment'
+type MockEquipmentServiceInterface_AvailableEquipment_Call struct {
+	*mock.Call
+}
+
+// AvailableEquipment is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter models.AvailabilityFilter
+func (_e *MockEquipmentServiceInterface_Expecter) AvailableEquipment(ctx interface{}, filter interface{}) *MockEquipmentServiceInterface_AvailableEquipment_Call {
+	return &MockEquipmentServiceInterface_AvailableEquipment_Call{Call: _e.mock.On("AvailableEquipment", ctx, filter)}
+}
+
+func (_c *MockEquipmentServiceInterface_AvailableEquipment_Call) Run(run func(ctx context.Context, filter models.AvailabilityFilter)) *MockEquipmentServiceInterface_AvailableEquipment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 models.AvailabilityFilter
+		if args[1] != nil {
+			arg1 = args[1].(models.AvailabilityFilter)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_AvailableEquipment_Call) Return(availableEquipments []models.AvailableEquipment, err error) *MockEquipmentServiceInterface_AvailableEquipment_Call {
+	_c.Call.Return(availableEquipments, err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_AvailableEquipment_Call) RunAndReturn(run func(ctx context.Context, filter models.AvailabilityFilter) ([]models.AvailableEquipment, error)) *MockEquipmentServiceInterface_AvailableEquipment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// BrowseEquipment provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) BrowseEquipment(ctx context.Context, filter models.EquipmentFilter) (*models.EquipmentBrowse, error) {
+	ret := _mock.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BrowseEquipment")
+	}
+
+	var r0 *models.EquipmentBrowse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.EquipmentFilter) (*models.EquipmentBrowse, error)); ok {
+		return returnFunc(ctx, filter)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.EquipmentFilter) *models.EquipmentBrowse); ok {
+		r0 = returnFunc(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.EquipmentBrowse)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, models.EquipmentFilter) error); ok {
+		r1 = returnFunc(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockEquipmentServiceInterface_BrowseEquipment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BrowseEquipment'
+type MockEquipmentServiceInterface_BrowseEquipment_Call struct {
+	*mock.Call
+}
+
+// BrowseEquipment is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter models.EquipmentFilter
+func (_e *MockEquipmentServiceInterface_Expecter) BrowseEquipment(ctx interface{}, filter interface{}) *MockEquipmentServiceInterface_BrowseEquipment_Call {
+	return &MockEquipmentServiceInterface_BrowseEquipment_Call{Call: _e.mock.On("BrowseEquipment", ctx, filter)}
+}
+
+func (_c *MockEquipmentServiceInterface_BrowseEquipment_Call) Run(run func(ctx context.Context, filter models.EquipmentFilter)) *MockEquipmentServiceInterface_BrowseEquipment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 models.EquipmentFilter
+		if args[1] != nil {
+			arg1 = args[1].(models.EquipmentFilter)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_BrowseEquipment_Call) Return(equipmentBrowse *models.EquipmentBrowse, err error) *MockEquipmentServiceInterface_BrowseEquipment_Call {
+	_c.Call.Return(equipmentBrowse, err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_BrowseEquipment_Call) RunAndReturn(run func(ctx context.Context, filter models.EquipmentFilter) (*models.EquipmentBrowse, error)) *MockEquipmentServiceInterface_BrowseEquipment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CreateEquipment provides a mock function for the type MockEquipmentServiceInterface
-func (_mock *MockEquipmentServiceInterface) CreateEquipment(ctx context.Context, equipment models.Equipment, image *multipart.FileHeader) (int, error) {
-	ret := _mock.Called(ctx, equipment, image)
+func (_mock *MockEquipmentServiceInterface) CreateEquipment(ctx context.Context, equipment models.Equipment, images []*multipart.FileHeader) (int, error) {
+	ret := _mock.Called(ctx, equipment, images)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateEquipment")
@@ -49,16 +262,16 @@ func (_mock *MockEquipmentServiceInterface) CreateEquipment(ctx context.Context,
 
 	var r0 int
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, models.Equipment, *multipart.FileHeader) (int, error)); ok {
-		return returnFunc(ctx, equipment, image)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.Equipment, []*multipart.FileHeader) (int, error)); ok {
+		return returnFunc(ctx, equipment, images)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, models.Equipment, *multipart.FileHeader) int); ok {
-		r0 = returnFunc(ctx, equipment, image)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.Equipment, []*multipart.FileHeader) int); ok {
+		r0 = returnFunc(ctx, equipment, images)
 	} else {
 		r0 = ret.Get(0).(int)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, models.Equipment, *multipart.FileHeader) error); ok {
-		r1 = returnFunc(ctx, equipment, image)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, models.Equipment, []*multipart.FileHeader) error); ok {
+		r1 = returnFunc(ctx, equipment, images)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -73,12 +286,12 @@ type MockEquipmentServiceInterface_CreateEquipment_Call struct {
 // CreateEquipment is a helper method to define mock.On call
 //   - ctx context.Context
 //   - equipment models.Equipment
-//   - image *multipart.FileHeader
-func (_e *MockEquipmentServiceInterface_Expecter) CreateEquipment(ctx interface{}, equipment interface{}, image interface{}) *MockEquipmentServiceInterface_CreateEquipment_Call {
-	return &MockEquipmentServiceInterface_CreateEquipment_Call{Call: _e.mock.On("CreateEquipment", ctx, equipment, image)}
+//   - images []*multipart.FileHeader
+func (_e *MockEquipmentServiceInterface_Expecter) CreateEquipment(ctx interface{}, equipment interface{}, images interface{}) *MockEquipmentServiceInterface_CreateEquipment_Call {
+	return &MockEquipmentServiceInterface_CreateEquipment_Call{Call: _e.mock.On("CreateEquipment", ctx, equipment, images)}
 }
 
-func (_c *MockEquipmentServiceInterface_CreateEquipment_Call) Run(run func(ctx context.Context, equipment models.Equipment, image *multipart.FileHeader)) *MockEquipmentServiceInterface_CreateEquipment_Call {
+func (_c *MockEquipmentServiceInterface_CreateEquipment_Call) Run(run func(ctx context.Context, equipment models.Equipment, images []*multipart.FileHeader)) *MockEquipmentServiceInterface_CreateEquipment_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -88,9 +301,9 @@ func (_c *MockEquipmentServiceInterface_CreateEquipment_Call) Run(run func(ctx c
 		if args[1] != nil {
 			arg1 = args[1].(models.Equipment)
 		}
-		var arg2 *multipart.FileHeader
+		var arg2 []*multipart.FileHeader
 		if args[2] != nil {
-			arg2 = args[2].(*multipart.FileHeader)
+			arg2 = args[2].([]*multipart.FileHeader)
 		}
 		run(
 			arg0,
@@ -106,41 +319,188 @@ func (_c *MockEquipmentServiceInterface_CreateEquipment_Call) Return(n int, err
 	return _c
 }
 
-func (_c *MockEquipmentServiceInterface_CreateEquipment_Call) RunAndReturn(run func(ctx context.Context, equipment models.Equipment, image *multipart.FileHeader) (int, error)) *MockEquipmentServiceInterface_CreateEquipment_Call {
+func (_c *MockEquipmentServiceInterface_CreateEquipment_Call) RunAndReturn(run func(ctx context.Context, equipment models.Equipment, images []*multipart.FileHeader) (int, error)) *MockEquipmentServiceInterface_CreateEquipment_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // DeleteEquipment provides a mock function for the type MockEquipmentServiceInterface
-func (_mock *MockEquipmentServiceInterface) DeleteEquipment(ctx context.Context, equipment_id int) error {
-	ret := _mock.Called(ctx, equipment_id)
+func (_mock *MockEquipmentServiceInterface) DeleteEquipment(ctx context.Context, equipmentId int, admin uuid.UUID, reason string, cancelBookings bool) (int, error) {
+	ret := _mock.Called(ctx, equipmentId, admin, reason, cancelBookings)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteEquipment")
 	}
 
+	var r0 int
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, uuid.UUID, string, bool) (int, error)); ok {
+		return returnFunc(ctx, equipmentId, admin, reason, cancelBookings)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, uuid.UUID, string, bool) int); ok {
+		r0 = returnFunc(ctx, equipmentId, admin, reason, cancelBookings)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, uuid.UUID, string, bool) error); ok {
+		r1 = returnFunc(ctx, equipmentId, admin, reason, cancelBookings)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockEquipmentServiceInterface_DeleteEquipment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteEquipment'
+type MockEquipmentServiceInterface_DeleteEquipment_Call struct {
+	*mock.Call
+}
+
+// DeleteEquipment is a helper method to define mock.On call
+//   - ctx context.Context
+//   - equipmentId int
+//   - admin uuid.UUID
+//   - reason string
+//   - cancelBookings bool
+func (_e *MockEquipmentServiceInterface_Expecter) DeleteEquipment(ctx interface{}, equipmentId interface{}, admin interface{}, reason interface{}, cancelBookings interface{}) *MockEquipmentServiceInterface_DeleteEquipment_Call {
+	return &MockEquipmentServiceInterface_DeleteEquipment_Call{Call: _e.mock.On("DeleteEquipment", ctx, equipmentId, admin, reason, cancelBookings)}
+}
+
+func (_c *MockEquipmentServiceInterface_DeleteEquipment_Call) Run(run func(ctx context.Context, equipmentId int, admin uuid.UUID, reason string, cancelBookings bool)) *MockEquipmentServiceInterface_DeleteEquipment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		var arg4 bool
+		if args[4] != nil {
+			arg4 = args[4].(bool)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_DeleteEquipment_Call) Return(n int, err error) *MockEquipmentServiceInterface_DeleteEquipment_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_DeleteEquipment_Call) RunAndReturn(run func(ctx context.Context, equipmentId int, admin uuid.UUID, reason string, cancelBookings bool) (int, error)) *MockEquipmentServiceInterface_DeleteEquipment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteImage provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) DeleteImage(ctx context.Context, equipmentId int, imageId int) error {
+	ret := _mock.Called(ctx, equipmentId, imageId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteImage")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = returnFunc(ctx, equipmentId, imageId)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockEquipmentServiceInterface_DeleteImage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteImage'
+type MockEquipmentServiceInterface_DeleteImage_Call struct {
+	*mock.Call
+}
+
+// DeleteImage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - equipmentId int
+//   - imageId int
+func (_e *MockEquipmentServiceInterface_Expecter) DeleteImage(ctx interface{}, equipmentId interface{}, imageId interface{}) *MockEquipmentServiceInterface_DeleteImage_Call {
+	return &MockEquipmentServiceInterface_DeleteImage_Call{Call: _e.mock.On("DeleteImage", ctx, equipmentId, imageId)}
+}
+
+func (_c *MockEquipmentServiceInterface_DeleteImage_Call) Run(run func(ctx context.Context, equipmentId int, imageId int)) *MockEquipmentServiceInterface_DeleteImage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_DeleteImage_Call) Return(err error) *MockEquipmentServiceInterface_DeleteImage_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_DeleteImage_Call) RunAndReturn(run func(ctx context.Context, equipmentId int, imageId int) error) *MockEquipmentServiceInterface_DeleteImage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeletePreemptionRule provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) DeletePreemptionRule(ctx context.Context, equipmentId int) error {
+	ret := _mock.Called(ctx, equipmentId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePreemptionRule")
+	}
+
 	var r0 error
 	if returnFunc, ok := ret.Get(0).(func(context.Context, int) error); ok {
-		r0 = returnFunc(ctx, equipment_id)
+		r0 = returnFunc(ctx, equipmentId)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// MockEquipmentServiceInterface_DeleteEquipment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteEquipment'
-type MockEquipmentServiceInterface_DeleteEquipment_Call struct {
+// MockEquipmentServiceInterface_DeletePreemptionRule_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeletePreemptionRule'
+type MockEquipmentServiceInterface_DeletePreemptionRule_Call struct {
 	*mock.Call
 }
 
-// DeleteEquipment is a helper method to define mock.On call
+// DeletePreemptionRule is a helper method to define mock.On call
 //   - ctx context.Context
-//   - equipment_id int
-func (_e *MockEquipmentServiceInterface_Expecter) DeleteEquipment(ctx interface{}, equipment_id interface{}) *MockEquipmentServiceInterface_DeleteEquipment_Call {
-	return &MockEquipmentServiceInterface_DeleteEquipment_Call{Call: _e.mock.On("DeleteEquipment", ctx, equipment_id)}
+//   - equipmentId int
+func (_e *MockEquipmentServiceInterface_Expecter) DeletePreemptionRule(ctx interface{}, equipmentId interface{}) *MockEquipmentServiceInterface_DeletePreemptionRule_Call {
+	return &MockEquipmentServiceInterface_DeletePreemptionRule_Call{Call: _e.mock.On("DeletePreemptionRule", ctx, equipmentId)}
 }
 
-func (_c *MockEquipmentServiceInterface_DeleteEquipment_Call) Run(run func(ctx context.Context, equipment_id int)) *MockEquipmentServiceInterface_DeleteEquipment_Call {
+func (_c *MockEquipmentServiceInterface_DeletePreemptionRule_Call) Run(run func(ctx context.Context, equipmentId int)) *MockEquipmentServiceInterface_DeletePreemptionRule_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -158,12 +518,12 @@ func (_c *MockEquipmentServiceInterface_DeleteEquipment_Call) Run(run func(ctx c
 	return _c
 }
 
-func (_c *MockEquipmentServiceInterface_DeleteEquipment_Call) Return(err error) *MockEquipmentServiceInterface_DeleteEquipment_Call {
+func (_c *MockEquipmentServiceInterface_DeletePreemptionRule_Call) Return(err error) *MockEquipmentServiceInterface_DeletePreemptionRule_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockEquipmentServiceInterface_DeleteEquipment_Call) RunAndReturn(run func(ctx context.Context, equipment_id int) error) *MockEquipmentServiceInterface_DeleteEquipment_Call {
+func (_c *MockEquipmentServiceInterface_DeletePreemptionRule_Call) RunAndReturn(run func(ctx context.Context, equipmentId int) error) *MockEquipmentServiceInterface_DeletePreemptionRule_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -304,22 +664,1244 @@ func (_c *MockEquipmentServiceInterface_EquipmentByName_Call) RunAndReturn(run f
 	return _c
 }
 
-// UpdateEquipment provides a mock function for the type MockEquipmentServiceInterface
-func (_mock *MockEquipmentServiceInterface) UpdateEquipment(ctx context.Context, equipment models.Equipment) error {
-	ret := _mock.Called(ctx, equipment)
+// Form provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) Form(ctx context.Context, equipmentId int) ([]models.FormField, error) {
+	ret := _mock.Called(ctx, equipmentId)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateEquipment")
+		panic("no return value specified for Form")
 	}
 
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, models.Equipment) error); ok {
-		r0 = returnFunc(ctx, equipment)
+	var r0 []models.FormField
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) ([]models.FormField, error)); ok {
+		return returnFunc(ctx, equipmentId)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) []models.FormField); ok {
+		r0 = returnFunc(ctx, equipmentId)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.FormField)
+		}
 	}
-	return r0
-}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = returnFunc(ctx, equipmentId)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockEquipmentServiceInterface_Form_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Form'
+type MockEquipmentServiceInterface_Form_Call struct {
+	*mock.Call
+}
+
+// Form is a helper method to define mock.On call
+//   - ctx context.Context
+//   - equipmentId int
+func (_e *MockEquipmentServiceInterface_Expecter) Form(ctx interface{}, equipmentId interface{}) *MockEquipmentServiceInterface_Form_Call {
+	return &MockEquipmentServiceInterface_Form_Call{Call: _e.mock.On("Form", ctx, equipmentId)}
+}
+
+func (_c *MockEquipmentServiceInterface_Form_Call) Run(run func(ctx context.Context, equipmentId int)) *MockEquipmentServiceInterface_Form_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_Form_Call) Return(formFields []models.FormField, err error) *MockEquipmentServiceInterface_Form_Call {
+	_c.Call.Return(formFields, err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_Form_Call) RunAndReturn(run func(ctx context.Context, equipmentId int) ([]models.FormField, error)) *MockEquipmentServiceInterface_Form_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Image provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) Image(ctx context.Context, objectName string, size string, expires int64, signature string) (*minio.Object, minio.ObjectInfo, error) {
+	ret := _mock.Called(ctx, objectName, size, expires, signature)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Image")
+	}
+
+	var r0 *minio.Object
+	var r1 minio.ObjectInfo
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, int64, string) (*minio.Object, minio.ObjectInfo, error)); ok {
+		return returnFunc(ctx, objectName, size, expires, signature)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, int64, string) *minio.Object); ok {
+		r0 = returnFunc(ctx, objectName, size, expires, signature)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*minio.Object)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, int64, string) minio.ObjectInfo); ok {
+		r1 = returnFunc(ctx, objectName, size, expires, signature)
+	} else {
+		r1 = ret.Get(1).(minio.ObjectInfo)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, string, string, int64, string) error); ok {
+		r2 = returnFunc(ctx, objectName, size, expires, signature)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockEquipmentServiceInterface_Image_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Image'
+type MockEquipmentServiceInterface_Image_Call struct {
+	*mock.Call
+}
+
+// Image is a helper method to define mock.On call
+//   - ctx context.Context
+//   - objectName string
+//   - size string
+//   - expires int64
+//   - signature string
+func (_e *MockEquipmentServiceInterface_Expecter) Image(ctx interface{}, objectName interface{}, size interface{}, expires interface{}, signature interface{}) *MockEquipmentServiceInterface_Image_Call {
+	return &MockEquipmentServiceInterface_Image_Call{Call: _e.mock.On("Image", ctx, objectName, size, expires, signature)}
+}
+
+func (_c *MockEquipmentServiceInterface_Image_Call) Run(run func(ctx context.Context, objectName string, size string, expires int64, signature string)) *MockEquipmentServiceInterface_Image_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 int64
+		if args[3] != nil {
+			arg3 = args[3].(int64)
+		}
+		var arg4 string
+		if args[4] != nil {
+			arg4 = args[4].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_Image_Call) Return(object *minio.Object, objectInfo minio.ObjectInfo, err error) *MockEquipmentServiceInterface_Image_Call {
+	_c.Call.Return(object, objectInfo, err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_Image_Call) RunAndReturn(run func(ctx context.Context, objectName string, size string, expires int64, signature string) (*minio.Object, minio.ObjectInfo, error)) *MockEquipmentServiceInterface_Image_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Images provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) Images(ctx context.Context, equipmentId int) ([]models.EquipmentImage, error) {
+	ret := _mock.Called(ctx, equipmentId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Images")
+	}
+
+	var r0 []models.EquipmentImage
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) ([]models.EquipmentImage, error)); ok {
+		return returnFunc(ctx, equipmentId)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) []models.EquipmentImage); ok {
+		r0 = returnFunc(ctx, equipmentId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.EquipmentImage)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = returnFunc(ctx, equipmentId)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockEquipmentServiceInterface_Images_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Images'
+type MockEquipmentServiceInterface_Images_Call struct {
+	*mock.Call
+}
+
+// Images is a helper method to define mock.On call
+//   - ctx context.Context
+//   - equipmentId int
+func (_e *MockEquipmentServiceInterface_Expecter) Images(ctx interface{}, equipmentId interface{}) *MockEquipmentServiceInterface_Images_Call {
+	return &MockEquipmentServiceInterface_Images_Call{Call: _e.mock.On("Images", ctx, equipmentId)}
+}
+
+func (_c *MockEquipmentServiceInterface_Images_Call) Run(run func(ctx context.Context, equipmentId int)) *MockEquipmentServiceInterface_Images_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_Images_Call) Return(equipmentImages []models.EquipmentImage, err error) *MockEquipmentServiceInterface_Images_Call {
+	_c.Call.Return(equipmentImages, err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_Images_Call) RunAndReturn(run func(ctx context.Context, equipmentId int) ([]models.EquipmentImage, error)) *MockEquipmentServiceInterface_Images_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OperatingHours provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) OperatingHours(ctx context.Context, equipmentId int) ([]models.OperatingHours, error) {
+	ret := _mock.Called(ctx, equipmentId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OperatingHours")
+	}
+
+	var r0 []models.OperatingHours
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) ([]models.OperatingHours, error)); ok {
+		return returnFunc(ctx, equipmentId)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) []models.OperatingHours); ok {
+		r0 = returnFunc(ctx, equipmentId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.OperatingHours)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = returnFunc(ctx, equipmentId)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockEquipmentServiceInterface_OperatingHours_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OperatingHours'
+type MockEquipmentServiceInterface_OperatingHours_Call struct {
+	*mock.Call
+}
+
+// OperatingHours is a helper method to define mock.On call
+//   - ctx context.Context
+//   - equipmentId int
+func (_e *MockEquipmentServiceInterface_Expecter) OperatingHours(ctx interface{}, equipmentId interface{}) *MockEquipmentServiceInterface_OperatingHours_Call {
+	return &MockEquipmentServiceInterface_OperatingHours_Call{Call: _e.mock.On("OperatingHours", ctx, equipmentId)}
+}
+
+func (_c *MockEquipmentServiceInterface_OperatingHours_Call) Run(run func(ctx context.Context, equipmentId int)) *MockEquipmentServiceInterface_OperatingHours_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_OperatingHours_Call) Return(operatingHourss []models.OperatingHours, err error) *MockEquipmentServiceInterface_OperatingHours_Call {
+	_c.Call.Return(operatingHourss, err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_OperatingHours_Call) RunAndReturn(run func(ctx context.Context, equipmentId int) ([]models.OperatingHours, error)) *MockEquipmentServiceInterface_OperatingHours_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PreemptionRule provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) PreemptionRule(ctx context.Context, equipmentId int) (*models.PreemptionRule, error) {
+	ret := _mock.Called(ctx, equipmentId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PreemptionRule")
+	}
+
+	var r0 *models.PreemptionRule
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) (*models.PreemptionRule, error)); ok {
+		return returnFunc(ctx, equipmentId)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) *models.PreemptionRule); ok {
+		r0 = returnFunc(ctx, equipmentId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PreemptionRule)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = returnFunc(ctx, equipmentId)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockEquipmentServiceInterface_PreemptionRule_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PreemptionRule'
+type MockEquipmentServiceInterface_PreemptionRule_Call struct {
+	*mock.Call
+}
+
+// PreemptionRule is a helper method to define mock.On call
+//   - ctx context.Context
+//   - equipmentId int
+func (_e *MockEquipmentServiceInterface_Expecter) PreemptionRule(ctx interface{}, equipmentId interface{}) *MockEquipmentServiceInterface_PreemptionRule_Call {
+	return &MockEquipmentServiceInterface_PreemptionRule_Call{Call: _e.mock.On("PreemptionRule", ctx, equipmentId)}
+}
+
+func (_c *MockEquipmentServiceInterface_PreemptionRule_Call) Run(run func(ctx context.Context, equipmentId int)) *MockEquipmentServiceInterface_PreemptionRule_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_PreemptionRule_Call) Return(preemptionRule *models.PreemptionRule, err error) *MockEquipmentServiceInterface_PreemptionRule_Call {
+	_c.Call.Return(preemptionRule, err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_PreemptionRule_Call) RunAndReturn(run func(ctx context.Context, equipmentId int) (*models.PreemptionRule, error)) *MockEquipmentServiceInterface_PreemptionRule_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReorderImages provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) ReorderImages(ctx context.Context, equipmentId int, imageIds []int) error {
+	ret := _mock.Called(ctx, equipmentId, imageIds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReorderImages")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, []int) error); ok {
+		r0 = returnFunc(ctx, equipmentId, imageIds)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockEquipmentServiceInterface_ReorderImages_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReorderImages'
+type MockEquipmentServiceInterface_ReorderImages_Call struct {
+	*mock.Call
+}
+
+// ReorderImages is a helper method to define mock.On call
+//   - ctx context.Context
+//   - equipmentId int
+//   - imageIds []int
+func (_e *MockEquipmentServiceInterface_Expecter) ReorderImages(ctx interface{}, equipmentId interface{}, imageIds interface{}) *MockEquipmentServiceInterface_ReorderImages_Call {
+	return &MockEquipmentServiceInterface_ReorderImages_Call{Call: _e.mock.On("ReorderImages", ctx, equipmentId, imageIds)}
+}
+
+func (_c *MockEquipmentServiceInterface_ReorderImages_Call) Run(run func(ctx context.Context, equipmentId int, imageIds []int)) *MockEquipmentServiceInterface_ReorderImages_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 []int
+		if args[2] != nil {
+			arg2 = args[2].([]int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_ReorderImages_Call) Return(err error) *MockEquipmentServiceInterface_ReorderImages_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_ReorderImages_Call) RunAndReturn(run func(ctx context.Context, equipmentId int, imageIds []int) error) *MockEquipmentServiceInterface_ReorderImages_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SearchEquipment provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) SearchEquipment(ctx context.Context, query string, after *models.EquipmentSearchCursor, limit int) (*pagination.Page[models.EquipmentSearchHit], error) {
+	ret := _mock.Called(ctx, query, after, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchEquipment")
+	}
+
+	var r0 *pagination.Page[models.EquipmentSearchHit]
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *models.EquipmentSearchCursor, int) (*pagination.Page[models.EquipmentSearchHit], error)); ok {
+		return returnFunc(ctx, query, after, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *models.EquipmentSearchCursor, int) *pagination.Page[models.EquipmentSearchHit]); ok {
+		r0 = returnFunc(ctx, query, after, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*pagination.Page[models.EquipmentSearchHit])
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, *models.EquipmentSearchCursor, int) error); ok {
+		r1 = returnFunc(ctx, query, after, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockEquipmentServiceInterface_SearchEquipment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SearchEquipment'
+type MockEquipmentServiceInterface_SearchEquipment_Call struct {
+	*mock.Call
+}
+
+// SearchEquipment is a helper method to define mock.On call
+//   - ctx context.Context
+//   - query string
+//   - after *models.EquipmentSearchCursor
+//   - limit int
+func (_e *MockEquipmentServiceInterface_Expecter) SearchEquipment(ctx interface{}, query interface{}, after interface{}, limit interface{}) *MockEquipmentServiceInterface_SearchEquipment_Call {
+	return &MockEquipmentServiceInterface_SearchEquipment_Call{Call: _e.mock.On("SearchEquipment", ctx, query, after, limit)}
+}
+
+func (_c *MockEquipmentServiceInterface_SearchEquipment_Call) Run(run func(ctx context.Context, query string, after *models.EquipmentSearchCursor, limit int)) *MockEquipmentServiceInterface_SearchEquipment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 *models.EquipmentSearchCursor
+		if args[2] != nil {
+			arg2 = args[2].(*models.EquipmentSearchCursor)
+		}
+		var arg3 int
+		if args[3] != nil {
+			arg3 = args[3].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SearchEquipment_Call) Return(page *pagination.Page[models.EquipmentSearchHit], err error) *MockEquipmentServiceInterface_SearchEquipment_Call {
+	_c.Call.Return(page, err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SearchEquipment_Call) RunAndReturn(run func(ctx context.Context, query string, after *models.EquipmentSearchCursor, limit int) (*pagination.Page[models.EquipmentSearchHit], error)) *MockEquipmentServiceInterface_SearchEquipment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetAllocationMode provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) SetAllocationMode(ctx context.Context, equipmentId int, mode string) error {
+	ret := _mock.Called(ctx, equipmentId, mode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetAllocationMode")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = returnFunc(ctx, equipmentId, mode)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockEquipmentServiceInterface_SetAllocationMode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetAllocationMode'
+type MockEquipmentServiceInterface_SetAllocationMode_Call struct {
+	*mock.Call
+}
+
+// SetAllocationMode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - equipmentId int
+//   - mode string
+func (_e *MockEquipmentServiceInterface_Expecter) SetAllocationMode(ctx interface{}, equipmentId interface{}, mode interface{}) *MockEquipmentServiceInterface_SetAllocationMode_Call {
+	return &MockEquipmentServiceInterface_SetAllocationMode_Call{Call: _e.mock.On("SetAllocationMode", ctx, equipmentId, mode)}
+}
+
+func (_c *MockEquipmentServiceInterface_SetAllocationMode_Call) Run(run func(ctx context.Context, equipmentId int, mode string)) *MockEquipmentServiceInterface_SetAllocationMode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SetAllocationMode_Call) Return(err error) *MockEquipmentServiceInterface_SetAllocationMode_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SetAllocationMode_Call) RunAndReturn(run func(ctx context.Context, equipmentId int, mode string) error) *MockEquipmentServiceInterface_SetAllocationMode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetCategory provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) SetCategory(ctx context.Context, equipmentId int, categoryId *int) error {
+	ret := _mock.Called(ctx, equipmentId, categoryId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetCategory")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, *int) error); ok {
+		r0 = returnFunc(ctx, equipmentId, categoryId)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockEquipmentServiceInterface_SetCategory_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetCategory'
+type MockEquipmentServiceInterface_SetCategory_Call struct {
+	*mock.Call
+}
+
+// SetCategory is a helper method to define mock.On call
+//   - ctx context.Context
+//   - equipmentId int
+//   - categoryId *int
+func (_e *MockEquipmentServiceInterface_Expecter) SetCategory(ctx interface{}, equipmentId interface{}, categoryId interface{}) *MockEquipmentServiceInterface_SetCategory_Call {
+	return &MockEquipmentServiceInterface_SetCategory_Call{Call: _e.mock.On("SetCategory", ctx, equipmentId, categoryId)}
+}
+
+func (_c *MockEquipmentServiceInterface_SetCategory_Call) Run(run func(ctx context.Context, equipmentId int, categoryId *int)) *MockEquipmentServiceInterface_SetCategory_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 *int
+		if args[2] != nil {
+			arg2 = args[2].(*int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SetCategory_Call) Return(err error) *MockEquipmentServiceInterface_SetCategory_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SetCategory_Call) RunAndReturn(run func(ctx context.Context, equipmentId int, categoryId *int) error) *MockEquipmentServiceInterface_SetCategory_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetForm provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) SetForm(ctx context.Context, equipmentId int, fields []models.FormField) error {
+	ret := _mock.Called(ctx, equipmentId, fields)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetForm")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, []models.FormField) error); ok {
+		r0 = returnFunc(ctx, equipmentId, fields)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockEquipmentServiceInterface_SetForm_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetForm'
+type MockEquipmentServiceInterface_SetForm_Call struct {
+	*mock.Call
+}
+
+// SetForm is a helper method to define mock.On call
+//   - ctx context.Context
+//   - equipmentId int
+//   - fields []models.FormField
+func (_e *MockEquipmentServiceInterface_Expecter) SetForm(ctx interface{}, equipmentId interface{}, fields interface{}) *MockEquipmentServiceInterface_SetForm_Call {
+	return &MockEquipmentServiceInterface_SetForm_Call{Call: _e.mock.On("SetForm", ctx, equipmentId, fields)}
+}
+
+func (_c *MockEquipmentServiceInterface_SetForm_Call) Run(run func(ctx context.Context, equipmentId int, fields []models.FormField)) *MockEquipmentServiceInterface_SetForm_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 []models.FormField
+		if args[2] != nil {
+			arg2 = args[2].([]models.FormField)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SetForm_Call) Return(err error) *MockEquipmentServiceInterface_SetForm_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SetForm_Call) RunAndReturn(run func(ctx context.Context, equipmentId int, fields []models.FormField) error) *MockEquipmentServiceInterface_SetForm_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetOperatingHours provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) SetOperatingHours(ctx context.Context, equipmentId int, hours []models.OperatingHours) error {
+	ret := _mock.Called(ctx, equipmentId, hours)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetOperatingHours")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, []models.OperatingHours) error); ok {
+		r0 = returnFunc(ctx, equipmentId, hours)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockEquipmentServiceInterface_SetOperatingHours_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetOperatingHours'
+type MockEquipmentServiceInterface_SetOperatingHours_Call struct {
+	*mock.Call
+}
+
+// SetOperatingHours is a helper method to define mock.On call
+//   - ctx context.Context
+//   - equipmentId int
+//   - hours []models.OperatingHours
+func (_e *MockEquipmentServiceInterface_Expecter) SetOperatingHours(ctx interface{}, equipmentId interface{}, hours interface{}) *MockEquipmentServiceInterface_SetOperatingHours_Call {
+	return &MockEquipmentServiceInterface_SetOperatingHours_Call{Call: _e.mock.On("SetOperatingHours", ctx, equipmentId, hours)}
+}
+
+func (_c *MockEquipmentServiceInterface_SetOperatingHours_Call) Run(run func(ctx context.Context, equipmentId int, hours []models.OperatingHours)) *MockEquipmentServiceInterface_SetOperatingHours_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 []models.OperatingHours
+		if args[2] != nil {
+			arg2 = args[2].([]models.OperatingHours)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SetOperatingHours_Call) Return(err error) *MockEquipmentServiceInterface_SetOperatingHours_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SetOperatingHours_Call) RunAndReturn(run func(ctx context.Context, equipmentId int, hours []models.OperatingHours) error) *MockEquipmentServiceInterface_SetOperatingHours_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetPreemptionRule provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) SetPreemptionRule(ctx context.Context, rule models.PreemptionRule) error {
+	ret := _mock.Called(ctx, rule)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetPreemptionRule")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.PreemptionRule) error); ok {
+		r0 = returnFunc(ctx, rule)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockEquipmentServiceInterface_SetPreemptionRule_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetPreemptionRule'
+type MockEquipmentServiceInterface_SetPreemptionRule_Call struct {
+	*mock.Call
+}
+
+// SetPreemptionRule is a helper method to define mock.On call
+//   - ctx context.Context
+//   - rule models.PreemptionRule
+func (_e *MockEquipmentServiceInterface_Expecter) SetPreemptionRule(ctx interface{}, rule interface{}) *MockEquipmentServiceInterface_SetPreemptionRule_Call {
+	return &MockEquipmentServiceInterface_SetPreemptionRule_Call{Call: _e.mock.On("SetPreemptionRule", ctx, rule)}
+}
+
+func (_c *MockEquipmentServiceInterface_SetPreemptionRule_Call) Run(run func(ctx context.Context, rule models.PreemptionRule)) *MockEquipmentServiceInterface_SetPreemptionRule_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 models.PreemptionRule
+		if args[1] != nil {
+			arg1 = args[1].(models.PreemptionRule)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SetPreemptionRule_Call) Return(err error) *MockEquipmentServiceInterface_SetPreemptionRule_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SetPreemptionRule_Call) RunAndReturn(run func(ctx context.Context, rule models.PreemptionRule) error) *MockEquipmentServiceInterface_SetPreemptionRule_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetRequiresOperator provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) SetRequiresOperator(ctx context.Context, equipmentId int, required bool) error {
+	ret := _mock.Called(ctx, equipmentId, required)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetRequiresOperator")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, bool) error); ok {
+		r0 = returnFunc(ctx, equipmentId, required)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockEquipmentServiceInterface_SetRequiresOperator_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetRequiresOperator'
+type MockEquipmentServiceInterface_SetRequiresOperator_Call struct {
+	*mock.Call
+}
+
+// SetRequiresOperator is a helper method to define mock.On call
+//   - ctx context.Context
+//   - equipmentId int
+//   - required bool
+func (_e *MockEquipmentServiceInterface_Expecter) SetRequiresOperator(ctx interface{}, equipmentId interface{}, required interface{}) *MockEquipmentServiceInterface_SetRequiresOperator_Call {
+	return &MockEquipmentServiceInterface_SetRequiresOperator_Call{Call: _e.mock.On("SetRequiresOperator", ctx, equipmentId, required)}
+}
+
+func (_c *MockEquipmentServiceInterface_SetRequiresOperator_Call) Run(run func(ctx context.Context, equipmentId int, required bool)) *MockEquipmentServiceInterface_SetRequiresOperator_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 bool
+		if args[2] != nil {
+			arg2 = args[2].(bool)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SetRequiresOperator_Call) Return(err error) *MockEquipmentServiceInterface_SetRequiresOperator_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SetRequiresOperator_Call) RunAndReturn(run func(ctx context.Context, equipmentId int, required bool) error) *MockEquipmentServiceInterface_SetRequiresOperator_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetRoom provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) SetRoom(ctx context.Context, equipmentId int, room string) error {
+	ret := _mock.Called(ctx, equipmentId, room)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetRoom")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = returnFunc(ctx, equipmentId, room)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockEquipmentServiceInterface_SetRoom_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetRoom'
+type MockEquipmentServiceInterface_SetRoom_Call struct {
+	*mock.Call
+}
+
+// SetRoom is a helper method to define mock.On call
+//   - ctx context.Context
+//   - equipmentId int
+//   - room string
+func (_e *MockEquipmentServiceInterface_Expecter) SetRoom(ctx interface{}, equipmentId interface{}, room interface{}) *MockEquipmentServiceInterface_SetRoom_Call {
+	return &MockEquipmentServiceInterface_SetRoom_Call{Call: _e.mock.On("SetRoom", ctx, equipmentId, room)}
+}
+
+func (_c *MockEquipmentServiceInterface_SetRoom_Call) Run(run func(ctx context.Context, equipmentId int, room string)) *MockEquipmentServiceInterface_SetRoom_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SetRoom_Call) Return(err error) *MockEquipmentServiceInterface_SetRoom_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SetRoom_Call) RunAndReturn(run func(ctx context.Context, equipmentId int, room string) error) *MockEquipmentServiceInterface_SetRoom_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetStatus provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) SetStatus(ctx context.Context, equipmentId int, status string, reason string) error {
+	ret := _mock.Called(ctx, equipmentId, status, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetStatus")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, string, string) error); ok {
+		r0 = returnFunc(ctx, equipmentId, status, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockEquipmentServiceInterface_SetStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetStatus'
+type MockEquipmentServiceInterface_SetStatus_Call struct {
+	*mock.Call
+}
+
+// SetStatus is a helper method to define mock.On call
+//   - ctx context.Context
+//   - equipmentId int
+//   - status string
+//   - reason string
+func (_e *MockEquipmentServiceInterface_Expecter) SetStatus(ctx interface{}, equipmentId interface{}, status interface{}, reason interface{}) *MockEquipmentServiceInterface_SetStatus_Call {
+	return &MockEquipmentServiceInterface_SetStatus_Call{Call: _e.mock.On("SetStatus", ctx, equipmentId, status, reason)}
+}
+
+func (_c *MockEquipmentServiceInterface_SetStatus_Call) Run(run func(ctx context.Context, equipmentId int, status string, reason string)) *MockEquipmentServiceInterface_SetStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SetStatus_Call) Return(err error) *MockEquipmentServiceInterface_SetStatus_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SetStatus_Call) RunAndReturn(run func(ctx context.Context, equipmentId int, status string, reason string) error) *MockEquipmentServiceInterface_SetStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetTags provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) SetTags(ctx context.Context, equipmentId int, tags []string) error {
+	ret := _mock.Called(ctx, equipmentId, tags)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetTags")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, []string) error); ok {
+		r0 = returnFunc(ctx, equipmentId, tags)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockEquipmentServiceInterface_SetTags_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetTags'
+type MockEquipmentServiceInterface_SetTags_Call struct {
+	*mock.Call
+}
+
+// SetTags is a helper method to define mock.On call
+//   - ctx context.Context
+//   - equipmentId int
+//   - tags []string
+func (_e *MockEquipmentServiceInterface_Expecter) SetTags(ctx interface{}, equipmentId interface{}, tags interface{}) *MockEquipmentServiceInterface_SetTags_Call {
+	return &MockEquipmentServiceInterface_SetTags_Call{Call: _e.mock.On("SetTags", ctx, equipmentId, tags)}
+}
+
+func (_c *MockEquipmentServiceInterface_SetTags_Call) Run(run func(ctx context.Context, equipmentId int, tags []string)) *MockEquipmentServiceInterface_SetTags_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 []string
+		if args[2] != nil {
+			arg2 = args[2].([]string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SetTags_Call) Return(err error) *MockEquipmentServiceInterface_SetTags_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SetTags_Call) RunAndReturn(run func(ctx context.Context, equipmentId int, tags []string) error) *MockEquipmentServiceInterface_SetTags_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetTimeZone provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) SetTimeZone(ctx context.Context, equipmentId int, timeZone string) error {
+	ret := _mock.Called(ctx, equipmentId, timeZone)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetTimeZone")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = returnFunc(ctx, equipmentId, timeZone)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockEquipmentServiceInterface_SetTimeZone_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetTimeZone'
+type MockEquipmentServiceInterface_SetTimeZone_Call struct {
+	*mock.Call
+}
+
+// SetTimeZone is a helper method to define mock.On call
+//   - ctx context.Context
+//   - equipmentId int
+//   - timeZone string
+func (_e *MockEquipmentServiceInterface_Expecter) SetTimeZone(ctx interface{}, equipmentId interface{}, timeZone interface{}) *MockEquipmentServiceInterface_SetTimeZone_Call {
+	return &MockEquipmentServiceInterface_SetTimeZone_Call{Call: _e.mock.On("SetTimeZone", ctx, equipmentId, timeZone)}
+}
+
+func (_c *MockEquipmentServiceInterface_SetTimeZone_Call) Run(run func(ctx context.Context, equipmentId int, timeZone string)) *MockEquipmentServiceInterface_SetTimeZone_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SetTimeZone_Call) Return(err error) *MockEquipmentServiceInterface_SetTimeZone_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_SetTimeZone_Call) RunAndReturn(run func(ctx context.Context, equipmentId int, timeZone string) error) *MockEquipmentServiceInterface_SetTimeZone_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Suggest provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) Suggest(ctx context.Context, prefix string, limit int) ([]models.Suggestion, error) {
+	ret := _mock.Called(ctx, prefix, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Suggest")
+	}
+
+	var r0 []models.Suggestion
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int) ([]models.Suggestion, error)); ok {
+		return returnFunc(ctx, prefix, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int) []models.Suggestion); ok {
+		r0 = returnFunc(ctx, prefix, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Suggestion)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = returnFunc(ctx, prefix, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockEquipmentServiceInterface_Suggest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Suggest'
+type MockEquipmentServiceInterface_Suggest_Call struct {
+	*mock.Call
+}
+
+// Suggest is a helper method to define mock.On call
+//   - ctx context.Context
+//   - prefix string
+//   - limit int
+func (_e *MockEquipmentServiceInterface_Expecter) Suggest(ctx interface{}, prefix interface{}, limit interface{}) *MockEquipmentServiceInterface_Suggest_Call {
+	return &MockEquipmentServiceInterface_Suggest_Call{Call: _e.mock.On("Suggest", ctx, prefix, limit)}
+}
+
+func (_c *MockEquipmentServiceInterface_Suggest_Call) Run(run func(ctx context.Context, prefix string, limit int)) *MockEquipmentServiceInterface_Suggest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_Suggest_Call) Return(suggestions []models.Suggestion, err error) *MockEquipmentServiceInterface_Suggest_Call {
+	_c.Call.Return(suggestions, err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_Suggest_Call) RunAndReturn(run func(ctx context.Context, prefix string, limit int) ([]models.Suggestion, error)) *MockEquipmentServiceInterface_Suggest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateEquipment provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) UpdateEquipment(ctx context.Context, equipmentId int, update models.EquipmentUpdate, image *multipart.FileHeader) (*models.Equipment, error) {
+	ret := _mock.Called(ctx, equipmentId, update, image)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateEquipment")
+	}
+
+	var r0 *models.Equipment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, models.EquipmentUpdate, *multipart.FileHeader) (*models.Equipment, error)); ok {
+		return returnFunc(ctx, equipmentId, update, image)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, models.EquipmentUpdate, *multipart.FileHeader) *models.Equipment); ok {
+		r0 = returnFunc(ctx, equipmentId, update, image)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Equipment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, models.EquipmentUpdate, *multipart.FileHeader) error); ok {
+		r1 = returnFunc(ctx, equipmentId, update, image)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
 
 // MockEquipmentServiceInterface_UpdateEquipment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateEquipment'
 type MockEquipmentServiceInterface_UpdateEquipment_Call struct {
@@ -328,35 +1910,127 @@ type MockEquipmentServiceInterface_UpdateEquipment_Call struct {
 
 // UpdateEquipment is a helper method to define mock.On call
 //   - ctx context.Context
-//   - equipment models.Equipment
-func (_e *MockEquipmentServiceInterface_Expecter) UpdateEquipment(ctx interface{}, equipment interface{}) *MockEquipmentServiceInterface_UpdateEquipment_Call {
-	return &MockEquipmentServiceInterface_UpdateEquipment_Call{Call: _e.mock.On("UpdateEquipment", ctx, equipment)}
+//   - equipmentId int
+//   - update models.EquipmentUpdate
+//   - image *multipart.FileHeader
+func (_e *MockEquipmentServiceInterface_Expecter) UpdateEquipment(ctx interface{}, equipmentId interface{}, update interface{}, image interface{}) *MockEquipmentServiceInterface_UpdateEquipment_Call {
+	return &MockEquipmentServiceInterface_UpdateEquipment_Call{Call: _e.mock.On("UpdateEquipment", ctx, equipmentId, update, image)}
 }
 
-func (_c *MockEquipmentServiceInterface_UpdateEquipment_Call) Run(run func(ctx context.Context, equipment models.Equipment)) *MockEquipmentServiceInterface_UpdateEquipment_Call {
+func (_c *MockEquipmentServiceInterface_UpdateEquipment_Call) Run(run func(ctx context.Context, equipmentId int, update models.EquipmentUpdate, image *multipart.FileHeader)) *MockEquipmentServiceInterface_UpdateEquipment_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 models.Equipment
+		var arg1 int
 		if args[1] != nil {
-			arg1 = args[1].(models.Equipment)
+			arg1 = args[1].(int)
+		}
+		var arg2 models.EquipmentUpdate
+		if args[2] != nil {
+			arg2 = args[2].(models.EquipmentUpdate)
+		}
+		var arg3 *multipart.FileHeader
+		if args[3] != nil {
+			arg3 = args[3].(*multipart.FileHeader)
 		}
 		run(
 			arg0,
 			arg1,
+			arg2,
+			arg3,
 		)
 	})
 	return _c
 }
 
-func (_c *MockEquipmentServiceInterface_UpdateEquipment_Call) Return(err error) *MockEquipmentServiceInterface_UpdateEquipment_Call {
-	_c.Call.Return(err)
+func (_c *MockEquipmentServiceInterface_UpdateEquipment_Call) Return(equipment *models.Equipment, err error) *MockEquipmentServiceInterface_UpdateEquipment_Call {
+	_c.Call.Return(equipment, err)
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_UpdateEquipment_Call) RunAndReturn(run func(ctx context.Context, equipmentId int, update models.EquipmentUpdate, image *multipart.FileHeader) (*models.Equipment, error)) *MockEquipmentServiceInterface_UpdateEquipment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateImage provides a mock function for the type MockEquipmentServiceInterface
+func (_mock *MockEquipmentServiceInterface) UpdateImage(ctx context.Context, equipmentId int, imageId int, update models.EquipmentImageUpdate) (*models.EquipmentImage, error) {
+	ret := _mock.Called(ctx, equipmentId, imageId, update)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateImage")
+	}
+
+	var r0 *models.EquipmentImage
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int, models.EquipmentImageUpdate) (*models.EquipmentImage, error)); ok {
+		return returnFunc(ctx, equipmentId, imageId, update)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int, models.EquipmentImageUpdate) *models.EquipmentImage); ok {
+		r0 = returnFunc(ctx, equipmentId, imageId, update)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.EquipmentImage)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, int, models.EquipmentImageUpdate) error); ok {
+		r1 = returnFunc(ctx, equipmentId, imageId, update)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockEquipmentServiceInterface_UpdateImage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateImage'
+type MockEquipmentServiceInterface_UpdateImage_Call struct {
+	*mock.Call
+}
+
+// UpdateImage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - equipmentId int
+//   - imageId int
+//   - update models.EquipmentImageUpdate
+func (_e *MockEquipmentServiceInterface_Expecter) UpdateImage(ctx interface{}, equipmentId interface{}, imageId interface{}, update interface{}) *MockEquipmentServiceInterface_UpdateImage_Call {
+	return &MockEquipmentServiceInterface_UpdateImage_Call{Call: _e.mock.On("UpdateImage", ctx, equipmentId, imageId, update)}
+}
+
+func (_c *MockEquipmentServiceInterface_UpdateImage_Call) Run(run func(ctx context.Context, equipmentId int, imageId int, update models.EquipmentImageUpdate)) *MockEquipmentServiceInterface_UpdateImage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		var arg3 models.EquipmentImageUpdate
+		if args[3] != nil {
+			arg3 = args[3].(models.EquipmentImageUpdate)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockEquipmentServiceInterface_UpdateImage_Call) Return(equipmentImage *models.EquipmentImage, err error) *MockEquipmentServiceInterface_UpdateImage_Call {
+	_c.Call.Return(equipmentImage, err)
 	return _c
 }
 
-func (_c *MockEquipmentServiceInterface_UpdateEquipment_Call) RunAndReturn(run func(ctx context.Context, equipment models.Equipment) error) *MockEquipmentServiceInterface_UpdateEquipment_Call {
+func (_c *MockEquipmentServiceInterface_UpdateImage_Call) RunAndReturn(run func(ctx context.Context, equipmentId int, imageId int, update models.EquipmentImageUpdate) (*models.EquipmentImage, error)) *MockEquipmentServiceInterface_UpdateImage_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/Gergenus/bookingService/pkg/pagination"
+	"github.com/google/uuid"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+type NotificationService struct {
+	repo repository.NotificationRepositoryInterface
+	log  *slog.Logger
+}
+
+type NotificationServiceInterface interface {
+	Notifications(ctx context.Context, uid uuid.UUID, unreadOnly bool, cursor string, limit int) (*pagination.Page[models.Notification], error)
+	MarkRead(ctx context.Context, id int64, uid uuid.UUID) error
+}
+
+func NewNotificationService(repo repository.NotificationRepositoryInterface, log *slog.Logger) NotificationService {
+	return NotificationService{repo: repo, log: log}
+}
+
+// notificationCursor is the position in the newest-first notification list
+type notificationCursor struct {
+	Id int64 `json:"id"`
+}
+
+func (n *NotificationService) Notifications(ctx context.Context, uid uuid.UUID, unreadOnly bool, cursor string, limit int) (*pagination.Page[models.Notification], error) {
+	const op = "notification_service.Notifications"
+	var after notificationCursor
+	if cursor != "" {
+		err := pagination.DecodeCursor(cursor, &after)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	notifications, err := n.repo.Notifications(ctx, uid, unreadOnly, after.Id, limit+1)
+	if err != nil {
+		n.log.Error("getting notifications error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	result := pagination.Page[models.Notification]{Items: notifications}
+	if len(notifications) > limit {
+		result.Items = notifications[:limit]
+		result.NextCursor, err = pagination.EncodeCursor(notificationCursor{Id: notifications[limit-1].Id})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	result.TotalEstimate, err = n.repo.CountNotifications(ctx, uid, unreadOnly)
+	if err != nil {
+		n.log.Error("counting notifications error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &result, nil
+}
+
+func (n *NotificationService) MarkRead(ctx context.Context, id int64, uid uuid.UUID) error {
+	const op = "notification_service.MarkRead"
+	err := n.repo.MarkRead(ctx, id, uid)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotificationNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrNotificationNotFound)
+		}
+		n.log.Error("marking notification read error", slog.String("op", op), slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
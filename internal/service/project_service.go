@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrProjectAlreadyExists = errors.New("project already exists")
+	ErrProjectNotFound      = errors.New("project not found")
+	ErrInvalidTier          = errors.New("invalid price tier")
+	ErrInvalidRate          = errors.New("invalid rate")
+	ErrInvalidPeriod        = errors.New("invalid period")
+	ErrInvalidPriority      = errors.New("invalid priority")
+	ErrInvalidRole          = errors.New("invalid role")
+)
+
+// priorities are kept in a SMALLINT column
+const maxPriority = 1000
+
+type ProjectService struct {
+	repo repository.ProjectRepositoryInterface
+	log  *slog.Logger
+}
+
+type ProjectServiceInterface interface {
+	CreateProject(ctx context.Context, project models.Project) (int, error)
+	Projects(ctx context.Context) ([]models.Project, error)
+	UserProjects(ctx context.Context, uid uuid.UUID) ([]models.Project, error)
+	AddMember(ctx context.Context, projectId int, uid uuid.UUID) error
+	RemoveMember(ctx context.Context, projectId int, uid uuid.UUID) error
+	SetRate(ctx context.Context, rate models.EquipmentRate) (int, error)
+	Rates(ctx context.Context, equipmentId int) ([]models.EquipmentRate, error)
+	Charges(ctx context.Context, from, to time.Time, projectId int) ([]models.ProjectCharge, error)
+	SetPriority(ctx context.Context, projectId int, priority int) error
+	SetRolePriority(ctx context.Context, rp models.RolePriority) error
+	RolePriorities(ctx context.Context) ([]models.RolePriority, error)
+}
+
+func NewProjectService(repo repository.ProjectRepositoryInterface, log *slog.Logger) ProjectService {
+	return ProjectService{repo: repo, log: log}
+}
+
+func (p *ProjectService) CreateProject(ctx context.Context, project models.Project) (int, error) {
+	const op = "project_service.CreateProject"
+	log := p.log.With(slog.String("op", op))
+	log.Info("creating project", slog.String("grant_code", project.GrantCode))
+	if project.Tier == "" {
+		project.Tier = models.TierInternal
+	}
+	if project.Tier != models.TierInternal && project.Tier != models.TierExternal {
+		return 0, fmt.Errorf("%s: %w", op, ErrInvalidTier)
+	}
+	if project.Priority < 0 || project.Priority > maxPriority {
+		return 0, fmt.Errorf("%s: %w", op, ErrInvalidPriority)
+	}
+	id, err := p.repo.CreateProject(ctx, project)
+	if err != nil {
+		if errors.Is(err, repository.ErrProjectAlreadyExists) {
+			return 0, fmt.Errorf("%s: %w", op, ErrProjectAlreadyExists)
+		}
+		log.Error("creating project error", slog.String("error", err.Error()))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (p *ProjectService) Projects(ctx context.Context) ([]models.Project, error) {
+	const op = "project_service.Projects"
+	projects, err := p.repo.Projects(ctx)
+	if err != nil {
+		p.log.Error("getting projects error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return projects, nil
+}
+
+func (p *ProjectService) UserProjects(ctx context.Context, uid uuid.UUID) ([]models.Project, error) {
+	const op = "project_service.UserProjects"
+	projects, err := p.repo.UserProjects(ctx, uid)
+	if err != nil {
+		p.log.Error("getting user projects error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return projects, nil
+}
+
+func (p *ProjectService) AddMember(ctx context.Context, projectId int, uid uuid.UUID) error {
+	const op = "project_service.AddMember"
+	log := p.log.With(slog.String("op", op))
+	log.Info("adding project member", slog.Int("project_id", projectId), slog.String("user_id", uid.String()))
+	err := p.repo.AddMember(ctx, projectId, uid)
+	if err != nil {
+		if errors.Is(err, repository.ErrProjectNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrProjectNotFound)
+		}
+		log.Error("adding project member error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (p *ProjectService) RemoveMember(ctx context.Context, projectId int, uid uuid.UUID) error {
+	const op = "project_service.RemoveMember"
+	log := p.log.With(slog.String("op", op))
+	log.Info("removing project member", slog.Int("project_id", projectId), slog.String("user_id", uid.String()))
+	err := p.repo.RemoveMember(ctx, projectId, uid)
+	if err != nil {
+		log.Error("removing project member error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (p *ProjectService) SetRate(ctx context.Context, rate models.EquipmentRate) (int, error) {
+	const op = "project_service.SetRate"
+	log := p.log.With(slog.String("op", op))
+	log.Info("setting equipment rate", slog.Int("equipment_id", rate.EquipmentId), slog.String("tier", rate.Tier))
+	if rate.Tier != models.TierInternal && rate.Tier != models.TierExternal {
+		return 0, fmt.Errorf("%s: %w", op, ErrInvalidTier)
+	}
+	if rate.PeakRate < 0 || rate.OffPeakRate < 0 || rate.PeakStart < 0 || rate.PeakEnd > 24 || rate.PeakStart > rate.PeakEnd {
+		return 0, fmt.Errorf("%s: %w", op, ErrInvalidRate)
+	}
+	id, err := p.repo.SetRate(ctx, rate)
+	if err != nil {
+		log.Error("setting equipment rate error", slog.String("error", err.Error()))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (p *ProjectService) Rates(ctx context.Context, equipmentId int) ([]models.EquipmentRate, error) {
+	const op = "project_service.Rates"
+	rates, err := p.repo.Rates(ctx, equipmentId)
+	if err != nil {
+		p.log.Error("getting equipment rates error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return rates, nil
+}
+
+// Charges sums the cost of bookings in [from, to) per project and calendar month.
+// Bookings on equipment without a rate for the project's tier are counted with zero amount
+func (p *ProjectService) Charges(ctx context.Context, from, to time.Time, projectId int) ([]models.ProjectCharge, error) {
+	const op = "project_service.Charges"
+	log := p.log.With(slog.String("op", op))
+	log.Info("computing project charges", slog.Time("from", from), slog.Time("to", to), slog.Int("project_id", projectId))
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidPeriod)
+	}
+	bookings, err := p.repo.ChargeableBookings(ctx, from, to, projectId)
+	if err != nil {
+		log.Error("getting chargeable bookings error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	charges := []models.ProjectCharge{}
+	index := make(map[string]int)
+	locations := make(map[string]*time.Location)
+	for _, b := range bookings {
+		loc, ok := locations[b.TimeZone]
+		if !ok {
+			loc, err = time.LoadLocation(b.TimeZone)
+			if err != nil {
+				log.Warn("unknown equipment time zone, using UTC", slog.String("time_zone", b.TimeZone))
+				loc = time.UTC
+			}
+			locations[b.TimeZone] = loc
+		}
+		b.Start, b.End = b.Start.In(loc), b.End.In(loc)
+		month := b.Start.Format("2006-01")
+		key := fmt.Sprintf("%d/%s", b.ProjectId, month)
+		i, ok := index[key]
+		if !ok {
+			charges = append(charges, models.ProjectCharge{ProjectId: b.ProjectId, GrantCode: b.GrantCode, Month: month})
+			i = len(charges) - 1
+			index[key] = i
+		}
+		charge := &charges[i]
+		charge.Bookings++
+		if b.Rate == nil {
+			charge.OffPeakHours += b.End.Sub(b.Start).Hours()
+			continue
+		}
+		peak, offPeak := splitPeakHours(b.Start, b.End, b.Rate.PeakStart, b.Rate.PeakEnd)
+		charge.PeakHours += peak.Hours()
+		charge.OffPeakHours += offPeak.Hours()
+		charge.Amount += peak.Hours()*b.Rate.PeakRate + offPeak.Hours()*b.Rate.OffPeakRate
+	}
+	return charges, nil
+}
+
+// splitPeakHours splits [start, end) into the time spent inside the daily
+// [peakStart, peakEnd) hour window and the time spent outside of it. The window
+// is wall clock time in start's location, so it stays put across DST changes
+func splitPeakHours(start, end time.Time, peakStart, peakEnd int) (time.Duration, time.Duration) {
+	var peak time.Duration
+	if !end.After(start) {
+		return 0, 0
+	}
+	loc := start.Location()
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	for ; day.Before(end); day = day.AddDate(0, 0, 1) {
+		windowStart := time.Date(day.Year(), day.Month(), day.Day(), peakStart, 0, 0, 0, loc)
+		windowEnd := time.Date(day.Year(), day.Month(), day.Day(), peakEnd, 0, 0, 0, loc)
+		if windowStart.Before(start) {
+			windowStart = start
+		}
+		if windowEnd.After(end) {
+			windowEnd = end
+		}
+		if windowEnd.After(windowStart) {
+			peak += windowEnd.Sub(windowStart)
+		}
+	}
+	return peak, end.Sub(start) - peak
+}
+
+func (p *ProjectService) SetPriority(ctx context.Context, projectId int, priority int) error {
+	const op = "project_service.SetPriority"
+	log := p.log.With(slog.String("op", op))
+	log.Info("setting project priority", slog.Int("project_id", projectId), slog.Int("priority", priority))
+	if priority < 0 || priority > maxPriority {
+		return fmt.Errorf("%s: %w", op, ErrInvalidPriority)
+	}
+	err := p.repo.SetPriority(ctx, projectId, priority)
+	if err != nil {
+		if errors.Is(err, repository.ErrProjectNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrProjectNotFound)
+		}
+		log.Error("setting project priority error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (p *ProjectService) SetRolePriority(ctx context.Context, rp models.RolePriority) error {
+	const op = "project_service.SetRolePriority"
+	log := p.log.With(slog.String("op", op))
+	log.Info("setting role priority", slog.String("role", rp.Role), slog.Int("priority", rp.Priority))
+	if rp.Role != "admin" && rp.Role != "scientist" {
+		return fmt.Errorf("%s: %w", op, ErrInvalidRole)
+	}
+	if rp.Priority < 0 || rp.Priority > maxPriority {
+		return fmt.Errorf("%s: %w", op, ErrInvalidPriority)
+	}
+	err := p.repo.SetRolePriority(ctx, rp)
+	if err != nil {
+		log.Error("setting role priority error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (p *ProjectService) RolePriorities(ctx context.Context) ([]models.RolePriority, error) {
+	const op = "project_service.RolePriorities"
+	priorities, err := p.repo.RolePriorities(ctx)
+	if err != nil {
+		p.log.Error("getting role priorities error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return priorities, nil
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/Gergenus/bookingService/pkg/export"
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	// reports over more bookings than this are generated in the background
+	asyncReportBookings = 5000
+	reportTimeout       = 10 * time.Minute
+)
+
+var (
+	ErrInvalidReportKind   = errors.New("invalid report kind")
+	ErrInvalidReportFormat = errors.New("invalid report format")
+	ErrReportNotFound      = errors.New("report not found")
+	ErrReportNotReady      = errors.New("report not ready")
+)
+
+var reportColumns = map[string]string{
+	models.ReportByEquipment: "equipment",
+	models.ReportByUser:      "user",
+	models.ReportByProject:   "project",
+}
+
+type ReportService struct {
+	repo    repository.ReportRepositoryInterface
+	storage repository.ReportStorageInterface
+	log     *slog.Logger
+	tasks   chan reportTask
+}
+
+type reportTask struct {
+	job models.ReportJob
+	req models.ReportRequest
+}
+
+type ReportServiceInterface interface {
+	// Generate returns the rendered file for small reports and a pending job for large ones
+	Generate(ctx context.Context, req models.ReportRequest) (*models.ReportFile, *models.ReportJob, error)
+	Job(ctx context.Context, id uuid.UUID, uid uuid.UUID) (*models.ReportJob, error)
+	Download(ctx context.Context, id uuid.UUID, uid uuid.UUID) (*models.ReportJob, *minio.Object, error)
+}
+
+func NewReportService(repo repository.ReportRepositoryInterface, storage repository.ReportStorageInterface, log *slog.Logger) ReportService {
+	return ReportService{repo: repo, storage: storage, log: log, tasks: make(chan reportTask)}
+}
+
+func (r *ReportService) Generate(ctx context.Context, req models.ReportRequest) (*models.ReportFile, *models.ReportJob, error) {
+	const op = "report_service.Generate"
+	log := r.log.With(slog.String("op", op))
+	log.Info("generating report", slog.String("kind", req.Kind), slog.String("format", req.Format),
+		slog.Time("from", req.From), slog.Time("to", req.To))
+	if _, ok := reportColumns[req.Kind]; !ok {
+		return nil, nil, fmt.Errorf("%s: %w", op, ErrInvalidReportKind)
+	}
+	if req.Format != export.FormatCSV && req.Format != export.FormatXLSX && req.Format != export.FormatPDF {
+		return nil, nil, fmt.Errorf("%s: %w", op, ErrInvalidReportFormat)
+	}
+	if !req.From.Before(req.To) {
+		return nil, nil, fmt.Errorf("%s: %w", op, ErrInvalidPeriod)
+	}
+	count, err := r.repo.CountBookings(ctx, req.From, req.To)
+	if err != nil {
+		log.Error("counting bookings error", slog.String("error", err.Error()))
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if count <= asyncReportBookings {
+		file, err := r.render(ctx, req)
+		if err != nil {
+			log.Error("rendering report error", slog.String("error", err.Error()))
+			return nil, nil, fmt.Errorf("%s: %w", op, err)
+		}
+		return file, nil, nil
+	}
+
+	job := models.ReportJob{
+		Id:        uuid.New(),
+		UserId:    req.UserId,
+		Kind:      req.Kind,
+		Format:    req.Format,
+		From:      req.From,
+		To:        req.To,
+		Status:    models.ReportPending,
+		CreatedAt: time.Now(),
+	}
+	err = r.repo.CreateJob(ctx, job)
+	if err != nil {
+		log.Error("creating report job error", slog.String("error", err.Error()))
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	select {
+	case r.tasks <- reportTask{job: job, req: req}:
+	case <-ctx.Done():
+		// the job stays pending until RunJobs fails it as stale
+		return nil, nil, fmt.Errorf("%s: %w", op, ctx.Err())
+	}
+	return nil, &job, nil
+}
+
+// RunJobs renders the queued reports until ctx is done. Jobs pending for longer than reportTimeout
+// were lost, e.g. to a restart, and are marked failed
+func (r *ReportService) RunJobs(ctx context.Context) {
+	const op = "report_service.RunJobs"
+	log := r.log.With(slog.String("op", op))
+	ticker := time.NewTicker(reportTimeout)
+	defer ticker.Stop()
+	r.failStaleJobs(ctx, log)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case task := <-r.tasks:
+			go r.runJob(ctx, task.job, task.req)
+		case <-ticker.C:
+			r.failStaleJobs(ctx, log)
+		}
+	}
+}
+
+func (r *ReportService) failStaleJobs(ctx context.Context, log *slog.Logger) {
+	failed, err := r.repo.FailStaleJobs(ctx, time.Now().Add(-reportTimeout))
+	if err != nil {
+		log.Error("failing stale report jobs error", slog.String("error", err.Error()))
+		return
+	}
+	if failed > 0 {
+		log.Warn("stale report jobs failed", slog.Int("jobs", failed))
+	}
+}
+
+// runJob renders the report detached from the request context and stores it in s3
+func (r *ReportService) runJob(ctx context.Context, job models.ReportJob, req models.ReportRequest) {
+	const op = "report_service.runJob"
+	log := r.log.With(slog.String("op", op), slog.String("job_id", job.Id.String()))
+	ctx, cancel := context.WithTimeout(ctx, reportTimeout)
+	defer cancel()
+	// the job is finished even when rendering was cancelled by a shutdown
+	finishCtx := context.WithoutCancel(ctx)
+
+	status, objectName := models.ReportFailed, ""
+	defer func() {
+		if p := recover(); p != nil {
+			log.Error("report job panicked", slog.Any("panic", p))
+			err := r.repo.FinishJob(finishCtx, job.Id, models.ReportFailed, "")
+			if err != nil {
+				log.Error("finishing report job error", slog.String("error", err.Error()))
+			}
+		}
+	}()
+	file, err := r.render(ctx, req)
+	if err != nil {
+		log.Error("rendering report error", slog.String("error", err.Error()))
+	} else {
+		objectName, err = r.storage.PutReport(ctx, job.Id.String()+"-"+file.Name, file.Data, file.ContentType)
+		if err != nil {
+			log.Error("storing report error", slog.String("error", err.Error()))
+		} else {
+			status = models.ReportReady
+		}
+	}
+	err = r.repo.FinishJob(finishCtx, job.Id, status, objectName)
+	if err != nil {
+		log.Error("finishing report job error", slog.String("error", err.Error()))
+		return
+	}
+	log.Info("report job finished", slog.String("status", status))
+}
+
+func (r *ReportService) render(ctx context.Context, req models.ReportRequest) (*models.ReportFile, error) {
+	rows, err := r.repo.Usage(ctx, req.Kind, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	table := export.Table{
+		Title: fmt.Sprintf("Usage by %s, %s - %s", reportColumns[req.Kind], req.From.Format(time.DateOnly),
+			req.To.AddDate(0, 0, -1).Format(time.DateOnly)),
+		Headers: []string{"id", reportColumns[req.Kind], "bookings", "checked in", "booked hours", "used hours"},
+	}
+	for _, row := range rows {
+		table.Rows = append(table.Rows, []string{
+			row.Key,
+			row.Label,
+			strconv.Itoa(row.Bookings),
+			strconv.Itoa(row.CheckedIn),
+			strconv.FormatFloat(row.BookedHours, 'f', 2, 64),
+			strconv.FormatFloat(row.UsedHours, 'f', 2, 64),
+		})
+	}
+	data, contentType, err := export.Render(table, req.Format)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ReportFile{
+		Name:        fmt.Sprintf("usage-%s-%s-%s.%s", req.Kind, req.From.Format("20060102"), req.To.Format("20060102"), req.Format),
+		ContentType: contentType,
+		Data:        data,
+	}, nil
+}
+
+func (r *ReportService) Job(ctx context.Context, id uuid.UUID, uid uuid.UUID) (*models.ReportJob, error) {
+	const op = "report_service.Job"
+	job, err := r.repo.Job(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrReportNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, ErrReportNotFound)
+		}
+		r.log.Error("getting report job error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if job.UserId != uid {
+		return nil, fmt.Errorf("%s: %w", op, ErrReportNotFound)
+	}
+	if job.Status == models.ReportReady {
+		job.DownloadURL = fmt.Sprintf("/api/v1/reports/%s/download", job.Id)
+	}
+	return job, nil
+}
+
+func (r *ReportService) Download(ctx context.Context, id uuid.UUID, uid uuid.UUID) (*models.ReportJob, *minio.Object, error) {
+	const op = "report_service.Download"
+	job, err := r.Job(ctx, id, uid)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if job.Status != models.ReportReady {
+		return nil, nil, fmt.Errorf("%s: %w", op, ErrReportNotReady)
+	}
+	obj, err := r.storage.Report(ctx, job.ObjectName)
+	if err != nil {
+		r.log.Error("getting report object error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return job, obj, nil
+}
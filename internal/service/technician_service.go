@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Gergenus/bookingService/internal/models"
+	"github.com/Gergenus/bookingService/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	maxShiftLength = 24 * time.Hour
+	// schedules are read in windows of at most this length
+	maxSchedulePeriod = 62 * 24 * time.Hour
+)
+
+var (
+	ErrTechnicianAlreadyExists = errors.New("technician already exists")
+	ErrTechnicianNotFound      = errors.New("technician not found")
+	ErrTechnicianAssigned      = errors.New("technician has upcoming assignments")
+	ErrInvalidShift            = errors.New("invalid shift")
+	ErrShiftNotFound           = errors.New("shift not found")
+	ErrShiftOverlap            = errors.New("shift overlaps another shift")
+	ErrShiftHasAssignments     = errors.New("shift has upcoming assignments")
+	ErrNoOperatorAvailable     = errors.New("no operator available")
+)
+
+type TechnicianService struct {
+	repo repository.TechnicianRepositoryInterface
+	log  *slog.Logger
+}
+
+type TechnicianServiceInterface interface {
+	AddTechnician(ctx context.Context, uid uuid.UUID) error
+	Technicians(ctx context.Context) ([]models.Technician, error)
+	RemoveTechnician(ctx context.Context, uid uuid.UUID) error
+	AddShift(ctx context.Context, shift models.Shift) (int, error)
+	Shifts(ctx context.Context, uid uuid.UUID, from, to time.Time) ([]models.Shift, error)
+	DeleteShift(ctx context.Context, uid uuid.UUID, shiftId int) error
+	Schedule(ctx context.Context, uid uuid.UUID, from, to time.Time) (*models.TechnicianSchedule, error)
+}
+
+func NewTechnicianService(repo repository.TechnicianRepositoryInterface, log *slog.Logger) TechnicianService {
+	return TechnicianService{repo: repo, log: log}
+}
+
+func (t *TechnicianService) AddTechnician(ctx context.Context, uid uuid.UUID) error {
+	const op = "technician_service.AddTechnician"
+	log := t.log.With(slog.String("op", op))
+	log.Info("adding technician", slog.String("user_id", uid.String()))
+	err := t.repo.AddTechnician(ctx, uid)
+	if err != nil {
+		if errors.Is(err, repository.ErrTechnicianAlreadyExists) {
+			return fmt.Errorf("%s: %w", op, ErrTechnicianAlreadyExists)
+		}
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+		log.Error("adding technician error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (t *TechnicianService) Technicians(ctx context.Context) ([]models.Technician, error) {
+	const op = "technician_service.Technicians"
+	technicians, err := t.repo.Technicians(ctx)
+	if err != nil {
+		t.log.Error("getting technicians error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return technicians, nil
+}
+
+// RemoveTechnician is refused while the technician operates upcoming bookings, they have to be
+// moved or cancelled first
+func (t *TechnicianService) RemoveTechnician(ctx context.Context, uid uuid.UUID) error {
+	const op = "technician_service.RemoveTechnician"
+	log := t.log.With(slog.String("op", op))
+	log.Info("removing technician", slog.String("user_id", uid.String()))
+	err := t.repo.RemoveTechnician(ctx, uid)
+	if err != nil {
+		if errors.Is(err, repository.ErrTechnicianNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrTechnicianNotFound)
+		}
+		if errors.Is(err, repository.ErrTechnicianAssigned) {
+			return fmt.Errorf("%s: %w", op, ErrTechnicianAssigned)
+		}
+		log.Error("removing technician error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (t *TechnicianService) AddShift(ctx context.Context, shift models.Shift) (int, error) {
+	const op = "technician_service.AddShift"
+	log := t.log.With(slog.String("op", op))
+	log.Info("adding shift", slog.String("technician_id", shift.TechnicianId.String()),
+		slog.Time("start_time", shift.StartTime), slog.Time("end_time", shift.EndTime))
+	if !shift.StartTime.Before(shift.EndTime) || shift.EndTime.Sub(shift.StartTime) > maxShiftLength {
+		return 0, fmt.Errorf("%s: %w", op, ErrInvalidShift)
+	}
+	id, err := t.repo.AddShift(ctx, shift)
+	if err != nil {
+		if errors.Is(err, repository.ErrTechnicianNotFound) {
+			return 0, fmt.Errorf("%s: %w", op, ErrTechnicianNotFound)
+		}
+		if errors.Is(err, repository.ErrShiftOverlap) {
+			return 0, fmt.Errorf("%s: %w", op, ErrShiftOverlap)
+		}
+		log.Error("adding shift error", slog.String("error", err.Error()))
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (t *TechnicianService) Shifts(ctx context.Context, uid uuid.UUID, from, to time.Time) ([]models.Shift, error) {
+	const op = "technician_service.Shifts"
+	if !from.Before(to) || to.Sub(from) > maxSchedulePeriod {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidPeriod)
+	}
+	shifts, err := t.repo.Shifts(ctx, uid, from, to)
+	if err != nil {
+		t.log.Error("getting shifts error", slog.String("op", op), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return shifts, nil
+}
+
+// DeleteShift is refused while the technician operates upcoming bookings in the shift
+func (t *TechnicianService) DeleteShift(ctx context.Context, uid uuid.UUID, shiftId int) error {
+	const op = "technician_service.DeleteShift"
+	log := t.log.With(slog.String("op", op))
+	log.Info("deleting shift", slog.String("technician_id", uid.String()), slog.Int("shift_id", shiftId))
+	err := t.repo.DeleteShift(ctx, uid, shiftId)
+	if err != nil {
+		if errors.Is(err, repository.ErrShiftNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrShiftNotFound)
+		}
+		if errors.Is(err, repository.ErrShiftHasAssignments) {
+			return fmt.Errorf("%s: %w", op, ErrShiftHasAssignments)
+		}
+		log.Error("deleting shift error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// Schedule returns the technician's shifts and the bookings they operate in [from, to)
+func (t *TechnicianService) Schedule(ctx context.Context, uid uuid.UUID, from, to time.Time) (*models.TechnicianSchedule, error) {
+	const op = "technician_service.Schedule"
+	log := t.log.With(slog.String("op", op))
+	if !from.Before(to) || to.Sub(from) > maxSchedulePeriod {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidPeriod)
+	}
+	_, err := t.repo.Technician(ctx, uid)
+	if err != nil {
+		if errors.Is(err, repository.ErrTechnicianNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, ErrTechnicianNotFound)
+		}
+		log.Error("getting technician error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	shifts, err := t.repo.Shifts(ctx, uid, from, to)
+	if err != nil {
+		log.Error("getting shifts error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	assignments, err := t.repo.Assignments(ctx, uid, from, to)
+	if err != nil {
+		log.Error("getting assignments error", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &models.TechnicianSchedule{Shifts: shifts, Assignments: assignments}, nil
+}
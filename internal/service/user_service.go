@@ -20,6 +20,7 @@ var (
 	ErrPasswordMismatch      = errors.New("password mismatch")
 	ErrTokenExpired          = errors.New("token expired")
 	ErrInvalidRefreshSession = errors.New("invalid refresh session")
+	ErrBadgeTaken            = errors.New("badge is assigned to another user")
 )
 
 type UserService struct {
@@ -33,6 +34,8 @@ type UserServiceInterface interface {
 	CreateUser(ctx context.Context, username, role, email, password string) (*uuid.UUID, error)
 	Login(ctx context.Context, email, password, userAgent, ip string) (string, string, error)
 	RefreshToken(ctx context.Context, oldRefresh uuid.UUID, userAgent, ip string, oldAccessToken string) (*uuid.UUID, string, error)
+	SetTimeZone(ctx context.Context, uid uuid.UUID, timeZone string) (string, error)
+	SetBadge(ctx context.Context, uid uuid.UUID, badgeId string) error
 }
 
 func NewUserService(userRepo repository.UserRepositoryInterface, log *slog.Logger, jwtTkn jwtpkg.TokenService, RefreshTTL time.Duration) *UserService {
@@ -132,3 +135,48 @@ func (u *UserService) RefreshToken(ctx context.Context, oldRefresh uuid.UUID, us
 	}
 	return &newRefresh, token, nil
 }
+
+// SetTimeZone changes the user's preferred zone and returns an access token carrying it
+func (u *UserService) SetTimeZone(ctx context.Context, uid uuid.UUID, timeZone string) (string, error) {
+	const op = "service.SetTimeZone"
+	log := u.log.With(slog.String("op", op))
+	log.Info("setting user time zone", slog.String("time_zone", timeZone))
+	if timeZone != "" && !validTimeZone(timeZone) {
+		return "", fmt.Errorf("%s: %w", op, ErrInvalidTimeZone)
+	}
+	err := u.userRepo.SetTimeZone(ctx, uid, timeZone)
+	if err != nil {
+		log.Error("setting user time zone error", slog.String("error", err.Error()))
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	user, err := u.userRepo.User(ctx, uid)
+	if err != nil {
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	token, err := u.jwtTkn.GenerateAccessToken(*user)
+	if err != nil {
+		log.Error("failed to create access token", slog.String("error", err.Error()))
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	return token, nil
+}
+
+// SetBadge assigns the badge users present at instrument interlocks, an empty id removes it
+func (u *UserService) SetBadge(ctx context.Context, uid uuid.UUID, badgeId string) error {
+	const op = "service.SetBadge"
+	log := u.log.With(slog.String("op", op))
+	log.Info("setting user badge", slog.String("user_id", uid.String()))
+	err := u.userRepo.SetBadge(ctx, uid, badgeId)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+		if errors.Is(err, repository.ErrBadgeTaken) {
+			return fmt.Errorf("%s: %w", op, ErrBadgeTaken)
+		}
+		log.Error("setting user badge error", slog.String("error", err.Error()))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
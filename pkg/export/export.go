@@ -0,0 +1,136 @@
+package export
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatPDF  = "pdf"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// DejaVu covers cyrillic, the pdf core fonts do not
+//
+//go:embed fonts/DejaVuSansCondensed.ttf
+var dejaVuSans []byte
+
+type Table struct {
+	Title   string
+	Headers []string
+	Rows    [][]string
+}
+
+// Render returns the table encoded in format and its content type
+func Render(t Table, format string) ([]byte, string, error) {
+	switch format {
+	case FormatCSV:
+		data, err := CSV(t)
+		return data, "text/csv", err
+	case FormatXLSX:
+		data, err := XLSX(t)
+		return data, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", err
+	case FormatPDF:
+		data, err := PDF(t)
+		return data, "application/pdf", err
+	default:
+		return nil, "", ErrUnsupportedFormat
+	}
+}
+
+func CSV(t Table) ([]byte, error) {
+	const op = "export.CSV"
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	err := w.Write(t.Headers)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	err = w.WriteAll(t.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return buf.Bytes(), nil
+}
+
+func XLSX(t Table) ([]byte, error) {
+	const op = "export.XLSX"
+	f := excelize.NewFile()
+	defer f.Close()
+	sheet := f.GetSheetName(0)
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	err = sw.SetRow("A1", toCells(t.Headers))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for i, row := range t.Rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		err = sw.SetRow(cell, toCells(row))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	err = sw.Flush()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return buf.Bytes(), nil
+}
+
+func toCells(values []string) []any {
+	cells := make([]any, len(values))
+	for i, v := range values {
+		cells[i] = v
+	}
+	return cells
+}
+
+func PDF(t Table) ([]byte, error) {
+	const op = "export.PDF"
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes("dejavu", "", dejaVuSans)
+	pdf.SetFont("dejavu", "", 14)
+	pdf.AddPage()
+	pdf.CellFormat(0, 10, t.Title, "", 1, "L", false, 0, "")
+
+	pdf.SetFont("dejavu", "", 9)
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	width := (pageWidth - left - right) / float64(max(len(t.Headers), 1))
+	pdf.SetFillColor(230, 230, 230)
+	for _, h := range t.Headers {
+		pdf.CellFormat(width, 7, h, "1", 0, "L", true, 0, "")
+	}
+	pdf.Ln(-1)
+	for _, row := range t.Rows {
+		for _, v := range row {
+			pdf.CellFormat(width, 6, v, "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	buf := &bytes.Buffer{}
+	err := pdf.Output(buf)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return buf.Bytes(), nil
+}
@@ -1,6 +1,11 @@
 package hash
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 
 	"golang.org/x/crypto/bcrypt"
@@ -18,3 +23,33 @@ func HashPassword(password string) (string, error) {
 func CheckPassword(hashedPassword, password string) bool {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password)) == nil
 }
+
+// NewToken returns a random url-safe token made of n random bytes
+func NewToken(n int) (string, error) {
+	const op = "hash.NewToken"
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken digests a random token for storage, unlike passwords such tokens
+// have enough entropy for a fast hash
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Sign returns the hex HMAC-SHA256 of the message under the secret
+func Sign(secret, message string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidSignature compares in constant time, so a signature can't be guessed byte by byte
+func ValidSignature(secret, message, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, message)), []byte(signature))
+}
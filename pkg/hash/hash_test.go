@@ -0,0 +1,93 @@
+package hash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		name              string
+		secret            string
+		message           string
+		expectedSignature string
+	}{
+		{
+			// RFC 4231 test case 2
+			name:              "rfc 4231",
+			secret:            "Jefe",
+			message:           "what do ya want for nothing?",
+			expectedSignature: "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+		},
+		{
+			name:              "empty",
+			secret:            "",
+			message:           "",
+			expectedSignature: "b613679a0814d9ec772f95d778c35fc5ff1697c493715653c6c712144292c5ad",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedSignature, Sign(tt.secret, tt.message))
+		})
+	}
+}
+
+func TestValidSignature(t *testing.T) {
+	const (
+		secret  = "image-secret"
+		message = "equipment/42/thumb.jpg:1767225600"
+	)
+	signature := Sign(secret, message)
+	tests := []struct {
+		name      string
+		secret    string
+		message   string
+		signature string
+		expected  bool
+	}{
+		{
+			name:      "valid",
+			secret:    secret,
+			message:   message,
+			signature: signature,
+			expected:  true,
+		},
+		{
+			name:      "other secret",
+			secret:    "other-secret",
+			message:   message,
+			signature: signature,
+		},
+		{
+			name:      "other message",
+			secret:    secret,
+			message:   "equipment/43/thumb.jpg:1767225600",
+			signature: signature,
+		},
+		{
+			name:      "tampered",
+			secret:    secret,
+			message:   message,
+			signature: signature[:len(signature)-1] + "0",
+		},
+		{
+			name:      "truncated",
+			secret:    secret,
+			message:   message,
+			signature: signature[:32],
+		},
+		{
+			name:      "empty",
+			secret:    secret,
+			message:   message,
+			signature: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ValidSignature(tt.secret, tt.message, tt.signature))
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrTooLarge        = errors.New("image too large")
+	ErrInvalidImage    = errors.New("invalid image")
+)
+
+const (
+	// MaxSize is the largest accepted upload in bytes
+	MaxSize = 10 << 20
+	// maxPixels keeps decompression bombs from being decoded
+	maxPixels = 40_000_000
+
+	renditionJPEGQuality = 85
+)
+
+// Rendition is a downscaled copy of an image whose longer side fits Size
+type Rendition struct {
+	Name string
+	Size int
+}
+
+var Renditions = []Rendition{
+	{Name: "thumb", Size: 200},
+	{Name: "medium", Size: 800},
+}
+
+type Image struct {
+	Data        []byte
+	ContentType string
+	Ext         string
+}
+
+// Processed is an upload with its metadata stripped and its renditions by name
+type Processed struct {
+	Original   Image
+	Renditions map[string]Image
+}
+
+// Process accepts JPEG, PNG and WebP by their magic bytes. The original keeps its encoding,
+// only EXIF, XMP, IPTC and text metadata are removed from it, except for the JPEG orientation.
+// Renditions are JPEG for JPEG originals and PNG otherwise, so transparency survives
+func Process(data []byte) (*Processed, error) {
+	const op = "imaging.Process"
+	if len(data) > MaxSize {
+		return nil, fmt.Errorf("%s: %w", op, ErrTooLarge)
+	}
+	original := Image{ContentType: http.DetectContentType(data)}
+	switch original.ContentType {
+	case "image/jpeg":
+		original.Ext = ".jpg"
+	case "image/png":
+		original.Ext = ".png"
+	case "image/webp":
+		original.Ext = ".webp"
+	default:
+		return nil, fmt.Errorf("%s: %w", op, ErrUnsupportedType)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidImage)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, fmt.Errorf("%s: %w", op, ErrTooLarge)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidImage)
+	}
+
+	orientation := 1
+	switch original.ContentType {
+	case "image/jpeg":
+		original.Data, orientation, err = stripJPEG(data)
+	case "image/png":
+		original.Data, err = stripPNG(data)
+	case "image/webp":
+		original.Data, err = stripWebP(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	processed := &Processed{Original: original, Renditions: make(map[string]Image, len(Renditions))}
+	for _, r := range Renditions {
+		// orienting after the downscale touches far fewer pixels
+		small := orient(fit(img, r.Size), orientation)
+		var (
+			buf       bytes.Buffer
+			rendition Image
+		)
+		if original.ContentType == "image/jpeg" {
+			err = jpeg.Encode(&buf, small, &jpeg.Options{Quality: renditionJPEGQuality})
+			rendition.ContentType, rendition.Ext = "image/jpeg", ".jpg"
+		} else {
+			err = png.Encode(&buf, small)
+			rendition.ContentType, rendition.Ext = "image/png", ".png"
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		rendition.Data = buf.Bytes()
+		processed.Renditions[r.Name] = rendition
+	}
+	return processed, nil
+}
+
+// fit scales the image down so its longer side is at most size, smaller images keep their size
+func fit(img image.Image, size int) *image.RGBA {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > size || h > size {
+		if w >= h {
+			w, h = size, max(1, h*size/w)
+		} else {
+			w, h = max(1, w*size/h), size
+		}
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// orient turns the image upright according to its EXIF orientation
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	// orientations 5 to 8 swap the sides
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			dst.SetRGBA(dx, dy, img.RGBAAt(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
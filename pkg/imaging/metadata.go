@@ -0,0 +1,163 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// stripJPEG drops the APP1 (EXIF, XMP), APP13 (IPTC) and comment segments. The orientation is
+// the only EXIF field kept, in a minimal EXIF segment, so the original is still shown upright
+func stripJPEG(data []byte) ([]byte, int, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, 0, ErrInvalidImage
+	}
+	orientation := 1
+	out := make([]byte, 0, len(data))
+	var segments []byte
+	i := 2
+	for {
+		if i+4 > len(data) || data[i] != 0xFF {
+			return nil, 0, ErrInvalidImage
+		}
+		marker := data[i+1]
+		if marker == 0xFF {
+			// fill byte
+			i++
+			continue
+		}
+		if marker == 0xDA {
+			// start of scan, the entropy-coded data and everything after it is kept as is
+			segments = append(segments, data[i:]...)
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, 0, ErrInvalidImage
+		}
+		payload := data[i+4 : end]
+		switch {
+		case marker == 0xE1 && bytes.HasPrefix(payload, []byte("Exif\x00\x00")):
+			if o := exifOrientation(payload[6:]); o != 0 {
+				orientation = o
+			}
+		case marker == 0xE1, marker == 0xED, marker == 0xFE:
+		default:
+			segments = append(segments, data[i:end]...)
+		}
+		i = end
+	}
+	out = append(out, 0xFF, 0xD8)
+	if orientation > 1 && orientation <= 8 {
+		out = append(out, orientationSegment(orientation)...)
+	}
+	return append(out, segments...), orientation, nil
+}
+
+// exifOrientation reads the orientation tag of IFD0 of a TIFF structure, 0 when there is none
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < count; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		// tag 0x0112 of type SHORT
+		if order.Uint16(tiff[entry:]) == 0x0112 && order.Uint16(tiff[entry+2:]) == 3 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 0
+}
+
+// orientationSegment is an APP1 segment whose EXIF only holds the orientation
+func orientationSegment(orientation int) []byte {
+	payload := []byte("Exif\x00\x00" +
+		"MM\x00\x2A\x00\x00\x00\x08" + // big endian TIFF header, IFD0 right after it
+		"\x00\x01" + // one entry
+		"\x01\x12\x00\x03\x00\x00\x00\x01") // orientation, SHORT, count 1
+	payload = binary.BigEndian.AppendUint16(payload, uint16(orientation))
+	payload = append(payload, 0, 0, 0, 0, 0, 0) // value padding, no next IFD
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// metadata chunks of PNG
+var pngMetadata = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+
+func stripPNG(data []byte) ([]byte, error) {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return nil, ErrInvalidImage
+	}
+	out := append(make([]byte, 0, len(data)), signature...)
+	for i := len(signature); i < len(data); {
+		if i+12 > len(data) {
+			return nil, ErrInvalidImage
+		}
+		end := i + 12 + int(binary.BigEndian.Uint32(data[i:]))
+		if end > len(data) || end < i {
+			return nil, ErrInvalidImage
+		}
+		if !pngMetadata[string(data[i+4:i+8])] {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return out, nil
+}
+
+// VP8X flags of the metadata chunks
+const (
+	webpFlagXMP  = 0x04
+	webpFlagEXIF = 0x08
+)
+
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrInvalidImage
+	}
+	out := append(make([]byte, 0, len(data)), data[:12]...)
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, ErrInvalidImage
+		}
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		// chunks are padded to an even size
+		end := i + 8 + size + size%2
+		if end > len(data) || end < i {
+			return nil, ErrInvalidImage
+		}
+		switch fourcc := string(data[i : i+4]); fourcc {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			start := len(out)
+			out = append(out, data[i:end]...)
+			if size > 0 {
+				out[start+8] &^= webpFlagEXIF | webpFlagXMP
+			}
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
@@ -0,0 +1,358 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jpegSegment builds a marker segment with its length
+func jpegSegment(marker byte, payload string) []byte {
+	segment := []byte{0xFF, marker}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// exifPayload is an APP1 EXIF payload with a make tag and, unless 0, the orientation
+func exifPayload(order binary.AppendByteOrder, orientation int) string {
+	tiff := []byte("MM\x00\x2A")
+	if order == binary.LittleEndian {
+		tiff = []byte("II\x2A\x00")
+	}
+	tiff = order.AppendUint32(tiff, 8)
+	count := 1
+	if orientation != 0 {
+		count++
+	}
+	tiff = order.AppendUint16(tiff, uint16(count))
+	// make, ASCII, stored elsewhere
+	tiff = order.AppendUint16(tiff, 0x010F)
+	tiff = order.AppendUint16(tiff, 2)
+	tiff = order.AppendUint32(tiff, 6)
+	tiff = order.AppendUint32(tiff, 0)
+	if orientation != 0 {
+		tiff = order.AppendUint16(tiff, 0x0112)
+		tiff = order.AppendUint16(tiff, 3)
+		tiff = order.AppendUint32(tiff, 1)
+		tiff = order.AppendUint16(tiff, uint16(orientation))
+		tiff = append(tiff, 0, 0)
+	}
+	tiff = append(tiff, 0, 0, 0, 0)
+	return "Exif\x00\x00" + string(tiff)
+}
+
+func concat(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+var (
+	soi       = []byte{0xFF, 0xD8}
+	jfif      = jpegSegment(0xE0, "JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00")
+	xmp       = jpegSegment(0xE1, "http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta>secret</x:xmpmeta>")
+	iptc      = jpegSegment(0xED, "Photoshop 3.0\x00secret")
+	comment   = jpegSegment(0xFE, "secret comment")
+	quantize  = jpegSegment(0xDB, "\x00quantization")
+	scan      = []byte{0xFF, 0xDA, 0x00, 0x04, 0x01, 0x02, 0x11, 0x22, 0xFF, 0x00, 0x33, 0xFF, 0xD9}
+	truncated = []byte{0xFF, 0xE1, 0x00, 0x40, 'E', 'x'}
+)
+
+func TestStripJPEG(t *testing.T) {
+	tests := []struct {
+		name                string
+		input               []byte
+		expectedOutput      []byte
+		expectedOrientation int
+		expectedErr         error
+	}{
+		{
+			name:                "no metadata",
+			input:               concat(soi, jfif, quantize, scan),
+			expectedOutput:      concat(soi, jfif, quantize, scan),
+			expectedOrientation: 1,
+		},
+		{
+			name:                "exif xmp iptc and comment removed",
+			input:               concat(soi, jfif, jpegSegment(0xE1, exifPayload(binary.BigEndian, 0)), xmp, iptc, comment, quantize, scan),
+			expectedOutput:      concat(soi, jfif, quantize, scan),
+			expectedOrientation: 1,
+		},
+		{
+			name:                "orientation kept big endian",
+			input:               concat(soi, jpegSegment(0xE1, exifPayload(binary.BigEndian, 6)), xmp, quantize, scan),
+			expectedOutput:      concat(soi, orientationSegment(6), quantize, scan),
+			expectedOrientation: 6,
+		},
+		{
+			name:                "orientation kept little endian",
+			input:               concat(soi, jfif, jpegSegment(0xE1, exifPayload(binary.LittleEndian, 3)), quantize, scan),
+			expectedOutput:      concat(soi, orientationSegment(3), jfif, quantize, scan),
+			expectedOrientation: 3,
+		},
+		{
+			name:                "upright orientation needs no segment",
+			input:               concat(soi, jpegSegment(0xE1, exifPayload(binary.BigEndian, 1)), quantize, scan),
+			expectedOutput:      concat(soi, quantize, scan),
+			expectedOrientation: 1,
+		},
+		{
+			name:                "fill bytes before a marker",
+			input:               concat(soi, []byte{0xFF, 0xFF}, comment, quantize, scan),
+			expectedOutput:      concat(soi, quantize, scan),
+			expectedOrientation: 1,
+		},
+		{
+			name:                "exif with an ifd offset out of range",
+			input:               concat(soi, jpegSegment(0xE1, "Exif\x00\x00MM\x00\x2A\xFF\xFF\xFF\xF0"), quantize, scan),
+			expectedOutput:      concat(soi, quantize, scan),
+			expectedOrientation: 1,
+		},
+		{
+			name:                "exif with more entries than bytes",
+			input:               concat(soi, jpegSegment(0xE1, "Exif\x00\x00MM\x00\x2A\x00\x00\x00\x08\xFF\xFF\x01\x12"), quantize, scan),
+			expectedOutput:      concat(soi, quantize, scan),
+			expectedOrientation: 1,
+		},
+		{
+			name:        "not a jpeg",
+			input:       []byte("\x89PNG\r\n\x1a\n"),
+			expectedErr: ErrInvalidImage,
+		},
+		{
+			name:        "empty",
+			input:       nil,
+			expectedErr: ErrInvalidImage,
+		},
+		{
+			name:        "segment length beyond the data",
+			input:       concat(soi, truncated),
+			expectedErr: ErrInvalidImage,
+		},
+		{
+			name:        "segment length below its own size",
+			input:       concat(soi, []byte{0xFF, 0xE0, 0x00, 0x01}, scan),
+			expectedErr: ErrInvalidImage,
+		},
+		{
+			name:        "no start of scan",
+			input:       concat(soi, jfif, quantize),
+			expectedErr: ErrInvalidImage,
+		},
+		{
+			name:        "garbage between segments",
+			input:       concat(soi, jfif, []byte{0x00, 0x01, 0x02, 0x03}, scan),
+			expectedErr: ErrInvalidImage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, orientation, err := stripJPEG(tt.input)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedOutput, output)
+			assert.Equal(t, tt.expectedOrientation, orientation)
+			assert.NotContains(t, string(output), "secret")
+		})
+	}
+}
+
+// pngChunk builds a chunk, the checksum isn't verified by stripPNG and left zero
+func pngChunk(kind, data string) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, kind...)
+	chunk = append(chunk, data...)
+	return append(chunk, 0, 0, 0, 0)
+}
+
+func TestStripPNG(t *testing.T) {
+	signature := []byte("\x89PNG\r\n\x1a\n")
+	ihdr := pngChunk("IHDR", "\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00")
+	idat := pngChunk("IDAT", "pixels")
+	iend := pngChunk("IEND", "")
+	tests := []struct {
+		name           string
+		input          []byte
+		expectedOutput []byte
+		expectedErr    error
+	}{
+		{
+			name:           "no metadata",
+			input:          concat(signature, ihdr, idat, iend),
+			expectedOutput: concat(signature, ihdr, idat, iend),
+		},
+		{
+			name: "metadata chunks removed",
+			input: concat(signature, ihdr, pngChunk("tEXt", "Author\x00secret"), pngChunk("eXIf", "MM\x00\x2Asecret"),
+				pngChunk("zTXt", "secret"), pngChunk("iTXt", "XML:com.adobe.xmp\x00secret"), pngChunk("tIME", "secret"), idat, iend),
+			expectedOutput: concat(signature, ihdr, idat, iend),
+		},
+		{
+			name:        "not a png",
+			input:       concat(soi, scan),
+			expectedErr: ErrInvalidImage,
+		},
+		{
+			name:        "truncated chunk header",
+			input:       concat(signature, ihdr, []byte{0x00, 0x00, 0x00}),
+			expectedErr: ErrInvalidImage,
+		},
+		{
+			name:        "chunk length beyond the data",
+			input:       concat(signature, ihdr, []byte{0x00, 0x00, 0x10, 0x00, 'I', 'D', 'A', 'T', 0, 0, 0, 0}),
+			expectedErr: ErrInvalidImage,
+		},
+		{
+			name:        "oversized chunk length",
+			input:       concat(signature, ihdr, []byte{0xFF, 0xFF, 0xFF, 0xFF, 'I', 'D', 'A', 'T', 0, 0, 0, 0}),
+			expectedErr: ErrInvalidImage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := stripPNG(tt.input)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedOutput, output)
+		})
+	}
+}
+
+// webpChunk builds a chunk padded to an even size
+func webpChunk(fourcc, data string) []byte {
+	chunk := append([]byte(fourcc), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+	chunk = append(chunk, data...)
+	if len(data)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+func webpFile(chunks ...[]byte) []byte {
+	body := concat(chunks...)
+	file := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)+4))...)
+	file = append(file, "WEBP"...)
+	return append(file, body...)
+}
+
+func TestStripWebP(t *testing.T) {
+	vp8x := func(flags byte) []byte {
+		return webpChunk("VP8X", string([]byte{flags, 0, 0, 0, 0, 0, 0, 0, 0, 0}))
+	}
+	bitstream := webpChunk("VP8L", "odd")
+	tests := []struct {
+		name           string
+		input          []byte
+		expectedOutput []byte
+		expectedErr    error
+	}{
+		{
+			name:           "simple file",
+			input:          webpFile(bitstream),
+			expectedOutput: webpFile(bitstream),
+		},
+		{
+			name:           "exif and xmp removed with their flags",
+			input:          webpFile(vp8x(0x10|webpFlagEXIF|webpFlagXMP), bitstream, webpChunk("EXIF", "MM\x00\x2Asecret"), webpChunk("XMP ", "secret")),
+			expectedOutput: webpFile(vp8x(0x10), bitstream),
+		},
+		{
+			name:        "not a webp",
+			input:       []byte("RIFF\x04\x00\x00\x00WAVE"),
+			expectedErr: ErrInvalidImage,
+		},
+		{
+			name:        "truncated chunk header",
+			input:       append(webpFile(bitstream), 'E', 'X', 'I', 'F'),
+			expectedErr: ErrInvalidImage,
+		},
+		{
+			name:        "chunk size beyond the data",
+			input:       append(webpFile(bitstream), 'E', 'X', 'I', 'F', 0x00, 0x01, 0x00, 0x00),
+			expectedErr: ErrInvalidImage,
+		},
+		{
+			name:        "oversized chunk size",
+			input:       append(webpFile(bitstream), 'E', 'X', 'I', 'F', 0xFF, 0xFF, 0xFF, 0xFF),
+			expectedErr: ErrInvalidImage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := stripWebP(tt.input)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedOutput, output)
+			assert.NotContains(t, string(output), "secret")
+		})
+	}
+}
+
+func TestProcessKeepsOrientation(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 2; y++ {
+			img.Set(x, y, color.RGBA{R: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	encoded := buf.Bytes()
+	// orientation 6 turns the 4x2 pixels into a 2x4 upright image
+	input := concat(soi, jpegSegment(0xE1, exifPayload(binary.BigEndian, 6)), xmp, encoded[2:])
+
+	processed, err := Process(input)
+	require.NoError(t, err)
+	assert.Equal(t, "image/jpeg", processed.Original.ContentType)
+	assert.True(t, bytes.HasPrefix(processed.Original.Data, concat(soi, orientationSegment(6))))
+	assert.NotContains(t, string(processed.Original.Data), "xmpmeta")
+	for name, rendition := range processed.Renditions {
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(rendition.Data))
+		require.NoError(t, err, name)
+		assert.Equal(t, 2, cfg.Width, name)
+		assert.Equal(t, 4, cfg.Height, name)
+	}
+}
+
+func TestProcessRejects(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       []byte
+		expectedErr error
+	}{
+		{
+			name:        "unsupported type",
+			input:       []byte("GIF89a"),
+			expectedErr: ErrUnsupportedType,
+		},
+		{
+			name:        "too large",
+			input:       make([]byte, MaxSize+1),
+			expectedErr: ErrTooLarge,
+		},
+		{
+			name:        "broken jpeg",
+			input:       concat(soi, truncated),
+			expectedErr: ErrInvalidImage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Process(tt.input)
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
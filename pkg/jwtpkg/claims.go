@@ -8,4 +8,5 @@ type UserClaims struct {
 	Username string
 	Role     string
 	Email    string
+	TimeZone string `json:",omitempty"`
 }
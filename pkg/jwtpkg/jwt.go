@@ -42,6 +42,7 @@ func (j JWTService) GenerateAccessToken(user models.User) (string, error) {
 		Username: user.Username,
 		Role:     user.Role,
 		Email:    user.Email,
+		TimeZone: user.TimeZone,
 	}
 
 	AccessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -75,6 +76,7 @@ func (u JWTService) RegenerateToken(oldToken string) (string, error) {
 		Username: claims.Username,
 		Role:     claims.Role,
 		Email:    claims.Email,
+		TimeZone: claims.TimeZone,
 	}
 	return u.GenerateAccessToken(user)
 }
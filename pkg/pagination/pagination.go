@@ -0,0 +1,61 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Page is the envelope every list endpoint answers with. NextCursor is empty on the
+// last page, TotalEstimate comes from the planner and is not an exact count
+type Page[T any] struct {
+	Items         []T    `json:"items"`
+	NextCursor    string `json:"next_cursor,omitempty"`
+	TotalEstimate int64  `json:"total_estimate"`
+}
+
+// All wraps a complete list in the envelope. Lists that are short by nature aren't paged but
+// answer in the same shape as the paged ones
+func All[T any](items []T) Page[T] {
+	return Page[T]{Items: items, TotalEstimate: int64(len(items))}
+}
+
+// EncodeCursor turns the keyset position of the last returned row into an opaque token
+func EncodeCursor(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func DecodeCursor(cursor string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if json.Unmarshal(data, v) != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+// ParseLimit returns DefaultLimit for an empty value and caps the limit at MaxLimit
+func ParseLimit(s string) (int, error) {
+	if s == "" {
+		return DefaultLimit, nil
+	}
+	limit, err := strconv.Atoi(s)
+	if err != nil || limit <= 0 {
+		return 0, errors.New("invalid limit")
+	}
+	return min(limit, MaxLimit), nil
+}
@@ -0,0 +1,139 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCursor struct {
+	Start time.Time `json:"s"`
+	Id    int       `json:"i"`
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor testCursor
+	}{
+		{
+			name:   "zero",
+			cursor: testCursor{},
+		},
+		{
+			name:   "position",
+			cursor: testCursor{Start: time.Date(2025, 11, 20, 9, 30, 0, 0, time.UTC), Id: 42},
+		},
+		{
+			name:   "offset zone",
+			cursor: testCursor{Start: time.Date(2025, 3, 30, 2, 15, 0, 0, time.FixedZone("MSK", 3*60*60)), Id: 2147483647},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := EncodeCursor(tt.cursor)
+			require.NoError(t, err)
+			assert.NotContains(t, token, "=")
+			assert.NotContains(t, token, "/")
+			assert.NotContains(t, token, "+")
+			var decoded testCursor
+			require.NoError(t, DecodeCursor(token, &decoded))
+			assert.True(t, tt.cursor.Start.Equal(decoded.Start))
+			assert.Equal(t, tt.cursor.Id, decoded.Id)
+		})
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{
+			name:   "empty",
+			cursor: "",
+		},
+		{
+			name:   "not base64",
+			cursor: "not a cursor!",
+		},
+		{
+			name:   "padded base64",
+			cursor: "eyJpIjoxfQ==",
+		},
+		{
+			name:   "not json",
+			cursor: "bm90IGpzb24",
+		},
+		{
+			name:   "wrong field type",
+			cursor: "eyJpIjoiMSJ9",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var decoded testCursor
+			assert.ErrorIs(t, DecodeCursor(tt.cursor, &decoded), ErrInvalidCursor)
+		})
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expectedLimit int
+		expectedErr   bool
+	}{
+		{
+			name:          "default",
+			input:         "",
+			expectedLimit: DefaultLimit,
+		},
+		{
+			name:          "set",
+			input:         "10",
+			expectedLimit: 10,
+		},
+		{
+			name:          "capped",
+			input:         "1000",
+			expectedLimit: MaxLimit,
+		},
+		{
+			name:        "zero",
+			input:       "0",
+			expectedErr: true,
+		},
+		{
+			name:        "negative",
+			input:       "-5",
+			expectedErr: true,
+		},
+		{
+			name:        "not a number",
+			input:       "ten",
+			expectedErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, err := ParseLimit(tt.input)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedLimit, limit)
+		})
+	}
+}
+
+func TestAll(t *testing.T) {
+	page := All([]int{1, 2, 3})
+	assert.Equal(t, []int{1, 2, 3}, page.Items)
+	assert.Empty(t, page.NextCursor)
+	assert.Equal(t, int64(3), page.TotalEstimate)
+}